@@ -0,0 +1,89 @@
+// Package audit 安全审计：请求元数据在上下文中的传递与变更对比
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// 审计事件类型
+const (
+	ActionLogin           = "user.login"
+	ActionLoginFailed     = "user.login_failed"
+	ActionUserUpdate      = "user.update"
+	ActionPasswordReset   = "user.password_reset"
+	ActionSystemInit      = "system.init"
+	ActionSettingsUpdate  = "settings.update"
+	ActionMomentDelete    = "moment.delete"
+	ActionAPITokenCreate  = "api_token.create"
+	ActionAPITokenRevoke  = "api_token.revoke"
+	ActionShareCreate     = "share.create"
+	ActionShareRevoke     = "share.revoke"
+	ActionAuditLogsPurged = "audit.purge"
+	ActionIdentityLink    = "user.identity_link"
+	ActionIdentityUnlink  = "user.identity_unlink"
+)
+
+// Redacted 敏感字段在审计记录中的占位值
+const Redacted = "******"
+
+// Meta 请求元数据，由中间件写入请求上下文，供服务层记录审计日志
+type Meta struct {
+	RequestID string
+	IP        string
+	UserAgent string
+	UserID    uint64 // 认证通过后由认证中间件填充
+}
+
+type metaKey struct{}
+
+// WithMeta 将请求元数据写入上下文
+func WithMeta(ctx context.Context, meta *Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+// MetaFrom 从上下文读取请求元数据，非 HTTP 请求（如后台任务）返回空值
+func MetaFrom(ctx context.Context) *Meta {
+	if meta, ok := ctx.Value(metaKey{}).(*Meta); ok {
+		return meta
+	}
+	return &Meta{}
+}
+
+// Diff 对比变更前后的字段，只保留发生变化的字段
+// before 或 after 为 nil 时表示创建或删除，原样返回
+func Diff(before, after map[string]any) (map[string]any, map[string]any) {
+	if before == nil || after == nil {
+		return before, after
+	}
+
+	changedBefore := make(map[string]any)
+	changedAfter := make(map[string]any)
+	for key, value := range after {
+		old, ok := before[key]
+		if !ok || !reflect.DeepEqual(old, value) {
+			changedBefore[key] = old
+			changedAfter[key] = value
+		}
+	}
+	for key, old := range before {
+		if _, ok := after[key]; !ok {
+			changedBefore[key] = old
+			changedAfter[key] = nil
+		}
+	}
+	return changedBefore, changedAfter
+}
+
+// Encode 将快照编码为 JSON，空值返回空字符串
+func Encode(snapshot map[string]any) string {
+	if len(snapshot) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
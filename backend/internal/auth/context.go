@@ -1,12 +1,47 @@
 package auth
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 )
 
 type Claims struct {
-	UserID uint64
-	Role   string
+	UserID  uint64
+	Role    string
+	TokenID uint64 // 个人访问令牌ID，JWT 登录时为 0
+	Scope   string // 个人访问令牌权限范围，JWT 登录时为空（完整权限）
+	Version int    // 签发时用户的令牌版本，用户重置密码后旧版本的凭证失效
+}
+
+// IsAPIToken 是否通过个人访问令牌认证
+func (c *Claims) IsAPIToken() bool {
+	return c.TokenID != 0
+}
+
+// uploadRoutes 上传权限允许访问的路由，键为 “方法 路由模板”
+var uploadRoutes = map[string]bool{
+	http.MethodPost + " /api/v1/file/upload":       true, // 上传文件
+	http.MethodPost + " /api/v1/moments":           true, // 发布带图片的动态
+	http.MethodPost + " /api/v1/albums/:id/photos": true, // 添加照片到相册
+}
+
+// Allows 判断当前凭证是否允许访问指定接口，route 为 gin 的路由模板（c.FullPath()）
+func (c *Claims) Allows(method, route string) bool {
+	switch c.Scope {
+	case "", ScopeFull:
+		return true
+	case ScopeUpload:
+		return uploadRoutes[method+" "+route]
+	case ScopeRead:
+		return isReadMethod(method)
+	default:
+		return false
+	}
+}
+
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
 }
 
 type contextKey string
@@ -34,6 +34,7 @@ func (j *HS256JWT) Generate(claims *Claims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"uid": claims.UserID,
 		"rol": claims.Role,
+		"ver": claims.Version,
 		"iss": j.issuer,
 		"iat": now.Unix(),
 		"exp": now.Add(ttl).Unix(),
@@ -70,9 +71,12 @@ func (j *HS256JWT) Parse(tokenStr string) (*Claims, error) {
 	}
 
 	role, _ := mc["rol"].(string)
+	// 未携带版本的旧令牌按版本 0 处理
+	version, _ := mc["ver"].(float64)
 
 	return &Claims{
-		UserID: uint64(uid),
-		Role:   role,
+		UserID:  uint64(uid),
+		Role:    role,
+		Version: int(version),
 	}, nil
 }
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"testing"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+func TestJWTCarriesVersion(t *testing.T) {
+	j := NewHS256JWT("secret", "love-girl", 60)
+	token, err := j.Generate(&Claims{UserID: 7, Role: "user", Version: 3})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	claims, err := j.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if claims.UserID != 7 || claims.Role != "user" || claims.Version != 3 {
+		t.Fatalf("claims = %+v", claims)
+	}
+}
+
+// TestJWTWithoutVersion 升级前签发的令牌没有版本，按版本 0 处理
+func TestJWTWithoutVersion(t *testing.T) {
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"uid": 7, "rol": "user"}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	claims, err := NewHS256JWT("secret", "love-girl", 60).Parse(legacy)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if claims.Version != 0 {
+		t.Fatalf("Version = %d, want 0", claims.Version)
+	}
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTooManyTickets = errors.New("too many pending tickets")
+	ErrInvalidTicket  = errors.New("invalid or expired ticket")
+)
+
+// TicketStore 一次性短期票据
+// 浏览器的 EventSource 和 WebSocket 无法设置请求头，长连接改为在查询参数中携带票据，
+// 避免访问令牌出现在 URL 和访问日志中；票据兑换一次后即失效
+type TicketStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	max     int
+	tickets map[string]ticketEntry
+}
+
+type ticketEntry struct {
+	claims    Claims
+	expiresAt time.Time
+}
+
+// NewTicketStore 创建票据存储，ttl 为票据有效期，max 为同时有效的票据数上限
+func NewTicketStore(ttl time.Duration, max int) *TicketStore {
+	return &TicketStore{
+		ttl:     ttl,
+		max:     max,
+		tickets: make(map[string]ticketEntry),
+	}
+}
+
+// TTL 票据有效期
+func (s *TicketStore) TTL() time.Duration {
+	return s.ttl
+}
+
+// Issue 为当前凭证签发票据
+func (s *TicketStore) Issue(claims *Claims) (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := cryptorand.Read(bytes); err != nil {
+		return "", err
+	}
+	ticket := base64.RawURLEncoding.EncodeToString(bytes)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if len(s.tickets) >= s.max {
+		s.prune(now)
+		if len(s.tickets) >= s.max {
+			return "", ErrTooManyTickets
+		}
+	}
+	s.tickets[ticket] = ticketEntry{claims: *claims, expiresAt: now.Add(s.ttl)}
+	return ticket, nil
+}
+
+// Redeem 兑换票据，票据不存在、已使用或已过期时返回 false
+func (s *TicketStore) Redeem(ticket string) (*Claims, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.tickets[ticket]
+	if !ok {
+		return nil, false
+	}
+	delete(s.tickets, ticket)
+	if time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	claims := entry.claims
+	return &claims, true
+}
+
+// prune 清理过期票据，调用方需持有 mu
+func (s *TicketStore) prune(now time.Time) {
+	for ticket, entry := range s.tickets {
+		if now.After(entry.expiresAt) {
+			delete(s.tickets, ticket)
+		}
+	}
+}
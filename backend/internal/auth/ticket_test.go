@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTicketIsSingleUse(t *testing.T) {
+	store := NewTicketStore(time.Minute, 10)
+	ticket, err := store.Issue(&Claims{UserID: 7, Scope: ScopeRead})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	claims, ok := store.Redeem(ticket)
+	if !ok || claims.UserID != 7 || claims.Scope != ScopeRead {
+		t.Fatalf("Redeem = %+v, %v", claims, ok)
+	}
+	if _, ok := store.Redeem(ticket); ok {
+		t.Fatal("票据只能兑换一次")
+	}
+	if _, ok := store.Redeem("unknown"); ok {
+		t.Fatal("未知票据不能兑换")
+	}
+}
+
+func TestTicketExpires(t *testing.T) {
+	store := NewTicketStore(time.Millisecond, 10)
+	ticket, _ := store.Issue(&Claims{UserID: 1})
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := store.Redeem(ticket); ok {
+		t.Fatal("过期票据不能兑换")
+	}
+}
+
+func TestTicketStoreLimit(t *testing.T) {
+	store := NewTicketStore(time.Millisecond, 2)
+	for i := 0; i < 2; i++ {
+		if _, err := store.Issue(&Claims{UserID: 1}); err != nil {
+			t.Fatalf("Issue: %v", err)
+		}
+	}
+	// 写满后先清理过期票据
+	time.Sleep(5 * time.Millisecond)
+	if _, err := store.Issue(&Claims{UserID: 1}); err != nil {
+		t.Fatalf("过期票据应被清理: %v", err)
+	}
+
+	store = NewTicketStore(time.Minute, 1)
+	_, _ = store.Issue(&Claims{UserID: 1})
+	if _, err := store.Issue(&Claims{UserID: 1}); !errors.Is(err, ErrTooManyTickets) {
+		t.Fatalf("err = %v, want ErrTooManyTickets", err)
+	}
+}
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// APITokenPrefix 个人访问令牌前缀，用于和 JWT 区分
+const APITokenPrefix = "lgp_"
+
+// 个人访问令牌权限范围
+const (
+	ScopeRead   = "read"   // 只读：仅允许 GET/HEAD/OPTIONS
+	ScopeUpload = "upload" // 上传：仅允许上传文件、发布动态、添加照片到相册，其余接口一律拒绝
+	ScopeFull   = "full"   // 完整权限
+)
+
+// TokenVerifier 校验个人访问令牌和登录令牌的版本
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (*Claims, error)
+	// VerifyVersion 校验凭证的令牌版本与用户当前版本一致，用户重置密码后此前签发的凭证失效
+	VerifyVersion(ctx context.Context, userID uint64, version int) error
+}
+
+// IsAPIToken 判断令牌字符串是否为个人访问令牌
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// HashAPIToken 计算个人访问令牌的哈希，数据库只保存哈希值
+// 令牌本身为高熵随机串，使用 SHA-256 即可，无需 bcrypt 加盐
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
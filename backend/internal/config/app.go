@@ -10,6 +10,12 @@ type AppConfig struct {
 	JWT        JWTConfig        `mapstructure:"jwt"`
 	Storage    StorageConfig    `mapstructure:"storage" validate:"required"`
 	ImageProxy ImageProxyConfig `mapstructure:"image_proxy"`
+	Mail       MailConfig       `mapstructure:"mail"`
+	OIDC       OIDCConfig       `mapstructure:"oidc"`
+	Search     SearchConfig     `mapstructure:"search"`
+	Comment    CommentConfig    `mapstructure:"comment"`
+	Realtime   RealtimeConfig   `mapstructure:"realtime"`
+	Notify     NotifyConfig     `mapstructure:"notify"`
 }
 
 // DataPaths 数据目录路径（运行时计算）
@@ -124,3 +130,92 @@ type WebDAVStorageConfig struct {
 		Password string `mapstructure:"password"`
 	} `mapstructure:"auth"`
 }
+
+// MailConfig 邮件发送配置，Host 为空时不发送邮件
+type MailConfig struct {
+	Host       string `mapstructure:"host"`
+	Port       int    `mapstructure:"port"`
+	Username   string `mapstructure:"username"`
+	Password   string `mapstructure:"password"`
+	From       string `mapstructure:"from"`                                                         // 发件人地址，未配置时使用 Username
+	FromName   string `mapstructure:"from_name"`                                                    // 发件人名称
+	Encryption string `mapstructure:"encryption" validate:"omitempty,oneof=auto none starttls tls"` // auto: 服务器支持时使用 STARTTLS
+	SiteURL    string `mapstructure:"site_url"`                                                     // 站点公开地址，用于生成邮件和订阅源中的链接，找回密码和通知摘要必须配置
+	QueueSize  int    `mapstructure:"queue_size"`                                                   // 发送队列长度
+	MaxRetries int    `mapstructure:"max_retries"`                                                  // 发送失败最大重试次数
+}
+
+// Enabled 是否已配置邮件服务
+func (m *MailConfig) Enabled() bool {
+	return m.Host != ""
+}
+
+// OIDCConfig OpenID Connect 登录配置，Issuer 为空时不启用
+type OIDCConfig struct {
+	Issuer           string   `mapstructure:"issuer"`             // 身份提供方地址，用于自动发现（/.well-known/openid-configuration）
+	ClientID         string   `mapstructure:"client_id"`          // 客户端ID
+	ClientSecret     string   `mapstructure:"client_secret"`      // 客户端密钥，公共客户端可为空（仅依赖 PKCE）
+	RedirectURL      string   `mapstructure:"redirect_url"`       // 回调地址，需与身份提供方登记的一致，如 https://example.com/api/v1/auth/oidc/callback
+	Scopes           []string `mapstructure:"scopes"`             // 申请的 scope，必须包含 openid
+	ProviderName     string   `mapstructure:"provider_name"`      // 登录按钮上显示的名称
+	AutoLinkByEmail  bool     `mapstructure:"auto_link_by_email"` // 首次登录时按已验证邮箱自动关联本站用户
+	FrontendRedirect string   `mapstructure:"frontend_redirect"`  // 登录完成后跳转的前端页面，令牌通过 URL 片段传递
+}
+
+// Enabled 是否已配置 OIDC 登录
+func (o *OIDCConfig) Enabled() bool {
+	return o.Issuer != "" && o.ClientID != ""
+}
+
+// SearchConfig 全文检索配置
+type SearchConfig struct {
+	Backend string `mapstructure:"backend" validate:"omitempty,oneof=auto database memory"` // auto: 优先数据库全文索引，不可用时使用内存索引
+}
+
+// CommentConfig 评论配置
+type CommentConfig struct {
+	GuestEnabled   bool `mapstructure:"guest_enabled"`                                    // 是否允许访客评论公开动态
+	PowDifficulty  int  `mapstructure:"pow_difficulty" validate:"omitempty,min=8,max=28"` // 访客评论工作量证明难度（哈希前导零比特数）
+	GuestRateLimit int  `mapstructure:"guest_rate_limit" validate:"omitempty,min=1"`      // 每个 IP 每小时最多提交的访客评论数
+
+	Moderation ModerationConfig `mapstructure:"moderation"`
+}
+
+// ModerationConfig 评论内容审核配置，各检查器给出 0 到 1 的风险分，取最高分与阈值比较
+type ModerationConfig struct {
+	Enabled         bool     `mapstructure:"enabled"`                                                                 // 是否在保存评论前审核内容
+	BannedWords     []string `mapstructure:"banned_words"`                                                            // 屏蔽词，命中即判定为垃圾评论
+	MaxLinks        int      `mapstructure:"max_links" validate:"min=0"`                                              // 允许的最大链接数，超出需要审核
+	DuplicateWindow int64    `mapstructure:"duplicate_window" validate:"omitempty,min=1"`                             // 重复内容检测的时间窗口（秒）
+	SpamThreshold   float64  `mapstructure:"spam_threshold" validate:"omitempty,gt=0,lte=1"`                          // 风险分不低于该值判定为垃圾评论
+	ReviewThreshold float64  `mapstructure:"review_threshold" validate:"omitempty,gt=0,lte=1,ltefield=SpamThreshold"` // 风险分不低于该值需要人工审核
+}
+
+// RealtimeConfig 实时推送配置（SSE / WebSocket）
+type RealtimeConfig struct {
+	MaxConnections int `mapstructure:"max_connections" validate:"omitempty,min=1"` // 每个用户的最大连接数
+	Heartbeat      int `mapstructure:"heartbeat" validate:"omitempty,min=5"`       // 心跳间隔（秒），需小于反向代理的空闲超时
+	BufferSize     int `mapstructure:"buffer_size" validate:"omitempty,min=1"`     // 每个用户保留用于断线续传的最近事件数
+}
+
+// NotifyConfig 站外通知渠道配置，投递失败按指数退避重试
+type NotifyConfig struct {
+	MaxAttempts int   `mapstructure:"max_attempts" validate:"omitempty,min=1"` // 每条投递的最大尝试次数
+	RetryDelay  int64 `mapstructure:"retry_delay" validate:"omitempty,min=1"`  // 首次重试间隔（秒），之后每次翻倍
+	Timeout     int64 `mapstructure:"timeout" validate:"omitempty,min=1"`      // 单次投递超时（秒）
+
+	Telegram TelegramConfig `mapstructure:"telegram"`
+	WebPush  WebPushConfig  `mapstructure:"webpush"`
+}
+
+// TelegramConfig Telegram 机器人配置，BotToken 为空时不启用
+type TelegramConfig struct {
+	BotToken string `mapstructure:"bot_token"` // 通过 @BotFather 创建机器人获得的令牌
+	APIURL   string `mapstructure:"api_url"`   // Bot API 地址，默认 https://api.telegram.org
+}
+
+// WebPushConfig 浏览器推送配置
+type WebPushConfig struct {
+	VAPIDPrivateKey string `mapstructure:"vapid_private_key"` // base64url 编码的 P-256 私钥，未配置时自动生成
+	Subject         string `mapstructure:"subject"`           // 推送服务联系站点时使用的 mailto: 或 https: 地址
+}
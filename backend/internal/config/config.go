@@ -1,7 +1,9 @@
 package config
 
 import (
+	"crypto/ecdh"
 	cryptorand "crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"os"
@@ -106,6 +108,7 @@ func LoadConfig() (*AppConfig, error) {
 
 		// 自动生成 JWT Secret（如果未配置）并持久化
 		applyJWTDefaults(&c, v)
+		applyWebPushDefaults(&c, v)
 
 		if e := validate.Struct(&c); e != nil {
 			err = e
@@ -174,6 +177,81 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("image_proxy.internal_url", "")
 	v.SetDefault("image_proxy.public_url", "")
 
+	// 邮件：默认未配置 host，不发送邮件
+	v.SetDefault("mail.port", 25)
+	v.SetDefault("mail.encryption", "auto")
+	v.SetDefault("mail.queue_size", 100)
+	v.SetDefault("mail.max_retries", 3)
+	_ = v.BindEnv("mail.host", "MAIL_HOST")
+	_ = v.BindEnv("mail.port", "MAIL_PORT")
+	_ = v.BindEnv("mail.username", "MAIL_USERNAME")
+	_ = v.BindEnv("mail.password", "MAIL_PASSWORD")
+	_ = v.BindEnv("mail.from", "MAIL_FROM")
+	_ = v.BindEnv("mail.from_name", "MAIL_FROM_NAME")
+	_ = v.BindEnv("mail.encryption", "MAIL_ENCRYPTION")
+	_ = v.BindEnv("mail.site_url", "MAIL_SITE_URL")
+
+	// OIDC：默认未配置 issuer，不启用
+	v.SetDefault("oidc.scopes", []string{"openid", "profile", "email"})
+	v.SetDefault("oidc.provider_name", "OpenID Connect")
+	v.SetDefault("oidc.auto_link_by_email", false)
+	v.SetDefault("oidc.frontend_redirect", "/admin/login")
+	_ = v.BindEnv("oidc.issuer", "OIDC_ISSUER")
+	_ = v.BindEnv("oidc.client_id", "OIDC_CLIENT_ID")
+	_ = v.BindEnv("oidc.client_secret", "OIDC_CLIENT_SECRET")
+	_ = v.BindEnv("oidc.redirect_url", "OIDC_REDIRECT_URL")
+	_ = v.BindEnv("oidc.provider_name", "OIDC_PROVIDER_NAME")
+	_ = v.BindEnv("oidc.auto_link_by_email", "OIDC_AUTO_LINK_BY_EMAIL")
+	_ = v.BindEnv("oidc.frontend_redirect", "OIDC_FRONTEND_REDIRECT")
+
+	v.SetDefault("search.backend", "auto")
+	_ = v.BindEnv("search.backend", "SEARCH_BACKEND")
+
+	// 访客评论：默认开启，需要完成工作量证明并进入审核队列
+	v.SetDefault("comment.guest_enabled", true)
+	v.SetDefault("comment.pow_difficulty", 18)
+	v.SetDefault("comment.guest_rate_limit", 10)
+	_ = v.BindEnv("comment.guest_enabled", "COMMENT_GUEST_ENABLED")
+	_ = v.BindEnv("comment.pow_difficulty", "COMMENT_POW_DIFFICULTY")
+	_ = v.BindEnv("comment.guest_rate_limit", "COMMENT_GUEST_RATE_LIMIT")
+
+	// 评论内容审核：屏蔽词默认为空，环境变量中多个屏蔽词用逗号分隔
+	v.SetDefault("comment.moderation.enabled", true)
+	v.SetDefault("comment.moderation.banned_words", []string{})
+	v.SetDefault("comment.moderation.max_links", 2)
+	v.SetDefault("comment.moderation.duplicate_window", 86400)
+	v.SetDefault("comment.moderation.spam_threshold", 0.9)
+	v.SetDefault("comment.moderation.review_threshold", 0.6)
+	_ = v.BindEnv("comment.moderation.enabled", "COMMENT_MODERATION_ENABLED")
+	_ = v.BindEnv("comment.moderation.banned_words", "COMMENT_BANNED_WORDS")
+	_ = v.BindEnv("comment.moderation.max_links", "COMMENT_MAX_LINKS")
+	_ = v.BindEnv("comment.moderation.duplicate_window", "COMMENT_DUPLICATE_WINDOW")
+	_ = v.BindEnv("comment.moderation.spam_threshold", "COMMENT_SPAM_THRESHOLD")
+	_ = v.BindEnv("comment.moderation.review_threshold", "COMMENT_REVIEW_THRESHOLD")
+
+	v.SetDefault("realtime.max_connections", 5)
+	v.SetDefault("realtime.heartbeat", 25)
+	v.SetDefault("realtime.buffer_size", 100)
+	_ = v.BindEnv("realtime.max_connections", "REALTIME_MAX_CONNECTIONS")
+	_ = v.BindEnv("realtime.heartbeat", "REALTIME_HEARTBEAT")
+	_ = v.BindEnv("realtime.buffer_size", "REALTIME_BUFFER_SIZE")
+
+	// 站外通知渠道
+	v.SetDefault("notify.max_attempts", 6)
+	v.SetDefault("notify.retry_delay", 30)
+	v.SetDefault("notify.timeout", 15)
+	v.SetDefault("notify.telegram.bot_token", "")
+	v.SetDefault("notify.telegram.api_url", "https://api.telegram.org")
+	v.SetDefault("notify.webpush.vapid_private_key", "")
+	v.SetDefault("notify.webpush.subject", "")
+	_ = v.BindEnv("notify.max_attempts", "NOTIFY_MAX_ATTEMPTS")
+	_ = v.BindEnv("notify.retry_delay", "NOTIFY_RETRY_DELAY")
+	_ = v.BindEnv("notify.timeout", "NOTIFY_TIMEOUT")
+	_ = v.BindEnv("notify.telegram.bot_token", "NOTIFY_TELEGRAM_BOT_TOKEN")
+	_ = v.BindEnv("notify.telegram.api_url", "NOTIFY_TELEGRAM_API_URL")
+	_ = v.BindEnv("notify.webpush.vapid_private_key", "NOTIFY_WEBPUSH_VAPID_PRIVATE_KEY")
+	_ = v.BindEnv("notify.webpush.subject", "NOTIFY_WEBPUSH_SUBJECT")
+
 	// 环境变量绑定
 	_ = v.BindEnv("data_dir", "DATA_DIR")
 	_ = v.BindEnv("datasource.database.driver", "DATABASE_DRIVER")
@@ -307,6 +385,28 @@ func applyJWTDefaults(cfg *AppConfig, v *viper.Viper) {
 	}
 }
 
+// applyWebPushDefaults 自动生成 VAPID 私钥（如果未配置）并持久化到配置文件
+// 浏览器的推送订阅与公钥绑定，更换密钥后已有订阅全部失效，因此只生成一次
+func applyWebPushDefaults(cfg *AppConfig, v *viper.Viper) {
+	if cfg.Notify.WebPush.VAPIDPrivateKey != "" {
+		return
+	}
+	key, err := ecdh.P256().GenerateKey(cryptorand.Reader)
+	if err != nil {
+		return
+	}
+	cfg.Notify.WebPush.VAPIDPrivateKey = base64.RawURLEncoding.EncodeToString(key.Bytes())
+	v.Set("notify.webpush.vapid_private_key", cfg.Notify.WebPush.VAPIDPrivateKey)
+
+	// 只写入私钥，避免把环境变量中的其他密钥写入配置文件
+	configPath := cfg.GetDataPaths().ConfigDir + "/config.yaml"
+	fileViper := viper.New()
+	fileViper.SetConfigFile(configPath)
+	_ = fileViper.ReadInConfig()
+	fileViper.Set("notify.webpush.vapid_private_key", cfg.Notify.WebPush.VAPIDPrivateKey)
+	_ = fileViper.WriteConfigAs(configPath)
+}
+
 // ApplyEnvPolicy 根据环境设置运行策略
 func ApplyEnvPolicy(cfg *AppConfig) {
 	switch cfg.App.Env {
@@ -0,0 +1,5 @@
+package error
+
+import "errors"
+
+var ErrInvalidAnniversaryDate = errors.New("invalid anniversary date")
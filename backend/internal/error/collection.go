@@ -0,0 +1,8 @@
+package error
+
+import "errors"
+
+var (
+	ErrCollectionMomentNotFound = errors.New("collection moment not found or not visible")
+	ErrCollectionCoverNotFound  = errors.New("collection cover image not found")
+)
@@ -0,0 +1,11 @@
+package error
+
+import "errors"
+
+var (
+	ErrGuestCommentDisabled = errors.New("guest comments are disabled")
+	ErrGuestChallengeFailed = errors.New("guest challenge verification failed")
+	ErrCommentHasReplies    = errors.New("comment has replies")
+	ErrCommentEditRejected  = errors.New("edited comment failed moderation")
+	ErrInvalidCommentCursor = errors.New("invalid comment cursor")
+)
@@ -0,0 +1,13 @@
+package error
+
+import "errors"
+
+var (
+	ErrMomentPublishAtInvalid    = errors.New("invalid moment publish time")
+	ErrMomentPublishAtRequired   = errors.New("moment publish time required")
+	ErrMomentPublishAtPast       = errors.New("moment publish time must be in the future")
+	ErrMomentRevisionNotFound    = errors.New("moment revision not found")
+	ErrMomentPlaceNotFound       = errors.New("linked place not found")
+	ErrMomentAnniversaryNotFound = errors.New("linked anniversary not found")
+	ErrMomentPinUnpublished      = errors.New("only published moments can be pinned")
+)
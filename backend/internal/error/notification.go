@@ -0,0 +1,14 @@
+package error
+
+import "errors"
+
+var (
+	ErrNotificationChannelNotFound    = errors.New("notification channel not found")
+	ErrNotificationChannelUnavailable = errors.New("notification channel is not configured")
+	ErrInvalidNotificationTarget      = errors.New("invalid notification target")
+	ErrInvalidNotificationType        = errors.New("invalid notification type")
+	ErrInvalidDigestSchedule          = errors.New("invalid digest schedule")
+	ErrInvalidUnsubscribeToken        = errors.New("invalid unsubscribe token")
+	ErrDigestEmailRequired            = errors.New("account email is required for digest")
+	ErrDigestSiteURLRequired          = errors.New("site url is required for digest")
+)
@@ -0,0 +1,13 @@
+package error
+
+import "errors"
+
+var (
+	ErrOIDCDisabled       = errors.New("oidc login is not enabled")
+	ErrOIDCInvalidState   = errors.New("invalid oidc state")
+	ErrOIDCNotLinked      = errors.New("external identity is not linked to any user")
+	ErrOIDCAlreadyLinked  = errors.New("external identity is linked to another user")
+	ErrOIDCEmailMismatch  = errors.New("no user matches the verified email")
+	ErrOIDCProviderFailed = errors.New("identity provider error")
+	ErrOIDCTooManyFlows   = errors.New("too many pending oidc logins")
+)
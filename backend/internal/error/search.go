@@ -0,0 +1,5 @@
+package error
+
+import "errors"
+
+var ErrSearchQueryEmpty = errors.New("search query is empty")
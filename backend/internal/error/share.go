@@ -0,0 +1,14 @@
+package error
+
+import "errors"
+
+var (
+	ErrShareNotFound         = errors.New("share not found")
+	ErrShareRevoked          = errors.New("share revoked")
+	ErrShareExpired          = errors.New("share expired")
+	ErrShareExhausted        = errors.New("share view limit reached")
+	ErrSharePasswordRequired = errors.New("share password required")
+	ErrSharePasswordInvalid  = errors.New("share password invalid")
+	ErrShareFileForbidden    = errors.New("file not in share")
+	ErrSharePageInvalid      = errors.New("share page out of range")
+)
@@ -8,4 +8,5 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrEncrypt            = errors.New("encrypt error")
 	ErrDecrypt            = errors.New("decrypt error")
+	ErrInvalidResetToken  = errors.New("invalid password reset token")
 )
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/bookandmusic/love-girl/internal/auth"
 	middle "github.com/bookandmusic/love-girl/internal/middleware"
 	"github.com/bookandmusic/love-girl/internal/server"
 	"github.com/bookandmusic/love-girl/internal/service"
@@ -341,8 +342,10 @@ func (h *AlbumHandler) AddPhotosToAlbum(c *gin.Context) {
 		return
 	}
 
+	claims := auth.MustGetAuthClaims(c)
+
 	// 调用服务层添加照片到相册
-	photos, err := h.AlbumService.AddPhotosToAlbum(c, id, &req)
+	photos, err := h.AlbumService.AddPhotosToAlbum(c, id, claims.UserID, &req)
 	if err != nil {
 		h.AlbumService.Log.Error("添加照片到相册失败", "albumId", id, "error", err, "request", req)
 		c.JSON(http.StatusInternalServerError, Response{
@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
 	middle "github.com/bookandmusic/love-girl/internal/middleware"
 	"github.com/bookandmusic/love-girl/internal/server"
 	"github.com/bookandmusic/love-girl/internal/service"
@@ -41,7 +43,7 @@ func (h *AnniversaryHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *s
 
 // ListAnniversaries 获取纪念日列表
 // @Summary 获取纪念日列表
-// @Description 分页获取纪念日列表，支持排序和过滤
+// @Description 分页获取纪念日列表，支持排序和过滤；每条纪念日包含下一次的公历日期、剩余天数和已满周年数，农历纪念日自动换算
 // @Tags anniversaries
 // @Produce json
 // @Param page query int false "页码" default(1)
@@ -83,7 +85,7 @@ func (h *AnniversaryHandler) ListAnniversaries(c *gin.Context) {
 
 // CreateAnniversary 创建纪念日
 // @Summary 创建纪念日
-// @Description 创建一个新的纪念日
+// @Description 创建一个新的纪念日，农历纪念日的 date 为农历年月日（1900–2100 年），闰月时 leapMonth 为 true
 // @Tags anniversaries
 // @Accept json
 // @Produce json
@@ -110,6 +112,15 @@ func (h *AnniversaryHandler) CreateAnniversary(c *gin.Context) {
 	// 调用服务层创建纪念日
 	anniversary, err := h.AnniversaryService.CreateAnniversary(ctx, &req)
 	if err != nil {
+		if errors.Is(err, errMsg.ErrInvalidAnniversaryDate) {
+			c.JSON(http.StatusBadRequest, Response{
+				Code:    1,
+				Message: "日期无效",
+				Data:    nil,
+			})
+			return
+		}
+
 		h.AnniversaryService.Log.Error("创建纪念日失败", "error", err)
 		c.JSON(http.StatusInternalServerError, Response{
 			Code:    1,
@@ -177,6 +188,14 @@ func (h *AnniversaryHandler) UpdateAnniversary(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, errMsg.ErrInvalidAnniversaryDate) {
+			c.JSON(http.StatusBadRequest, Response{
+				Code:    1,
+				Message: "日期无效",
+				Data:    nil,
+			})
+			return
+		}
 
 		h.AnniversaryService.Log.Error("更新纪念日失败", "error", err)
 		c.JSON(http.StatusInternalServerError, Response{
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+	middle "github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/server"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+type APITokenHandler struct {
+	APITokenService *service.APITokenService
+}
+
+func NewAPITokenHandler(apiTokenService *service.APITokenService) *APITokenHandler {
+	return &APITokenHandler{
+		APITokenService: apiTokenService,
+	}
+}
+
+// RegisterRoutes 注册个人访问令牌相关的路由
+func (h *APITokenHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *server.GinEngine, authMiddleware *middle.AuthMiddleware) {
+	authGroup := apiGroup.Group("/tokens")
+	authGroup.Use(authMiddleware.Handle(), h.requireLogin)
+	{
+		authGroup.GET("", h.ListTokens)         // 令牌列表
+		authGroup.POST("", h.CreateToken)       // 创建令牌
+		authGroup.DELETE("/:id", h.RevokeToken) // 撤销令牌
+	}
+}
+
+// requireLogin 令牌管理只允许通过密码登录的会话操作，防止令牌自我复制
+func (h *APITokenHandler) requireLogin(c *gin.Context) {
+	if auth.MustGetAuthClaims(c).IsAPIToken() {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response{
+			Code:    1,
+			Message: "访问令牌无权管理令牌",
+			Data:    nil,
+		})
+		return
+	}
+	c.Next()
+}
+
+// ListTokens 获取个人访问令牌列表
+// @Summary 获取个人访问令牌列表
+// @Description 获取当前用户的全部个人访问令牌，不包含令牌明文
+// @Tags tokens
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {object} Response{data=[]service.FrontendAPIToken}
+// @Failure 500 {object} Response
+// @Router /tokens [get]
+func (h *APITokenHandler) ListTokens(c *gin.Context) {
+	claims := auth.MustGetAuthClaims(c)
+
+	tokens, err := h.APITokenService.ListTokens(c.Request.Context(), claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    tokens,
+	})
+}
+
+// CreateToken 创建个人访问令牌
+// @Summary 创建个人访问令牌
+// @Description 创建长期有效的个人访问令牌，权限范围为 read/upload/full，令牌明文仅在本次响应中返回
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param token body service.APITokenCreateRequest true "令牌信息"
+// @Success 200 {object} Response{data=service.FrontendAPIToken}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /tokens [post]
+func (h *APITokenHandler) CreateToken(c *gin.Context) {
+	var req service.APITokenCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.APITokenService.Log.Error("参数校验失败", "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "参数校验失败",
+			Data:    nil,
+		})
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+
+	token, err := h.APITokenService.CreateToken(c.Request.Context(), claims.UserID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "创建成功",
+		Data:    token,
+	})
+}
+
+// RevokeToken 撤销个人访问令牌
+// @Summary 撤销个人访问令牌
+// @Description 撤销后令牌立即失效
+// @Tags tokens
+// @Produce json
+// @Security OAuth2Password
+// @Param id path int true "令牌ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /tokens/{id} [delete]
+func (h *APITokenHandler) RevokeToken(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.APITokenService.Log.Error("无效的令牌ID", "id", idStr, "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的令牌ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+
+	revoked, err := h.APITokenService.RevokeToken(c.Request.Context(), claims.UserID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+
+	if !revoked {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "令牌不存在",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "撤销成功",
+		Data:    nil,
+	})
+}
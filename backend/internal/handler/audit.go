@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	middle "github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/server"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+type AuditHandler struct {
+	AuditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		AuditService: auditService,
+	}
+}
+
+// RegisterRoutes 注册审计日志相关的路由
+func (h *AuditHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *server.GinEngine, authMiddleware *middle.AuthMiddleware) {
+	authGroup := apiGroup.Group("")
+	authGroup.Use(authMiddleware.Handle())
+	{
+		authGroup.GET("/audit-logs", h.ListAuditLogs)
+	}
+}
+
+// ListAuditLogs 获取审计日志列表
+// @Summary 获取审计日志列表
+// @Description 分页获取安全审计日志，按时间倒序，支持按事件、操作人、对象、IP和时间过滤
+// @Tags audit
+// @Produce json
+// @Security OAuth2Password
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param filter query []string false "过滤条件，格式: field:op:value，如 action:eq:user.login、created_at:gte:2026-01-01" collectionFormat(multi)
+// @Success 200 {object} Response{data=service.AuditLogListResponse}
+// @Failure 500 {object} Response
+// @Router /audit-logs [get]
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	queryParams := ParseQueryParams(c, "audit_logs")
+
+	logs, err := h.AuditService.ListLogs(c.Request.Context(), &service.AuditQueryParams{
+		Page:    queryParams.Page,
+		Size:    queryParams.Size,
+		Filters: queryParams.Filters,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    logs,
+	})
+}
@@ -0,0 +1,461 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	middle "github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/server"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+type CollectionHandler struct {
+	CollectionService *service.CollectionService
+}
+
+func NewCollectionHandler(collectionService *service.CollectionService) *CollectionHandler {
+	return &CollectionHandler{
+		CollectionService: collectionService,
+	}
+}
+
+// RegisterRoutes 注册动态合集相关的路由
+func (h *CollectionHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *server.GinEngine, authMiddleware *middle.AuthMiddleware) {
+	collectionGroup := apiGroup.Group("/collections")
+	{
+		// 公开路由：合集内的动态按访问者的可见范围过滤
+		collectionGroup.GET("", authMiddleware.Optional(), h.ListCollections)                   // 获取合集列表
+		collectionGroup.GET("/:id", authMiddleware.Optional(), h.GetCollection)                 // 获取合集详情
+		collectionGroup.GET("/:id/moments", authMiddleware.Optional(), h.ListCollectionMoments) // 获取合集内的动态
+
+		// 需要认证的路由
+		authGroup := collectionGroup.Group("")
+		authGroup.Use(authMiddleware.Handle())
+		{
+			authGroup.POST("", h.CreateCollection)                               // 创建合集
+			authGroup.PUT("/:id", h.UpdateCollection)                            // 更新合集
+			authGroup.DELETE("/:id", h.DeleteCollection)                         // 删除合集
+			authGroup.PUT("/:id/moments", h.SetCollectionMoments)                // 设置合集动态及顺序
+			authGroup.POST("/:id/moments", h.AppendCollectionMoments)            // 追加动态到合集
+			authGroup.DELETE("/:id/moments/:momentId", h.RemoveCollectionMoment) // 从合集移除动态
+		}
+	}
+}
+
+// optionalUserID 当前访问者的用户ID，未登录时为 0
+func optionalUserID(c *gin.Context) uint64 {
+	if claims, ok := auth.GetAuthClaims(c); ok {
+		return claims.UserID
+	}
+	return 0
+}
+
+// parseCollectionID 解析路径中的合集ID，失败时直接返回 400
+func parseCollectionID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的合集ID",
+			Data:    nil,
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondCollectionError 将合集相关的错误转换为响应
+func (h *CollectionHandler) respondCollectionError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, errMsg.ErrCollectionMomentNotFound):
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "动态不存在或不可见",
+			Data:    nil,
+		})
+	case errors.Is(err, errMsg.ErrCollectionCoverNotFound):
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "封面图片不存在",
+			Data:    nil,
+		})
+	default:
+		h.CollectionService.Log.Error(action, "error", err)
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+	}
+}
+
+func respondCollectionNotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, Response{
+		Code:    1,
+		Message: "合集不存在",
+		Data:    nil,
+	})
+}
+
+// ListCollections 获取合集列表
+// @Summary 获取合集列表
+// @Description 获取动态合集列表，支持分页、排序和过滤。动态数量和默认封面按访问者的可见范围计算
+// @Tags collections
+// @Produce json
+// @Param page query int false "页码，默认1"
+// @Param size query int false "每页数量，默认10"
+// @Param sort_by query string false "排序字段 (created_at, name)"
+// @Param order query string false "排序方向 (asc, desc)" default(desc)
+// @Param filter query []string false "过滤条件，格式: field:op:value (如: name:like:旅行)"
+// @Success 200 {object} Response{data=service.CollectionListResponse}
+// @Failure 500 {object} Response
+// @Router /collections [get]
+func (h *CollectionHandler) ListCollections(c *gin.Context) {
+	queryParams := ParseQueryParams(c, "collections")
+
+	collections, err := h.CollectionService.ListCollections(c, &service.CollectionQueryParams{
+		Page:    queryParams.Page,
+		Size:    queryParams.Size,
+		SortBy:  queryParams.SortBy,
+		Order:   queryParams.Order,
+		Filters: queryParams.Filters,
+	}, optionalUserID(c))
+	if err != nil {
+		h.respondCollectionError(c, "获取合集列表失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    collections,
+	})
+}
+
+// GetCollection 获取合集详情
+// @Summary 获取合集详情
+// @Description 获取动态合集的名称、描述、封面和可见动态数量
+// @Tags collections
+// @Produce json
+// @Param id path int true "合集ID"
+// @Success 200 {object} Response{data=service.CollectionResponse}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /collections/{id} [get]
+func (h *CollectionHandler) GetCollection(c *gin.Context) {
+	id, ok := parseCollectionID(c)
+	if !ok {
+		return
+	}
+
+	collection, err := h.CollectionService.GetCollection(c, id, optionalUserID(c))
+	if err != nil {
+		h.respondCollectionError(c, "获取合集失败", err)
+		return
+	}
+	if collection == nil {
+		respondCollectionNotFound(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    collection,
+	})
+}
+
+// ListCollectionMoments 获取合集内的动态
+// @Summary 获取合集内的动态
+// @Description 按合集内的顺序分页获取动态，可见范围规则与动态列表一致
+// @Tags collections
+// @Produce json
+// @Param id path int true "合集ID"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response{data=service.MomentListResponse}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /collections/{id}/moments [get]
+func (h *CollectionHandler) ListCollectionMoments(c *gin.Context) {
+	id, ok := parseCollectionID(c)
+	if !ok {
+		return
+	}
+
+	queryParams := ParseQueryParams(c, "moments")
+	claims, isLoggedIn := auth.GetAuthClaims(c)
+	var userID uint64
+	if isLoggedIn {
+		userID = claims.UserID
+	}
+
+	listResp, err := h.CollectionService.ListCollectionMoments(c, id, &service.MomentQueryParams{
+		Page:    queryParams.Page,
+		Size:    queryParams.Size,
+		Filters: queryParams.Filters,
+		Actor:   reactionActorFromRequest(c, h.CollectionService.MomentService),
+	}, isLoggedIn, userID)
+	if err != nil {
+		h.respondCollectionError(c, "获取合集动态失败", err)
+		return
+	}
+	if listResp == nil {
+		respondCollectionNotFound(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    listResp,
+	})
+}
+
+// CreateCollection 创建合集
+// @Summary 创建合集
+// @Description 创建动态合集，可同时指定封面和合集内的动态（按展示顺序），动态必须对当前用户可见
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param collection body service.CollectionCreateRequest true "合集信息"
+// @Success 200 {object} Response{data=service.CollectionResponse}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /collections [post]
+func (h *CollectionHandler) CreateCollection(c *gin.Context) {
+	var req service.CollectionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "请求参数错误: " + err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+	collection, err := h.CollectionService.CreateCollection(c, &req, claims.UserID)
+	if err != nil {
+		h.respondCollectionError(c, "创建合集失败", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "创建成功",
+		Data:    collection,
+	})
+}
+
+// UpdateCollection 更新合集
+// @Summary 更新合集
+// @Description 更新合集的名称、描述和封面，coverImageId 为 0 时取消封面
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param id path int true "合集ID"
+// @Param collection body service.CollectionUpdateRequest true "合集信息"
+// @Success 200 {object} Response{data=service.CollectionResponse}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /collections/{id} [put]
+func (h *CollectionHandler) UpdateCollection(c *gin.Context) {
+	id, ok := parseCollectionID(c)
+	if !ok {
+		return
+	}
+
+	var req service.CollectionUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "请求参数错误: " + err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+	collection, err := h.CollectionService.UpdateCollection(c, id, &req, claims.UserID)
+	if err != nil {
+		h.respondCollectionError(c, "更新合集失败", err)
+		return
+	}
+	if collection == nil {
+		respondCollectionNotFound(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "更新成功",
+		Data:    collection,
+	})
+}
+
+// DeleteCollection 删除合集
+// @Summary 删除合集
+// @Description 删除合集，合集内的动态保留
+// @Tags collections
+// @Produce json
+// @Security OAuth2Password
+// @Param id path int true "合集ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /collections/{id} [delete]
+func (h *CollectionHandler) DeleteCollection(c *gin.Context) {
+	id, ok := parseCollectionID(c)
+	if !ok {
+		return
+	}
+
+	found, err := h.CollectionService.DeleteCollection(c.Request.Context(), id)
+	if err != nil {
+		h.respondCollectionError(c, "删除合集失败", err)
+		return
+	}
+	if !found {
+		respondCollectionNotFound(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "删除成功",
+		Data:    nil,
+	})
+}
+
+// SetCollectionMoments 设置合集内的动态
+// @Summary 设置合集内的动态
+// @Description 用给定的动态列表替换合集内的全部动态，列表顺序即展示顺序，可用于调整排序
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param id path int true "合集ID"
+// @Param moments body service.CollectionMomentsRequest true "动态ID列表"
+// @Success 200 {object} Response{data=service.CollectionResponse}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /collections/{id}/moments [put]
+func (h *CollectionHandler) SetCollectionMoments(c *gin.Context) {
+	h.updateCollectionMoments(c, "设置合集动态失败", h.CollectionService.SetCollectionMoments)
+}
+
+// AppendCollectionMoments 追加动态到合集
+// @Summary 追加动态到合集
+// @Description 将动态按给定顺序追加到合集末尾，已在合集中的动态位置不变
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param id path int true "合集ID"
+// @Param moments body service.CollectionMomentsRequest true "动态ID列表"
+// @Success 200 {object} Response{data=service.CollectionResponse}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /collections/{id}/moments [post]
+func (h *CollectionHandler) AppendCollectionMoments(c *gin.Context) {
+	h.updateCollectionMoments(c, "追加合集动态失败", h.CollectionService.AppendCollectionMoments)
+}
+
+type collectionMomentsUpdater func(c *gin.Context, id uint64, req *service.CollectionMomentsRequest, userID uint64) (*service.CollectionResponse, error)
+
+func (h *CollectionHandler) updateCollectionMoments(c *gin.Context, action string, update collectionMomentsUpdater) {
+	id, ok := parseCollectionID(c)
+	if !ok {
+		return
+	}
+
+	var req service.CollectionMomentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "请求参数错误: " + err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+	collection, err := update(c, id, &req, claims.UserID)
+	if err != nil {
+		h.respondCollectionError(c, action, err)
+		return
+	}
+	if collection == nil {
+		respondCollectionNotFound(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "更新成功",
+		Data:    collection,
+	})
+}
+
+// RemoveCollectionMoment 从合集移除动态
+// @Summary 从合集移除动态
+// @Description 从合集中移除动态，动态本身保留
+// @Tags collections
+// @Produce json
+// @Security OAuth2Password
+// @Param id path int true "合集ID"
+// @Param momentId path int true "动态ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /collections/{id}/moments/{momentId} [delete]
+func (h *CollectionHandler) RemoveCollectionMoment(c *gin.Context) {
+	id, ok := parseCollectionID(c)
+	if !ok {
+		return
+	}
+	momentID, err := strconv.ParseUint(c.Param("momentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的动态ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	removed, err := h.CollectionService.RemoveCollectionMoment(c.Request.Context(), id, momentID)
+	if err != nil {
+		h.respondCollectionError(c, "从合集移除动态失败", err)
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "合集不存在或动态不在合集中",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "移除成功",
+		Data:    nil,
+	})
+}
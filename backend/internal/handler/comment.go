@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/bookandmusic/love-girl/internal/auth"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
 	middle "github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/model"
 	"github.com/bookandmusic/love-girl/internal/server"
 	"github.com/bookandmusic/love-girl/internal/service"
 )
@@ -23,13 +27,23 @@ func NewCommentHandler(commentService *service.CommentService) *CommentHandler {
 }
 
 func (h *CommentHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *server.GinEngine, authMiddleware *middle.AuthMiddleware) {
-	apiGroup.GET("/moments/:id/comments", h.ListComments)
+	apiGroup.GET("/moments/:id/comments", authMiddleware.Optional(), h.ListComments)
+	apiGroup.GET("/comments/:id/replies", authMiddleware.Optional(), h.ListReplies)
+
+	// 访客评论：获取题目每个IP每分钟最多30次，提交评论按配置限制每小时次数
+	challengeLimiter := middle.RateLimit(30, time.Minute)
+	guestLimiter := middle.RateLimit(h.CommentService.GuestRateLimit(), time.Hour)
+	apiGroup.GET("/comments/challenge", challengeLimiter, h.GetGuestChallenge)
+	apiGroup.POST("/moments/:id/comments/guest", guestLimiter, h.CreateGuestComment)
 
 	authGroup := apiGroup.Group("")
 	authGroup.Use(authMiddleware.Handle())
 	{
 		authGroup.POST("/moments/:id/comments", h.CreateComment)
+		authGroup.PUT("/comments/:id", h.UpdateComment)
 		authGroup.DELETE("/comments/:id", h.DeleteComment)
+		authGroup.GET("/comments/moderation", h.ListModerationQueue) // 审核队列
+		authGroup.PUT("/comments/:id/status", h.SetCommentStatus)    // 审核评论
 	}
 }
 
@@ -81,9 +95,14 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 		return
 	}
 
+	// 内容审核未通过的评论进入审核队列
+	message := "评论成功"
+	if comment.Status != string(model.CommentStatusApproved) {
+		message = "评论已提交，审核通过后展示"
+	}
 	c.JSON(http.StatusOK, Response{
 		Code:    0,
-		Message: "评论成功",
+		Message: message,
 		Data:    comment,
 	})
 }
@@ -101,18 +120,31 @@ func (h *CommentHandler) ListComments(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+	replies, _ := strconv.Atoi(c.DefaultQuery("replies", "3"))
 
-	if page < 1 {
-		page = 1
-	}
 	if size < 1 || size > 100 {
 		size = 20
 	}
+	if replies < 1 || replies > 20 {
+		replies = 3
+	}
+
+	var viewerID uint64
+	if claims, ok := auth.GetAuthClaims(c); ok {
+		viewerID = claims.UserID
+	}
 
-	response, err := h.CommentService.ListComments(c, momentID, page, size)
+	response, err := h.CommentService.ListComments(c, momentID, viewerID, c.Query("cursor"), size, replies)
 	if err != nil {
+		if errors.Is(err, errMsg.ErrInvalidCommentCursor) {
+			c.JSON(http.StatusBadRequest, Response{
+				Code:    1,
+				Message: "无效的游标",
+				Data:    nil,
+			})
+			return
+		}
 		h.CommentService.Log.Error("获取评论列表失败", "error", err, "momentID", momentID)
 		c.JSON(http.StatusInternalServerError, Response{
 			Code:    1,
@@ -122,6 +154,15 @@ func (h *CommentHandler) ListComments(c *gin.Context) {
 		return
 	}
 
+	if response == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "动态不存在",
+			Data:    nil,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    0,
 		Message: "查询成功",
@@ -129,6 +170,124 @@ func (h *CommentHandler) ListComments(c *gin.Context) {
 	})
 }
 
+// ListReplies 加载更多回复
+// @Summary 加载评论的更多回复
+// @Description 按时间升序返回评论下的各级回复，cursor 为评论列表中的 repliesCursor 或上一次返回的 nextCursor
+// @Tags comments
+// @Produce json
+// @Param id path int true "评论ID"
+// @Param cursor query string false "游标"
+// @Param size query int false "每次数量" default(20)
+// @Success 200 {object} Response{data=service.CommentRepliesResponse}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Router /comments/{id}/replies [get]
+func (h *CommentHandler) ListReplies(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的评论ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	var viewerID uint64
+	if claims, ok := auth.GetAuthClaims(c); ok {
+		viewerID = claims.UserID
+	}
+
+	response, err := h.CommentService.ListReplies(c, id, viewerID, c.Query("cursor"), size)
+	if err != nil {
+		status, message := http.StatusInternalServerError, err.Error()
+		switch {
+		case errors.Is(err, errMsg.ErrInvalidCommentCursor):
+			status, message = http.StatusBadRequest, "无效的游标"
+		case message == "评论不存在":
+			status = http.StatusNotFound
+		}
+		c.JSON(status, Response{
+			Code:    1,
+			Message: message,
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    response,
+	})
+}
+
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.CommentService.Log.Error("无效的评论ID", "id", idStr, "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的评论ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	claims, ok := auth.GetAuthClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Response{
+			Code:    1,
+			Message: "未登录",
+			Data:    nil,
+		})
+		return
+	}
+
+	var req service.CommentUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.CommentService.Log.Error("参数校验失败", "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "参数校验失败",
+			Data:    nil,
+		})
+		return
+	}
+
+	comment, err := h.CommentService.UpdateComment(c, id, claims.UserID, &req)
+	if err != nil {
+		h.CommentService.Log.Error("编辑评论失败", "id", id, "error", err)
+		status, message := http.StatusInternalServerError, err.Error()
+		switch {
+		case errors.Is(err, errMsg.ErrCommentEditRejected):
+			status, message = http.StatusBadRequest, "该评论已有回复，编辑后的内容未通过审核"
+		case message == "评论不存在", message == "动态不存在":
+			status = http.StatusNotFound
+		case message == "无权限编辑此评论":
+			status = http.StatusForbidden
+		}
+		c.JSON(status, Response{
+			Code:    1,
+			Message: message,
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "编辑成功",
+		Data:    comment,
+	})
+}
+
 func (h *CommentHandler) DeleteComment(c *gin.Context) {
 	ctx := c.Request.Context()
 
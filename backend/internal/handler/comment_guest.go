@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+// GetGuestChallenge 获取访客评论的工作量证明题目
+// @Summary 获取访客评论题目
+// @Description 访客评论前需要找到字符串 solution，使 SHA-256(challenge + ":" + solution) 的前导零比特数不少于 difficulty
+// @Tags comments
+// @Produce json
+// @Success 200 {object} Response{data=pow.Challenge}
+// @Failure 403 {object} Response
+// @Failure 429 {object} Response
+// @Router /comments/challenge [get]
+func (h *CommentHandler) GetGuestChallenge(c *gin.Context) {
+	challenge, err := h.CommentService.IssueGuestChallenge()
+	if err != nil {
+		if errors.Is(err, errMsg.ErrGuestCommentDisabled) {
+			c.JSON(http.StatusForbidden, Response{
+				Code:    1,
+				Message: "未开启访客评论",
+				Data:    nil,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "获取成功",
+		Data:    challenge,
+	})
+}
+
+// CreateGuestComment 访客评论
+// @Summary 访客评论
+// @Description 未登录访客评论已发布的公开动态，需要先完成工作量证明；评论审核通过前不会展示
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "动态ID"
+// @Param request body service.GuestCommentCreateRequest true "评论内容、昵称和题目解答"
+// @Success 200 {object} Response{data=service.FrontendComment}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 429 {object} Response
+// @Router /moments/{id}/comments/guest [post]
+func (h *CommentHandler) CreateGuestComment(c *gin.Context) {
+	momentIDStr := c.Param("id")
+	momentID, err := strconv.ParseUint(momentIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的动态ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	var req service.GuestCommentCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.CommentService.Log.Info("访客评论参数校验失败", "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "参数校验失败",
+			Data:    nil,
+		})
+		return
+	}
+	req.MomentID = momentID
+	req.IP = c.ClientIP()
+
+	comment, err := h.CommentService.CreateGuestComment(c, &req)
+	if err != nil {
+		status, message := http.StatusBadRequest, err.Error()
+		switch {
+		case errors.Is(err, errMsg.ErrGuestCommentDisabled):
+			status, message = http.StatusForbidden, "未开启访客评论"
+		case errors.Is(err, errMsg.ErrGuestChallengeFailed):
+			message = "验证失败，请重新获取题目"
+		case message == "动态不存在":
+			status = http.StatusNotFound
+		case message == "系统内部错误":
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, Response{
+			Code:    1,
+			Message: message,
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "评论已提交，审核通过后展示",
+		Data:    comment,
+	})
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+// ListModerationQueue 获取评论审核队列
+// @Summary 获取评论审核队列
+// @Description 获取当前用户可见动态下的评论，默认只返回待审核的访客评论，可通过 filter=status:eq:spam 查看垃圾评论
+// @Tags comments
+// @Produce json
+// @Security OAuth2Password
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param order query string false "排序方向：asc/desc" default(desc)
+// @Param filter query []string false "过滤条件：status:eq:pending、moment_id:eq:1" collectionFormat(multi)
+// @Success 200 {object} Response{data=service.ModerationListResponse}
+// @Failure 401 {object} Response
+// @Failure 500 {object} Response
+// @Router /comments/moderation [get]
+func (h *CommentHandler) ListModerationQueue(c *gin.Context) {
+	claims := auth.MustGetAuthClaims(c)
+
+	query := ParseQueryParams(c, "comments")
+	params := &service.CommentQueryParams{
+		Page:    query.Page,
+		Size:    query.Size,
+		SortBy:  query.SortBy,
+		Order:   query.Order,
+		Filters: query.Filters,
+	}
+
+	response, err := h.CommentService.ListModerationQueue(c, params, claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    response,
+	})
+}
+
+// SetCommentStatus 审核评论
+// @Summary 审核评论
+// @Description 将评论标记为待审核、通过或垃圾评论；已有回复的评论不能撤回审核
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param id path int true "评论ID"
+// @Param request body service.CommentStatusRequest true "审核状态"
+// @Success 200 {object} Response{data=service.FrontendComment}
+// @Failure 400 {object} Response
+// @Failure 401 {object} Response
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Router /comments/{id}/status [put]
+func (h *CommentHandler) SetCommentStatus(c *gin.Context) {
+	claims := auth.MustGetAuthClaims(c)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的评论ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	var req service.CommentStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "参数校验失败",
+			Data:    nil,
+		})
+		return
+	}
+
+	comment, err := h.CommentService.SetCommentStatus(c, id, claims.UserID, req.Status)
+	if err != nil {
+		status, message := http.StatusInternalServerError, err.Error()
+		switch {
+		case errors.Is(err, errMsg.ErrCommentHasReplies):
+			status, message = http.StatusConflict, "该评论已有回复，不能撤回审核，可直接删除"
+		case message == "评论不存在":
+			status = http.StatusNotFound
+		}
+		c.JSON(status, Response{
+			Code:    1,
+			Message: message,
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "审核成功",
+		Data:    comment,
+	})
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/server"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+// feedCacheControl 订阅源的缓存策略，阅读器可缓存 5 分钟，之后通过条件请求确认是否更新
+const feedCacheControl = "public, max-age=300"
+
+type FeedHandler struct {
+	FeedService *service.FeedService
+}
+
+func NewFeedHandler(feedService *service.FeedService) *FeedHandler {
+	return &FeedHandler{
+		FeedService: feedService,
+	}
+}
+
+// RegisterRoutes 注册订阅源路由（站点根路径，便于阅读器自动发现）
+func (h *FeedHandler) RegisterRoutes(ginEngine *server.GinEngine) {
+	engine := ginEngine.Engine
+
+	// 部分阅读器先用 HEAD 请求检查更新
+	methods := []string{http.MethodGet, http.MethodHead}
+	engine.Match(methods, "/feed.atom", h.render("application/atom+xml; charset=utf-8", (*service.Feed).Atom))
+	engine.Match(methods, "/feed.rss", h.render("application/rss+xml; charset=utf-8", (*service.Feed).RSS))
+	engine.Match(methods, "/feed.json", h.render("application/feed+json; charset=utf-8", (*service.Feed).JSON))
+}
+
+// render 生成指定格式的订阅源
+// 说明：响应带 ETag（内容摘要）和 Last-Modified（最后修改时间），
+// If-None-Match / If-Modified-Since 命中时由 http.ServeContent 返回 304
+func (h *FeedHandler) render(contentType string, encode func(*service.Feed) ([]byte, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		feed, err := h.FeedService.BuildFeed(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, Response{
+				Code:    1,
+				Message: err.Error(),
+				Data:    nil,
+			})
+			return
+		}
+
+		body, err := encode(feed)
+		if err != nil {
+			h.FeedService.Log.Error("生成订阅源失败", "error", err, "path", c.Request.URL.Path)
+			c.JSON(http.StatusInternalServerError, Response{
+				Code:    1,
+				Message: "系统内部错误",
+				Data:    nil,
+			})
+			return
+		}
+
+		sum := sha256.Sum256(body)
+		c.Header("Content-Type", contentType)
+		c.Header("Cache-Control", feedCacheControl)
+		c.Header("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		http.ServeContent(c.Writer, c.Request, "", feed.Updated, bytes.NewReader(body))
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+	middle "github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/server"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+type MemoryHandler struct {
+	MemoryService *service.MemoryService
+}
+
+func NewMemoryHandler(memoryService *service.MemoryService) *MemoryHandler {
+	return &MemoryHandler{
+		MemoryService: memoryService,
+	}
+}
+
+// RegisterRoutes 注册那年今日相关的路由
+func (h *MemoryHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *server.GinEngine, authMiddleware *middle.AuthMiddleware) {
+	authGroup := apiGroup.Group("")
+	authGroup.Use(authMiddleware.Handle())
+	{
+		authGroup.GET("/memories", h.GetMemories)
+	}
+}
+
+// GetMemories 获取那年今日
+// @Summary 获取那年今日
+// @Description 获取往年同一天的动态、相册照片（按 EXIF 拍摄时间，没有时按上传时间）、去过的地点和公历纪念日，按年份倒序分组
+// @Tags memories
+// @Produce json
+// @Security OAuth2Password
+// @Param date query string false "月日，格式 MM-DD，默认今天"
+// @Success 200 {object} Response{data=service.MemoryResponse}
+// @Failure 400 {object} Response
+// @Failure 401 {object} Response
+// @Failure 500 {object} Response
+// @Router /memories [get]
+func (h *MemoryHandler) GetMemories(c *gin.Context) {
+	claims, ok := auth.GetAuthClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, Response{
+			Code:    1,
+			Message: "未登录",
+			Data:    nil,
+		})
+		return
+	}
+
+	now := time.Now()
+	month, day := now.Month(), now.Day()
+	if raw := c.Query("date"); raw != "" {
+		// 按闰年解析，允许查询 02-29
+		date, err := time.Parse("2006-01-02", "2000-"+raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, Response{
+				Code:    1,
+				Message: "日期格式错误，应为: MM-DD",
+				Data:    nil,
+			})
+			return
+		}
+		month, day = date.Month(), date.Day()
+	}
+
+	response, err := h.MemoryService.GetMemories(c, month, day, claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    response,
+	})
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -8,11 +9,18 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/bookandmusic/love-girl/internal/auth"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
 	middle "github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/model"
 	"github.com/bookandmusic/love-girl/internal/server"
 	"github.com/bookandmusic/love-girl/internal/service"
 )
 
+const (
+	DefaultTagCloudLimit = 50
+	MaxTagCloudLimit     = 200
+)
+
 type MomentHandler struct {
 	MomentService *service.MomentService
 }
@@ -25,24 +33,52 @@ func NewMomentHandler(momentService *service.MomentService) *MomentHandler {
 
 // RegisterRoutes 注册动态相关的路由
 func (h *MomentHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *server.GinEngine, authMiddleware *middle.AuthMiddleware) {
-	apiGroup.GET("/moments", h.ListMoments) // 获取动态列表（公开的）
+	// 公开路由：登录用户可额外看到情侣可见和自己的私密动态
+	apiGroup.GET("/moments", authMiddleware.Optional(), h.ListMoments)       // 获取动态列表
+	apiGroup.GET("/moments/tags", authMiddleware.Optional(), h.ListTagCloud) // 获取标签云
 
-	// 公开但有限流的路由：每个IP对同一动态每5分钟只能点赞1次
-	apiGroup.POST("/moments/:id/like", middle.RateLimitByKey(
+	// 公开但有限流的路由：点赞和表情回应均为切换语义，两者共用限额
+	// 匿名访客每个IP对同一动态每5分钟只能操作1次；登录用户按账号识别，每人对同一动态每分钟最多操作20次
+	visitorLimit := middle.RateLimitByKey(
 		func(c *gin.Context) string {
 			return c.ClientIP() + ":" + c.Param("id")
 		},
 		1, 5*time.Minute,
-	), h.LikeMoment)
+	)
+	userLimit := middle.RateLimitByKey(
+		func(c *gin.Context) string {
+			return strconv.FormatUint(auth.MustGetAuthClaims(c).UserID, 10) + ":" + c.Param("id")
+		},
+		20, time.Minute,
+	)
+	reactionLimit := func(c *gin.Context) {
+		if _, ok := auth.GetAuthClaims(c); ok {
+			userLimit(c)
+			return
+		}
+		visitorLimit(c)
+	}
+	apiGroup.POST("/moments/:id/like", authMiddleware.Optional(), reactionLimit, h.LikeMoment)          // 点赞/取消点赞
+	apiGroup.POST("/moments/:id/reactions", authMiddleware.Optional(), reactionLimit, h.ToggleReaction) // 添加/取消表情回应
+	apiGroup.GET("/moments/:id/reactions", authMiddleware.Optional(), h.GetReactions)                   // 获取表情回应统计
+	apiGroup.GET("/places/:id/moments", authMiddleware.Optional(), h.ListPlaceMoments)                  // 获取发生在该地点的动态
+	apiGroup.GET("/anniversaries/:id/moments", authMiddleware.Optional(), h.ListAnniversaryMoments)     // 获取关联该纪念日的动态
 
 	// 需要认证的路由
 	authGroup := apiGroup.Group("")
 	authGroup.Use(authMiddleware.Handle())
 	{
-		authGroup.POST("/moments", h.CreateMoment)           // 创建动态
-		authGroup.PUT("/moments/:id", h.UpdateMoment)        // 更新动态
-		authGroup.DELETE("/moments/:id", h.DeleteMoment)     // 删除动态
-		authGroup.PUT("/moments/:id/public", h.UpdatePublic) // 更新动态公开状态
+		authGroup.POST("/moments", h.CreateMoment)                   // 创建动态
+		authGroup.PUT("/moments/:id", h.UpdateMoment)                // 更新动态
+		authGroup.DELETE("/moments/:id", h.DeleteMoment)             // 删除动态
+		authGroup.PUT("/moments/:id/visibility", h.UpdateVisibility) // 更新动态可见范围
+		authGroup.PUT("/moments/:id/public", h.UpdateVisibility)     // 兼容旧客户端
+		authGroup.PUT("/moments/:id/pin", h.PinMoment)               // 置顶动态
+		authGroup.DELETE("/moments/:id/pin", h.UnpinMoment)          // 取消置顶
+
+		authGroup.GET("/moments/:id/revisions", h.ListRevisions)                     // 获取修订历史
+		authGroup.GET("/moments/:id/revisions/diff", h.DiffRevisions)                // 比较两个修订版本
+		authGroup.POST("/moments/:id/revisions/:version/restore", h.RestoreRevision) // 恢复到指定版本
 	}
 
 }
@@ -73,6 +109,14 @@ func (h *MomentHandler) CreateMoment(c *gin.Context) {
 
 	moment, err := h.MomentService.CreateMoment(c, &req)
 	if err != nil {
+		if message, ok := momentRequestErrorMessage(err); ok {
+			c.JSON(http.StatusBadRequest, Response{
+				Code:    1,
+				Message: message,
+				Data:    nil,
+			})
+			return
+		}
 		h.MomentService.Log.Error("创建动态失败", "error", err)
 		c.JSON(http.StatusInternalServerError, Response{
 			Code:    1,
@@ -91,14 +135,14 @@ func (h *MomentHandler) CreateMoment(c *gin.Context) {
 
 // ListMoments 获取动态列表
 // @Summary 获取动态列表
-// @Description 分页获取动态列表，支持排序和过滤
+// @Description 分页获取动态列表，支持排序和过滤，置顶动态始终排在最前。访客只能看到公开动态，登录用户还能看到情侣可见的动态和自己的全部动态
 // @Tags moments
 // @Produce json
 // @Param page query int false "页码" default(1)
 // @Param size query int false "每页数量" default(10)
 // @Param sort_by query string false "排序字段 (created_at, likes)"
 // @Param order query string false "排序方向 (asc, desc)" default(desc)
-// @Param filter query []string false "过滤条件，格式: field:op:value (如: is_public:eq:true)"
+// @Param filter query []string false "过滤条件，格式: field:op:value (如: visibility:eq:couple)"
 // @Success 200 {object} Response{data=service.MomentListResponse}
 // @Failure 400 {object} Response
 // @Failure 500 {object} Response
@@ -114,11 +158,13 @@ func (h *MomentHandler) ListMoments(c *gin.Context) {
 	}
 
 	listResp, err := h.MomentService.ListMomentsWithQuery(c, &service.MomentQueryParams{
-		Page:    queryParams.Page,
-		Size:    queryParams.Size,
-		SortBy:  queryParams.SortBy,
-		Order:   queryParams.Order,
-		Filters: queryParams.Filters,
+		Page:        queryParams.Page,
+		Size:        queryParams.Size,
+		SortBy:      queryParams.SortBy,
+		Order:       queryParams.Order,
+		Filters:     queryParams.Filters,
+		Actor:       reactionActorFromRequest(c, h.MomentService),
+		PinnedFirst: true,
 	}, isLoggedIn, userID)
 	if err != nil {
 		h.MomentService.Log.Error("获取动态列表失败", "error", err)
@@ -137,6 +183,43 @@ func (h *MomentHandler) ListMoments(c *gin.Context) {
 	})
 }
 
+// ListTagCloud 获取标签云
+// @Summary 获取标签云
+// @Description 统计当前用户可见动态中的话题标签及其动态数量，按数量降序
+// @Tags moments
+// @Produce json
+// @Param limit query int false "返回的标签数量上限" default(50)
+// @Success 200 {object} Response{data=service.TagCloudResponse}
+// @Failure 500 {object} Response
+// @Router /moments/tags [get]
+func (h *MomentHandler) ListTagCloud(c *gin.Context) {
+	limit := DefaultTagCloudLimit
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+		limit = min(l, MaxTagCloudLimit)
+	}
+
+	var viewerID uint64
+	if claims, ok := auth.GetAuthClaims(c); ok {
+		viewerID = claims.UserID
+	}
+
+	resp, err := h.MomentService.ListTagCloud(c, viewerID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    resp,
+	})
+}
+
 // UpdateMoment 更新动态
 // @Summary 更新动态
 // @Description 更新动态信息
@@ -180,6 +263,14 @@ func (h *MomentHandler) UpdateMoment(c *gin.Context) {
 
 	moment, err := h.MomentService.UpdateMoment(c, id, userID, &req)
 	if err != nil {
+		if message, ok := momentRequestErrorMessage(err); ok {
+			c.JSON(http.StatusBadRequest, Response{
+				Code:    1,
+				Message: message,
+				Data:    nil,
+			})
+			return
+		}
 		if err.Error() == "无权操作此动态" {
 			c.JSON(http.StatusForbidden, Response{
 				Code:    1,
@@ -278,21 +369,21 @@ func (h *MomentHandler) DeleteMoment(c *gin.Context) {
 	})
 }
 
-// UpdatePublic 更新动态公开状态
-// @Summary 更新动态公开状态
-// @Description 更新动态的公开状态
+// UpdateVisibility 更新动态可见范围
+// @Summary 更新动态可见范围
+// @Description 更新动态的可见范围：private 仅自己可见，couple 情侣可见，public 所有人可见
 // @Tags moments
 // @Accept json
 // @Produce json
 // @Security OAuth2Password
 // @Param id path string true "动态ID"
-// @Param status body service.MomentPublicRequest true "公开状态"
+// @Param visibility body service.MomentVisibilityRequest true "可见范围"
 // @Success 200 {object} Response{data=service.FrontendMoment}
 // @Failure 400 {object} Response
 // @Failure 404 {object} Response
 // @Failure 500 {object} Response
-// @Router /moments/{id}/public [put]
-func (h *MomentHandler) UpdatePublic(c *gin.Context) {
+// @Router /moments/{id}/visibility [put]
+func (h *MomentHandler) UpdateVisibility(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
@@ -308,7 +399,7 @@ func (h *MomentHandler) UpdatePublic(c *gin.Context) {
 	claims := auth.MustGetAuthClaims(c)
 	userID := claims.UserID
 
-	var req service.MomentPublicRequest
+	var req service.MomentVisibilityRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.MomentService.Log.Error("参数校验失败", "error", err)
 		c.JSON(http.StatusBadRequest, Response{
@@ -319,7 +410,17 @@ func (h *MomentHandler) UpdatePublic(c *gin.Context) {
 		return
 	}
 
-	moment, err := h.MomentService.UpdatePublicStatus(c, id, userID, req.IsPublic)
+	visibility, ok := service.ResolveVisibility(req.Visibility, req.IsPublic)
+	if !ok {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "请指定可见范围",
+			Data:    nil,
+		})
+		return
+	}
+
+	moment, err := h.MomentService.UpdateVisibility(c, id, userID, visibility)
 	if err != nil {
 		if err.Error() == "无权操作此动态" {
 			c.JSON(http.StatusForbidden, Response{
@@ -329,7 +430,7 @@ func (h *MomentHandler) UpdatePublic(c *gin.Context) {
 			})
 			return
 		}
-		h.MomentService.Log.Error("更新动态公开状态失败", "id", id, "error", err)
+		h.MomentService.Log.Error("更新动态可见范围失败", "id", id, "error", err)
 		c.JSON(http.StatusInternalServerError, Response{
 			Code:    1,
 			Message: "系统内部错误",
@@ -356,32 +457,99 @@ func (h *MomentHandler) UpdatePublic(c *gin.Context) {
 
 // LikeMoment 点赞动态
 // @Summary 点赞动态
-// @Description 为指定ID的动态点赞
+// @Description 切换当前用户对动态的点赞状态，已点赞则取消；未登录访客仅能点赞公开动态
 // @Tags moments
 // @Produce json
-// @Security OAuth2Password
 // @Param id path string true "动态ID"
 // @Success 200 {object} Response{data=service.MomentLikeResponse}
 // @Failure 400 {object} Response
 // @Failure 404 {object} Response
+// @Failure 429 {object} Response
 // @Failure 500 {object} Response
 // @Router /moments/{id}/like [post]
 func (h *MomentHandler) LikeMoment(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
+	id, ok := h.parseMomentID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.MomentService.ToggleReaction(c, id, reactionActorFromRequest(c, h.MomentService), model.ReactionLike)
 	if err != nil {
-		h.MomentService.Log.Error("无效的动态ID", "id", idStr, "error", err)
+		h.MomentService.Log.Error("点赞动态失败", "id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+
+	if result == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "动态不存在",
+			Data:    nil,
+		})
+		return
+	}
+
+	message := "点赞成功"
+	if !result.Reacted {
+		message = "已取消点赞"
+	}
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: message,
+		Data: service.MomentLikeResponse{
+			Likes: result.Likes,
+			Liked: result.Reacted,
+		},
+	})
+}
+
+// ToggleReaction 添加或取消表情回应
+// @Summary 表情回应
+// @Description 切换当前用户对动态的某种表情回应（like/love/haha/wow/sad/hug），已回应则取消；未登录访客仅能回应公开动态
+// @Tags moments
+// @Accept json
+// @Produce json
+// @Param id path string true "动态ID"
+// @Param reaction body service.MomentReactionRequest true "回应类型"
+// @Success 200 {object} Response{data=service.MomentReactionResponse}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 429 {object} Response
+// @Failure 500 {object} Response
+// @Router /moments/{id}/reactions [post]
+func (h *MomentHandler) ToggleReaction(c *gin.Context) {
+	id, ok := h.parseMomentID(c)
+	if !ok {
+		return
+	}
+
+	var req service.MomentReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, Response{
 			Code:    1,
-			Message: "无效的动态ID",
+			Message: "参数错误: " + err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	reactionType := model.ReactionType(req.Type)
+	if !reactionType.Valid() {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "不支持的回应类型",
 			Data:    nil,
 		})
 		return
 	}
 
-	liked, err := h.MomentService.LikeMoment(c, id)
+	result, err := h.MomentService.ToggleReaction(c, id, reactionActorFromRequest(c, h.MomentService), reactionType)
 	if err != nil {
-		h.MomentService.Log.Error("点赞动态失败", "id", id, "error", err)
+		h.MomentService.Log.Error("表情回应失败", "id", id, "error", err)
 		c.JSON(http.StatusInternalServerError, Response{
 			Code:    1,
 			Message: "系统内部错误",
@@ -390,7 +558,7 @@ func (h *MomentHandler) LikeMoment(c *gin.Context) {
 		return
 	}
 
-	if liked == nil {
+	if result == nil {
 		c.JSON(http.StatusNotFound, Response{
 			Code:    1,
 			Message: "动态不存在",
@@ -399,11 +567,100 @@ func (h *MomentHandler) LikeMoment(c *gin.Context) {
 		return
 	}
 
+	message := "回应成功"
+	if !result.Reacted {
+		message = "已取消回应"
+	}
 	c.JSON(http.StatusOK, Response{
 		Code:    0,
-		Message: "点赞成功",
-		Data: service.MomentLikeResponse{
-			Likes: liked.Likes,
-		},
+		Message: message,
+		Data:    result,
 	})
 }
+
+// GetReactions 获取表情回应统计
+// @Summary 获取表情回应
+// @Description 获取动态各类表情回应的数量以及当前用户已做出的回应
+// @Tags moments
+// @Produce json
+// @Param id path string true "动态ID"
+// @Success 200 {object} Response{data=service.MomentReactionResponse}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /moments/{id}/reactions [get]
+func (h *MomentHandler) GetReactions(c *gin.Context) {
+	id, ok := h.parseMomentID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.MomentService.GetReactions(c, id, reactionActorFromRequest(c, h.MomentService))
+	if err != nil {
+		h.MomentService.Log.Error("获取表情回应失败", "id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+
+	if result == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "动态不存在",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    result,
+	})
+}
+
+// parseMomentID 解析路径中的动态ID，解析失败时直接写入 400 响应
+func (h *MomentHandler) parseMomentID(c *gin.Context) (uint64, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.MomentService.Log.Error("无效的动态ID", "id", idStr, "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的动态ID",
+			Data:    nil,
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// reactionActorFromRequest 获取回应发起者：已登录时使用用户ID，否则使用服务端计算的 IP + User-Agent 指纹
+func reactionActorFromRequest(c *gin.Context, momentService *service.MomentService) service.ReactionActor {
+	if claims, ok := auth.GetAuthClaims(c); ok {
+		return service.NewUserReactionActor(claims.UserID)
+	}
+	return momentService.VisitorReactionActor(c.ClientIP(), c.Request.UserAgent())
+}
+
+// momentRequestErrorMessage 将发布状态、关联地点和纪念日相关的错误转换为提示信息
+func momentRequestErrorMessage(err error) (string, bool) {
+	switch {
+	case errors.Is(err, errMsg.ErrMomentPinUnpublished):
+		return "只能置顶已发布的动态", true
+	case errors.Is(err, errMsg.ErrMomentPublishAtInvalid):
+		return "发布时间格式错误，应为: 2006-01-02 15:04:05", true
+	case errors.Is(err, errMsg.ErrMomentPublishAtRequired):
+		return "定时发布需要指定发布时间", true
+	case errors.Is(err, errMsg.ErrMomentPublishAtPast):
+		return "发布时间必须晚于当前时间", true
+	case errors.Is(err, errMsg.ErrMomentPlaceNotFound):
+		return "关联的地点不存在", true
+	case errors.Is(err, errMsg.ErrMomentAnniversaryNotFound):
+		return "关联的纪念日不存在", true
+	}
+	return "", false
+}
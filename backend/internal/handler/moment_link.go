@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+// ListPlaceMoments 获取发生在指定地点的动态
+// @Summary 获取地点的动态
+// @Description 分页获取关联到指定地点的动态，默认按动态时间倒序，可见范围规则与动态列表一致
+// @Tags moments
+// @Produce json
+// @Param id path string true "地点ID"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param sort_by query string false "排序字段 (created_at, likes)"
+// @Param order query string false "排序方向 (asc, desc)" default(desc)
+// @Success 200 {object} Response{data=service.MomentListResponse}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /places/{id}/moments [get]
+func (h *MomentHandler) ListPlaceMoments(c *gin.Context) {
+	h.listLinkedMoments(c, "无效的地点ID", "地点不存在", h.MomentService.ListPlaceMoments)
+}
+
+// ListAnniversaryMoments 获取关联指定纪念日的动态
+// @Summary 获取纪念日的动态
+// @Description 分页获取关联到指定纪念日的动态，默认按动态时间倒序，可见范围规则与动态列表一致
+// @Tags moments
+// @Produce json
+// @Param id path string true "纪念日ID"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param sort_by query string false "排序字段 (created_at, likes)"
+// @Param order query string false "排序方向 (asc, desc)" default(desc)
+// @Success 200 {object} Response{data=service.MomentListResponse}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /anniversaries/{id}/moments [get]
+func (h *MomentHandler) ListAnniversaryMoments(c *gin.Context) {
+	h.listLinkedMoments(c, "无效的纪念日ID", "纪念日不存在", h.MomentService.ListAnniversaryMoments)
+}
+
+type linkedMomentsLister func(c *gin.Context, id uint64, params *service.MomentQueryParams, isLoggedIn bool, userID uint64) (*service.MomentListResponse, error)
+
+func (h *MomentHandler) listLinkedMoments(c *gin.Context, invalidIDMessage, notFoundMessage string, list linkedMomentsLister) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: invalidIDMessage,
+			Data:    nil,
+		})
+		return
+	}
+
+	queryParams := ParseQueryParams(c, "moments")
+	claims, isLoggedIn := auth.GetAuthClaims(c)
+	var userID uint64
+	if isLoggedIn {
+		userID = claims.UserID
+	}
+
+	listResp, err := list(c, id, &service.MomentQueryParams{
+		Page:    queryParams.Page,
+		Size:    queryParams.Size,
+		SortBy:  queryParams.SortBy,
+		Order:   queryParams.Order,
+		Filters: queryParams.Filters,
+		Actor:   reactionActorFromRequest(c, h.MomentService),
+	}, isLoggedIn, userID)
+	if err != nil {
+		h.MomentService.Log.Error("获取关联动态失败", "id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+	if listResp == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: notFoundMessage,
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    listResp,
+	})
+}
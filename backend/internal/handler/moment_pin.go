@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+)
+
+// PinMoment 置顶动态
+// @Summary 置顶动态
+// @Description 将动态置顶到动态列表最前，多条置顶动态按置顶时间倒序。只有作者可以操作，草稿和定时动态不能置顶
+// @Tags moments
+// @Produce json
+// @Security OAuth2Password
+// @Param id path string true "动态ID"
+// @Success 200 {object} Response{data=service.FrontendMoment}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /moments/{id}/pin [put]
+func (h *MomentHandler) PinMoment(c *gin.Context) {
+	h.setPinned(c, true)
+}
+
+// UnpinMoment 取消置顶动态
+// @Summary 取消置顶动态
+// @Description 取消动态置顶，只有作者可以操作
+// @Tags moments
+// @Produce json
+// @Security OAuth2Password
+// @Param id path string true "动态ID"
+// @Success 200 {object} Response{data=service.FrontendMoment}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /moments/{id}/pin [delete]
+func (h *MomentHandler) UnpinMoment(c *gin.Context) {
+	h.setPinned(c, false)
+}
+
+func (h *MomentHandler) setPinned(c *gin.Context, pinned bool) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的动态ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+	moment, err := h.MomentService.SetPinned(c, id, claims.UserID, pinned)
+	if err != nil {
+		if message, ok := momentRequestErrorMessage(err); ok {
+			c.JSON(http.StatusBadRequest, Response{
+				Code:    1,
+				Message: message,
+				Data:    nil,
+			})
+			return
+		}
+		if err.Error() == "无权操作此动态" {
+			c.JSON(http.StatusForbidden, Response{
+				Code:    1,
+				Message: "无权操作此动态",
+				Data:    nil,
+			})
+			return
+		}
+		h.MomentService.Log.Error("更新动态置顶状态失败", "id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+	if moment == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "动态不存在",
+			Data:    nil,
+		})
+		return
+	}
+
+	message := "置顶成功"
+	if !pinned {
+		message = "已取消置顶"
+	}
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: message,
+		Data:    moment,
+	})
+}
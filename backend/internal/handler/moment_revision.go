@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+)
+
+// ListRevisions 获取动态修订历史
+// @Summary 获取动态修订历史
+// @Description 按版本号倒序返回动态每次变更后的快照，仅动态作者可查看
+// @Tags moments
+// @Produce json
+// @Security OAuth2Password
+// @Param id path string true "动态ID"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response{data=service.MomentRevisionListResponse}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /moments/{id}/revisions [get]
+func (h *MomentHandler) ListRevisions(c *gin.Context) {
+	id, ok := h.parseMomentID(c)
+	if !ok {
+		return
+	}
+	claims := auth.MustGetAuthClaims(c)
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(DefaultPageSize)))
+	page, size = ParsePagination(page, size)
+
+	result, err := h.MomentService.ListRevisions(c, id, claims.UserID, page, size)
+	if err != nil {
+		h.revisionError(c, "获取动态修订历史失败", id, err)
+		return
+	}
+	if result == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "动态不存在",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    result,
+	})
+}
+
+// DiffRevisions 比较动态的两个修订版本
+// @Summary 比较动态修订版本
+// @Description 返回两个版本之间的内容差异（逐字比较）、图片增减以及可见范围和动态时间是否变化，仅动态作者可查看
+// @Tags moments
+// @Produce json
+// @Security OAuth2Password
+// @Param id path string true "动态ID"
+// @Param from query int false "较早的版本号，默认为 to 的上一个版本"
+// @Param to query int false "较新的版本号，默认为最新版本"
+// @Success 200 {object} Response{data=service.MomentRevisionDiffResponse}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /moments/{id}/revisions/diff [get]
+func (h *MomentHandler) DiffRevisions(c *gin.Context) {
+	id, ok := h.parseMomentID(c)
+	if !ok {
+		return
+	}
+	claims := auth.MustGetAuthClaims(c)
+
+	from, okFrom := parseRevisionVersion(c.Query("from"))
+	to, okTo := parseRevisionVersion(c.Query("to"))
+	if !okFrom || !okTo {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的版本号",
+			Data:    nil,
+		})
+		return
+	}
+
+	result, err := h.MomentService.DiffRevisions(c, id, claims.UserID, from, to)
+	if err != nil {
+		h.revisionError(c, "比较动态修订版本失败", id, err)
+		return
+	}
+	if result == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "动态不存在",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    result,
+	})
+}
+
+// RestoreRevision 恢复动态到指定版本
+// @Summary 恢复动态修订版本
+// @Description 将动态的内容、图片、可见范围和动态时间恢复到指定版本，恢复操作本身会记录为一个新版本；发布状态不受影响
+// @Tags moments
+// @Produce json
+// @Security OAuth2Password
+// @Param id path string true "动态ID"
+// @Param version path int true "要恢复的版本号"
+// @Success 200 {object} Response{data=service.FrontendMoment}
+// @Failure 400 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /moments/{id}/revisions/{version}/restore [post]
+func (h *MomentHandler) RestoreRevision(c *gin.Context) {
+	id, ok := h.parseMomentID(c)
+	if !ok {
+		return
+	}
+	claims := auth.MustGetAuthClaims(c)
+
+	version, ok := parseRevisionVersion(c.Param("version"))
+	if !ok || version == 0 {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的版本号",
+			Data:    nil,
+		})
+		return
+	}
+
+	moment, err := h.MomentService.RestoreRevision(c, id, claims.UserID, version)
+	if err != nil {
+		h.revisionError(c, "恢复动态版本失败", id, err)
+		return
+	}
+	if moment == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "动态不存在",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "恢复成功",
+		Data:    moment,
+	})
+}
+
+// parseRevisionVersion 解析版本号，空字符串视为未指定（返回 0）
+func parseRevisionVersion(value string) (int, bool) {
+	if value == "" {
+		return 0, true
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+// revisionError 将修订版本相关的错误写入响应
+func (h *MomentHandler) revisionError(c *gin.Context, logMessage string, id uint64, err error) {
+	switch {
+	case errors.Is(err, errMsg.ErrMomentRevisionNotFound):
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "版本不存在",
+			Data:    nil,
+		})
+	case err.Error() == "无权操作此动态":
+		c.JSON(http.StatusForbidden, Response{
+			Code:    1,
+			Message: "无权操作此动态",
+			Data:    nil,
+		})
+	default:
+		h.MomentService.Log.Error(logMessage, "id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+	}
+}
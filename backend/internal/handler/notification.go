@@ -26,13 +26,54 @@ func (h *NotificationHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *
 	authGroup := apiGroup.Group("")
 	authGroup.Use(authMiddleware.Handle())
 	{
+		authGroup.GET("/notifications", h.ListNotifications) // 通知历史，包含已读通知
 		authGroup.GET("/notifications/unread", h.ListUnreadNotifications)
 		authGroup.POST("/notifications/:id/read", h.MarkAsRead)
 		authGroup.GET("/notifications/count", h.GetUnreadCount)
+		authGroup.POST("/notifications/read", h.MarkManyAsRead) // 批量标记已读
 		authGroup.POST("/notifications/read-all", h.MarkAllAsRead)
+		authGroup.POST("/notifications/delete", h.DeleteNotifications) // 批量删除
+		authGroup.DELETE("/notifications/:id", h.DeleteNotification)
 	}
 }
 
+// ListNotifications 获取通知历史
+// @Summary 获取通知历史
+// @Description 分页获取当前用户的通知，包含已读通知，最新的在前；支持 filter=type:eq:comment、filter=is_read:eq:false 过滤
+// @Tags notifications
+// @Produce json
+// @Security OAuth2Password
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param filter query []string false "过滤条件，格式为 字段:操作符:值" collectionFormat(multi)
+// @Success 200 {object} Response{data=service.NotificationListResponse}
+// @Failure 500 {object} Response
+// @Router /notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	claims := auth.MustGetAuthClaims(c)
+	queryParams := ParseQueryParams(c, "notifications")
+
+	response, err := h.NotificationService.ListNotifications(c, claims.UserID, &service.NotificationQueryParams{
+		Page:    queryParams.Page,
+		Size:    queryParams.Size,
+		Filters: queryParams.Filters,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    response,
+	})
+}
+
 func (h *NotificationHandler) ListUnreadNotifications(c *gin.Context) {
 	claims, ok := auth.GetAuthClaims(c)
 	if !ok {
@@ -176,3 +217,146 @@ func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
 		Data:    nil,
 	})
 }
+
+// MarkManyAsRead 批量标记已读
+// @Summary 批量标记已读
+// @Description 将指定的通知标记为已读，一次最多 100 条，不属于当前用户的通知会被忽略
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param request body service.NotificationIDsRequest true "通知ID"
+// @Success 200 {object} Response{data=map[string]int64}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /notifications/read [post]
+func (h *NotificationHandler) MarkManyAsRead(c *gin.Context) {
+	req, ok := h.bindIDs(c)
+	if !ok {
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+
+	updated, err := h.NotificationService.MarkManyAsRead(c.Request.Context(), claims.UserID, req.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "标记成功",
+		Data: map[string]int64{
+			"count": updated,
+		},
+	})
+}
+
+// DeleteNotifications 批量删除通知
+// @Summary 批量删除通知
+// @Description 删除指定的通知，一次最多 100 条，不属于当前用户的通知会被忽略
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param request body service.NotificationIDsRequest true "通知ID"
+// @Success 200 {object} Response{data=map[string]int64}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /notifications/delete [post]
+func (h *NotificationHandler) DeleteNotifications(c *gin.Context) {
+	req, ok := h.bindIDs(c)
+	if !ok {
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+
+	deleted, err := h.NotificationService.DeleteNotifications(c.Request.Context(), claims.UserID, req.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "删除成功",
+		Data: map[string]int64{
+			"count": deleted,
+		},
+	})
+}
+
+// DeleteNotification 删除通知
+// @Summary 删除通知
+// @Tags notifications
+// @Produce json
+// @Security OAuth2Password
+// @Param id path int true "通知ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /notifications/{id} [delete]
+func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.NotificationService.Log.Error("无效的通知ID", "id", idStr, "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的通知ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+
+	deleted, err := h.NotificationService.DeleteNotifications(c.Request.Context(), claims.UserID, []uint64{id})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+	if deleted == 0 {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "通知不存在",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "删除成功",
+		Data:    nil,
+	})
+}
+
+func (h *NotificationHandler) bindIDs(c *gin.Context) (*service.NotificationIDsRequest, bool) {
+	var req service.NotificationIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.NotificationService.Log.Error("参数校验失败", "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "参数校验失败",
+			Data:    nil,
+		})
+		return nil, false
+	}
+	return &req, true
+}
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	middle "github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/server"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+type NotificationChannelHandler struct {
+	NotificationChannelService *service.NotificationChannelService
+}
+
+func NewNotificationChannelHandler(notificationChannelService *service.NotificationChannelService) *NotificationChannelHandler {
+	return &NotificationChannelHandler{
+		NotificationChannelService: notificationChannelService,
+	}
+}
+
+// RegisterRoutes 注册站外通知渠道相关的路由
+func (h *NotificationChannelHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *server.GinEngine, authMiddleware *middle.AuthMiddleware) {
+	authGroup := apiGroup.Group("/notification-channels")
+	authGroup.Use(authMiddleware.Handle())
+	{
+		authGroup.GET("/options", h.GetOptions)    // 可用的渠道类型
+		authGroup.GET("", h.ListChannels)          // 渠道列表
+		authGroup.POST("", h.CreateChannel)        // 添加渠道
+		authGroup.PUT("/:id", h.UpdateChannel)     // 修改渠道
+		authGroup.DELETE("/:id", h.DeleteChannel)  // 删除渠道
+		authGroup.POST("/:id/test", h.TestChannel) // 发送测试通知
+	}
+}
+
+// GetOptions 获取可用的通知渠道
+// @Summary 获取可用的通知渠道
+// @Description 返回站点已配置的渠道类型、可选的通知类型，以及浏览器订阅推送使用的 VAPID 公钥
+// @Tags notifications
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {object} Response{data=service.NotificationChannelOptions}
+// @Router /notification-channels/options [get]
+func (h *NotificationChannelHandler) GetOptions(c *gin.Context) {
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    h.NotificationChannelService.GetOptions(),
+	})
+}
+
+// ListChannels 获取通知渠道列表
+// @Summary 获取通知渠道列表
+// @Description 获取当前用户的全部站外通知渠道，不包含投递目标中的密钥
+// @Tags notifications
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {object} Response{data=[]service.FrontendNotificationChannel}
+// @Failure 500 {object} Response
+// @Router /notification-channels [get]
+func (h *NotificationChannelHandler) ListChannels(c *gin.Context) {
+	claims := auth.MustGetAuthClaims(c)
+
+	channels, err := h.NotificationChannelService.ListChannels(c.Request.Context(), claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    channels,
+	})
+}
+
+// CreateChannel 添加通知渠道
+// @Summary 添加通知渠道
+// @Description 添加邮件、Webhook、Telegram 或浏览器推送渠道，types 为空表示接收全部类型的通知；投递目标相同的渠道已存在时更新该渠道
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param channel body service.NotificationChannelRequest true "渠道信息"
+// @Success 200 {object} Response{data=service.FrontendNotificationChannel}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /notification-channels [post]
+func (h *NotificationChannelHandler) CreateChannel(c *gin.Context) {
+	var req service.NotificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.NotificationChannelService.Log.Error("参数校验失败", "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "参数校验失败",
+			Data:    nil,
+		})
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+
+	channel, err := h.NotificationChannelService.CreateChannel(c.Request.Context(), claims.UserID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "保存成功",
+		Data:    channel,
+	})
+}
+
+// UpdateChannel 修改通知渠道
+// @Summary 修改通知渠道
+// @Description 修改名称、投递目标、接收的通知类型或启用状态，省略的字段保持不变
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param id path int true "渠道ID"
+// @Param channel body service.NotificationChannelUpdateRequest true "渠道信息"
+// @Success 200 {object} Response{data=service.FrontendNotificationChannel}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /notification-channels/{id} [put]
+func (h *NotificationChannelHandler) UpdateChannel(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req service.NotificationChannelUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.NotificationChannelService.Log.Error("参数校验失败", "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "参数校验失败",
+			Data:    nil,
+		})
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+
+	channel, err := h.NotificationChannelService.UpdateChannel(c.Request.Context(), claims.UserID, id, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "保存成功",
+		Data:    channel,
+	})
+}
+
+// DeleteChannel 删除通知渠道
+// @Summary 删除通知渠道
+// @Description 删除渠道，尚未投递的通知不再投递
+// @Tags notifications
+// @Produce json
+// @Security OAuth2Password
+// @Param id path int true "渠道ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /notification-channels/{id} [delete]
+func (h *NotificationChannelHandler) DeleteChannel(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+
+	if err := h.NotificationChannelService.DeleteChannel(c.Request.Context(), claims.UserID, id); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "删除成功",
+		Data:    nil,
+	})
+}
+
+// TestChannel 发送测试通知
+// @Summary 发送测试通知
+// @Description 立即向渠道发送一条测试通知，失败时返回错误原因（只包含状态码，不包含对方的响应内容）
+// @Tags notifications
+// @Produce json
+// @Security OAuth2Password
+// @Param id path int true "渠道ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 502 {object} Response
+// @Router /notification-channels/{id}/test [post]
+func (h *NotificationChannelHandler) TestChannel(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+
+	err := h.NotificationChannelService.TestChannel(c.Request.Context(), claims.UserID, id)
+	switch {
+	case err == nil:
+	case errors.Is(err, errMsg.ErrNotificationChannelNotFound),
+		errors.Is(err, errMsg.ErrNotificationChannelUnavailable),
+		err.Error() == "系统内部错误":
+		h.respondError(c, err)
+		return
+	default:
+		c.JSON(http.StatusBadGateway, Response{
+			Code:    1,
+			Message: "测试通知发送失败：" + err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "测试通知已发送",
+		Data:    nil,
+	})
+}
+
+func (h *NotificationChannelHandler) parseID(c *gin.Context) (uint64, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.NotificationChannelService.Log.Error("无效的渠道ID", "id", idStr, "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的渠道ID",
+			Data:    nil,
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *NotificationChannelHandler) respondError(c *gin.Context, err error) {
+	status, message := http.StatusInternalServerError, "系统内部错误"
+	switch {
+	case errors.Is(err, errMsg.ErrNotificationChannelNotFound):
+		status, message = http.StatusNotFound, "通知渠道不存在"
+	case errors.Is(err, errMsg.ErrNotificationChannelUnavailable):
+		status, message = http.StatusBadRequest, "站点未配置该通知渠道"
+	case errors.Is(err, errMsg.ErrInvalidNotificationTarget):
+		status, message = http.StatusBadRequest, "投递目标无效"
+	case errors.Is(err, errMsg.ErrInvalidNotificationType):
+		status, message = http.StatusBadRequest, "不支持的通知类型"
+	}
+	c.JSON(status, Response{
+		Code:    1,
+		Message: message,
+		Data:    nil,
+	})
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	middle "github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/server"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+// unsubscribePage 退订页面，邮件中的链接直接在浏览器打开，因此返回 HTML 而不是 JSON
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head><meta charset="UTF-8"><meta name="viewport" content="width=device-width,initial-scale=1"><title>退订通知摘要</title></head>
+<body style="margin:0;padding:24px;background:#fdf2f8;font-family:-apple-system,'PingFang SC','Microsoft YaHei',sans-serif;color:#374151;">
+  <div style="max-width:520px;margin:0 auto;background:#ffffff;border-radius:12px;padding:32px;text-align:center;">
+    <p style="font-size:16px;">{{.Message}}</p>
+    {{- if .Token}}
+    <form method="post" style="margin:32px 0;">
+      <input type="hidden" name="token" value="{{.Token}}">
+      <button type="submit" style="padding:12px 28px;background:#ec4899;color:#ffffff;border:none;border-radius:8px;font-size:15px;cursor:pointer;">确认退订</button>
+    </form>
+    {{- end}}
+  </div>
+</body>
+</html>`))
+
+type NotificationDigestHandler struct {
+	NotificationDigestService *service.NotificationDigestService
+}
+
+func NewNotificationDigestHandler(notificationDigestService *service.NotificationDigestService) *NotificationDigestHandler {
+	return &NotificationDigestHandler{
+		NotificationDigestService: notificationDigestService,
+	}
+}
+
+// RegisterRoutes 注册通知摘要相关的路由
+func (h *NotificationDigestHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *server.GinEngine, authMiddleware *middle.AuthMiddleware) {
+	// 退订链接来自邮件，无需登录
+	apiGroup.GET("/notification-digest/unsubscribe", h.UnsubscribePage) // 退订确认页
+	apiGroup.POST("/notification-digest/unsubscribe", h.Unsubscribe)    // 退订，同时支持邮件客户端的一键退订
+
+	authGroup := apiGroup.Group("/notification-digest")
+	authGroup.Use(authMiddleware.Handle())
+	{
+		authGroup.GET("", h.GetSettings)    // 摘要设置
+		authGroup.PUT("", h.UpdateSettings) // 修改摘要设置
+	}
+}
+
+// GetSettings 获取通知摘要设置
+// @Summary 获取通知摘要设置
+// @Description 获取当前用户的通知摘要设置，未设置过时返回默认值（关闭）
+// @Tags notifications
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {object} Response{data=service.FrontendNotificationDigest}
+// @Failure 500 {object} Response
+// @Router /notification-digest [get]
+func (h *NotificationDigestHandler) GetSettings(c *gin.Context) {
+	claims := auth.MustGetAuthClaims(c)
+
+	settings, err := h.NotificationDigestService.GetSettings(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    settings,
+	})
+}
+
+// UpdateSettings 修改通知摘要设置
+// @Summary 修改通知摘要设置
+// @Description 按天或按周将未读通知汇总为一封邮件发送到账号邮箱，开启后邮件渠道不再逐条发送通知；需要配置邮件服务和站点地址（mail.site_url）
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param settings body service.NotificationDigestRequest true "摘要设置"
+// @Success 200 {object} Response{data=service.FrontendNotificationDigest}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /notification-digest [put]
+func (h *NotificationDigestHandler) UpdateSettings(c *gin.Context) {
+	var req service.NotificationDigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.NotificationDigestService.Log.Error("参数校验失败", "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "参数校验失败",
+			Data:    nil,
+		})
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+
+	settings, err := h.NotificationDigestService.UpdateSettings(c.Request.Context(), claims.UserID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "保存成功",
+		Data:    settings,
+	})
+}
+
+// UnsubscribePage 退订确认页
+// @Summary 退订确认页
+// @Description 邮件中的退订链接，返回 HTML 确认页面，确认后以 POST 请求退订
+// @Tags notifications
+// @Produce html
+// @Param token query string true "退订令牌"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Router /notification-digest/unsubscribe [get]
+func (h *NotificationDigestHandler) UnsubscribePage(c *gin.Context) {
+	token := c.Query("token")
+	if err := h.NotificationDigestService.CheckUnsubscribeToken(c.Request.Context(), token); err != nil {
+		h.renderUnsubscribe(c, h.unsubscribeErrorStatus(err), h.unsubscribeErrorMessage(err), "")
+		return
+	}
+	h.renderUnsubscribe(c, http.StatusOK, "确认不再接收通知摘要邮件？退订后可以在通知设置中重新开启。", token)
+}
+
+// Unsubscribe 退订通知摘要
+// @Summary 退订通知摘要
+// @Description 确认页提交或邮件客户端一键退订（RFC 8058），令牌可以放在查询参数或表单中
+// @Tags notifications
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param token query string false "退订令牌"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Router /notification-digest/unsubscribe [post]
+func (h *NotificationDigestHandler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		token = c.PostForm("token")
+	}
+	if err := h.NotificationDigestService.Unsubscribe(c.Request.Context(), token); err != nil {
+		h.renderUnsubscribe(c, h.unsubscribeErrorStatus(err), h.unsubscribeErrorMessage(err), "")
+		return
+	}
+	h.renderUnsubscribe(c, http.StatusOK, "已退订通知摘要，之后不会再收到摘要邮件。", "")
+}
+
+func (h *NotificationDigestHandler) renderUnsubscribe(c *gin.Context, status int, message, token string) {
+	var buf bytes.Buffer
+	if err := unsubscribePage.Execute(&buf, map[string]string{"Message": message, "Token": token}); err != nil {
+		h.NotificationDigestService.Log.Error("渲染退订页面失败", "error", err)
+		c.String(http.StatusInternalServerError, "系统内部错误")
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+func (h *NotificationDigestHandler) unsubscribeErrorStatus(err error) int {
+	if errors.Is(err, errMsg.ErrInvalidUnsubscribeToken) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *NotificationDigestHandler) unsubscribeErrorMessage(err error) string {
+	if errors.Is(err, errMsg.ErrInvalidUnsubscribeToken) {
+		return "退订链接无效或已过期。"
+	}
+	return "系统内部错误，请稍后重试。"
+}
+
+func (h *NotificationDigestHandler) respondError(c *gin.Context, err error) {
+	status, message := http.StatusInternalServerError, "系统内部错误"
+	switch {
+	case errors.Is(err, errMsg.ErrInvalidDigestSchedule):
+		status, message = http.StatusBadRequest, "发送时间或时区无效"
+	case errors.Is(err, errMsg.ErrNotificationChannelUnavailable):
+		status, message = http.StatusBadRequest, "站点未配置邮件服务"
+	case errors.Is(err, errMsg.ErrDigestSiteURLRequired):
+		status, message = http.StatusBadRequest, "站点地址未配置"
+	case errors.Is(err, errMsg.ErrDigestEmailRequired):
+		status, message = http.StatusBadRequest, "请先设置账号邮箱"
+	}
+	c.JSON(status, Response{
+		Code:    1,
+		Message: message,
+		Data:    nil,
+	})
+}
@@ -0,0 +1,306 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+	"github.com/bookandmusic/love-girl/internal/config"
+	middle "github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/realtime"
+	"github.com/bookandmusic/love-girl/internal/server"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+const (
+	// defaultHeartbeat 未配置时的心跳间隔，小于常见反向代理 60 秒的空闲超时
+	defaultHeartbeat = 25 * time.Second
+	// sseRetry 断线后浏览器自动重连的等待时间（毫秒），仅对使用 Authorization 请求头的连接有效
+	sseRetry = 3000
+)
+
+// StreamTicket 实时连接票据
+type StreamTicket struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int    `json:"expiresIn"` // 有效期（秒）
+}
+
+// streamMessage WebSocket 推送的消息，字段与 SSE 的 id、event、data 对应
+type streamMessage struct {
+	ID   uint64 `json:"id,omitempty"`
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// NotificationStreamHandler 实时推送通知、评论数和点赞数
+// 长连接不经过 API 分组的请求超时中间件，因此直接注册在引擎上
+type NotificationStreamHandler struct {
+	NotificationService *service.NotificationService
+	AuthMiddleware      *middle.AuthMiddleware
+	heartbeat           time.Duration
+}
+
+func NewNotificationStreamHandler(notificationService *service.NotificationService, authMiddleware *middle.AuthMiddleware, appCfg *config.AppConfig) *NotificationStreamHandler {
+	heartbeat := defaultHeartbeat
+	if appCfg.Realtime.Heartbeat > 0 {
+		heartbeat = time.Duration(appCfg.Realtime.Heartbeat) * time.Second
+	}
+	return &NotificationStreamHandler{
+		NotificationService: notificationService,
+		AuthMiddleware:      authMiddleware,
+		heartbeat:           heartbeat,
+	}
+}
+
+func (h *NotificationStreamHandler) RegisterRoutes(ginEngine *server.GinEngine) {
+	ginEngine.Engine.POST("/api/v1/notifications/stream/ticket", middle.Logging(), h.AuthMiddleware.Handle(), h.IssueTicket)
+
+	group := ginEngine.Engine.Group("/api/v1", middle.Logging(), h.AuthMiddleware.HandleWithTicket())
+	group.GET("/notifications/stream", h.Stream)
+	group.GET("/notifications/ws", h.WebSocket)
+}
+
+// IssueTicket 签发实时连接票据
+// @Summary 签发实时连接票据
+// @Description EventSource 和 WebSocket 无法设置请求头，连接前先获取一次性票据，通过 ticket 查询参数传递。
+// @Description 票据只能使用一次，断线重连前需要重新获取
+// @Tags notifications
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {object} Response{data=StreamTicket}
+// @Failure 401 {object} Response
+// @Failure 429 {object} Response
+// @Router /notifications/stream/ticket [post]
+func (h *NotificationStreamHandler) IssueTicket(c *gin.Context) {
+	claims := auth.MustGetAuthClaims(c)
+
+	ticket, err := h.AuthMiddleware.Tickets.Issue(claims)
+	if err != nil {
+		status, message := http.StatusInternalServerError, "系统内部错误"
+		if errors.Is(err, auth.ErrTooManyTickets) {
+			status, message = http.StatusTooManyRequests, "请求过于频繁，请稍后再试"
+		}
+		h.NotificationService.Log.Warn("签发实时连接票据失败", "error", err, "userID", claims.UserID)
+		c.JSON(status, Response{
+			Code:    1,
+			Message: message,
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "签发成功",
+		Data: StreamTicket{
+			Ticket:    ticket,
+			ExpiresIn: int(h.AuthMiddleware.Tickets.TTL().Seconds()),
+		},
+	})
+}
+
+// Stream 通过 SSE 推送实时事件
+// @Summary 实时事件流（SSE）
+// @Description 推送 notification、unread_count、comment_count、reactions 事件；连接建立后先推送当前未读数。
+// @Description 重连时通过 Last-Event-ID 请求头或 lastEventId 查询参数补发错过的事件，无法补发时推送 resync 事件，客户端需重新拉取数据。
+// @Description EventSource 无法设置请求头，可通过 ticket 查询参数传递一次性票据（POST /notifications/stream/ticket）。
+// @Description 票据只能使用一次，浏览器自动重连会复用原 URL 而返回 401；使用票据时客户端需关闭连接、重新获取票据，并通过 lastEventId 查询参数手动重连
+// @Tags notifications
+// @Produce text/event-stream
+// @Security OAuth2Password
+// @Param ticket query string false "一次性票据，未使用 Authorization 请求头时必填"
+// @Param lastEventId query int false "最后收到的事件ID，与 Last-Event-ID 请求头等效"
+// @Success 200 {string} string "事件流"
+// @Failure 401 {object} Response
+// @Failure 429 {object} Response
+// @Router /notifications/stream [get]
+func (h *NotificationStreamHandler) Stream(c *gin.Context) {
+	claims := auth.MustGetAuthClaims(c)
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	sub, missed, complete, ok := h.subscribe(c, claims.UserID, lastEventID)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	// 长连接不受服务器写超时限制
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭 Nginx 的响应缓冲
+	c.Status(http.StatusOK)
+
+	write := func(id uint64, eventType string, data any) bool {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			h.NotificationService.Log.Error("序列化实时事件失败", "error", err, "type", eventType)
+			return true
+		}
+		if id != 0 {
+			_, _ = fmt.Fprintf(c.Writer, "id: %d\n", id)
+		}
+		_, err = fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", eventType, payload)
+		c.Writer.Flush()
+		return err == nil
+	}
+
+	_, _ = fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetry)
+	for _, message := range h.initialMessages(c, claims.UserID, missed, complete) {
+		if !write(message.ID, message.Type, message.Data) {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-sub.Done():
+			return
+		case event := <-sub.Events():
+			if !write(event.ID, event.Type, h.NotificationService.RenderEvent(c, event)) {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// WebSocket 通过 WebSocket 推送实时事件
+// @Summary 实时事件流（WebSocket）
+// @Description 与 SSE 推送相同的事件，每条消息为 {"id","type","data"} 格式的 JSON 文本；服务端定期发送 ping 帧。
+// @Description 重连时通过 lastEventId 查询参数补发错过的事件；浏览器通过 ticket 查询参数传递一次性票据
+// @Tags notifications
+// @Security OAuth2Password
+// @Param ticket query string false "一次性票据，未使用 Authorization 请求头时必填"
+// @Param lastEventId query int false "最后收到的事件ID"
+// @Success 101 {string} string "切换协议"
+// @Failure 400 {object} Response
+// @Failure 401 {object} Response
+// @Failure 429 {object} Response
+// @Router /notifications/ws [get]
+func (h *NotificationStreamHandler) WebSocket(c *gin.Context) {
+	claims := auth.MustGetAuthClaims(c)
+
+	if !realtime.IsWebSocketRequest(c.Request) {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "需要 WebSocket 连接",
+			Data:    nil,
+		})
+		return
+	}
+
+	sub, missed, complete, ok := h.subscribe(c, claims.UserID, c.Query("lastEventId"))
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	ws, err := realtime.UpgradeWebSocket(c.Writer, c.Request)
+	if err != nil {
+		h.NotificationService.Log.Info("WebSocket 握手失败", "error", err, "userID", claims.UserID)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "WebSocket 握手失败",
+			Data:    nil,
+		})
+		return
+	}
+	defer func() { _ = ws.Close() }()
+
+	write := func(message streamMessage) bool {
+		payload, err := json.Marshal(message)
+		if err != nil {
+			h.NotificationService.Log.Error("序列化实时事件失败", "error", err, "type", message.Type)
+			return true
+		}
+		return ws.WriteText(payload) == nil
+	}
+
+	for _, message := range h.initialMessages(c, claims.UserID, missed, complete) {
+		if !write(message) {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ws.Closed():
+			return
+		case <-sub.Done():
+			return
+		case event := <-sub.Events():
+			if !write(streamMessage{ID: event.ID, Type: event.Type, Data: h.NotificationService.RenderEvent(c, event)}) {
+				return
+			}
+		case <-ticker.C:
+			if ws.Ping() != nil {
+				return
+			}
+		}
+	}
+}
+
+// subscribe 订阅当前用户的事件，超出连接数限制时返回 429
+func (h *NotificationStreamHandler) subscribe(c *gin.Context, userID uint64, lastEventID string) (*realtime.Subscription, []realtime.Event, bool, bool) {
+	var lastID uint64
+	if lastEventID != "" {
+		// 无法解析的ID按无法补发处理
+		parsed, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			parsed = 1
+		}
+		lastID = parsed
+	}
+
+	sub, missed, complete, err := h.NotificationService.Subscribe(userID, lastID)
+	if err != nil {
+		status, message := http.StatusServiceUnavailable, "服务正在重启，请稍后重连"
+		if errors.Is(err, realtime.ErrTooManyConnections) {
+			status, message = http.StatusTooManyRequests, "实时连接数过多，请关闭其他页面后重试"
+		}
+		h.NotificationService.Log.Info("实时连接被拒绝", "error", err, "userID", userID)
+		c.JSON(status, Response{
+			Code:    1,
+			Message: message,
+			Data:    nil,
+		})
+		return nil, nil, false, false
+	}
+	return sub, missed, complete, true
+}
+
+// initialMessages 连接建立后首先推送的消息：当前未读数，以及补发错过的事件或 resync
+func (h *NotificationStreamHandler) initialMessages(c *gin.Context, userID uint64, missed []realtime.Event, complete bool) []streamMessage {
+	var messages []streamMessage
+	if count, err := h.NotificationService.GetUnreadCount(c.Request.Context(), userID); err == nil {
+		messages = append(messages, streamMessage{Type: service.EventUnreadCount, Data: service.UnreadCountEvent{Count: count}})
+	}
+	if !complete {
+		return append(messages, streamMessage{Type: service.EventResync, Data: struct{}{}})
+	}
+	for _, event := range missed {
+		messages = append(messages, streamMessage{ID: event.ID, Type: event.Type, Data: h.NotificationService.RenderEvent(c, event)})
+	}
+	return messages
+}
@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	middle "github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/server"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+const (
+	// oidcBindingCookie 浏览器绑定 Cookie，回调时与 state 对应的流程比对，防止登录 CSRF
+	oidcBindingCookie = "lg_oidc_binding"
+	oidcCookiePath    = "/api/v1/auth/oidc"
+	oidcCookieMaxAge  = 600
+)
+
+type OIDCHandler struct {
+	OIDCService *service.OIDCService
+}
+
+func NewOIDCHandler(oidcService *service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		OIDCService: oidcService,
+	}
+}
+
+// RegisterRoutes 注册 OpenID Connect 登录相关的路由
+func (h *OIDCHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *server.GinEngine, authMiddleware *middle.AuthMiddleware) {
+	// 每次发起登录都会在内存中保存一个流程，每个IP每10分钟最多20次
+	flowLimiter := middle.RateLimit(20, 10*time.Minute)
+
+	oidcGroup := apiGroup.Group("/auth/oidc")
+	oidcGroup.GET("/config", h.GetConfig)
+	oidcGroup.GET("/login", flowLimiter, h.Login)
+	oidcGroup.GET("/callback", h.Callback)
+
+	// 需要认证的路由
+	authGroup := oidcGroup.Group("")
+	authGroup.Use(authMiddleware.Handle(), h.requireLogin)
+	{
+		authGroup.POST("/link", flowLimiter, h.Link)          // 关联外部身份
+		authGroup.GET("/identities", h.ListIdentities)        // 已关联的外部身份
+		authGroup.DELETE("/identities/:id", h.UnlinkIdentity) // 解除关联
+	}
+}
+
+// requireLogin 身份关联只允许通过登录会话操作
+func (h *OIDCHandler) requireLogin(c *gin.Context) {
+	if auth.MustGetAuthClaims(c).IsAPIToken() {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response{
+			Code:    1,
+			Message: "访问令牌无权管理外部身份",
+			Data:    nil,
+		})
+		return
+	}
+	c.Next()
+}
+
+// setBindingCookie 写入浏览器绑定 Cookie
+// 回调是身份提供方发起的跨站跳转，SameSite 需为 Lax 才会携带
+func setBindingCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcBindingCookie, value, maxAge, oidcCookiePath, "", secure, true)
+}
+
+// redirectToFrontend 跳转回前端页面，结果放在 URL 片段中，避免令牌出现在服务器日志和 Referer 中
+func (h *OIDCHandler) redirectToFrontend(c *gin.Context, values url.Values) {
+	c.Redirect(http.StatusFound, h.OIDCService.FrontendRedirect()+"#"+values.Encode())
+}
+
+// oidcErrorCode 将错误转换为前端可识别的错误码
+func oidcErrorCode(err error) string {
+	switch {
+	case errors.Is(err, errMsg.ErrOIDCDisabled):
+		return "disabled"
+	case errors.Is(err, errMsg.ErrOIDCInvalidState):
+		return "invalid_state"
+	case errors.Is(err, errMsg.ErrOIDCNotLinked), errors.Is(err, errMsg.ErrOIDCEmailMismatch):
+		return "not_linked"
+	case errors.Is(err, errMsg.ErrOIDCAlreadyLinked):
+		return "already_linked"
+	case errors.Is(err, errMsg.ErrOIDCProviderFailed):
+		return "provider_error"
+	case errors.Is(err, errMsg.ErrOIDCTooManyFlows):
+		return "too_many_requests"
+	default:
+		return "server_error"
+	}
+}
+
+// GetConfig 获取 OIDC 登录配置
+// @Summary 获取 OIDC 登录配置
+// @Description 登录页据此决定是否显示第三方登录按钮
+// @Tags auth
+// @Produce json
+// @Success 200 {object} Response{data=service.OIDCConfigResponse}
+// @Router /auth/oidc/config [get]
+func (h *OIDCHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    h.OIDCService.GetConfig(),
+	})
+}
+
+// Login 跳转到身份提供方登录
+// @Summary 跳转到身份提供方登录
+// @Description 生成 state、nonce 与 PKCE 校验码后重定向到身份提供方的授权页面
+// @Tags auth
+// @Success 302
+// @Failure 404 {object} Response
+// @Failure 429 {object} Response
+// @Router /auth/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	authURL, binding, err := h.OIDCService.BeginAuth(c.Request.Context(), 0)
+	if err != nil {
+		if errors.Is(err, errMsg.ErrOIDCDisabled) {
+			c.JSON(http.StatusNotFound, Response{
+				Code:    1,
+				Message: "未启用第三方登录",
+				Data:    nil,
+			})
+			return
+		}
+		h.redirectToFrontend(c, url.Values{"error": {oidcErrorCode(err)}})
+		return
+	}
+
+	setBindingCookie(c, binding, oidcCookieMaxAge)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback 身份提供方回调
+// @Summary 身份提供方回调
+// @Description 校验授权结果后跳转回前端，登录成功时通过 URL 片段 access_token 返回令牌，失败时返回 error
+// @Tags auth
+// @Param state query string true "state"
+// @Param code query string true "授权码"
+// @Success 302
+// @Router /auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	binding, _ := c.Cookie(oidcBindingCookie)
+	setBindingCookie(c, "", -1)
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		h.OIDCService.Log.Warn("身份提供方返回错误", "error", providerErr, "description", c.Query("error_description"))
+		h.redirectToFrontend(c, url.Values{"error": {"provider_error"}})
+		return
+	}
+
+	result, err := h.OIDCService.HandleCallback(c.Request.Context(), c.Query("state"), c.Query("code"), binding)
+	if err != nil {
+		h.redirectToFrontend(c, url.Values{"error": {oidcErrorCode(err)}})
+		return
+	}
+
+	if result.Linked {
+		h.redirectToFrontend(c, url.Values{"linked": {"1"}})
+		return
+	}
+	h.redirectToFrontend(c, url.Values{"access_token": {result.Token}})
+}
+
+// Link 开始关联外部身份
+// @Summary 开始关联外部身份
+// @Description 返回身份提供方授权地址，浏览器跳转完成授权后外部身份将关联到当前用户
+// @Tags auth
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {object} Response{data=map[string]string}
+// @Failure 404 {object} Response
+// @Failure 429 {object} Response
+// @Failure 500 {object} Response
+// @Router /auth/oidc/link [post]
+func (h *OIDCHandler) Link(c *gin.Context) {
+	claims := auth.MustGetAuthClaims(c)
+
+	authURL, binding, err := h.OIDCService.BeginAuth(c.Request.Context(), claims.UserID)
+	if err != nil {
+		if errors.Is(err, errMsg.ErrOIDCDisabled) {
+			c.JSON(http.StatusNotFound, Response{
+				Code:    1,
+				Message: "未启用第三方登录",
+				Data:    nil,
+			})
+			return
+		}
+		if errors.Is(err, errMsg.ErrOIDCTooManyFlows) {
+			c.JSON(http.StatusTooManyRequests, Response{
+				Code:    1,
+				Message: "请求过于频繁，请稍后再试",
+				Data:    nil,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "身份提供方不可用",
+			Data:    nil,
+		})
+		return
+	}
+
+	setBindingCookie(c, binding, oidcCookieMaxAge)
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "请在浏览器中完成授权",
+		Data:    map[string]string{"url": authURL},
+	})
+}
+
+// ListIdentities 获取已关联的外部身份
+// @Summary 获取已关联的外部身份
+// @Description 获取当前用户关联的全部外部身份
+// @Tags auth
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {object} Response{data=[]service.FrontendUserIdentity}
+// @Failure 500 {object} Response
+// @Router /auth/oidc/identities [get]
+func (h *OIDCHandler) ListIdentities(c *gin.Context) {
+	claims := auth.MustGetAuthClaims(c)
+
+	identities, err := h.OIDCService.ListIdentities(c.Request.Context(), claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    identities,
+	})
+}
+
+// UnlinkIdentity 解除外部身份关联
+// @Summary 解除外部身份关联
+// @Description 解除后无法再使用该外部身份登录
+// @Tags auth
+// @Produce json
+// @Security OAuth2Password
+// @Param id path int true "外部身份ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /auth/oidc/identities/{id} [delete]
+func (h *OIDCHandler) UnlinkIdentity(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的外部身份ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+
+	deleted, err := h.OIDCService.UnlinkIdentity(c.Request.Context(), claims.UserID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "外部身份不存在",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "解除成功",
+		Data:    nil,
+	})
+}
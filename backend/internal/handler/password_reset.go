@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	middle "github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/server"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+type PasswordResetHandler struct {
+	PasswordResetService *service.PasswordResetService
+}
+
+func NewPasswordResetHandler(passwordResetService *service.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		PasswordResetService: passwordResetService,
+	}
+}
+
+// RegisterRoutes 注册找回密码相关的路由
+func (h *PasswordResetHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *server.GinEngine, authMiddleware *middle.AuthMiddleware) {
+	// 每个IP每15分钟最多5次，防止邮件轰炸和令牌爆破
+	limiter := middle.RateLimit(5, 15*time.Minute)
+	apiGroup.POST("/user/password/forgot", limiter, h.ForgotPassword)
+	apiGroup.POST("/user/password/reset", limiter, h.ResetPassword)
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// ForgotPassword 发送重置密码邮件
+// @Summary 发送重置密码邮件
+// @Description 向用户邮箱发送重置密码链接，链接30分钟内有效且只能使用一次；需要配置邮件服务和站点地址（mail.site_url）
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "用户邮箱"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /user/password/forgot [post]
+func (h *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.PasswordResetService.Log.Error("参数校验失败", "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "请输入有效的邮箱地址",
+			Data:    nil,
+		})
+		return
+	}
+
+	if err := h.PasswordResetService.RequestReset(c.Request.Context(), req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "如果该邮箱已绑定账号，重置邮件将很快送达",
+		Data:    nil,
+	})
+}
+
+// ResetPassword 重置密码
+// @Summary 重置密码
+// @Description 使用邮件中的重置令牌设置新密码，此前签发的登录令牌和个人访问令牌全部失效
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "重置令牌和新密码"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /user/password/reset [post]
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.PasswordResetService.Log.Error("参数校验失败", "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "参数校验失败，密码至少6位",
+			Data:    nil,
+		})
+		return
+	}
+
+	if err := h.PasswordResetService.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		if errors.Is(err, errMsg.ErrInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, Response{
+				Code:    1,
+				Message: "重置链接无效或已过期",
+				Data:    nil,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "密码已重置，请使用新密码登录",
+		Data:    nil,
+	})
+}
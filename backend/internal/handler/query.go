@@ -25,19 +25,25 @@ type QueryParams struct {
 var AllowedSortFields = map[string][]string{
 	"moments":       {"created_at", "likes"},
 	"places":        {"created_at", "name"},
-	"wishes":        {"created_at"},
+	"comments":      {"created_at"},
 	"anniversaries": {"date", "created_at"},
 	"albums":        {"created_at", "name"},
+	"collections":   {"created_at", "name"},
 }
 
 var AllowedFilterFields = map[string]map[string][]string{
 	"moments": {
-		"is_public": {"eq"},
-		"user_id":   {"eq"},
-		"likes":     {"eq", "gt", "lt", "gte", "lte"},
+		"visibility":     {"eq"},
+		"status":         {"eq"},
+		"tag":            {"eq"}, // 话题标签，不带 #
+		"place_id":       {"eq"},
+		"anniversary_id": {"eq"},
+		"user_id":        {"eq"},
+		"likes":          {"eq", "gt", "lt", "gte", "lte"},
 	},
-	"wishes": {
-		"approved": {"eq"},
+	"comments": {
+		"status":    {"eq"}, // 审核状态：pending、approved、spam
+		"moment_id": {"eq"},
 	},
 	"albums": {
 		"name": {"like"},
@@ -45,6 +51,23 @@ var AllowedFilterFields = map[string]map[string][]string{
 	"places": {
 		"name": {"like"},
 	},
+	"collections": {
+		"name": {"like"},
+	},
+	"notifications": {
+		"type":    {"eq"},
+		"is_read": {"eq"},
+	},
+	"audit_logs": {
+		"action":      {"eq"},
+		"actor_id":    {"eq"},
+		"target_type": {"eq"},
+		"target_id":   {"eq"},
+		"success":     {"eq"},
+		"ip":          {"eq"},
+		"request_id":  {"eq"},
+		"created_at":  {"gte", "lte"},
+	},
 }
 
 func ParseQueryParams(c *gin.Context, resource string) *QueryParams {
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	middle "github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/server"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+type SearchHandler struct {
+	SearchService *service.SearchService
+}
+
+func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+	return &SearchHandler{
+		SearchService: searchService,
+	}
+}
+
+// RegisterRoutes 注册全文检索相关的路由
+func (h *SearchHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *server.GinEngine, authMiddleware *middle.AuthMiddleware) {
+	// 公开但有限流的路由：匿名用户只能检索公开内容，每个IP每分钟最多检索30次
+	searchLimit := middle.RateLimitByKey(
+		func(c *gin.Context) string {
+			return c.ClientIP()
+		},
+		30, time.Minute,
+	)
+	apiGroup.GET("/search", authMiddleware.Optional(), searchLimit, h.Search)
+}
+
+// Search 全文检索
+// @Summary 全文检索
+// @Description 检索动态、评论、相册、地点和纪念日，结果按相关度排序并只包含当前访问者可见的内容
+// @Tags search
+// @Produce json
+// @Security OAuth2Password
+// @Param q query string true "搜索关键词"
+// @Param types query string false "限定类型，逗号分隔：moment,comment,album,place,anniversary"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response{data=service.SearchResponse}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	var types []model.SearchDocumentType
+	if raw := c.Query("types"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			docType := model.SearchDocumentType(part)
+			if !docType.Valid() {
+				c.JSON(http.StatusBadRequest, Response{
+					Code:    1,
+					Message: "不支持的检索类型: " + part,
+					Data:    nil,
+				})
+				return
+			}
+			types = append(types, docType)
+		}
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(DefaultPageSize)))
+	page, size = ParsePagination(page, size)
+
+	var viewerID uint64
+	if claims, ok := auth.GetAuthClaims(c); ok {
+		viewerID = claims.UserID
+	}
+
+	resp, err := h.SearchService.Search(c, &service.SearchRequest{
+		Query: c.Query("q"),
+		Types: types,
+		Page:  page,
+		Size:  size,
+	}, viewerID)
+	if err != nil {
+		if errors.Is(err, errMsg.ErrSearchQueryEmpty) {
+			c.JSON(http.StatusBadRequest, Response{
+				Code:    1,
+				Message: "请输入搜索关键词",
+				Data:    nil,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    resp,
+	})
+}
@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	middle "github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/server"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+// SharePasswordHeader 访问密码保护分享时携带密码的请求头
+const SharePasswordHeader = "X-Share-Password"
+
+type ShareHandler struct {
+	ShareService *service.ShareService
+	FileHandler  *FileHandler
+}
+
+func NewShareHandler(shareService *service.ShareService, fileHandler *FileHandler) *ShareHandler {
+	return &ShareHandler{
+		ShareService: shareService,
+		FileHandler:  fileHandler,
+	}
+}
+
+// RegisterRoutes 注册分享链接相关的路由
+func (h *ShareHandler) RegisterRoutes(apiGroup *gin.RouterGroup, server *server.GinEngine, authMiddleware *middle.AuthMiddleware) {
+	// 公开路由：每个IP对同一分享每分钟最多尝试30次，防止暴力破解密码
+	shareLimiter := middle.RateLimitByKey(
+		func(c *gin.Context) string {
+			return c.ClientIP() + ":" + c.Param("token")
+		},
+		30, time.Minute,
+	)
+	apiGroup.GET("/s/:token", shareLimiter, h.ResolveShare)
+	// 文件接口同样接受密码，携带密码的请求与分享页共用限额；页面图片使用访问令牌，不受影响
+	fileLimiter := func(c *gin.Context) {
+		if shareAccessFromRequest(c).Password == "" {
+			c.Next()
+			return
+		}
+		shareLimiter(c)
+	}
+	apiGroup.GET("/s/:token/files/:fileId", fileLimiter, h.GetShareFile)
+
+	// 需要认证的路由
+	authGroup := apiGroup.Group("")
+	authGroup.Use(authMiddleware.Handle())
+	{
+		authGroup.POST("/shares", h.CreateShare)       // 创建分享
+		authGroup.GET("/shares", h.ListShares)         // 分享列表
+		authGroup.DELETE("/shares/:id", h.RevokeShare) // 撤销分享
+	}
+}
+
+// shareAccessFromRequest 从请求中读取分享访问凭证
+func shareAccessFromRequest(c *gin.Context) service.ShareAccess {
+	password := c.GetHeader(SharePasswordHeader)
+	if password == "" {
+		password = c.Query("password")
+	}
+	return service.ShareAccess{
+		Password:    password,
+		AccessToken: c.Query("access"),
+	}
+}
+
+// failShareAccess 将分享访问错误转换为响应
+func (h *ShareHandler) failShareAccess(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errMsg.ErrShareNotFound):
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "分享不存在",
+			Data:    nil,
+		})
+	case errors.Is(err, errMsg.ErrShareRevoked),
+		errors.Is(err, errMsg.ErrShareExpired),
+		errors.Is(err, errMsg.ErrShareExhausted):
+		c.JSON(http.StatusGone, Response{
+			Code:    1,
+			Message: "分享已失效",
+			Data:    nil,
+		})
+	case errors.Is(err, errMsg.ErrSharePasswordRequired):
+		c.JSON(http.StatusUnauthorized, Response{
+			Code:    1,
+			Message: "请输入访问密码",
+			Data:    map[string]bool{"passwordRequired": true},
+		})
+	case errors.Is(err, errMsg.ErrSharePasswordInvalid):
+		c.JSON(http.StatusUnauthorized, Response{
+			Code:    1,
+			Message: "访问密码错误",
+			Data:    map[string]bool{"passwordRequired": true},
+		})
+	case errors.Is(err, errMsg.ErrShareFileForbidden):
+		c.JSON(http.StatusForbidden, Response{
+			Code:    1,
+			Message: "禁止访问",
+			Data:    nil,
+		})
+	case errors.Is(err, errMsg.ErrSharePageInvalid):
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "页码无效",
+			Data:    nil,
+		})
+	default:
+		h.ShareService.Log.Error("访问分享失败", "error", err)
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+	}
+}
+
+// ResolveShare 访问分享链接
+// @Summary 访问分享链接
+// @Description 匿名访问分享的相册、动态或照片集，密码保护的分享需通过 X-Share-Password 请求头或 password 参数提供密码
+// @Description 每次请求（包括相册翻页）计入一次访问次数；动态和照片集只有第 1 页
+// @Tags shares
+// @Produce json
+// @Param token path string true "分享令牌"
+// @Param password query string false "访问密码"
+// @Param access query string false "访问令牌（密码验证通过后签发）"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(20)
+// @Success 200 {object} Response{data=service.ShareContentResponse}
+// @Failure 400 {object} Response
+// @Failure 401 {object} Response
+// @Failure 404 {object} Response
+// @Failure 410 {object} Response
+// @Router /s/{token} [get]
+func (h *ShareHandler) ResolveShare(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+	page, size = ParsePagination(page, size)
+
+	content, err := h.ShareService.ResolveShare(c, c.Param("token"), shareAccessFromRequest(c), page, size)
+	if err != nil {
+		h.failShareAccess(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    content,
+	})
+}
+
+// GetShareFile 通过分享链接访问文件
+// @Summary 通过分享链接访问文件
+// @Description 校验文件属于分享内容后，转发到文件接口返回文件流
+// @Tags shares
+// @Produce application/octet-stream
+// @Param token path string true "分享令牌"
+// @Param fileId path string true "文件ID"
+// @Param access query string false "访问令牌"
+// @Param w query int false "缩略图宽度"
+// @Success 200 {file} file "File stream"
+// @Failure 401 {object} Response
+// @Failure 403 {object} Response
+// @Failure 410 {object} Response
+// @Router /s/{token}/files/{fileId} [get]
+func (h *ShareHandler) GetShareFile(c *gin.Context) {
+	fileIDStr := c.Param("fileId")
+	fileID, err := strconv.ParseUint(fileIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的文件ID格式",
+			Data:    nil,
+		})
+		return
+	}
+
+	if err := h.ShareService.AuthorizeFile(c.Request.Context(), c.Param("token"), shareAccessFromRequest(c), fileID); err != nil {
+		h.failShareAccess(c, err)
+		return
+	}
+
+	c.Params = gin.Params{{Key: "id", Value: fileIDStr}}
+	h.FileHandler.GetFile(c)
+}
+
+// CreateShare 创建分享链接
+// @Summary 创建分享链接
+// @Description 为相册、动态或一组照片创建分享链接，可设置密码、过期时间和访问次数
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param share body service.ShareCreateRequest true "分享信息"
+// @Success 200 {object} Response{data=service.FrontendShare}
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /shares [post]
+func (h *ShareHandler) CreateShare(c *gin.Context) {
+	var req service.ShareCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.ShareService.Log.Error("参数校验失败", "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "参数校验失败",
+			Data:    nil,
+		})
+		return
+	}
+
+	claims := auth.MustGetAuthClaims(c)
+
+	share, err := h.ShareService.CreateShare(c.Request.Context(), claims.UserID, &req)
+	if err != nil {
+		h.ShareService.Log.Error("创建分享失败", "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "创建成功",
+		Data:    share,
+	})
+}
+
+// ListShares 获取分享列表
+// @Summary 获取分享列表
+// @Description 分页获取所有分享链接及其状态
+// @Tags shares
+// @Produce json
+// @Security OAuth2Password
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response{data=service.ShareListResponse}
+// @Failure 500 {object} Response
+// @Router /shares [get]
+func (h *ShareHandler) ListShares(c *gin.Context) {
+	queryParams := ParseQueryParams(c, "shares")
+
+	shares, err := h.ShareService.ListShares(c.Request.Context(), queryParams.Page, queryParams.Size)
+	if err != nil {
+		h.ShareService.Log.Error("获取分享列表失败", "error", err)
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "查询成功",
+		Data:    shares,
+	})
+}
+
+// RevokeShare 撤销分享链接
+// @Summary 撤销分享链接
+// @Description 撤销后分享链接立即失效
+// @Tags shares
+// @Produce json
+// @Security OAuth2Password
+// @Param id path int true "分享ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /shares/{id} [delete]
+func (h *ShareHandler) RevokeShare(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		h.ShareService.Log.Error("无效的分享ID", "id", idStr, "error", err)
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    1,
+			Message: "无效的分享ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	revoked, err := h.ShareService.RevokeShare(c.Request.Context(), id)
+	if err != nil {
+		h.ShareService.Log.Error("撤销分享失败", "id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    1,
+			Message: "系统内部错误",
+			Data:    nil,
+		})
+		return
+	}
+
+	if !revoked {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    1,
+			Message: "分享不存在",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "撤销成功",
+		Data:    nil,
+	})
+}
@@ -0,0 +1,89 @@
+// Package job 后台定时任务
+package job
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bookandmusic/love-girl/internal/log"
+)
+
+// Job 周期执行的后台任务
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Delay    time.Duration // 服务启动后首次执行前的等待时间
+	Run      func(ctx context.Context) error
+}
+
+// Runner 后台任务调度器，服务重启或退出时统一停止
+type Runner struct {
+	log    *log.Logger
+	jobs   []Job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRunner(logger *log.Logger, jobs []Job) *Runner {
+	return &Runner{
+		log:  logger,
+		jobs: jobs,
+	}
+}
+
+// Start 为每个任务启动独立的协程
+func (r *Runner) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	for _, j := range r.jobs {
+		r.wg.Add(1)
+		go r.loop(ctx, j)
+	}
+	r.log.Info("后台任务已启动", "count", len(r.jobs))
+}
+
+// Stop 取消所有任务并等待正在执行的任务结束
+func (r *Runner) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	r.wg.Wait()
+	r.log.Info("后台任务已停止")
+}
+
+func (r *Runner) loop(ctx context.Context, j Job) {
+	defer r.wg.Done()
+
+	timer := time.NewTimer(j.Delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		r.execute(ctx, j)
+		timer.Reset(j.Interval)
+	}
+}
+
+// execute 执行一次任务，任务 panic 不影响其他任务
+func (r *Runner) execute(ctx context.Context, j Job) {
+	defer func() {
+		if err := recover(); err != nil {
+			r.log.Error("后台任务异常", "job", j.Name, "panic", err)
+		}
+	}()
+
+	start := time.Now()
+	if err := j.Run(ctx); err != nil {
+		r.log.Error("后台任务执行失败", "job", j.Name, "error", err)
+		return
+	}
+	r.log.Debug("后台任务执行完成", "job", j.Name, "elapsed", time.Since(start))
+}
@@ -0,0 +1,208 @@
+// Package lunar 农历（阴阳历）与公历的相互换算，支持闰月，覆盖农历 1900–2100 年
+package lunar
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	MinYear = 1900
+	MaxYear = 2100
+)
+
+var (
+	ErrOutOfRange  = errors.New("date out of supported lunar range")
+	ErrInvalidDate = errors.New("invalid lunar date")
+)
+
+// baseDate 农历 1900 年正月初一对应的公历日期
+var baseDate = time.Date(1900, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+// yearInfo 每年的月份信息，从 1900 年开始：
+//   - 0-3 位：闰月月份，0 表示无闰月
+//   - 4-15 位：正月到十二月是否为大月（30 天），正月在最高位
+//   - 16 位：闰月是否为大月
+var yearInfo = [MaxYear - MinYear + 1]uint32{
+	0x04bd8, 0x04ae0, 0x0a570, 0x054d5, 0x0d260, 0x0d950, 0x16554, 0x056a0, 0x09ad0, 0x055d2, // 1900-1909
+	0x04ae0, 0x0a5b6, 0x0a4d0, 0x0d250, 0x1d255, 0x0b540, 0x0d6a0, 0x0ada2, 0x095b0, 0x14977, // 1910-1919
+	0x04970, 0x0a4b0, 0x0b4b5, 0x06a50, 0x06d40, 0x1ab54, 0x02b60, 0x09570, 0x052f2, 0x04970, // 1920-1929
+	0x06566, 0x0d4a0, 0x0ea50, 0x16a95, 0x05ad0, 0x02b60, 0x186e3, 0x092e0, 0x1c8d7, 0x0c950, // 1930-1939
+	0x0d4a0, 0x1d8a6, 0x0b550, 0x056a0, 0x1a5b4, 0x025d0, 0x092d0, 0x0d2b2, 0x0a950, 0x0b557, // 1940-1949
+	0x06ca0, 0x0b550, 0x15355, 0x04da0, 0x0a5b0, 0x14573, 0x052b0, 0x0a9a8, 0x0e950, 0x06aa0, // 1950-1959
+	0x0aea6, 0x0ab50, 0x04b60, 0x0aae4, 0x0a570, 0x05260, 0x0f263, 0x0d950, 0x05b57, 0x056a0, // 1960-1969
+	0x096d0, 0x04dd5, 0x04ad0, 0x0a4d0, 0x0d4d4, 0x0d250, 0x0d558, 0x0b540, 0x0b6a0, 0x195a6, // 1970-1979
+	0x095b0, 0x049b0, 0x0a974, 0x0a4b0, 0x0b27a, 0x06a50, 0x06d40, 0x0af46, 0x0ab60, 0x09570, // 1980-1989
+	0x04af5, 0x04970, 0x064b0, 0x074a3, 0x0ea50, 0x06b58, 0x05ac0, 0x0ab60, 0x096d5, 0x092e0, // 1990-1999
+	0x0c960, 0x0d954, 0x0d4a0, 0x0da50, 0x07552, 0x056a0, 0x0abb7, 0x025d0, 0x092d0, 0x0cab5, // 2000-2009
+	0x0a950, 0x0b4a0, 0x0baa4, 0x0ad50, 0x055d9, 0x04ba0, 0x0a5b0, 0x15176, 0x052b0, 0x0a930, // 2010-2019
+	0x07954, 0x06aa0, 0x0ad50, 0x05b52, 0x04b60, 0x0a6e6, 0x0a4e0, 0x0d260, 0x0ea65, 0x0d530, // 2020-2029
+	0x05aa0, 0x076a3, 0x096d0, 0x04afb, 0x04ad0, 0x0a4d0, 0x1d0b6, 0x0d250, 0x0d520, 0x0dd45, // 2030-2039
+	0x0b5a0, 0x056d0, 0x055b2, 0x049b0, 0x0a577, 0x0a4b0, 0x0aa50, 0x1b255, 0x06d20, 0x0ada0, // 2040-2049
+	0x14b63, 0x09370, 0x049f8, 0x04970, 0x064b0, 0x168a6, 0x0ea50, 0x06b20, 0x1a6c4, 0x0aae0, // 2050-2059
+	0x092e0, 0x0d2e3, 0x0c960, 0x0d557, 0x0d4a0, 0x0da50, 0x05d55, 0x056a0, 0x0a6d0, 0x055d4, // 2060-2069
+	0x052d0, 0x0a9b8, 0x0a950, 0x0b4a0, 0x0b6a6, 0x0ad50, 0x055a0, 0x0aba4, 0x0a5b0, 0x052b0, // 2070-2079
+	0x0b273, 0x06930, 0x07337, 0x06aa0, 0x0ad50, 0x14b55, 0x04b60, 0x0a570, 0x054e4, 0x0d160, // 2080-2089
+	0x0e968, 0x0d520, 0x0daa0, 0x16aa6, 0x056d0, 0x04ae0, 0x0a9d4, 0x0a2d0, 0x0d150, 0x0f252, // 2090-2099
+	0x0d520, // 2100
+}
+
+// Date 农历日期
+type Date struct {
+	Year   int
+	Month  int  // 1-12
+	Day    int  // 1-30
+	IsLeap bool // 是否为闰月
+}
+
+var (
+	monthNames = []string{"正", "二", "三", "四", "五", "六", "七", "八", "九", "十", "冬", "腊"}
+	dayTens    = []string{"初", "十", "廿", "三"}
+	dayDigits  = []string{"十", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+)
+
+// String 中文表示，如 “闰四月初八”，不含年份
+func (d Date) String() string {
+	if d.Month < 1 || d.Month > 12 || d.Day < 1 || d.Day > 30 {
+		return fmt.Sprintf("%d-%02d-%02d", d.Year, d.Month, d.Day)
+	}
+	month := monthNames[d.Month-1] + "月"
+	if d.IsLeap {
+		month = "闰" + month
+	}
+	var day string
+	switch d.Day {
+	case 10:
+		day = "初十"
+	case 20:
+		day = "二十"
+	case 30:
+		day = "三十"
+	default:
+		day = dayTens[d.Day/10] + dayDigits[d.Day%10]
+	}
+	return month + day
+}
+
+func inRange(year int) bool {
+	return year >= MinYear && year <= MaxYear
+}
+
+// LeapMonth 指定年份的闰月月份，无闰月或超出范围时返回 0
+func LeapMonth(year int) int {
+	if !inRange(year) {
+		return 0
+	}
+	return int(yearInfo[year-MinYear] & 0xf)
+}
+
+// MonthDays 指定月份的天数（29 或 30），月份不存在（如该年没有这个闰月）时返回 0
+func MonthDays(year, month int, leap bool) int {
+	if !inRange(year) || month < 1 || month > 12 {
+		return 0
+	}
+	info := yearInfo[year-MinYear]
+	if leap {
+		if LeapMonth(year) != month {
+			return 0
+		}
+		if info&0x10000 != 0 {
+			return 30
+		}
+		return 29
+	}
+	if info&(0x10000>>uint(month)) != 0 {
+		return 30
+	}
+	return 29
+}
+
+// YearDays 农历年的总天数，超出范围时返回 0
+func YearDays(year int) int {
+	if !inRange(year) {
+		return 0
+	}
+	days := 0
+	for month := 1; month <= 12; month++ {
+		days += MonthDays(year, month, false)
+	}
+	if leap := LeapMonth(year); leap > 0 {
+		days += MonthDays(year, leap, true)
+	}
+	return days
+}
+
+// Valid 日期是否存在
+func (d Date) Valid() bool {
+	return d.Day >= 1 && d.Day <= MonthDays(d.Year, d.Month, d.IsLeap)
+}
+
+// ToSolar 换算为公历日期，返回 loc 时区当天零点
+func ToSolar(d Date, loc *time.Location) (time.Time, error) {
+	if !inRange(d.Year) {
+		return time.Time{}, ErrOutOfRange
+	}
+	if !d.Valid() {
+		return time.Time{}, ErrInvalidDate
+	}
+
+	offset := 0
+	for year := MinYear; year < d.Year; year++ {
+		offset += YearDays(year)
+	}
+	leap := LeapMonth(d.Year)
+	for month := 1; month < d.Month; month++ {
+		offset += MonthDays(d.Year, month, false)
+		if month == leap {
+			offset += MonthDays(d.Year, month, true)
+		}
+	}
+	// 闰月在同名的普通月之后
+	if d.IsLeap {
+		offset += MonthDays(d.Year, d.Month, false)
+	}
+	offset += d.Day - 1
+
+	solar := baseDate.AddDate(0, 0, offset)
+	return time.Date(solar.Year(), solar.Month(), solar.Day(), 0, 0, 0, 0, loc), nil
+}
+
+// FromSolar 换算为农历日期，只使用 t 的年月日
+func FromSolar(t time.Time) (Date, error) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := int(day.Sub(baseDate).Hours() / 24)
+	if offset < 0 {
+		return Date{}, ErrOutOfRange
+	}
+
+	year := MinYear
+	for ; year <= MaxYear; year++ {
+		days := YearDays(year)
+		if offset < days {
+			break
+		}
+		offset -= days
+	}
+	if year > MaxYear {
+		return Date{}, ErrOutOfRange
+	}
+
+	leap := LeapMonth(year)
+	for month := 1; month <= 12; month++ {
+		days := MonthDays(year, month, false)
+		if offset < days {
+			return Date{Year: year, Month: month, Day: offset + 1}, nil
+		}
+		offset -= days
+		if month == leap {
+			days = MonthDays(year, month, true)
+			if offset < days {
+				return Date{Year: year, Month: month, Day: offset + 1, IsLeap: true}, nil
+			}
+			offset -= days
+		}
+	}
+	// 年内天数已全部分配，不会执行到这里
+	return Date{}, ErrOutOfRange
+}
@@ -0,0 +1,170 @@
+package lunar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func solarDate(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestSpringFestival(t *testing.T) {
+	tests := []struct {
+		year      int
+		solar     string
+		leapMonth int
+		leapStart string // 闰月初一
+	}{
+		{year: 1900, solar: "1900-01-31", leapMonth: 8, leapStart: "1900-09-24"},
+		{year: 1912, solar: "1912-02-18"},
+		{year: 1949, solar: "1949-01-29", leapMonth: 7, leapStart: "1949-08-24"},
+		{year: 1985, solar: "1985-02-20"},
+		{year: 2000, solar: "2000-02-05"},
+		{year: 2020, solar: "2020-01-25", leapMonth: 4, leapStart: "2020-05-23"},
+		{year: 2023, solar: "2023-01-22", leapMonth: 2, leapStart: "2023-03-22"},
+		{year: 2024, solar: "2024-02-10"},
+		{year: 2025, solar: "2025-01-29", leapMonth: 6, leapStart: "2025-07-25"},
+		{year: 2026, solar: "2026-02-17"},
+		{year: 2033, solar: "2033-01-31", leapMonth: 11, leapStart: "2033-12-22"},
+		{year: 2050, solar: "2050-01-23", leapMonth: 3, leapStart: "2050-04-21"},
+		{year: 2100, solar: "2100-02-09"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.solar, func(t *testing.T) {
+			got, err := ToSolar(Date{Year: tt.year, Month: 1, Day: 1}, time.UTC)
+			if err != nil {
+				t.Fatalf("ToSolar: %v", err)
+			}
+			if want := solarDate(tt.solar); !got.Equal(want) {
+				t.Fatalf("%d 年春节 = %s, want %s", tt.year, got.Format("2006-01-02"), tt.solar)
+			}
+
+			if leap := LeapMonth(tt.year); leap != tt.leapMonth {
+				t.Fatalf("%d 年闰月 = %d, want %d", tt.year, leap, tt.leapMonth)
+			}
+			if tt.leapMonth == 0 {
+				return
+			}
+			leapStart, err := ToSolar(Date{Year: tt.year, Month: tt.leapMonth, Day: 1, IsLeap: true}, time.UTC)
+			if err != nil {
+				t.Fatalf("ToSolar 闰月: %v", err)
+			}
+			if want := solarDate(tt.leapStart); !leapStart.Equal(want) {
+				t.Fatalf("%d 年闰%d月初一 = %s, want %s", tt.year, tt.leapMonth, leapStart.Format("2006-01-02"), tt.leapStart)
+			}
+			// 闰月紧跟在同名的普通月之后
+			normalEnd, _ := ToSolar(Date{Year: tt.year, Month: tt.leapMonth, Day: MonthDays(tt.year, tt.leapMonth, false)}, time.UTC)
+			if !normalEnd.AddDate(0, 0, 1).Equal(leapStart) {
+				t.Fatalf("闰月应紧接普通月，普通月最后一天为 %s", normalEnd.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestMonthDays(t *testing.T) {
+	tests := []struct {
+		name  string
+		year  int
+		month int
+		leap  bool
+		want  int
+	}{
+		{name: "2023 年腊月有三十", year: 2023, month: 12, want: 30},
+		{name: "2024 年腊月没有三十", year: 2024, month: 12, want: 29},
+		{name: "存在的闰月", year: 2025, month: 6, leap: true, want: 29},
+		{name: "不存在的闰月", year: 2024, month: 6, leap: true, want: 0},
+		{name: "闰月月份不符", year: 2025, month: 5, leap: true, want: 0},
+		{name: "月份超出范围", year: 2025, month: 13, want: 0},
+		{name: "年份超出范围", year: 2101, month: 1, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MonthDays(tt.year, tt.month, tt.leap); got != tt.want {
+				t.Fatalf("MonthDays = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRoundTrip 支持范围内的每一天换算为农历再换算回来保持不变，且农历日期逐日连续
+func TestRoundTrip(t *testing.T) {
+	last, err := ToSolar(Date{Year: MaxYear, Month: 12, Day: MonthDays(MaxYear, 12, false)}, time.UTC)
+	if err != nil {
+		t.Fatalf("ToSolar: %v", err)
+	}
+
+	var prev Date
+	days := 0
+	for day := baseDate; !day.After(last); day = day.AddDate(0, 0, 1) {
+		d, err := FromSolar(day)
+		if err != nil {
+			t.Fatalf("FromSolar(%s): %v", day.Format("2006-01-02"), err)
+		}
+		back, err := ToSolar(d, time.UTC)
+		if err != nil {
+			t.Fatalf("ToSolar(%+v): %v", d, err)
+		}
+		if !back.Equal(day) {
+			t.Fatalf("%s -> %+v -> %s", day.Format("2006-01-02"), d, back.Format("2006-01-02"))
+		}
+
+		if days > 0 {
+			switch {
+			case d.Day == prev.Day+1 && d.Month == prev.Month && d.IsLeap == prev.IsLeap && d.Year == prev.Year:
+			case d.Day == 1 && prev.Day == MonthDays(prev.Year, prev.Month, prev.IsLeap):
+			default:
+				t.Fatalf("%s: %+v 不是 %+v 的下一天", day.Format("2006-01-02"), d, prev)
+			}
+		}
+		prev = d
+		days++
+	}
+
+	total := 0
+	for year := MinYear; year <= MaxYear; year++ {
+		total += YearDays(year)
+	}
+	if days != total {
+		t.Fatalf("共 %d 天, want %d", days, total)
+	}
+}
+
+func TestOutOfRange(t *testing.T) {
+	if _, err := FromSolar(solarDate("1900-01-30")); !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("1900-01-30: err = %v, want ErrOutOfRange", err)
+	}
+	if _, err := FromSolar(solarDate("2101-01-29")); !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("2101-01-29: err = %v, want ErrOutOfRange", err)
+	}
+	if _, err := ToSolar(Date{Year: 2101, Month: 1, Day: 1}, time.UTC); !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("2101 年: err = %v, want ErrOutOfRange", err)
+	}
+	if _, err := ToSolar(Date{Year: 2024, Month: 12, Day: 30}, time.UTC); !errors.Is(err, ErrInvalidDate) {
+		t.Fatalf("2024 年腊月三十: err = %v, want ErrInvalidDate", err)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		date Date
+		want string
+	}{
+		{date: Date{Month: 1, Day: 1}, want: "正月初一"},
+		{date: Date{Month: 4, Day: 8, IsLeap: true}, want: "闰四月初八"},
+		{date: Date{Month: 8, Day: 15}, want: "八月十五"},
+		{date: Date{Month: 11, Day: 20}, want: "冬月二十"},
+		{date: Date{Month: 12, Day: 30}, want: "腊月三十"},
+		{date: Date{Month: 12, Day: 23}, want: "腊月廿三"},
+	}
+	for _, tt := range tests {
+		if got := tt.date.String(); got != tt.want {
+			t.Fatalf("%+v.String() = %s, want %s", tt.date, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package mail 邮件发送：SMTP 发送器、模板渲染与带重试的发送队列
+package mail
+
+import (
+	"bytes"
+	"context"
+	cryptorand "crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bookandmusic/love-girl/internal/config"
+)
+
+var ErrSTARTTLSUnsupported = errors.New("smtp server does not support STARTTLS")
+
+// Message 待发送的邮件
+type Message struct {
+	To      []string
+	Subject string
+	Text    string            // 纯文本正文
+	HTML    string            // HTML 正文，可为空
+	Headers map[string]string // 额外的邮件头，如 List-Unsubscribe
+}
+
+// Sender 邮件发送器
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// SMTPSender 基于 net/smtp 的发送器
+type SMTPSender struct {
+	cfg *config.MailConfig
+}
+
+func NewSMTPSender(cfg *config.MailConfig) *SMTPSender {
+	return &SMTPSender{cfg: cfg}
+}
+
+func (s *SMTPSender) from() netmail.Address {
+	address := s.cfg.From
+	if address == "" {
+		address = s.cfg.Username
+	}
+	return netmail.Address{Name: s.cfg.FromName, Address: address}
+}
+
+// Send 建立连接并发送一封邮件，每次发送使用独立连接
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: s.cfg.Host}
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if s.cfg.Encryption == "tls" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("dial smtp server: %w", err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("create smtp client: %w", err)
+	}
+	defer client.Close()
+
+	switch s.cfg.Encryption {
+	case "tls", "none":
+	default:
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("starttls: %w", err)
+			}
+		} else if s.cfg.Encryption == "starttls" {
+			return ErrSTARTTLSUnsupported
+		}
+	}
+
+	if s.cfg.Username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+				return fmt.Errorf("smtp auth: %w", err)
+			}
+		}
+	}
+
+	from := s.from()
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("smtp rcpt %s: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := w.Write(buildMessage(from, msg)); err != nil {
+		w.Close()
+		return fmt.Errorf("write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("close message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage 构建 MIME 邮件内容，同时包含纯文本和 HTML 时使用 multipart/alternative
+func buildMessage(from netmail.Address, msg *Message) []byte {
+	var buf bytes.Buffer
+
+	host := "localhost"
+	if i := strings.LastIndex(from.Address, "@"); i >= 0 {
+		host = from.Address[i+1:]
+	}
+
+	writeHeader := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	writeHeader("From", from.String())
+	writeHeader("To", strings.Join(msg.To, ", "))
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("Message-ID", fmt.Sprintf("<%s@%s>", randomID(), host))
+	writeHeader("MIME-Version", "1.0")
+	keys := make([]string, 0, len(msg.Headers))
+	for key := range msg.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		writeHeader(key, msg.Headers[key])
+	}
+
+	if msg.HTML == "" {
+		writeHeader("Content-Type", "text/plain; charset=UTF-8")
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		writeQuotedPrintable(&buf, msg.Text)
+		return buf.Bytes()
+	}
+
+	boundary := "lg-" + randomID()
+	writeHeader("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		buf.WriteString("--" + boundary + "\r\n")
+		writeHeader("Content-Type", part.contentType+"; charset=UTF-8")
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		writeQuotedPrintable(&buf, part.body)
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+	return buf.Bytes()
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, body string) {
+	w := quotedprintable.NewWriter(buf)
+	_, _ = w.Write([]byte(body))
+	_ = w.Close()
+}
+
+func randomID() string {
+	bytes := make([]byte, 12)
+	if _, err := cryptorand.Read(bytes); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(bytes)
+}
@@ -0,0 +1,169 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bookandmusic/love-girl/internal/log"
+)
+
+var (
+	ErrDisabled  = errors.New("mail is not configured")
+	ErrQueueFull = errors.New("mail queue is full")
+)
+
+// sendTimeout 单次发送超时
+const sendTimeout = 30 * time.Second
+
+// Queue 异步发送队列，发送失败按指数退避重试
+// 重试通过定时重新入队完成，等待重试的邮件不占用发送协程，不会阻塞后续邮件
+type Queue struct {
+	sender     Sender
+	log        *log.Logger
+	jobs       chan *job
+	maxRetries int
+	retryDelay time.Duration // 首次重试间隔，之后每次翻倍
+	stop       chan struct{}
+	wg         sync.WaitGroup
+	once       sync.Once
+}
+
+// job 队列中的邮件及已失败的次数
+type job struct {
+	msg     *Message
+	attempt int
+}
+
+// NewQueue 创建发送队列，sender 为 nil 表示未配置邮件服务
+func NewQueue(sender Sender, logger *log.Logger, size, maxRetries int) *Queue {
+	if size <= 0 {
+		size = 100
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	return &Queue{
+		sender:     sender,
+		log:        logger,
+		jobs:       make(chan *job, size),
+		maxRetries: maxRetries,
+		retryDelay: 5 * time.Second,
+		stop:       make(chan struct{}),
+	}
+}
+
+// Enabled 是否已配置邮件服务
+func (q *Queue) Enabled() bool {
+	return q.sender != nil
+}
+
+// Start 启动后台发送协程
+func (q *Queue) Start() {
+	if !q.Enabled() {
+		return
+	}
+	q.wg.Add(1)
+	go q.run()
+}
+
+// Close 停止发送协程，队列中剩余的邮件各尝试发送一次，等待重试的邮件放弃
+func (q *Queue) Close() {
+	q.once.Do(func() {
+		close(q.stop)
+		q.wg.Wait()
+		if q.Enabled() {
+			q.drain()
+		}
+	})
+}
+
+// Enqueue 将邮件放入队列，不阻塞调用方
+func (q *Queue) Enqueue(msg *Message) error {
+	if !q.Enabled() {
+		return ErrDisabled
+	}
+	select {
+	case <-q.stop:
+		return ErrQueueFull
+	default:
+	}
+	select {
+	case q.jobs <- &job{msg: msg}:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (q *Queue) run() {
+	defer q.wg.Done()
+	for {
+		select {
+		case j := <-q.jobs:
+			q.deliver(j)
+		case <-q.stop:
+			return
+		}
+	}
+}
+
+// drain 退出前发送队列中剩余的邮件，不再重试
+func (q *Queue) drain() {
+	for {
+		select {
+		case j := <-q.jobs:
+			if err := q.send(j.msg); err != nil {
+				q.log.Error("邮件发送失败，已放弃", "error", err, "to", j.msg.To, "subject", j.msg.Subject)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (q *Queue) deliver(j *job) {
+	err := q.send(j.msg)
+	if err == nil {
+		q.log.Info("邮件发送成功", "to", j.msg.To, "subject", j.msg.Subject)
+		return
+	}
+	if j.attempt >= q.maxRetries {
+		q.log.Error("邮件发送失败，已达最大重试次数", "error", err, "to", j.msg.To, "subject", j.msg.Subject, "attempts", j.attempt+1)
+		return
+	}
+
+	delay := q.retryDelay << j.attempt
+	q.log.Warn("邮件发送失败，稍后重试", "error", err, "to", j.msg.To, "attempt", j.attempt+1, "delay", delay)
+	j.attempt++
+	q.retryAfter(j, delay)
+}
+
+// retryAfter 等待 delay 后将邮件重新放入队列
+func (q *Queue) retryAfter(j *job, delay time.Duration) {
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-q.stop:
+			q.log.Error("服务停止，放弃重试邮件", "to", j.msg.To, "subject", j.msg.Subject)
+			return
+		}
+		// 队列已满时等待空位，不丢弃重试的邮件
+		select {
+		case q.jobs <- j:
+		case <-q.stop:
+			q.log.Error("服务停止，放弃重试邮件", "to", j.msg.To, "subject", j.msg.Subject)
+		}
+	}()
+}
+
+func (q *Queue) send(msg *Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	return q.sender.Send(ctx, msg)
+}
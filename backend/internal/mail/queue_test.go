@@ -0,0 +1,169 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bookandmusic/love-girl/internal/config"
+	"github.com/bookandmusic/love-girl/internal/log"
+)
+
+// fakeSender 收件人在 failures 中时按剩余次数返回错误，-1 表示一直失败
+type fakeSender struct {
+	mu       sync.Mutex
+	failures map[string]int
+	attempts map[string]int
+	sent     chan string
+}
+
+func newFakeSender(failures map[string]int) *fakeSender {
+	return &fakeSender{failures: failures, attempts: make(map[string]int), sent: make(chan string, 10)}
+}
+
+func (f *fakeSender) Send(_ context.Context, msg *Message) error {
+	to := msg.To[0]
+	f.mu.Lock()
+	f.attempts[to]++
+	remaining := f.failures[to]
+	if remaining > 0 {
+		f.failures[to]--
+	}
+	f.mu.Unlock()
+
+	if remaining != 0 {
+		return errors.New("connection refused")
+	}
+	f.sent <- to
+	return nil
+}
+
+func (f *fakeSender) attemptsFor(to string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attempts[to]
+}
+
+func newTestQueue(sender Sender, maxRetries int, retryDelay time.Duration) *Queue {
+	q := NewQueue(sender, log.NewLogger(config.LogConfig{Level: "error"}), 10, maxRetries)
+	q.retryDelay = retryDelay
+	return q
+}
+
+func waitSent(t *testing.T, sender *fakeSender, want string) {
+	t.Helper()
+	select {
+	case to := <-sender.sent:
+		if to != want {
+			t.Fatalf("发送给 %s, want %s", to, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("等待发送给 %s 超时", want)
+	}
+}
+
+// TestQueueRetryDoesNotBlock 等待重试的邮件不阻塞后续邮件
+func TestQueueRetryDoesNotBlock(t *testing.T) {
+	sender := newFakeSender(map[string]int{"down@example.com": -1})
+	q := newTestQueue(sender, 3, time.Hour)
+	q.Start()
+	defer q.Close()
+
+	if err := q.Enqueue(&Message{To: []string{"down@example.com"}, Subject: "a"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if err := q.Enqueue(&Message{To: []string{"reset@example.com"}, Subject: "重置密码"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	waitSent(t, sender, "reset@example.com")
+	if n := sender.attemptsFor("down@example.com"); n != 1 {
+		t.Fatalf("重试时间未到，发送次数 = %d, want 1", n)
+	}
+}
+
+func TestQueueRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		retries  int
+		sent     bool
+		attempts int
+	}{
+		{name: "重试后成功", failures: 2, retries: 3, sent: true, attempts: 3},
+		{name: "达到最大重试次数", failures: -1, retries: 2, sent: false, attempts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := newFakeSender(map[string]int{"flaky@example.com": tt.failures})
+			q := newTestQueue(sender, tt.retries, time.Millisecond)
+			q.Start()
+			defer q.Close()
+
+			if err := q.Enqueue(&Message{To: []string{"flaky@example.com"}}); err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+			if tt.sent {
+				waitSent(t, sender, "flaky@example.com")
+			} else {
+				deadline := time.Now().Add(2 * time.Second)
+				for sender.attemptsFor("flaky@example.com") < tt.attempts && time.Now().Before(deadline) {
+					time.Sleep(5 * time.Millisecond)
+				}
+				time.Sleep(50 * time.Millisecond)
+			}
+			if n := sender.attemptsFor("flaky@example.com"); n != tt.attempts {
+				t.Fatalf("发送次数 = %d, want %d", n, tt.attempts)
+			}
+		})
+	}
+}
+
+// TestQueueCloseDrains 停止时队列中剩余的邮件各发送一次，等待重试的邮件放弃
+func TestQueueCloseDrains(t *testing.T) {
+	sender := newFakeSender(map[string]int{"down@example.com": -1})
+	q := newTestQueue(sender, 3, time.Hour)
+	if err := q.Enqueue(&Message{To: []string{"a@example.com"}}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	q.Close()
+
+	waitSent(t, sender, "a@example.com")
+	if err := q.Enqueue(&Message{To: []string{"b@example.com"}}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("停止后 Enqueue err = %v, want ErrQueueFull", err)
+	}
+
+	// 已在等待重试的邮件在停止时放弃
+	sender = newFakeSender(map[string]int{"down@example.com": -1})
+	q = newTestQueue(sender, 3, time.Hour)
+	q.Start()
+	if err := q.Enqueue(&Message{To: []string{"down@example.com"}}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	for sender.attemptsFor("down@example.com") == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	done := make(chan struct{})
+	go func() {
+		q.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close 不应等待重试间隔")
+	}
+	if n := sender.attemptsFor("down@example.com"); n != 1 {
+		t.Fatalf("发送次数 = %d, want 1", n)
+	}
+}
+
+func TestQueueDisabled(t *testing.T) {
+	q := newTestQueue(nil, 0, time.Second)
+	q.Start()
+	defer q.Close()
+	if err := q.Enqueue(&Message{To: []string{"a@example.com"}}); !errors.Is(err, ErrDisabled) {
+		t.Fatalf("err = %v, want ErrDisabled", err)
+	}
+}
@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// 每个模板由两个文件组成：
+//   - {name}.txt：纯文本正文，并通过 {{define "{name}.subject"}} 定义邮件主题
+//   - {name}.html：HTML 正文（可选）
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html"))
+)
+
+// Render 渲染指定模板，返回未填写收件人的邮件
+func Render(name string, data any) (*Message, error) {
+	var subject, text bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&subject, name+".subject", data); err != nil {
+		return nil, err
+	}
+	if err := textTemplates.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return nil, err
+	}
+
+	msg := &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()),
+	}
+
+	if tmpl := htmlTemplates.Lookup(name + ".html"); tmpl != nil {
+		var html bytes.Buffer
+		if err := tmpl.Execute(&html, data); err != nil {
+			return nil, err
+		}
+		msg.HTML = html.String()
+	}
+	return msg, nil
+}
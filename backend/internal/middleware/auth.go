@@ -3,49 +3,133 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/bookandmusic/love-girl/internal/audit"
 	"github.com/bookandmusic/love-girl/internal/auth"
 )
 
-func NewAuthMiddleware(jwt auth.JWT) *AuthMiddleware {
+const (
+	// streamTicketTTL 实时连接票据的有效期
+	streamTicketTTL = 30 * time.Second
+	// maxStreamTickets 同时有效的实时连接票据数上限
+	maxStreamTickets = 1000
+)
+
+func NewAuthMiddleware(jwt auth.JWT, tokenVerifier auth.TokenVerifier) *AuthMiddleware {
 	return &AuthMiddleware{
-		JWT: jwt,
+		JWT:           jwt,
+		TokenVerifier: tokenVerifier,
+		Tickets:       auth.NewTicketStore(streamTicketTTL, maxStreamTickets),
 	}
 }
 
 type AuthMiddleware struct {
-	JWT auth.JWT
+	JWT           auth.JWT
+	TokenVerifier auth.TokenVerifier
+	Tickets       *auth.TicketStore // 实时连接的一次性票据
 }
 
 func (m *AuthMiddleware) Handle() gin.HandlerFunc {
+	return m.handle(false)
+}
+
+// HandleWithTicket 与 Handle 相同，未携带 Authorization 请求头时允许通过 ticket 查询参数传递一次性票据
+// 浏览器的 EventSource 和 WebSocket 无法设置请求头，仅用于实时推送这类长连接接口
+func (m *AuthMiddleware) HandleWithTicket() gin.HandlerFunc {
+	return m.handle(true)
+}
+
+func (m *AuthMiddleware) handle(allowTicket bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		var (
+			claims *auth.Claims
+			err    error
+		)
 		token := m.extractBearerToken(c)
-		if token == "" {
+		switch {
+		case token != "":
+			claims, err = m.verify(c, token)
+		case allowTicket && c.Query("ticket") != "":
+			var ok bool
+			if claims, ok = m.Tickets.Redeem(c.Query("ticket")); !ok {
+				err = auth.ErrInvalidTicket
+			}
+		default:
 			m.unauthorized(c)
 			return
 		}
-
-		claims, err := m.JWT.Parse(token)
 		if err != nil {
 			m.unauthorized(c)
 			return
 		}
 
-		// 注入 Claims，不查 DB
+		if !claims.Allows(c.Request.Method, c.FullPath()) {
+			m.forbidden(c)
+			return
+		}
+
+		// 注入 Claims
 		auth.SetAuthClaims(c, claims)
+		audit.MetaFrom(c.Request.Context()).UserID = claims.UserID
+
+		c.Next()
+	}
+}
+
+// Optional 可选认证：携带有效令牌时注入 Claims，未携带或无效时按访客继续处理
+// 用于对访客和登录用户返回不同内容的公开接口
+func (m *AuthMiddleware) Optional() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := m.extractBearerToken(c)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		claims, err := m.verify(c, token)
+		if err == nil && claims.Allows(c.Request.Method, c.FullPath()) {
+			auth.SetAuthClaims(c, claims)
+			audit.MetaFrom(c.Request.Context()).UserID = claims.UserID
+		}
 
 		c.Next()
 	}
 }
 
+// verify 校验 JWT 或个人访问令牌
+// 说明：JWT 签名有效后仍需查询用户的令牌版本，保证重置密码后旧的登录令牌立即失效
+func (m *AuthMiddleware) verify(c *gin.Context, token string) (*auth.Claims, error) {
+	if auth.IsAPIToken(token) && m.TokenVerifier != nil {
+		// 个人访问令牌需查 DB 校验
+		return m.TokenVerifier.VerifyToken(c.Request.Context(), token)
+	}
+	claims, err := m.JWT.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	if m.TokenVerifier != nil {
+		if err := m.TokenVerifier.VerifyVersion(c.Request.Context(), claims.UserID, claims.Version); err != nil {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
 func (m *AuthMiddleware) unauthorized(c *gin.Context) {
 	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 		"error": "unauthorized",
 	})
 }
 
+func (m *AuthMiddleware) forbidden(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error": "insufficient scope",
+	})
+}
+
 func (m *AuthMiddleware) extractBearerToken(c *gin.Context) string {
 	auth := c.GetHeader("Authorization")
 	if auth == "" {
@@ -1,18 +1,60 @@
 package middleware
 
 import (
+	"fmt"
 	"log/slog"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// sensitiveQueryKeys 日志中需要隐藏的查询参数：实时连接票据、退订令牌、分享密码和访问令牌等
+var sensitiveQueryKeys = []string{"access_token", "ticket", "token", "password", "access"}
+
+// RedactQuery 隐藏查询字符串中的敏感参数值
+func RedactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, _ := url.ParseQuery(rawQuery)
+	redacted := false
+	for _, key := range sensitiveQueryKeys {
+		if _, ok := values[key]; ok {
+			values[key] = []string{"REDACTED"}
+			redacted = true
+		}
+	}
+	if !redacted {
+		return rawQuery
+	}
+	return values.Encode()
+}
+
+// LogFormatter Gin 访问日志格式，与默认格式相同，查询参数中的敏感值会被隐藏
+func LogFormatter(param gin.LogFormatterParams) string {
+	path := param.Path
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i+1] + RedactQuery(path[i+1:])
+	}
+	return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		param.StatusCode,
+		param.Latency,
+		param.ClientIP,
+		param.Method,
+		path,
+		param.ErrorMessage,
+	)
+}
+
 // Logging 请求日志中间件（集成 RequestID）
 func Logging() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
-		query := c.Request.URL.RawQuery
+		query := RedactQuery(c.Request.URL.RawQuery)
 
 		c.Next()
 
@@ -3,6 +3,8 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+
+	"github.com/bookandmusic/love-girl/internal/audit"
 )
 
 const (
@@ -25,6 +27,13 @@ func RequestID() gin.HandlerFunc {
 		c.Set(RequestIDKey, requestID)
 		c.Header(RequestIDHeader, requestID)
 
+		// 请求元数据写入请求上下文，服务层记录审计日志时使用
+		c.Request = c.Request.WithContext(audit.WithMeta(c.Request.Context(), &audit.Meta{
+			RequestID: requestID,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}))
+
 		c.Next()
 	}
 }
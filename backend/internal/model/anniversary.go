@@ -1,12 +1,19 @@
 package model
 
+// 纪念日的日历类型
+const (
+	AnniversaryCalendarSolar = "solar"
+	AnniversaryCalendarLunar = "lunar"
+)
+
 // Anniversary 纪念日表，存储重要的纪念日信息
 type Anniversary struct {
 	BaseModel
 	Title       string `gorm:"size:255;not null" json:"title"`
-	Date        string `gorm:"size:20;not null" json:"date"` // 格式如 'YYYY-MM-DD'
+	Date        string `gorm:"size:20;not null" json:"date"` // 格式如 'YYYY-MM-DD'，农历纪念日为农历的年月日
 	Description string `gorm:"type:text" json:"description"`
-	Calendar    string `gorm:"size:10;default:'solar'" json:"calendar"` // 日历类型：solar/lunar
+	Calendar    string `gorm:"size:10;default:'solar'" json:"calendar"`     // 日历类型：solar/lunar
+	IsLeapMonth bool   `gorm:"not null;default:false" json:"is_leap_month"` // 农历闰月，仅农历纪念日有效
 }
 
 func (Anniversary) TableName() string {
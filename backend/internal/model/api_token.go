@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// APIToken 个人访问令牌表，供桌面客户端和导入脚本长期使用
+type APIToken struct {
+	BaseModel
+	UserID     uint64     `gorm:"not null;index" json:"user_id"`
+	Name       string     `gorm:"size:64;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;not null" json:"prefix"`               // 令牌明文前几位，便于在列表中辨认
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`        // 令牌 SHA-256 哈希，明文只在创建时返回一次
+	Scope      string     `gorm:"size:16;not null;default:'read'" json:"scope"` // read/upload/full
+	ExpiresAt  *time.Time `json:"expires_at"`                                   // 过期时间，nil 表示永不过期
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	// TokenVersion 创建时用户的令牌版本，与用户当前版本不一致时令牌失效
+	TokenVersion int   `gorm:"not null;default:0" json:"-"`
+	User         *User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// AuditLog 安全审计日志表，只追加不修改
+// 说明：不嵌入 BaseModel，没有更新时间和软删除，只有超出保留期限时才会被物理删除
+type AuditLog struct {
+	ID         uint64    `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+	Action     string    `gorm:"size:64;not null;index" json:"action"`
+	ActorID    uint64    `gorm:"index" json:"actor_id"` // 操作人ID，未登录（如登录失败）为 0
+	ActorName  string    `gorm:"size:128" json:"actor_name"`
+	TargetType string    `gorm:"size:32;index:idx_audit_logs_target" json:"target_type"`
+	TargetID   uint64    `gorm:"index:idx_audit_logs_target" json:"target_id"`
+	Success    bool      `gorm:"not null" json:"success"`
+	IP         string    `gorm:"size:64" json:"ip"`
+	UserAgent  string    `gorm:"size:512" json:"user_agent"`
+	RequestID  string    `gorm:"size:64" json:"request_id"`
+	Before     string    `gorm:"type:text" json:"before"` // 变更前的字段（JSON）
+	After      string    `gorm:"type:text" json:"after"`  // 变更后的字段（JSON）
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
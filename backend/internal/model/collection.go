@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Collection 动态合集，例如“一起去日本旅行”，合集内的动态按手动排序展示
+type Collection struct {
+	BaseModel
+	Name         string  `gorm:"size:255;not null" json:"name"`
+	Description  string  `gorm:"size:512" json:"description"`
+	CoverImageID *uint64 `gorm:"index" json:"cover_image_id,omitempty"` // 封面图片ID，为空时使用合集内第一张可见图片
+	CoverImage   *File   `gorm:"foreignKey:CoverImageID" json:"cover_image,omitempty"`
+	UserID       uint64  `gorm:"not null;index" json:"user_id"` // 创建者
+}
+
+func (Collection) TableName() string {
+	return "collections"
+}
+
+// CollectionMoment 合集与动态的关联表，Position 越小越靠前
+type CollectionMoment struct {
+	ID           uint64    `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	CollectionID uint64    `gorm:"not null;uniqueIndex:idx_collection_moments_moment" json:"collection_id"`
+	MomentID     uint64    `gorm:"not null;uniqueIndex:idx_collection_moments_moment;index" json:"moment_id"`
+	Position     int       `gorm:"not null;default:0" json:"position"`
+}
+
+func (CollectionMoment) TableName() string {
+	return "collection_moments"
+}
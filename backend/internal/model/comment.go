@@ -1,17 +1,73 @@
 package model
 
+import "time"
+
+// CommentStatus 评论审核状态
+type CommentStatus string
+
+const (
+	CommentStatusPending  CommentStatus = "pending"  // 待审核，仅管理者可见
+	CommentStatusApproved CommentStatus = "approved" // 已通过，正常展示
+	CommentStatusSpam     CommentStatus = "spam"     // 垃圾评论，不展示
+)
+
+// IsValid 是否为合法的审核状态
+func (s CommentStatus) IsValid() bool {
+	switch s {
+	case CommentStatusPending, CommentStatusApproved, CommentStatusSpam:
+		return true
+	}
+	return false
+}
+
 type Comment struct {
 	BaseModel
 	Content   string  `gorm:"type:text;not null" json:"content"`
 	MomentID  uint64  `gorm:"not null;index" json:"moment_id"`
 	ParentID  *uint64 `gorm:"index" json:"parent_id"`
 	ReplyToID *uint64 `gorm:"index" json:"reply_to_id"`
-	UserID    uint64  `gorm:"not null;index" json:"user_id"`
+	UserID    *uint64 `gorm:"index" json:"user_id"` // 访客评论为空
 	User      *User   `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
 	Path      string  `gorm:"type:varchar(512);index" json:"path"`
 	Depth     int     `gorm:"default:0" json:"depth"`
+	// EditedAt 最后一次编辑时间，未编辑过为空
+	EditedAt *time.Time `json:"edited_at"`
+	// TombstonedAt 有回复的评论被删除时只清空内容、保留占位，以免回复失去上级
+	TombstonedAt *time.Time `gorm:"index" json:"tombstoned_at"`
+	// Status 审核状态，登录用户的评论直接通过，访客评论需要审核
+	Status CommentStatus `gorm:"type:varchar(16);not null;default:'approved';index" json:"status"`
+	// 访客信息，仅访客评论填写；邮箱和 IP 只对管理者展示
+	GuestName  string `gorm:"size:64" json:"guest_name,omitempty"`
+	GuestEmail string `gorm:"size:255" json:"-"`
+	GuestIP    string `gorm:"size:64" json:"-"`
+	// 内容审核结果：结论为 pass、review、spam，审核关闭前创建的评论为空；分数为 0 到 1 的风险分
+	ModerationVerdict string  `gorm:"type:varchar(16)" json:"moderation_verdict"`
+	ModerationScore   float64 `gorm:"default:0" json:"moderation_score"`
+	ModerationReason  string  `gorm:"size:255" json:"moderation_reason"`
+	// ContentHash 归一化内容的指纹，用于检测重复评论
+	ContentHash string `gorm:"type:varchar(64);index" json:"-"`
+	// ReviewedAt 管理者最后一次人工审核的时间，人工标记的垃圾评论作为分类器的训练样本
+	ReviewedAt *time.Time `json:"reviewed_at"`
 }
 
 func (Comment) TableName() string {
 	return "comments"
 }
+
+// IsTombstone 评论是否已删除、仅作为回复的占位保留
+func (c *Comment) IsTombstone() bool {
+	return c.TombstonedAt != nil
+}
+
+// IsGuest 是否为访客评论
+func (c *Comment) IsGuest() bool {
+	return c.UserID == nil
+}
+
+// AuthorID 评论作者的用户ID，访客评论返回 0
+func (c *Comment) AuthorID() uint64 {
+	if c.UserID == nil {
+		return 0
+	}
+	return *c.UserID
+}
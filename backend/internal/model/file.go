@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // File represents a file record in the database.
 type File struct {
 	BaseModel
@@ -9,4 +11,6 @@ type File struct {
 	Size         int64  `gorm:"not null" json:"size"`
 	MimeType     string `gorm:"type:varchar(128)" json:"mime_type,omitempty"`
 	Hash         string `gorm:"type:char(64)" json:"hash,omitempty"`
+	// TakenAt 照片拍摄时间，从 EXIF 中解析，没有时为空
+	TakenAt *time.Time `gorm:"index" json:"taken_at,omitempty"`
 }
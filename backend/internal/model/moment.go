@@ -1,14 +1,60 @@
 package model
 
+import "time"
+
+// MomentVisibility 动态可见范围
+type MomentVisibility string
+
+const (
+	MomentVisibilityPrivate MomentVisibility = "private" // 仅作者可见
+	MomentVisibilityCouple  MomentVisibility = "couple"  // 情侣双方（已登录用户）可见
+	MomentVisibilityPublic  MomentVisibility = "public"  // 所有人可见
+)
+
+// Valid 是否为合法的可见范围
+func (v MomentVisibility) Valid() bool {
+	switch v {
+	case MomentVisibilityPrivate, MomentVisibilityCouple, MomentVisibilityPublic:
+		return true
+	}
+	return false
+}
+
+// MomentStatus 动态发布状态
+type MomentStatus string
+
+const (
+	MomentStatusDraft     MomentStatus = "draft"     // 草稿，仅作者可见
+	MomentStatusScheduled MomentStatus = "scheduled" // 定时发布，到达发布时间前仅作者可见
+	MomentStatusPublished MomentStatus = "published" // 已发布，按可见范围展示
+)
+
+// Valid 是否为合法的发布状态
+func (s MomentStatus) Valid() bool {
+	switch s {
+	case MomentStatusDraft, MomentStatusScheduled, MomentStatusPublished:
+		return true
+	}
+	return false
+}
+
 // Moment 动态表
 type Moment struct {
 	BaseModel
-	Content     string       `gorm:"column:content;type:text;not null" json:"content"`
-	Likes       int          `gorm:"column:likes;type:int;default:0;not null" json:"likes"`
-	IsPublic    bool         `gorm:"column:is_public;type:boolean;not null" json:"is_public"`
-	UserID      uint64       `gorm:"column:user_id;type:bigint;not null;index" json:"user_id"` // 关联用户
-	User        *User        `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
-	EntityFiles []EntityFile `gorm:"foreignKey:EntityID;references:ID;constraint:-" json:"entity_files"` // 关联的文件记录（多态关联，禁止外键约束）
+	Content     string           `gorm:"column:content;type:text;not null" json:"content"`
+	Likes       int              `gorm:"column:likes;type:int;default:0;not null" json:"likes"`
+	Visibility  MomentVisibility `gorm:"column:visibility;size:16;not null;default:'private';index" json:"visibility"`
+	Status      MomentStatus     `gorm:"column:status;size:16;not null;default:'published';index:idx_moments_status_publish_at" json:"status"`
+	PublishAt   *time.Time       `gorm:"column:publish_at;index:idx_moments_status_publish_at" json:"publish_at"` // 定时发布时间，仅 scheduled 状态有效
+	PinnedAt    *time.Time       `gorm:"column:pinned_at;index" json:"pinned_at"`                                 // 置顶时间，为空表示未置顶，越晚置顶越靠前
+	UserID      uint64           `gorm:"column:user_id;type:bigint;not null;index" json:"user_id"`                // 关联用户
+	User        *User            `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+	EntityFiles []EntityFile     `gorm:"foreignKey:EntityID;references:ID;constraint:-" json:"entity_files"` // 关联的文件记录（多态关联，禁止外键约束）
+
+	PlaceID       *uint64      `gorm:"column:place_id;index" json:"place_id"`                                            // 可选，动态发生的地点
+	Place         *Place       `gorm:"foreignKey:PlaceID;references:ID;constraint:-" json:"place,omitempty"`             // 地点删除时由仓库清空引用
+	AnniversaryID *uint64      `gorm:"column:anniversary_id;index" json:"anniversary_id"`                                // 可选，动态关联的纪念日
+	Anniversary   *Anniversary `gorm:"foreignKey:AnniversaryID;references:ID;constraint:-" json:"anniversary,omitempty"` // 纪念日删除时由仓库清空引用
 }
 
 func (Moment) TableName() string {
@@ -19,3 +65,23 @@ func (Moment) TableName() string {
 func (m *Moment) GetEntityFiles() []EntityFile {
 	return m.EntityFiles
 }
+
+// IsPublished 是否已发布，草稿和未到时间的定时动态只有作者可见
+func (m *Moment) IsPublished() bool {
+	return m.Status == MomentStatusPublished
+}
+
+// VisibleTo 判断动态对指定用户是否可见，viewerID 为 0 表示未登录访客
+func (m *Moment) VisibleTo(viewerID uint64) bool {
+	if !m.IsPublished() {
+		return viewerID != 0 && viewerID == m.UserID
+	}
+	switch m.Visibility {
+	case MomentVisibilityPublic:
+		return true
+	case MomentVisibilityCouple:
+		return viewerID != 0
+	default:
+		return viewerID != 0 && viewerID == m.UserID
+	}
+}
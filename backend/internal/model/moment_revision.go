@@ -0,0 +1,46 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// MomentRevisionAction 产生修订版本的操作
+type MomentRevisionAction string
+
+const (
+	MomentRevisionCreate     MomentRevisionAction = "create"     // 创建动态
+	MomentRevisionUpdate     MomentRevisionAction = "update"     // 编辑动态
+	MomentRevisionVisibility MomentRevisionAction = "visibility" // 修改可见范围
+	MomentRevisionPublish    MomentRevisionAction = "publish"    // 定时发布，动态时间变为发布时间
+	MomentRevisionRestore    MomentRevisionAction = "restore"    // 恢复到历史版本
+)
+
+// MomentRevision 动态修订版本表，只追加不修改
+// 说明：每个版本保存该次操作后动态的完整快照，版本号在同一动态内从 1 递增
+type MomentRevision struct {
+	ID           uint64               `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time            `json:"created_at"`                                                             // 修订时间
+	MomentID     uint64               `gorm:"not null;uniqueIndex:idx_moment_revisions_version" json:"moment_id"`     // 关联动态
+	Version      int                  `gorm:"not null;uniqueIndex:idx_moment_revisions_version" json:"version"`       // 版本号
+	Action       MomentRevisionAction `gorm:"size:16;not null" json:"action"`                                         // 产生该版本的操作
+	RestoredFrom int                  `gorm:"not null;default:0" json:"restored_from"`                                // 恢复自的版本号，仅 restore 操作有效
+	EditorID     uint64               `gorm:"not null;index" json:"editor_id"`                                        // 操作人
+	Editor       *User                `gorm:"foreignKey:EditorID;references:ID;constraint:-" json:"editor,omitempty"` // 操作人信息
+	Content      string               `gorm:"type:text;not null" json:"content"`                                      // 动态内容
+	ImageIDs     []uint64             `gorm:"column:image_ids;type:text;serializer:json" json:"image_ids"`            // 关联图片ID，按展示顺序
+	Visibility   MomentVisibility     `gorm:"size:16;not null" json:"visibility"`                                     // 可见范围
+	MomentTime   time.Time            `gorm:"not null" json:"moment_time"`                                            // 动态时间（动态的 created_at）
+}
+
+func (MomentRevision) TableName() string {
+	return "moment_revisions"
+}
+
+// SameSnapshot 两个版本的动态内容、图片、可见范围和动态时间是否完全一致
+func (r *MomentRevision) SameSnapshot(other *MomentRevision) bool {
+	return r.Content == other.Content &&
+		slices.Equal(r.ImageIDs, other.ImageIDs) &&
+		r.Visibility == other.Visibility &&
+		r.MomentTime.Equal(other.MomentTime)
+}
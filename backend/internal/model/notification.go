@@ -1,25 +1,151 @@
 package model
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type NotificationType string
 
 const (
-	NotificationTypeComment NotificationType = "comment"
-	NotificationTypeReply   NotificationType = "reply"
+	NotificationTypeComment     NotificationType = "comment"
+	NotificationTypeReply       NotificationType = "reply"
+	NotificationTypeReaction    NotificationType = "reaction"
+	NotificationTypePublish     NotificationType = "moment_published"
+	NotificationTypeMemory      NotificationType = "memory"        // 那年今日，不关联对象
+	NotificationTypeGuest       NotificationType = "guest_comment" // 访客评论待审核
+	NotificationTypeAlbumPhoto  NotificationType = "album_photo"   // 相册中添加了照片
+	NotificationTypeAnniversary NotificationType = "anniversary"   // 纪念日即将到来
+	NotificationTypeSystem      NotificationType = "system_alert"  // 系统告警，如存储不可用
+)
+
+// NotificationTypes 全部通知类型，用于校验用户的渠道偏好
+var NotificationTypes = []NotificationType{
+	NotificationTypeComment,
+	NotificationTypeReply,
+	NotificationTypeReaction,
+	NotificationTypePublish,
+	NotificationTypeMemory,
+	NotificationTypeGuest,
+	NotificationTypeAlbumPhoto,
+	NotificationTypeAnniversary,
+	NotificationTypeSystem,
+}
+
+// Valid 是否为支持的通知类型
+func (t NotificationType) Valid() bool {
+	for _, v := range NotificationTypes {
+		if t == v {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationEntityType 通知关联的对象类型
+type NotificationEntityType string
+
+const (
+	NotificationEntityNone        NotificationEntityType = ""
+	NotificationEntityMoment      NotificationEntityType = "moment"
+	NotificationEntityComment     NotificationEntityType = "comment"
+	NotificationEntityAlbum       NotificationEntityType = "album"
+	NotificationEntityAnniversary NotificationEntityType = "anniversary"
 )
 
 type Notification struct {
 	BaseModel
-	UserID    uint64           `gorm:"not null;index" json:"user_id"`
-	SenderID  uint64           `gorm:"not null;index" json:"sender_id"`
-	MomentID  uint64           `gorm:"not null;index" json:"moment_id"`
-	CommentID uint64           `gorm:"not null;index" json:"comment_id"`
-	Type      NotificationType `gorm:"type:varchar(20);not null" json:"type"`
-	Content   string           `gorm:"type:text" json:"content"`
-	IsRead    bool             `gorm:"default:false" json:"is_read"`
-	User      *User            `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
-	Sender    *User            `gorm:"foreignKey:SenderID;references:ID;constraint:OnDelete:CASCADE" json:"sender,omitempty"`
+	UserID     uint64                 `gorm:"not null;index" json:"user_id"`
+	SenderID   uint64                 `gorm:"not null;index" json:"sender_id"`
+	Type       NotificationType       `gorm:"type:varchar(20);not null" json:"type"`
+	EntityType NotificationEntityType `gorm:"type:varchar(20);not null;default:'';index:idx_notifications_entity" json:"entity_type"`
+	EntityID   uint64                 `gorm:"not null;default:0;index:idx_notifications_entity" json:"entity_id"`
+	Payload    string                 `gorm:"type:text" json:"-"` // 按通知类型组织的 JSON 数据，见各 *Payload 类型
+	IsRead     bool                   `gorm:"default:false" json:"is_read"`
+	DigestedAt *time.Time             `gorm:"index" json:"digested_at"` // 已汇总到通知摘要邮件的时间，每条通知只汇总一次
+	User       *User                  `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+	Sender     *User                  `gorm:"foreignKey:SenderID;references:ID;constraint:OnDelete:CASCADE" json:"sender,omitempty"`
 }
 
 func (Notification) TableName() string {
 	return "notifications"
 }
+
+// SetPayload 序列化通知数据
+func (n *Notification) SetPayload(payload any) error {
+	if payload == nil {
+		n.Payload = "{}"
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	n.Payload = string(data)
+	return nil
+}
+
+// DecodePayload 按通知类型解析通知数据，旧数据缺少的字段保持零值
+func (n *Notification) DecodePayload(payload any) error {
+	if n.Payload == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(n.Payload), payload)
+}
+
+// CommentPayload 评论、回复和访客评论通知，关联对象为评论
+type CommentPayload struct {
+	MomentID  uint64 `json:"momentId"`
+	Excerpt   string `json:"excerpt"`
+	GuestName string `json:"guestName,omitempty"` // 仅访客评论
+}
+
+// ReactionPayload 表情回应通知，关联对象为动态
+type ReactionPayload struct {
+	Reaction ReactionType `json:"reaction"`
+}
+
+// MomentPayload 新动态通知，关联对象为动态
+type MomentPayload struct {
+	Excerpt string `json:"excerpt"`
+}
+
+// MemoryPayload 那年今日通知，各类回忆的数量
+type MemoryPayload struct {
+	Moments       int `json:"moments"`
+	Photos        int `json:"photos"`
+	Places        int `json:"places"`
+	Anniversaries int `json:"anniversaries"`
+}
+
+// AlbumPhotoPayload 相册新照片通知，关联对象为相册
+type AlbumPhotoPayload struct {
+	AlbumName string   `json:"albumName"`
+	Count     int      `json:"count"`
+	FileIDs   []uint64 `json:"fileIds"` // 新添加的照片，最多保留前几张用于预览
+}
+
+// AnniversaryPayload 纪念日提醒，关联对象为纪念日
+type AnniversaryPayload struct {
+	Title     string `json:"title"`
+	Date      string `json:"date"`                // 本次纪念日的公历日期 YYYY-MM-DD
+	Calendar  string `json:"calendar"`            // solar/lunar
+	LunarDate string `json:"lunarDate,omitempty"` // 农历纪念日的中文日期，如 九月初十
+	DaysLeft  int    `json:"daysLeft"`            // 距离纪念日的天数，当天为 0
+	Years     int    `json:"years"`               // 本次是第几周年，首次为 0
+}
+
+// AlertLevel 系统告警级别
+type AlertLevel string
+
+const (
+	AlertLevelWarning AlertLevel = "warning"
+	AlertLevelError   AlertLevel = "error"
+)
+
+// SystemAlertPayload 系统告警，不关联对象
+type SystemAlertPayload struct {
+	Level   AlertLevel `json:"level"`
+	Code    string     `json:"code"` // 告警类别，如 storage_error
+	Message string     `json:"message"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// NotificationChannel 用户的站外通知渠道，同一用户可以配置多个同类渠道（如多台设备的浏览器推送）
+type NotificationChannel struct {
+	BaseModel
+	UserID          uint64             `gorm:"not null;index" json:"user_id"`
+	Kind            string             `gorm:"size:16;not null" json:"kind"`           // email/webhook/telegram/webpush
+	Name            string             `gorm:"size:64" json:"name"`                    // 便于辨认的名称，如设备名
+	Target          string             `gorm:"type:text;not null" json:"-"`            // 投递目标（JSON），可能包含密钥，不返回前端
+	Types           []NotificationType `gorm:"type:text;serializer:json" json:"types"` // 投递的通知类型，为空表示全部类型
+	Enabled         bool               `gorm:"not null;default:true" json:"enabled"`
+	LastError       string             `gorm:"type:text" json:"last_error"` // 最近一次投递失败的原因，成功后清空
+	LastDeliveredAt *time.Time         `json:"last_delivered_at"`
+	User            *User              `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+func (NotificationChannel) TableName() string {
+	return "notification_channels"
+}
+
+// Accepts 渠道是否接收指定类型的通知
+func (c *NotificationChannel) Accepts(notificationType NotificationType) bool {
+	if len(c.Types) == 0 {
+		return true
+	}
+	for _, t := range c.Types {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
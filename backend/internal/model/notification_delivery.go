@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending DeliveryStatus = "pending" // 等待投递或等待重试
+	DeliveryStatusSent    DeliveryStatus = "sent"
+	DeliveryStatusFailed  DeliveryStatus = "failed" // 达到最大尝试次数或无法重试
+)
+
+// NotificationDelivery 通知发件箱，与通知在同一事务中写入，由后台任务投递到站外渠道
+type NotificationDelivery struct {
+	BaseModel
+	NotificationID uint64               `gorm:"not null;index" json:"notification_id"`
+	ChannelID      uint64               `gorm:"not null;index" json:"channel_id"`
+	Status         DeliveryStatus       `gorm:"size:16;not null;default:'pending';index:idx_deliveries_due,priority:1" json:"status"`
+	Attempts       int                  `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time            `gorm:"index:idx_deliveries_due,priority:2" json:"next_attempt_at"`
+	LastError      string               `gorm:"type:text" json:"last_error"`
+	SentAt         *time.Time           `json:"sent_at"`
+	Notification   *Notification        `gorm:"foreignKey:NotificationID;references:ID;constraint:OnDelete:CASCADE" json:"notification,omitempty"`
+	Channel        *NotificationChannel `gorm:"foreignKey:ChannelID;references:ID;constraint:OnDelete:CASCADE" json:"channel,omitempty"`
+}
+
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// DigestFrequency 通知摘要的发送频率
+type DigestFrequency string
+
+const (
+	DigestFrequencyOff    DigestFrequency = "off"
+	DigestFrequencyDaily  DigestFrequency = "daily"
+	DigestFrequencyWeekly DigestFrequency = "weekly"
+)
+
+// NotificationDigest 用户的通知摘要设置，开启后定期将未读通知汇总为一封邮件
+type NotificationDigest struct {
+	BaseModel
+	UserID           uint64          `gorm:"not null;uniqueIndex" json:"user_id"`
+	Frequency        DigestFrequency `gorm:"type:varchar(10);not null;default:'off'" json:"frequency"`
+	SendTime         string          `gorm:"size:5;not null;default:'08:00'" json:"send_time"` // 用户本地时间 HH:MM
+	Weekday          int             `gorm:"not null;default:1" json:"weekday"`                // 每周摘要的发送日，0 为周日
+	Timezone         string          `gorm:"size:64" json:"timezone"`                          // IANA 时区，为空时使用服务器时区
+	UnsubscribeToken string          `gorm:"size:64;not null;uniqueIndex" json:"-"`            // 邮件中退订链接的令牌
+	NextSendAt       *time.Time      `gorm:"index" json:"next_send_at"`
+	LastSentAt       *time.Time      `json:"last_sent_at"`
+	User             *User           `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+func (NotificationDigest) TableName() string {
+	return "notification_digests"
+}
+
+// Enabled 是否开启了摘要
+func (d *NotificationDigest) Enabled() bool {
+	return d.Frequency == DigestFrequencyDaily || d.Frequency == DigestFrequencyWeekly
+}
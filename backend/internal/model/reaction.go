@@ -0,0 +1,39 @@
+package model
+
+// ReactionType 表情回应类型
+type ReactionType string
+
+const (
+	ReactionLike ReactionType = "like"
+	ReactionLove ReactionType = "love"
+	ReactionHaha ReactionType = "haha"
+	ReactionWow  ReactionType = "wow"
+	ReactionSad  ReactionType = "sad"
+	ReactionHug  ReactionType = "hug"
+)
+
+// ReactionTypes 支持的全部表情回应类型
+var ReactionTypes = []ReactionType{ReactionLike, ReactionLove, ReactionHaha, ReactionWow, ReactionSad, ReactionHug}
+
+// Valid 是否为支持的表情回应类型
+func (t ReactionType) Valid() bool {
+	for _, v := range ReactionTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Reaction 动态表情回应表，同一用户（或匿名访客）对同一动态的每种回应最多一条
+type Reaction struct {
+	BaseModel
+	MomentID    uint64       `gorm:"not null;uniqueIndex:idx_reactions_actor" json:"moment_id"`
+	UserID      uint64       `gorm:"not null;default:0;uniqueIndex:idx_reactions_actor;index" json:"user_id"` // 登录用户ID，匿名访客为 0
+	Fingerprint string       `gorm:"size:64;not null;default:'';uniqueIndex:idx_reactions_actor" json:"-"`    // 匿名访客指纹哈希，登录用户为空
+	Type        ReactionType `gorm:"size:16;not null;uniqueIndex:idx_reactions_actor" json:"type"`
+}
+
+func (Reaction) TableName() string {
+	return "moment_reactions"
+}
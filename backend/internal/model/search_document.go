@@ -0,0 +1,61 @@
+package model
+
+// SearchDocumentType 全文检索的文档类型
+type SearchDocumentType string
+
+const (
+	SearchDocumentMoment      SearchDocumentType = "moment"
+	SearchDocumentComment     SearchDocumentType = "comment"
+	SearchDocumentAlbum       SearchDocumentType = "album"
+	SearchDocumentPlace       SearchDocumentType = "place"
+	SearchDocumentAnniversary SearchDocumentType = "anniversary"
+)
+
+// SearchDocumentTypes 支持检索的全部文档类型
+var SearchDocumentTypes = []SearchDocumentType{
+	SearchDocumentMoment,
+	SearchDocumentComment,
+	SearchDocumentAlbum,
+	SearchDocumentPlace,
+	SearchDocumentAnniversary,
+}
+
+// Valid 是否为支持的文档类型
+func (t SearchDocumentType) Valid() bool {
+	for _, v := range SearchDocumentTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchDocument 全文检索文档表，由各业务数据同步生成，记录直接硬删除
+// 可见范围沿用动态的三级可见范围：评论继承所属动态，相册、地点、纪念日为公开
+type SearchDocument struct {
+	BaseModel
+	DocType  SearchDocumentType `gorm:"size:20;not null;uniqueIndex:idx_search_documents_doc" json:"doc_type"`
+	DocID    uint64             `gorm:"not null;uniqueIndex:idx_search_documents_doc" json:"doc_id"`
+	MomentID uint64             `gorm:"not null;default:0;index" json:"moment_id"` // 动态及其评论所属的动态ID，其他类型为 0
+	OwnerID  uint64             `gorm:"not null;default:0;index" json:"owner_id"`  // private 文档唯一可见的用户
+	Audience MomentVisibility   `gorm:"size:16;not null;index" json:"audience"`
+	Title    string             `gorm:"size:255;not null;default:''" json:"title"`
+	Content  string             `gorm:"type:text" json:"content"`
+	Tokens   string             `gorm:"type:text" json:"-"` // 切分后空格分隔的索引词
+}
+
+func (SearchDocument) TableName() string {
+	return "search_documents"
+}
+
+// VisibleTo 判断文档对指定用户是否可见，规则与 Moment.VisibleTo 一致
+func (d *SearchDocument) VisibleTo(viewerID uint64) bool {
+	switch d.Audience {
+	case MomentVisibilityPublic:
+		return true
+	case MomentVisibilityCouple:
+		return viewerID != 0
+	default:
+		return viewerID != 0 && viewerID == d.OwnerID
+	}
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+type ShareTargetType string
+
+const (
+	ShareTargetAlbum  ShareTargetType = "album"
+	ShareTargetMoment ShareTargetType = "moment"
+	ShareTargetPhotos ShareTargetType = "photos"
+)
+
+// Share 分享链接表，持有者凭随机令牌匿名访问单个相册、单条动态或一组照片
+type Share struct {
+	BaseModel
+	Token        string          `gorm:"size:64;not null;uniqueIndex" json:"token"`
+	TargetType   ShareTargetType `gorm:"size:20;not null;index:idx_shares_target" json:"target_type"`
+	TargetID     uint64          `gorm:"index:idx_shares_target" json:"target_id"` // 相册或动态ID，photos 类型为 0
+	Title        string          `gorm:"size:255" json:"title"`
+	Password     string          `gorm:"size:128" json:"-"`          // bcrypt 哈希，空表示无需密码
+	ExpiresAt    *time.Time      `json:"expires_at"`                 // 过期时间，nil 表示永不过期
+	MaxViews     int             `gorm:"default:0" json:"max_views"` // 最大访问次数，0 表示不限制
+	ViewCount    int             `gorm:"default:0" json:"view_count"`
+	LastViewedAt *time.Time      `json:"last_viewed_at"` // 最后一次计入次数的访问时间
+	RevokedAt    *time.Time      `json:"revoked_at"`
+	CreatedBy    uint64          `gorm:"not null;index" json:"created_by"`
+	Creator      *User           `gorm:"foreignKey:CreatedBy;references:ID;constraint:OnDelete:CASCADE" json:"creator,omitempty"`
+	EntityFiles  []EntityFile    `gorm:"foreignKey:EntityID;constraint:-" json:"-"` // photos 类型分享的照片（多态关联，禁止外键约束）
+}
+
+func (Share) TableName() string {
+	return "shares"
+}
+
+// GetEntityFiles 实现 EntityFilesGetter 接口
+func (s *Share) GetEntityFiles() []EntityFile {
+	return s.EntityFiles
+}
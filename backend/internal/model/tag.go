@@ -0,0 +1,23 @@
+package model
+
+// Tag 话题标签表，名称统一为小写且不带 # 前缀
+type Tag struct {
+	BaseModel
+	Name string `gorm:"size:64;not null;uniqueIndex:idx_tags_name" json:"name"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// MomentTag 动态与话题标签的多对多关联表，随动态内容重新同步，记录直接硬删除
+type MomentTag struct {
+	BaseModel
+	MomentID uint64 `gorm:"not null;uniqueIndex:idx_moment_tags_pair" json:"moment_id"`
+	TagID    uint64 `gorm:"not null;uniqueIndex:idx_moment_tags_pair;index" json:"tag_id"`
+	Tag      *Tag   `gorm:"foreignKey:TagID;constraint:OnDelete:CASCADE" json:"tag,omitempty"`
+}
+
+func (MomentTag) TableName() string {
+	return "moment_tags"
+}
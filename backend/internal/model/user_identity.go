@@ -0,0 +1,15 @@
+package model
+
+// UserIdentity 外部身份表，将 OpenID Connect 身份提供方的用户（issuer + sub）关联到本站用户
+type UserIdentity struct {
+	BaseModel
+	UserID  uint64 `gorm:"not null;index" json:"user_id"`
+	Issuer  string `gorm:"size:255;not null;uniqueIndex:idx_user_identities_subject" json:"issuer"`
+	Subject string `gorm:"size:255;not null;uniqueIndex:idx_user_identities_subject" json:"subject"`
+	Email   string `gorm:"size:255" json:"email"` // 关联时身份提供方返回的邮箱，仅用于展示
+	User    *User  `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
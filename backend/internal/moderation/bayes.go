@@ -0,0 +1,136 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/bookandmusic/love-girl/internal/search"
+)
+
+// defaultMinSamples 每类训练样本少于该数量时不参与判定，避免样本太少时误判
+const defaultMinSamples = 5
+
+// tokenCounts 一类样本的词频统计
+type tokenCounts struct {
+	docs   int
+	tokens int
+	freq   map[string]int
+}
+
+func newTokenCounts() *tokenCounts {
+	return &tokenCounts{freq: make(map[string]int)}
+}
+
+func (t *tokenCounts) add(tokens []string) {
+	t.docs++
+	t.tokens += len(tokens)
+	for _, token := range tokens {
+		t.freq[token]++
+	}
+}
+
+// Bayes 朴素贝叶斯分类器，以被标记为垃圾的评论和已通过的评论为样本
+// 中文按单字和二元组切分，与全文检索的分词一致；两类先验概率按相等处理，避免正常样本远多于垃圾样本时压低垃圾概率
+type Bayes struct {
+	mu         sync.RWMutex
+	spam       *tokenCounts
+	ham        *tokenCounts
+	minSamples int
+}
+
+// NewBayes 创建未训练的分类器
+func NewBayes() *Bayes {
+	return &Bayes{
+		spam:       newTokenCounts(),
+		ham:        newTokenCounts(),
+		minSamples: defaultMinSamples,
+	}
+}
+
+// Train 用样本重新训练，替换已有的统计结果
+func (b *Bayes) Train(spam, ham []string) {
+	spamCounts, hamCounts := newTokenCounts(), newTokenCounts()
+	for _, text := range spam {
+		spamCounts.add(bayesTokens(text))
+	}
+	for _, text := range ham {
+		hamCounts.add(bayesTokens(text))
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spam, b.ham = spamCounts, hamCounts
+}
+
+// Samples 两类样本的数量
+func (b *Bayes) Samples() (spam, ham int) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.spam.docs, b.ham.docs
+}
+
+// SpamProbability 计算文本为垃圾评论的概率，样本不足时 ok 为 false
+func (b *Bayes) SpamProbability(text string) (probability float64, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.spam.docs < b.minSamples || b.ham.docs < b.minSamples {
+		return 0, false
+	}
+
+	vocabulary := len(b.ham.freq)
+	for token := range b.spam.freq {
+		if _, exists := b.ham.freq[token]; !exists {
+			vocabulary++
+		}
+	}
+
+	// 拉普拉斯平滑，未出现在样本中的词不参与计算
+	var logSpam, logHam float64
+	known := false
+	for _, token := range bayesTokens(text) {
+		spamFreq, hamFreq := b.spam.freq[token], b.ham.freq[token]
+		if spamFreq == 0 && hamFreq == 0 {
+			continue
+		}
+		known = true
+		logSpam += math.Log(float64(spamFreq+1) / float64(b.spam.tokens+vocabulary))
+		logHam += math.Log(float64(hamFreq+1) / float64(b.ham.tokens+vocabulary))
+	}
+	if !known {
+		return 0, true
+	}
+	return 1 / (1 + math.Exp(logHam-logSpam)), true
+}
+
+func (b *Bayes) Name() string {
+	return "bayes"
+}
+
+func (b *Bayes) Check(_ context.Context, in *Input) (Finding, error) {
+	probability, ok := b.SpamProbability(in.Content)
+	if !ok {
+		return Finding{}, nil
+	}
+	return Finding{
+		Score:  probability,
+		Reason: fmt.Sprintf("分类器判定垃圾概率 %.2f", probability),
+	}, nil
+}
+
+// bayesTokens 切分文本并去重，同一个词在一条评论中只计一次
+func bayesTokens(text string) []string {
+	tokens := search.Tokenize(Normalize(text))
+	seen := make(map[string]struct{}, len(tokens))
+	result := tokens[:0]
+	for _, token := range tokens {
+		if _, ok := seen[token]; ok {
+			continue
+		}
+		seen[token] = struct{}{}
+		result = append(result, token)
+	}
+	return result
+}
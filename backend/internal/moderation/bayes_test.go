@@ -0,0 +1,107 @@
+package moderation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+var (
+	spamSamples = []string{
+		"加微信领取优惠券 限时免费",
+		"免费领取会员 加微信咨询",
+		"低价代购 加微信下单 免费包邮",
+		"兼职刷单日赚三百 加微信",
+		"限时优惠 免费领取 点击链接",
+	}
+	hamSamples = []string{
+		"今天的晚霞好美，想和你一起看",
+		"这张照片拍得真好看",
+		"周末一起去公园散步吧",
+		"想你了，早点回家",
+		"晚饭做了你最爱吃的红烧肉",
+	}
+)
+
+func TestBayesSpamProbability(t *testing.T) {
+	bayes := NewBayes()
+	bayes.Train(spamSamples, hamSamples)
+	if spam, ham := bayes.Samples(); spam != 5 || ham != 5 {
+		t.Fatalf("Samples = %d, %d", spam, ham)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		min     float64
+		max     float64
+	}{
+		{name: "垃圾评论", content: "加微信免费领取优惠", min: 0.9, max: 1},
+		{name: "全角和大小写不影响", content: "加微信！免费领取！", min: 0.9, max: 1},
+		{name: "正常评论", content: "周末一起去看晚霞吧", min: 0, max: 0.1},
+		{name: "样本中没有出现的词", content: "xyz", min: 0, max: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probability, ok := bayes.SpamProbability(tt.content)
+			if !ok {
+				t.Fatal("样本充足时应给出概率")
+			}
+			if probability < tt.min || probability > tt.max {
+				t.Fatalf("SpamProbability(%q) = %.4f, want [%v, %v]", tt.content, probability, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestBayesNotEnoughSamples(t *testing.T) {
+	bayes := NewBayes()
+	if _, ok := bayes.SpamProbability("加微信"); ok {
+		t.Fatal("未训练时不应给出概率")
+	}
+
+	bayes.Train(spamSamples, hamSamples[:4])
+	if _, ok := bayes.SpamProbability("加微信"); ok {
+		t.Fatal("正常样本不足时不应给出概率")
+	}
+	finding, err := bayes.Check(context.Background(), &Input{Content: "加微信"})
+	if err != nil || finding != (Finding{}) {
+		t.Fatalf("样本不足时 Check = %+v, %v", finding, err)
+	}
+}
+
+// TestBayesPriorIgnored 两类先验按相等处理，正常样本远多于垃圾样本时垃圾评论仍能被识别
+func TestBayesPriorIgnored(t *testing.T) {
+	ham := make([]string, 0, len(hamSamples)*20)
+	for i := 0; i < 20; i++ {
+		ham = append(ham, hamSamples...)
+	}
+	bayes := NewBayes()
+	bayes.Train(spamSamples, ham)
+
+	if probability, _ := bayes.SpamProbability("加微信免费领取"); probability < 0.9 {
+		t.Fatalf("SpamProbability = %.4f", probability)
+	}
+}
+
+func TestBayesCheck(t *testing.T) {
+	bayes := NewBayes()
+	bayes.Train(spamSamples, hamSamples)
+
+	finding, err := bayes.Check(context.Background(), &Input{Content: "加微信免费领取优惠"})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	probability, _ := bayes.SpamProbability("加微信免费领取优惠")
+	if finding.Score != probability || finding.Reason == "" {
+		t.Fatalf("finding = %+v, probability = %v", finding, probability)
+	}
+}
+
+func TestBayesTokens(t *testing.T) {
+	got := bayesTokens("好好 Nice nice")
+	want := []string{"好", "好好", "nice"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("bayesTokens = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package moderation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// minDuplicateLength 参与重复检测的最短内容（去除分隔符后的字符数），"好看"、"哈哈哈" 这类短评论重复很正常
+	minDuplicateLength = 8
+	// floodDuplicates 时间窗口内相同内容出现的次数达到该值时判定为刷屏
+	floodDuplicates = 3
+)
+
+// ContentHash 内容指纹：归一化并去除分隔符后的 SHA-256，用于识别重复评论
+func ContentHash(text string) string {
+	sum := sha256.Sum256([]byte(Compact(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// DuplicateCounter 统计相同内容的历史评论
+type DuplicateCounter interface {
+	// CountDuplicates 统计 since 之后内容指纹为 hash 的评论数，sameAuthor 为同一作者（用户或访客 IP）发表的数量，不计入 excludeID
+	CountDuplicates(ctx context.Context, hash string, since time.Time, userID uint64, ip string, excludeID uint64) (total, sameAuthor int64, err error)
+}
+
+// Duplicate 重复内容检查
+// 同一作者在时间窗口内重复发表相同内容需要审核；不同作者累计发表相同内容达到刷屏次数判定为垃圾评论
+type Duplicate struct {
+	counter DuplicateCounter
+	window  time.Duration
+}
+
+// NewDuplicate 创建重复内容检查器
+func NewDuplicate(counter DuplicateCounter, window time.Duration) *Duplicate {
+	return &Duplicate{counter: counter, window: window}
+}
+
+func (d *Duplicate) Name() string {
+	return "duplicate"
+}
+
+func (d *Duplicate) Check(ctx context.Context, in *Input) (Finding, error) {
+	if utf8.RuneCountInString(Compact(in.Content)) < minDuplicateLength {
+		return Finding{}, nil
+	}
+
+	total, sameAuthor, err := d.counter.CountDuplicates(ctx, ContentHash(in.Content), time.Now().Add(-d.window), in.UserID, in.IP, in.CommentID)
+	if err != nil {
+		return Finding{}, err
+	}
+	switch {
+	case total+1 >= floodDuplicates:
+		return Finding{Score: 1, Reason: fmt.Sprintf("相同内容近期已出现 %d 次", total)}, nil
+	case sameAuthor > 0:
+		return Finding{Score: 0.7, Reason: "重复发表相同内容"}, nil
+	case total > 0:
+		return Finding{Score: 0.6, Reason: "与其他评论内容相同"}, nil
+	}
+	return Finding{}, nil
+}
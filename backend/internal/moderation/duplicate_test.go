@@ -0,0 +1,77 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeCounter 返回固定统计结果并记录查询参数
+type fakeCounter struct {
+	total, sameAuthor int64
+	err               error
+
+	calls     int
+	hash      string
+	excludeID uint64
+}
+
+func (f *fakeCounter) CountDuplicates(_ context.Context, hash string, _ time.Time, _ uint64, _ string, excludeID uint64) (int64, int64, error) {
+	f.calls++
+	f.hash, f.excludeID = hash, excludeID
+	return f.total, f.sameAuthor, f.err
+}
+
+func TestContentHash(t *testing.T) {
+	if ContentHash("今天 天气，真好！Nice") != ContentHash("今天天气真好nice") {
+		t.Fatal("忽略分隔符和大小写后相同的内容指纹应相同")
+	}
+	if ContentHash("今天天气真好") == ContentHash("今天天气不好") {
+		t.Fatal("不同内容的指纹不应相同")
+	}
+}
+
+func TestDuplicate(t *testing.T) {
+	const content = "这是一条足够长的评论内容"
+	tests := []struct {
+		name       string
+		content    string
+		total      int64
+		sameAuthor int64
+		score      float64
+		reason     string
+		queried    bool
+	}{
+		{name: "短评论不检查", content: "哈哈哈哈", total: 5, score: 0},
+		{name: "没有重复", content: content, queried: true, score: 0},
+		{name: "与其他评论相同", content: content, total: 1, queried: true, score: 0.6, reason: "与其他评论内容相同"},
+		{name: "同一作者重复", content: content, total: 1, sameAuthor: 1, queried: true, score: 0.7, reason: "重复发表相同内容"},
+		{name: "刷屏", content: content, total: 2, queried: true, score: 1, reason: "相同内容近期已出现 2 次"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := &fakeCounter{total: tt.total, sameAuthor: tt.sameAuthor}
+			finding, err := NewDuplicate(counter, time.Hour).Check(context.Background(), &Input{Content: tt.content, CommentID: 9})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if finding.Score != tt.score || finding.Reason != tt.reason {
+				t.Fatalf("score=%v reason=%q, want %v %q", finding.Score, finding.Reason, tt.score, tt.reason)
+			}
+			if (counter.calls > 0) != tt.queried {
+				t.Fatalf("查询次数 = %d", counter.calls)
+			}
+			if tt.queried && (counter.hash != ContentHash(tt.content) || counter.excludeID != 9) {
+				t.Fatalf("hash=%s excludeID=%d", counter.hash, counter.excludeID)
+			}
+		})
+	}
+}
+
+func TestDuplicateError(t *testing.T) {
+	counter := &fakeCounter{err: errors.New("db down")}
+	if _, err := NewDuplicate(counter, time.Hour).Check(context.Background(), &Input{Content: "这是一条足够长的评论内容"}); err == nil {
+		t.Fatal("统计失败时应返回错误")
+	}
+}
@@ -0,0 +1,40 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// linkPattern 匹配带协议或 www 前缀的链接，以及常见顶级域名的裸域名
+var linkPattern = regexp.MustCompile(`(?i)(?:https?://|www\.)[^\s]+|\b[a-z0-9][a-z0-9-]*(?:\.[a-z0-9-]+)*\.(?:com|net|org|cn|io|me|cc|top|xyz|info|vip|shop|site|club)\b`)
+
+// LinkLimit 链接数量检查，超出上限的每个链接增加 0.2 风险分
+type LinkLimit struct {
+	max int
+}
+
+// NewLinkLimit 创建链接数量检查器，max 为允许的最大链接数
+func NewLinkLimit(max int) *LinkLimit {
+	return &LinkLimit{max: max}
+}
+
+func (l *LinkLimit) Name() string {
+	return "link_limit"
+}
+
+func (l *LinkLimit) Check(_ context.Context, in *Input) (Finding, error) {
+	count := CountLinks(in.Content)
+	if count <= l.max {
+		return Finding{}, nil
+	}
+	return Finding{
+		Score:  min(1, 0.4+0.2*float64(count-l.max)),
+		Reason: fmt.Sprintf("包含 %d 个链接，超过上限 %d", count, l.max),
+	}, nil
+}
+
+// CountLinks 统计文本中的链接数量，全角字符按半角处理
+func CountLinks(text string) int {
+	return len(linkPattern.FindAllStringIndex(Normalize(text), -1))
+}
@@ -0,0 +1,60 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCountLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{name: "无链接", content: "今天天气真好", want: 0},
+		{name: "带协议", content: "看这里 https://example.com/a?b=1 和 http://foo.bar", want: 2},
+		{name: "www 前缀", content: "www.example.org/page", want: 1},
+		{name: "裸域名", content: "访问 spam.xyz 或 shop.cn", want: 2},
+		{name: "全角域名", content: "ｅｘａｍｐｌｅ．ｃｏｍ", want: 1},
+		{name: "其他后缀不计入", content: "文件 photo.jpg 和 v1.2.3", want: 0},
+		{name: "单词内部不计入", content: "welcome", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CountLinks(tt.content); got != tt.want {
+				t.Fatalf("CountLinks(%q) = %d, want %d", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		score   float64
+		reason  string
+	}{
+		{name: "未超过上限", content: "a.com b.com", score: 0},
+		{name: "超出一个", content: "a.com b.com c.com", score: 0.6, reason: "包含 3 个链接，超过上限 2"},
+		{name: "超出三个", content: "a.com b.com c.com d.com e.com", score: 1, reason: "包含 5 个链接，超过上限 2"},
+		{name: "最高为 1", content: "a.com b.com c.com d.com e.com f.com g.com", score: 1, reason: "包含 7 个链接，超过上限 2"},
+	}
+	checker := NewLinkLimit(2)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finding, err := checker.Check(context.Background(), &Input{Content: tt.content})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if !approxEqual(finding.Score, tt.score) || finding.Reason != tt.reason {
+				t.Fatalf("score=%v reason=%q, want %v %q", finding.Score, finding.Reason, tt.score, tt.reason)
+			}
+		})
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	const epsilon = 1e-9
+	return a-b < epsilon && b-a < epsilon
+}
@@ -0,0 +1,97 @@
+// Package moderation 评论内容审核，由多个检查器组成流水线，按最高风险分给出结论
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Verdict 审核结论
+type Verdict string
+
+const (
+	VerdictPass   Verdict = "pass"   // 未发现问题
+	VerdictReview Verdict = "review" // 可疑，需要人工审核
+	VerdictSpam   Verdict = "spam"   // 判定为垃圾评论
+)
+
+// Input 待审核的评论
+type Input struct {
+	Content string
+	UserID  uint64 // 登录用户ID，访客为 0
+	IP      string // 访客 IP，用于识别访客身份
+	// CommentID 编辑已有评论时为评论ID，重复检测不计入评论自身
+	CommentID uint64
+}
+
+// Finding 单个检查器的结果
+type Finding struct {
+	Score  float64 // 风险分，0 到 1，越大越可能是垃圾评论
+	Reason string  // 风险分大于 0 时的原因说明
+}
+
+// Checker 内容检查器
+type Checker interface {
+	// Name 检查器名称
+	Name() string
+	// Check 检查评论内容，未发现问题时返回零值
+	Check(ctx context.Context, in *Input) (Finding, error)
+}
+
+// Result 审核结果
+type Result struct {
+	Verdict Verdict
+	Score   float64  // 各检查器风险分的最大值
+	Reasons []string // 风险分达到待审核阈值的原因
+}
+
+// Pipeline 审核流水线，依次执行全部检查器，取最高风险分
+type Pipeline struct {
+	checkers        []Checker
+	spamThreshold   float64
+	reviewThreshold float64
+}
+
+// NewPipeline 创建审核流水线，风险分不低于 spamThreshold 判定为垃圾评论，不低于 reviewThreshold 需要人工审核
+func NewPipeline(spamThreshold, reviewThreshold float64, checkers ...Checker) *Pipeline {
+	return &Pipeline{
+		checkers:        checkers,
+		spamThreshold:   spamThreshold,
+		reviewThreshold: reviewThreshold,
+	}
+}
+
+// Moderate 审核评论内容
+// 说明：单个检查器出错时跳过该检查器，其余检查器的结果仍然有效，错误合并后返回
+func (p *Pipeline) Moderate(ctx context.Context, in *Input) (*Result, error) {
+	result := &Result{Verdict: VerdictPass}
+	var errs []error
+	for _, checker := range p.checkers {
+		finding, err := checker.Check(ctx, in)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", checker.Name(), err))
+			continue
+		}
+		if finding.Score > result.Score {
+			result.Score = finding.Score
+		}
+		if finding.Score >= p.reviewThreshold && finding.Reason != "" {
+			result.Reasons = append(result.Reasons, finding.Reason)
+		}
+	}
+
+	switch {
+	case result.Score >= p.spamThreshold:
+		result.Verdict = VerdictSpam
+	case result.Score >= p.reviewThreshold:
+		result.Verdict = VerdictReview
+	}
+	return result, errors.Join(errs...)
+}
+
+// Reason 合并后的原因说明
+func (r *Result) Reason() string {
+	return strings.Join(r.Reasons, "；")
+}
@@ -0,0 +1,53 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fixedChecker 返回固定结果的检查器
+type fixedChecker struct {
+	finding Finding
+	err     error
+}
+
+func (f fixedChecker) Name() string {
+	return "fixed"
+}
+
+func (f fixedChecker) Check(context.Context, *Input) (Finding, error) {
+	return f.finding, f.err
+}
+
+func TestPipeline(t *testing.T) {
+	low := fixedChecker{finding: Finding{Score: 0.2, Reason: "低风险"}}
+	mid := fixedChecker{finding: Finding{Score: 0.6, Reason: "可疑"}}
+	high := fixedChecker{finding: Finding{Score: 1, Reason: "垃圾"}}
+	broken := fixedChecker{err: errors.New("boom")}
+
+	tests := []struct {
+		name     string
+		checkers []Checker
+		verdict  Verdict
+		reason   string
+		wantErr  bool
+	}{
+		{name: "无检查器", verdict: VerdictPass},
+		{name: "低于待审核阈值", checkers: []Checker{low}, verdict: VerdictPass},
+		{name: "待审核", checkers: []Checker{low, mid}, verdict: VerdictReview, reason: "可疑"},
+		{name: "垃圾评论取最高分", checkers: []Checker{mid, high}, verdict: VerdictSpam, reason: "可疑；垃圾"},
+		{name: "出错的检查器被跳过", checkers: []Checker{broken, mid}, verdict: VerdictReview, reason: "可疑", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewPipeline(0.9, 0.5, tt.checkers...).Moderate(context.Background(), &Input{Content: "x"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+			if result.Verdict != tt.verdict || result.Reason() != tt.reason {
+				t.Fatalf("verdict=%s reason=%q, want %s %q", result.Verdict, result.Reason(), tt.verdict, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package moderation
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Normalize 归一化文本：全角转半角、转小写
+func Normalize(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '　':
+			return ' '
+		case r >= '！' && r <= '～':
+			r -= 0xFEE0
+		}
+		return unicode.ToLower(r)
+	}, text)
+}
+
+// Compact 归一化后只保留字母、数字和中文，用于识别 "傻 逼" 这类插入分隔符的写法以及计算内容指纹
+func Compact(text string) string {
+	return strings.Map(func(r rune) rune {
+		if isWordRune(r) {
+			return r
+		}
+		return -1
+	}, Normalize(text))
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// words 将归一化文本切分为非中文词，中文和标点都作为分隔
+func words(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !isWordRune(r) || isCJK(r)
+	})
+}
+
+// shortBannedWord 少于该字符数的非中文屏蔽词跨分隔符匹配时，要求每段只有一个字符
+// 这样 "v.x" 能命中 "vx"，而 "a ss" 这类正常文本不会命中 "ass"
+const shortBannedWord = 4
+
+type bannedWord struct {
+	text    string // 原始配置，用于说明原因
+	compact string // 含中文的屏蔽词：在去除分隔符的文本中按子串匹配
+	latin   string // 不含中文的屏蔽词去除分隔符后的形式：由连续的完整单词拼接匹配，避免 "ass" 命中 "class"
+}
+
+// BannedWords 屏蔽词检查，命中任意屏蔽词即判定为垃圾评论
+//
+// 中文没有空格分词，含中文的屏蔽词在去除空格和标点后的文本中按子串匹配；
+// 其余屏蔽词要求由文本中连续的完整单词拼接而成，因此 "buy now"、"buy-now"、"buynow" 都能命中 "buy now"，
+// "v.x"、"v x" 能命中 "vx"，但不会命中单词内部的片段
+type BannedWords struct {
+	list []bannedWord
+}
+
+// NewBannedWords 创建屏蔽词检查器，忽略空白项
+func NewBannedWords(list []string) *BannedWords {
+	b := &BannedWords{}
+	for _, text := range list {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		word := bannedWord{text: text}
+		if strings.ContainsFunc(text, isCJK) {
+			word.compact = Compact(text)
+		} else {
+			word.latin = strings.Join(words(Normalize(text)), "")
+		}
+		if word.compact == "" && word.latin == "" {
+			continue
+		}
+		b.list = append(b.list, word)
+	}
+	return b
+}
+
+func (b *BannedWords) Name() string {
+	return "banned_words"
+}
+
+func (b *BannedWords) Check(_ context.Context, in *Input) (Finding, error) {
+	if len(b.list) == 0 {
+		return Finding{}, nil
+	}
+
+	compact := Compact(in.Content)
+	tokens := words(Normalize(in.Content))
+	for _, word := range b.list {
+		matched := false
+		if word.compact != "" {
+			matched = strings.Contains(compact, word.compact)
+		} else {
+			matched = containsJoined(tokens, word.latin)
+		}
+		if matched {
+			return Finding{Score: 1, Reason: "包含屏蔽词「" + word.text + "」"}, nil
+		}
+	}
+	return Finding{}, nil
+}
+
+// containsJoined tokens 中是否有连续的若干个单词拼接后等于 word
+// 说明：word 较短时，跨单词拼接要求每个单词只有一个字符
+func containsJoined(tokens []string, word string) bool {
+	short := utf8.RuneCountInString(word) < shortBannedWord
+	for i := range tokens {
+		joined := ""
+		for j := i; j < len(tokens); j++ {
+			if short && j > i && (utf8.RuneCountInString(tokens[i]) > 1 || utf8.RuneCountInString(tokens[j]) > 1) {
+				break
+			}
+			joined += tokens[j]
+			if !strings.HasPrefix(word, joined) {
+				break
+			}
+			if joined == word {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"ＡＢＣ１２３":    "abc123",
+		"Ｈｉ　ｔｈｅｒｅ！": "hi there!",
+		"加微信":       "加微信",
+	}
+	for input, want := range tests {
+		if got := Normalize(input); got != want {
+			t.Fatalf("Normalize(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestBannedWords(t *testing.T) {
+	checker := NewBannedWords([]string{"加微信", "vx", "ass", "buy now", "  ", "!!"})
+
+	tests := []struct {
+		name    string
+		content string
+		want    string // 命中的屏蔽词，空表示不命中
+	}{
+		{name: "中文子串", content: "有需要的加微信聊", want: "加微信"},
+		{name: "中文插入空格和标点", content: "加 微，信 123456", want: "加微信"},
+		{name: "中文全角标点", content: "加！微！信", want: "加微信"},
+		{name: "中文未命中", content: "加个微博好友", want: ""},
+		{name: "英文完整单词", content: "add my vx please", want: "vx"},
+		{name: "英文紧跟中文", content: "加我vx", want: "vx"},
+		{name: "英文全角大写", content: "ＶＸ：abc", want: "vx"},
+		{name: "英文插入标点", content: "加 v.x 私聊", want: "vx"},
+		{name: "英文插入空格", content: "v x 123", want: "vx"},
+		{name: "短词逐字母分隔", content: "you are an a.s.s", want: "ass"},
+		{name: "单词内部不命中", content: "first class seat", want: ""},
+		{name: "短词跨单词不命中", content: "a ss b", want: ""},
+		{name: "短词前缀不命中", content: "vxx yz", want: ""},
+		{name: "短语连续出现", content: "Buy Now!", want: "buy now"},
+		{name: "短语连字符", content: "click to buy-now", want: "buy now"},
+		{name: "短语连写", content: "buynow 限时", want: "buy now"},
+		{name: "短语不连续不命中", content: "buy it now", want: ""},
+		{name: "正常评论", content: "照片拍得真好看 nice", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finding, err := checker.Check(context.Background(), &Input{Content: tt.content})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if tt.want == "" {
+				if finding.Score != 0 {
+					t.Fatalf("%q 不应命中，实际原因为 %q", tt.content, finding.Reason)
+				}
+				return
+			}
+			if want := "包含屏蔽词「" + tt.want + "」"; finding.Score != 1 || finding.Reason != want {
+				t.Fatalf("%q: score=%v reason=%q, want %q", tt.content, finding.Score, finding.Reason, want)
+			}
+		})
+	}
+}
+
+func TestBannedWordsEmpty(t *testing.T) {
+	finding, err := NewBannedWords([]string{"", " ", "，"}).Check(context.Background(), &Input{Content: "， 任何内容"})
+	if err != nil || finding.Score != 0 {
+		t.Fatalf("空屏蔽词列表不应命中: %+v, %v", finding, err)
+	}
+}
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"net/textproto"
+
+	"github.com/bookandmusic/love-girl/internal/mail"
+)
+
+// EmailTarget 邮件投递目标
+type EmailTarget struct {
+	Address string `json:"address"`
+}
+
+// Email 邮件渠道，直接使用 SMTP 发送器，失败由发件箱重试
+type Email struct {
+	sender mail.Sender
+}
+
+func NewEmail(sender mail.Sender) *Email {
+	return &Email{sender: sender}
+}
+
+func (e *Email) Validate(target []byte) ([]byte, error) {
+	var t EmailTarget
+	if err := json.Unmarshal(target, &t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+	addr, err := netmail.ParseAddress(t.Address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid email address", ErrInvalidTarget)
+	}
+	return json.Marshal(EmailTarget{Address: addr.Address})
+}
+
+func (e *Email) Summary(target []byte) string {
+	var t EmailTarget
+	_ = json.Unmarshal(target, &t)
+	return t.Address
+}
+
+func (e *Email) Send(ctx context.Context, target []byte, msg *Message) error {
+	var t EmailTarget
+	if err := json.Unmarshal(target, &t); err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+
+	email, err := mail.Render("notification", msg)
+	if err != nil {
+		return fmt.Errorf("%w: render: %v", ErrPermanent, err)
+	}
+	email.To = []string{t.Address}
+
+	if err := e.sender.Send(ctx, email); err != nil {
+		// 5xx 为 SMTP 永久错误，例如收件人不存在
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+			return fmt.Errorf("%w: %v", ErrPermanent, err)
+		}
+		return err
+	}
+	return nil
+}
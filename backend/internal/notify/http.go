@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const userAgent = "love-girl-notify/1.0"
+
+// ErrForbiddenAddress 投递目标指向回环、私有网络等内部地址
+var ErrForbiddenAddress = errors.New("destination address is not allowed")
+
+// nonPublicPrefixes netip 没有单独判断的非公网地址段
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // 本网络
+	netip.MustParsePrefix("100.64.0.0/10"), // 运营商级 NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF 协议分配
+	netip.MustParsePrefix("198.18.0.0/15"), // 基准测试
+	netip.MustParsePrefix("240.0.0.0/4"),   // 保留
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64，可映射到内网 IPv4
+}
+
+// NewHTTPClient 创建投递到用户填写地址（Webhook、Web Push）的 HTTP 客户端
+// 建立连接时拒绝回环、私有网络、链路本地（云服务器元数据）等地址，DNS 解析后再检查，防止借通知渠道访问内网；
+// 不使用代理、不跟随重定向，避免绕过检查
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return ErrForbiddenAddress
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || !isPublicAddr(addr) {
+				return ErrForbiddenAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isPublicAddr 是否为公网单播地址
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// post 发送请求并按状态码区分失败类型，返回状态码和响应正文，未收到响应时状态码为 0
+// 408、429 和 5xx 可以重试，410 表示目标已失效，其余状态码（包括重定向）重试无意义；
+// 错误中只包含状态码，不包含对方的响应正文，避免通过测试通知读取目标地址的内容
+func post(ctx context.Context, client *http.Client, target string, header http.Header, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// 地址中可能带有令牌，只保留底层错误
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		if errors.Is(err, ErrForbiddenAddress) {
+			return 0, nil, fmt.Errorf("%w: %w", ErrPermanent, ErrForbiddenAddress)
+		}
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, data, nil
+	}
+	return resp.StatusCode, data, statusError(resp.StatusCode)
+}
+
+func statusError(code int) error {
+	detail := fmt.Sprintf("status %d", code)
+	switch {
+	case code == http.StatusGone:
+		return fmt.Errorf("%w: %s", ErrGone, detail)
+	case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests, code >= 500:
+		return errors.New(detail)
+	default:
+		return fmt.Errorf("%w: %s", ErrPermanent, detail)
+	}
+}
+
+// validHTTPURL 校验 http/https 地址
+func validHTTPURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: url must be http or https", ErrInvalidTarget)
+	}
+	return u, nil
+}
+
+// validTargetURL 校验用户填写的投递地址：http/https，且不是 localhost 或内网 IP
+// 域名解析到内网的情况由 NewHTTPClient 在连接时拦截
+func validTargetURL(raw string) (*url.URL, error) {
+	u, err := validHTTPURL(raw)
+	if err != nil {
+		return nil, err
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTarget, ErrForbiddenAddress)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !isPublicAddr(addr) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTarget, ErrForbiddenAddress)
+	}
+	return u, nil
+}
@@ -0,0 +1,51 @@
+// Package notify 站外通知渠道：邮件、Webhook、Telegram 机器人和 Web Push
+// 渠道只负责把一条通知投递到用户保存的投递目标，重试由调用方的发件箱负责
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 渠道类型
+const (
+	KindEmail    = "email"
+	KindWebhook  = "webhook"
+	KindTelegram = "telegram"
+	KindWebPush  = "webpush"
+)
+
+var (
+	ErrInvalidTarget = errors.New("invalid notification target")
+	// ErrPermanent 重试也无法成功的失败，例如目标拒绝请求
+	ErrPermanent = errors.New("permanent delivery failure")
+	// ErrGone 投递目标已失效，例如推送订阅已取消、机器人被用户屏蔽，渠道应停用
+	ErrGone = errors.New("notification target is gone")
+)
+
+// Message 投递到外部渠道的一条通知
+type Message struct {
+	ID    uint64 // 通知ID，接收方可用于去重
+	Type  string // 通知类型
+	Site  string // 站点名称
+	Title string
+	Body  string
+	URL   string // 相关页面地址，未配置站点地址时为空
+	Time  time.Time
+}
+
+// Channel 通知渠道，target 为用户保存的投递目标（JSON）
+type Channel interface {
+	// Validate 校验投递目标，返回规范化后的 JSON
+	Validate(target []byte) ([]byte, error)
+	// Summary 投递目标的简短描述，不包含密钥，用于在列表中展示
+	Summary(target []byte) string
+	Send(ctx context.Context, target []byte, msg *Message) error
+}
+
+// Channels 按渠道类型索引的已启用渠道，未配置的渠道不在其中
+type Channels map[string]Channel
+
+// Kinds 全部渠道类型，按展示顺序排列
+var Kinds = []string{KindEmail, KindWebhook, KindTelegram, KindWebPush}
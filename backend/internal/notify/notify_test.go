@@ -0,0 +1,372 @@
+package notify
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testMessage() *Message {
+	return &Message{
+		ID:    42,
+		Type:  "comment",
+		Site:  "小站",
+		Title: "收到新评论",
+		Body:  "你好",
+		URL:   "https://example.com/moments/1",
+		Time:  time.Date(2024, 5, 20, 13, 14, 0, 0, time.UTC),
+	}
+}
+
+// captured 测试替身收到的请求
+type captured struct {
+	path   string
+	header http.Header
+	body   []byte
+}
+
+// newRecorder 启动本地 HTTP 服务，记录收到的请求并按 respond 返回
+func newRecorder(t *testing.T, respond func(w http.ResponseWriter)) (*httptest.Server, chan captured) {
+	t.Helper()
+	requests := make(chan captured, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- captured{path: r.URL.Path, header: r.Header.Clone(), body: body}
+		respond(w)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func status(code int) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) { w.WriteHeader(code) }
+}
+
+func TestWebhookSend(t *testing.T) {
+	server, requests := newRecorder(t, status(http.StatusNoContent))
+	webhook := NewWebhook(server.Client())
+
+	target, _ := json.Marshal(WebhookTarget{URL: server.URL + "/hook", Secret: "s3cret"})
+	if err := webhook.Send(context.Background(), target, testMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	req := <-requests
+	if req.path != "/hook" || req.header.Get("X-LoveGirl-Event") != "comment" || req.header.Get("X-LoveGirl-Delivery") != "42" {
+		t.Fatalf("请求不符合预期: path=%s header=%v", req.path, req.header)
+	}
+	timestamp := req.header.Get("X-LoveGirl-Timestamp")
+	if timestamp == "" {
+		t.Fatal("缺少时间戳")
+	}
+	if got, want := req.header.Get("X-LoveGirl-Signature"), "sha256="+SignWebhook("s3cret", timestamp, req.body); got != want {
+		t.Fatalf("签名 = %s, want %s", got, want)
+	}
+	var payload WebhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if payload.ID != 42 || payload.Title != "收到新评论" || payload.Time != "2024-05-20T13:14:00Z" {
+		t.Fatalf("payload = %+v", payload)
+	}
+}
+
+func TestWebhookSendWithoutSecret(t *testing.T) {
+	server, requests := newRecorder(t, status(http.StatusOK))
+	target, _ := json.Marshal(WebhookTarget{URL: server.URL})
+	if err := NewWebhook(server.Client()).Send(context.Background(), target, testMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := <-requests
+	if req.header.Get("X-LoveGirl-Signature") != "" || req.header.Get("X-LoveGirl-Timestamp") != "" {
+		t.Fatal("未设置密钥时不应签名")
+	}
+}
+
+func TestWebhookSendStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		want    error
+		noMatch []error
+	}{
+		{name: "服务端错误可重试", code: http.StatusBadGateway, noMatch: []error{ErrPermanent, ErrGone}},
+		{name: "限流可重试", code: http.StatusTooManyRequests, noMatch: []error{ErrPermanent, ErrGone}},
+		{name: "客户端错误不再重试", code: http.StatusBadRequest, want: ErrPermanent},
+		{name: "目标已失效", code: http.StatusGone, want: ErrGone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.code)
+				_, _ = io.WriteString(w, "internal secret")
+			}))
+			defer server.Close()
+
+			target, _ := json.Marshal(WebhookTarget{URL: server.URL})
+			err := NewWebhook(server.Client()).Send(context.Background(), target, testMessage())
+			if err == nil {
+				t.Fatal("应返回错误")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			for _, e := range tt.noMatch {
+				if errors.Is(err, e) {
+					t.Fatalf("err = %v, 不应是 %v", err, e)
+				}
+			}
+			if strings.Contains(err.Error(), "internal secret") {
+				t.Fatalf("错误中不应包含响应正文: %v", err)
+			}
+		})
+	}
+}
+
+func TestTelegramSend(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		resp    string
+		wantErr bool
+		want    error
+	}{
+		{name: "发送成功", code: http.StatusOK, resp: `{"ok":true}`},
+		{name: "接口返回失败", code: http.StatusOK, resp: `{"ok":false,"description":"chat not found"}`, wantErr: true},
+		{name: "机器人被屏蔽", code: http.StatusForbidden, resp: `{"ok":false}`, wantErr: true, want: ErrGone},
+		{name: "接口异常", code: http.StatusInternalServerError, resp: `{}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newRecorder(t, func(w http.ResponseWriter) {
+				w.WriteHeader(tt.code)
+				_, _ = io.WriteString(w, tt.resp)
+			})
+			telegram := NewTelegram(server.Client(), server.URL+"/", "123:abc")
+
+			err := telegram.Send(context.Background(), []byte(`{"chatId":"10086"}`), testMessage())
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+
+			req := <-requests
+			if req.path != "/bot123:abc/sendMessage" {
+				t.Fatalf("path = %s", req.path)
+			}
+			var body struct {
+				ChatID string `json:"chat_id"`
+				Text   string `json:"text"`
+			}
+			if err := json.Unmarshal(req.body, &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.ChatID != "10086" || !strings.HasPrefix(body.Text, "【小站】收到新评论") || !strings.HasSuffix(body.Text, testMessage().URL) {
+				t.Fatalf("body = %+v", body)
+			}
+		})
+	}
+}
+
+// pushSubscriber 模拟浏览器的推送订阅，持有解密所需的私钥
+type pushSubscriber struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+}
+
+func newPushSubscriber(t *testing.T) *pushSubscriber {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(cryptorand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	auth := make([]byte, 16)
+	_, _ = cryptorand.Read(auth)
+	return &pushSubscriber{key: key, auth: auth}
+}
+
+func (p *pushSubscriber) target(endpoint string) []byte {
+	var sub PushSubscription
+	sub.Endpoint = endpoint
+	sub.Keys.P256dh = base64.RawURLEncoding.EncodeToString(p.key.PublicKey().Bytes())
+	sub.Keys.Auth = base64.RawURLEncoding.EncodeToString(p.auth)
+	data, _ := json.Marshal(sub)
+	return data
+}
+
+// decrypt 按 RFC 8291 解密 aes128gcm 请求体
+func (p *pushSubscriber) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+	if len(body) < 21 || len(body) < 21+int(body[20]) {
+		t.Fatalf("请求体过短: %d", len(body))
+	}
+	salt, idLen := body[:16], int(body[20])
+	serverKey, err := ecdh.P256().NewPublicKey(body[21 : 21+idLen])
+	if err != nil {
+		t.Fatalf("parse server key: %v", err)
+	}
+	shared, err := p.key.ECDH(serverKey)
+	if err != nil {
+		t.Fatalf("ecdh: %v", err)
+	}
+	keyInfo := append([]byte("WebPush: info\x00"), p.key.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, serverKey.Bytes()...)
+	ikm, _ := hkdfExpand(p.auth, shared, keyInfo, 32)
+	cek, _ := hkdfExpand(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce, _ := hkdfExpand(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	record, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if len(record) == 0 || record[len(record)-1] != 0x02 {
+		t.Fatal("记录缺少结尾分隔符")
+	}
+	return record[:len(record)-1]
+}
+
+func newTestWebPush(t *testing.T, client *http.Client) *WebPush {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+	if err != nil {
+		t.Fatalf("generate vapid key: %v", err)
+	}
+	raw, err := key.Bytes()
+	if err != nil {
+		t.Fatalf("encode vapid key: %v", err)
+	}
+	push, err := NewWebPush(client, base64.RawURLEncoding.EncodeToString(raw), "mailto:admin@example.com")
+	if err != nil {
+		t.Fatalf("NewWebPush: %v", err)
+	}
+	return push
+}
+
+func TestWebPushSend(t *testing.T) {
+	server, requests := newRecorder(t, status(http.StatusCreated))
+	push := newTestWebPush(t, server.Client())
+	subscriber := newPushSubscriber(t)
+
+	if err := push.Send(context.Background(), subscriber.target(server.URL+"/push/abc"), testMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	req := <-requests
+	if req.header.Get("Content-Encoding") != "aes128gcm" || req.header.Get("TTL") == "" {
+		t.Fatalf("header = %v", req.header)
+	}
+	if auth := req.header.Get("Authorization"); !strings.HasPrefix(auth, "vapid t=") || !strings.HasSuffix(auth, ", k="+push.PublicKey()) {
+		t.Fatalf("Authorization = %s", auth)
+	}
+	var payload WebPushPayload
+	if err := json.Unmarshal(subscriber.decrypt(t, req.body), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.ID != 42 || payload.Title != "收到新评论" || payload.URL != testMessage().URL {
+		t.Fatalf("payload = %+v", payload)
+	}
+}
+
+func TestWebPushSendGone(t *testing.T) {
+	for _, code := range []int{http.StatusGone, http.StatusNotFound} {
+		server, _ := newRecorder(t, status(code))
+		push := newTestWebPush(t, server.Client())
+		err := push.Send(context.Background(), newPushSubscriber(t).target(server.URL), testMessage())
+		if !errors.Is(err, ErrGone) {
+			t.Fatalf("status %d: err = %v, want ErrGone", code, err)
+		}
+	}
+}
+
+func TestHTTPClientRejectsInternalAddress(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+
+	target, _ := json.Marshal(WebhookTarget{URL: server.URL})
+	err := NewWebhook(NewHTTPClient(5*time.Second)).Send(context.Background(), target, testMessage())
+	if !errors.Is(err, ErrForbiddenAddress) || !errors.Is(err, ErrPermanent) {
+		t.Fatalf("err = %v, want ErrForbiddenAddress", err)
+	}
+	if hits.Load() != 0 {
+		t.Fatal("不应连接到内网地址")
+	}
+}
+
+func TestHTTPClientDoesNotFollowRedirects(t *testing.T) {
+	var followed atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hook", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/internal", func(w http.ResponseWriter, r *http.Request) {
+		followed.Store(true)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// 测试服务器在回环地址上，换用默认连接方式，只检查重定向策略
+	client := NewHTTPClient(5 * time.Second)
+	client.Transport = server.Client().Transport
+
+	target, _ := json.Marshal(WebhookTarget{URL: server.URL + "/hook"})
+	err := NewWebhook(client).Send(context.Background(), target, testMessage())
+	if !errors.Is(err, ErrPermanent) {
+		t.Fatalf("err = %v, want ErrPermanent", err)
+	}
+	if followed.Load() {
+		t.Fatal("不应跟随重定向")
+	}
+}
+
+func TestValidTargetURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "公网域名", url: "https://hooks.example.com/a"},
+		{name: "公网 IP", url: "http://8.8.8.8/a"},
+		{name: "非 http 协议", url: "ftp://example.com", wantErr: true},
+		{name: "localhost", url: "http://localhost:8080", wantErr: true},
+		{name: "localhost 子域名", url: "http://api.localhost.", wantErr: true},
+		{name: "回环地址", url: "http://127.0.0.1/", wantErr: true},
+		{name: "私有网络", url: "http://192.168.1.1/", wantErr: true},
+		{name: "云服务器元数据", url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{name: "运营商级 NAT", url: "http://100.64.0.1/", wantErr: true},
+		{name: "IPv6 回环", url: "http://[::1]/", wantErr: true},
+		{name: "IPv4 映射的 IPv6", url: "http://[::ffff:10.0.0.1]/", wantErr: true},
+		{name: "未指定地址", url: "http://0.0.0.0/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validTargetURL(tt.url)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidTarget) {
+				t.Fatalf("err = %v, want ErrInvalidTarget", err)
+			}
+		})
+	}
+}
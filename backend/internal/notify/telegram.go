@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultTelegramAPIURL Telegram Bot API 地址
+const DefaultTelegramAPIURL = "https://api.telegram.org"
+
+// TelegramTarget Telegram 投递目标，用户向机器人发送消息后可获得会话ID
+type TelegramTarget struct {
+	ChatID string `json:"chatId"`
+}
+
+// Telegram 通过机器人的 sendMessage 接口发送消息
+// apiURL 可以指向兼容 Bot API 的自建服务或测试替身
+type Telegram struct {
+	client *http.Client
+	apiURL string
+	token  string
+}
+
+func NewTelegram(client *http.Client, apiURL, token string) *Telegram {
+	if apiURL == "" {
+		apiURL = DefaultTelegramAPIURL
+	}
+	return &Telegram{
+		client: client,
+		apiURL: strings.TrimRight(apiURL, "/"),
+		token:  token,
+	}
+}
+
+func (t *Telegram) Validate(target []byte) ([]byte, error) {
+	var tt TelegramTarget
+	if err := json.Unmarshal(target, &tt); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+	tt.ChatID = strings.TrimSpace(tt.ChatID)
+	if tt.ChatID == "" || strings.ContainsAny(tt.ChatID, " /?#") {
+		return nil, fmt.Errorf("%w: invalid chat id", ErrInvalidTarget)
+	}
+	return json.Marshal(tt)
+}
+
+func (t *Telegram) Summary(target []byte) string {
+	var tt TelegramTarget
+	_ = json.Unmarshal(target, &tt)
+	return tt.ChatID
+}
+
+// telegramResponse Bot API 的响应
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+func (t *Telegram) Send(ctx context.Context, target []byte, msg *Message) error {
+	var tt TelegramTarget
+	if err := json.Unmarshal(target, &tt); err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+
+	text := "【" + msg.Site + "】" + msg.Title
+	if msg.Body != "" {
+		text += "\n\n" + msg.Body
+	}
+	if msg.URL != "" {
+		text += "\n\n" + msg.URL
+	}
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  tt.ChatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	status, data, err := post(ctx, t.client, t.apiURL+"/bot"+t.token+"/sendMessage", header, body)
+	if status == http.StatusForbidden {
+		// 用户屏蔽了机器人或机器人被移出群组
+		return fmt.Errorf("%w: %v", ErrGone, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	var resp telegramResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("decode telegram response: %w", err)
+	}
+	if !resp.OK {
+		return errors.New("telegram: " + resp.Description)
+	}
+	return nil
+}
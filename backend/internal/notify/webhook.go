@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WebhookTarget Webhook 投递目标，Secret 不为空时对请求体签名
+type WebhookTarget struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookPayload Webhook 请求体
+type WebhookPayload struct {
+	ID    uint64 `json:"id"`
+	Type  string `json:"type"`
+	Site  string `json:"site"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+	Time  string `json:"time"`
+}
+
+// Webhook 向用户指定的地址 POST JSON
+// 设置了密钥时通过 X-LoveGirl-Signature 请求头携带 "sha256=" + HMAC-SHA256(密钥, 时间戳 + "." + 请求体) 的十六进制值，
+// 时间戳在 X-LoveGirl-Timestamp 请求头中，接收方可据此拒绝重放的请求
+type Webhook struct {
+	client *http.Client
+}
+
+func NewWebhook(client *http.Client) *Webhook {
+	return &Webhook{client: client}
+}
+
+func (w *Webhook) Validate(target []byte) ([]byte, error) {
+	var t WebhookTarget
+	if err := json.Unmarshal(target, &t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+	if _, err := validTargetURL(t.URL); err != nil {
+		return nil, err
+	}
+	return json.Marshal(t)
+}
+
+func (w *Webhook) Summary(target []byte) string {
+	var t WebhookTarget
+	_ = json.Unmarshal(target, &t)
+	if u, err := validHTTPURL(t.URL); err == nil {
+		return u.Scheme + "://" + u.Host + u.Path
+	}
+	return ""
+}
+
+func (w *Webhook) Send(ctx context.Context, target []byte, msg *Message) error {
+	var t WebhookTarget
+	if err := json.Unmarshal(target, &t); err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+
+	body, err := json.Marshal(WebhookPayload{
+		ID:    msg.ID,
+		Type:  msg.Type,
+		Site:  msg.Site,
+		Title: msg.Title,
+		Body:  msg.Body,
+		URL:   msg.URL,
+		Time:  msg.Time.Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("X-LoveGirl-Event", msg.Type)
+	header.Set("X-LoveGirl-Delivery", strconv.FormatUint(msg.ID, 10))
+	if t.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		header.Set("X-LoveGirl-Timestamp", timestamp)
+		header.Set("X-LoveGirl-Signature", "sha256="+SignWebhook(t.Secret, timestamp, body))
+	}
+
+	_, _, err = post(ctx, w.client, t.URL, header, body)
+	return err
+}
+
+// SignWebhook 计算 Webhook 签名，供接收方校验时参考
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
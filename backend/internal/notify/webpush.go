@@ -0,0 +1,266 @@
+package notify
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// webPushTTL 推送服务为离线设备保留消息的时间（秒）
+	webPushTTL = 24 * 60 * 60
+	// webPushRecordSize aes128gcm 的记录大小，消息只使用一条记录
+	webPushRecordSize = 4096
+	// webPushMaxPayload 加密前的最大长度：记录大小减去 16 字节认证标签和 1 字节分隔符
+	webPushMaxPayload = webPushRecordSize - 16 - 1
+	// vapidTokenTTL VAPID 令牌有效期，规范要求不超过 24 小时
+	vapidTokenTTL = 12 * time.Hour
+)
+
+// PushSubscription 浏览器 PushSubscription.toJSON() 的结果
+type PushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// WebPushPayload 推送给 Service Worker 的消息
+type WebPushPayload struct {
+	ID    uint64 `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+}
+
+// WebPush 按 RFC 8030/8291/8292 发送 Web Push：aes128gcm 加密消息，VAPID 标识应用服务器
+type WebPush struct {
+	client    *http.Client
+	key       *ecdsa.PrivateKey
+	publicKey string // base64url 编码的未压缩公钥，前端订阅时作为 applicationServerKey
+	subject   string // 推送服务联系应用服务器时使用的 mailto: 或 https: 地址
+}
+
+// NewWebPush privateKey 为 base64url 编码的 P-256 私钥（32 字节），与常见 web-push 工具生成的格式相同
+func NewWebPush(client *http.Client, privateKey, subject string) (*WebPush, error) {
+	raw, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode vapid private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse vapid private key: %w", err)
+	}
+	publicKey, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return &WebPush{
+		client:    client,
+		key:       key,
+		publicKey: base64.RawURLEncoding.EncodeToString(publicKey),
+		subject:   subject,
+	}, nil
+}
+
+// PublicKey 前端调用 pushManager.subscribe 时使用的 applicationServerKey
+func (w *WebPush) PublicKey() string {
+	return w.publicKey
+}
+
+func (w *WebPush) Validate(target []byte) ([]byte, error) {
+	var sub PushSubscription
+	if err := json.Unmarshal(target, &sub); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+	if _, err := validTargetURL(sub.Endpoint); err != nil {
+		return nil, err
+	}
+	if _, _, err := sub.keys(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(sub)
+}
+
+func (w *WebPush) Summary(target []byte) string {
+	var sub PushSubscription
+	_ = json.Unmarshal(target, &sub)
+	if u, err := validHTTPURL(sub.Endpoint); err == nil {
+		return u.Host
+	}
+	return ""
+}
+
+func (w *WebPush) Send(ctx context.Context, target []byte, msg *Message) error {
+	var sub PushSubscription
+	if err := json.Unmarshal(target, &sub); err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	endpoint, err := validHTTPURL(sub.Endpoint)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	userKey, authSecret, err := sub.keys()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+
+	payload := WebPushPayload{ID: msg.ID, Type: msg.Type, Title: msg.Title, Body: msg.Body, URL: msg.URL}
+	plaintext, err := json.Marshal(payload)
+	for err == nil && len(plaintext) > webPushMaxPayload && payload.Body != "" {
+		// 超出单条记录时截短正文
+		payload.Body = truncateRunes(payload.Body, utf8.RuneCountInString(payload.Body)/2)
+		plaintext, err = json.Marshal(payload)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	body, err := encryptPushPayload(plaintext, userKey, authSecret)
+	if err != nil {
+		return fmt.Errorf("%w: encrypt: %v", ErrPermanent, err)
+	}
+
+	token, err := w.vapidToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return fmt.Errorf("%w: sign vapid token: %v", ErrPermanent, err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Encoding", "aes128gcm")
+	header.Set("TTL", strconv.Itoa(webPushTTL))
+	header.Set("Urgency", "normal")
+	header.Set("Authorization", "vapid t="+token+", k="+w.publicKey)
+
+	status, _, err := post(ctx, w.client, sub.Endpoint, header, body)
+	if status == http.StatusNotFound {
+		// 推送服务对已过期的订阅返回 404 或 410
+		return fmt.Errorf("%w: %v", ErrGone, err)
+	}
+	return err
+}
+
+// vapidToken 生成 RFC 8292 的 VAPID 令牌，aud 为推送服务的源
+func (w *WebPush) vapidToken(audience string) (string, error) {
+	claims := jwt.MapClaims{
+		"aud": audience,
+		"exp": time.Now().Add(vapidTokenTTL).Unix(),
+	}
+	if w.subject != "" {
+		claims["sub"] = w.subject
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(w.key)
+}
+
+// keys 解码订阅中的用户代理公钥和认证密钥
+func (sub *PushSubscription) keys() (*ecdh.PublicKey, []byte, error) {
+	rawKey, err := decodeBase64URL(sub.Keys.P256dh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid p256dh", ErrInvalidTarget)
+	}
+	userKey, err := ecdh.P256().NewPublicKey(rawKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid p256dh", ErrInvalidTarget)
+	}
+	authSecret, err := decodeBase64URL(sub.Keys.Auth)
+	if err != nil || len(authSecret) != 16 {
+		return nil, nil, fmt.Errorf("%w: invalid auth secret", ErrInvalidTarget)
+	}
+	return userKey, authSecret, nil
+}
+
+// encryptPushPayload 按 RFC 8291 使用一次性密钥对加密消息，返回 aes128gcm 编码的请求体
+func encryptPushPayload(plaintext []byte, userKey *ecdh.PublicKey, authSecret []byte) ([]byte, error) {
+	serverKey, err := ecdh.P256().GenerateKey(cryptorand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret, err := serverKey.ECDH(userKey)
+	if err != nil {
+		return nil, err
+	}
+	serverPublic := serverKey.PublicKey().Bytes()
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public)
+	keyInfo := append([]byte("WebPush: info\x00"), userKey.Bytes()...)
+	keyInfo = append(keyInfo, serverPublic...)
+	ikm, err := hkdfExpand(authSecret, sharedSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := cryptorand.Read(salt); err != nil {
+		return nil, err
+	}
+	cek, err := hkdfExpand(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdfExpand(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// 头部：salt(16) || rs(4) || idlen(1) || keyid(as_public)
+	body := make([]byte, 0, 16+4+1+len(serverPublic)+len(plaintext)+1+gcm.Overhead())
+	body = append(body, salt...)
+	body = binary.BigEndian.AppendUint32(body, webPushRecordSize)
+	body = append(body, byte(len(serverPublic)))
+	body = append(body, serverPublic...)
+	// 唯一的记录以 0x02 分隔符结尾
+	record := append(append([]byte{}, plaintext...), 0x02)
+	return gcm.Seal(body, nonce, record, nil), nil
+}
+
+func hkdfExpand(salt, secret, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := hkdf.New(sha256.New, secret, salt, info).Read(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+	if data, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return data, nil
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
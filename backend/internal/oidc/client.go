@@ -0,0 +1,200 @@
+// Package oidc OpenID Connect 依赖方（Relying Party）实现：自动发现、授权码 + PKCE、ID Token 校验
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bookandmusic/love-girl/internal/config"
+)
+
+var (
+	ErrDisabled       = errors.New("oidc is not configured")
+	ErrIssuerMismatch = errors.New("oidc discovery issuer mismatch")
+)
+
+// discoveryTTL 自动发现文档的缓存时间
+const discoveryTTL = time.Hour
+
+// Discovery 自动发现文档中用到的字段
+type Discovery struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	JWKSURI                       string   `json:"jwks_uri"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+}
+
+// TokenResponse 令牌端点的响应
+type TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Client OIDC 客户端，发现文档与签名公钥均在内存中缓存
+type Client struct {
+	cfg  *config.OIDCConfig
+	http *http.Client
+
+	mu           sync.Mutex
+	discovery    *Discovery
+	discoveredAt time.Time
+	keys         *keySet
+}
+
+func NewClient(cfg *config.OIDCConfig) *Client {
+	return &Client{
+		cfg:  cfg,
+		http: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Enabled 是否已配置 OIDC 登录
+func (c *Client) Enabled() bool {
+	return c.cfg.Enabled()
+}
+
+// ProviderName 登录按钮显示的名称
+func (c *Client) ProviderName() string {
+	return c.cfg.ProviderName
+}
+
+// Discover 获取自动发现文档
+func (c *Client) Discover(ctx context.Context) (*Discovery, error) {
+	if !c.Enabled() {
+		return nil, ErrDisabled
+	}
+
+	c.mu.Lock()
+	if c.discovery != nil && time.Since(c.discoveredAt) < discoveryTTL {
+		d := c.discovery
+		c.mu.Unlock()
+		return d, nil
+	}
+	c.mu.Unlock()
+
+	issuer := strings.TrimRight(c.cfg.Issuer, "/")
+	var d Discovery
+	if err := c.getJSON(ctx, issuer+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("fetch discovery document: %w", err)
+	}
+	// 规范要求发现文档中的 issuer 与请求地址完全一致，防止混淆攻击
+	if strings.TrimRight(d.Issuer, "/") != issuer {
+		return nil, ErrIssuerMismatch
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("incomplete discovery document")
+	}
+
+	c.mu.Lock()
+	c.discovery = &d
+	c.discoveredAt = time.Now()
+	if c.keys == nil || c.keys.uri != d.JWKSURI {
+		c.keys = newKeySet(d.JWKSURI)
+	}
+	c.mu.Unlock()
+	return &d, nil
+}
+
+// AuthCodeURL 构建授权地址
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	d, err := c.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := c.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid"}
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", c.cfg.ClientID)
+	query.Set("redirect_uri", c.cfg.RedirectURL)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + query.Encode(), nil
+}
+
+// Exchange 使用授权码和 PKCE 校验码换取令牌
+func (c *Client) Exchange(ctx context.Context, code, codeVerifier string) (*TokenResponse, error) {
+	d, err := c.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", c.cfg.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token TokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&token); err != nil {
+		return nil, fmt.Errorf("decode token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || token.Error != "" {
+		return nil, fmt.Errorf("token endpoint returned %d: %s %s", resp.StatusCode, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+	return &token, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, endpoint string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
@@ -0,0 +1,366 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+
+	"github.com/bookandmusic/love-girl/internal/config"
+)
+
+const (
+	testClientID    = "love-girl"
+	testRedirectURL = "https://love.example.com/api/v1/auth/oidc/callback"
+	testKeyID       = "key-1"
+)
+
+// testIssuer 本地身份提供方，提供自动发现、JWKS 和令牌端点
+type testIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu      sync.Mutex
+	codes   map[string]string // 授权码 -> code_challenge，授权码只能使用一次
+	idToken string            // 令牌端点返回的 ID Token
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	issuer := &testIssuer{key: key, codes: make(map[string]string)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Discovery{
+			Issuer:                        issuer.server.URL,
+			AuthorizationEndpoint:         issuer.server.URL + "/authorize",
+			TokenEndpoint:                 issuer.server.URL + "/token",
+			JWKSURI:                       issuer.server.URL + "/jwks",
+			CodeChallengeMethodsSupported: []string{"S256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"keys": []jsonWebKey{{
+			Kty: "RSA",
+			Kid: testKeyID,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", issuer.token)
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// token 令牌端点：校验授权码、回调地址和 PKCE 校验码
+func (i *testIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+		writeJSON(w, http.StatusBadRequest, TokenResponse{Error: "invalid_request"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("client_id") != testClientID ||
+		r.PostForm.Get("redirect_uri") != testRedirectURL {
+		writeJSON(w, http.StatusBadRequest, TokenResponse{Error: "invalid_request"})
+		return
+	}
+
+	i.mu.Lock()
+	challenge, ok := i.codes[r.PostForm.Get("code")]
+	delete(i.codes, r.PostForm.Get("code"))
+	idToken := i.idToken
+	i.mu.Unlock()
+
+	if !ok || CodeChallenge(r.PostForm.Get("code_verifier")) != challenge {
+		writeJSON(w, http.StatusBadRequest, TokenResponse{Error: "invalid_grant"})
+		return
+	}
+	writeJSON(w, http.StatusOK, TokenResponse{AccessToken: "access", TokenType: "Bearer", IDToken: idToken, ExpiresIn: 300})
+}
+
+// authorize 模拟用户在授权页面同意授权，返回授权码
+func (i *testIssuer) authorize(t *testing.T, authURL string) (code string, query url.Values) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil || !strings.HasPrefix(authURL, i.server.URL+"/authorize?") {
+		t.Fatalf("授权地址不正确: %s", authURL)
+	}
+	query = u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("授权地址缺少 PKCE 参数: %s", authURL)
+	}
+
+	code = RandomString(8)
+	i.mu.Lock()
+	i.codes[code] = query.Get("code_challenge")
+	i.mu.Unlock()
+	return code, query
+}
+
+func (i *testIssuer) setIDToken(idToken string) {
+	i.mu.Lock()
+	i.idToken = idToken
+	i.mu.Unlock()
+}
+
+// sign 使用身份提供方的密钥签发 ID Token
+func (i *testIssuer) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		t.Fatalf("sign id token: %v", err)
+	}
+	return signed
+}
+
+// claims 有效的 ID Token 声明
+func (i *testIssuer) claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            i.server.URL,
+		"sub":            "user-1",
+		"aud":            testClientID,
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          "partner@example.com",
+		"email_verified": true,
+	}
+}
+
+func (i *testIssuer) client() *Client {
+	return NewClient(&config.OIDCConfig{
+		Issuer:      i.server.URL,
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+	})
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	issuer := newTestIssuer(t)
+	client := issuer.client()
+	ctx := context.Background()
+
+	states := NewStateStore()
+	flow := &Flow{Nonce: RandomString(16), CodeVerifier: RandomString(32), Binding: RandomString(16)}
+	state, err := states.Put(flow)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	authURL, err := client.AuthCodeURL(ctx, state, flow.Nonce, CodeChallenge(flow.CodeVerifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	code, query := issuer.authorize(t, authURL)
+	if query.Get("state") != state || query.Get("nonce") != flow.Nonce || query.Get("client_id") != testClientID ||
+		query.Get("redirect_uri") != testRedirectURL || query.Get("scope") != "openid" {
+		t.Fatalf("授权参数不正确: %v", query)
+	}
+	issuer.setIDToken(issuer.sign(t, issuer.claims(query.Get("nonce"))))
+
+	taken := states.Take(state)
+	if taken == nil {
+		t.Fatal("state 应存在")
+	}
+	token, err := client.Exchange(ctx, code, taken.CodeVerifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	claims, err := client.VerifyIDToken(ctx, token.IDToken, taken.Nonce)
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Email != "partner@example.com" || !claims.EmailVerified {
+		t.Fatalf("claims = %+v", claims)
+	}
+
+	if _, err := client.Exchange(ctx, code, taken.CodeVerifier); err == nil {
+		t.Fatal("授权码不能重复使用")
+	}
+}
+
+func TestExchangeRequiresCodeVerifier(t *testing.T) {
+	issuer := newTestIssuer(t)
+	client := issuer.client()
+	ctx := context.Background()
+	issuer.setIDToken(issuer.sign(t, issuer.claims("nonce")))
+
+	verifier := RandomString(32)
+	authURL, err := client.AuthCodeURL(ctx, "state", "nonce", CodeChallenge(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	// 截获授权码的攻击者不知道校验码
+	code, _ := issuer.authorize(t, authURL)
+	if _, err := client.Exchange(ctx, code, RandomString(32)); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("校验码错误时应失败，err = %v", err)
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	issuer := newTestIssuer(t)
+	client := issuer.client()
+	const nonce = "expected-nonce"
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(claims jwt.MapClaims)
+		sign    func(claims jwt.MapClaims) string // 为空时使用身份提供方的密钥签名
+		wantErr error                             // 为空时只要求返回错误
+		valid   bool
+	}{
+		{name: "有效令牌", valid: true},
+		{name: "nonce 不匹配", modify: func(c jwt.MapClaims) { c["nonce"] = "other" }, wantErr: ErrNonceMismatch},
+		{name: "缺少 nonce", modify: func(c jwt.MapClaims) { delete(c, "nonce") }, wantErr: ErrNonceMismatch},
+		{name: "aud 不是本客户端", modify: func(c jwt.MapClaims) { c["aud"] = "other-client" }, wantErr: jwt.ErrTokenInvalidAudience},
+		{name: "多个 aud 缺少 azp", modify: func(c jwt.MapClaims) { c["aud"] = []string{testClientID, "other-client"} }},
+		{name: "多个 aud 且 azp 是其他客户端", modify: func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "other-client"}
+			c["azp"] = "other-client"
+		}},
+		{name: "多个 aud 且 azp 是本客户端", modify: func(c jwt.MapClaims) {
+			c["aud"] = []string{"other-client", testClientID}
+			c["azp"] = testClientID
+		}, valid: true},
+		{name: "已过期", modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() }, wantErr: jwt.ErrTokenExpired},
+		{name: "过期时间在容差内", modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-30 * time.Second).Unix() }, valid: true},
+		{name: "缺少过期时间", modify: func(c jwt.MapClaims) { delete(c, "exp") }, wantErr: jwt.ErrTokenRequiredClaimMissing},
+		{name: "签发时间在未来", modify: func(c jwt.MapClaims) { c["iat"] = time.Now().Add(10 * time.Minute).Unix() }, wantErr: jwt.ErrTokenUsedBeforeIssued},
+		{name: "issuer 不匹配", modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, wantErr: jwt.ErrTokenInvalidIssuer},
+		{name: "缺少 subject", modify: func(c jwt.MapClaims) { delete(c, "sub") }},
+		{name: "其他密钥签名", sign: func(c jwt.MapClaims) string {
+			token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+			token.Header["kid"] = testKeyID
+			signed, _ := token.SignedString(otherKey)
+			return signed
+		}, wantErr: jwt.ErrTokenSignatureInvalid},
+		{name: "对称签名", sign: func(c jwt.MapClaims) string {
+			signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte(testClientID))
+			return signed
+		}, wantErr: jwt.ErrTokenSignatureInvalid},
+		{name: "不签名", sign: func(c jwt.MapClaims) string {
+			signed, _ := jwt.NewWithClaims(jwt.SigningMethodNone, c).SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return signed
+		}, wantErr: jwt.ErrTokenSignatureInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuer.claims(nonce)
+			if tt.modify != nil {
+				tt.modify(claims)
+			}
+			var raw string
+			if tt.sign != nil {
+				raw = tt.sign(claims)
+			} else {
+				raw = issuer.sign(t, claims)
+			}
+
+			got, err := client.VerifyIDToken(context.Background(), raw, nonce)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("VerifyIDToken: %v", err)
+				}
+				if got.Subject != "user-1" {
+					t.Fatalf("subject = %s", got.Subject)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("应拒绝该令牌")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Discovery{
+			Issuer:                "https://evil.example.com",
+			AuthorizationEndpoint: "https://evil.example.com/authorize",
+			TokenEndpoint:         "https://evil.example.com/token",
+			JWKSURI:               "https://evil.example.com/jwks",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.OIDCConfig{Issuer: server.URL, ClientID: testClientID})
+	if _, err := client.Discover(context.Background()); !errors.Is(err, ErrIssuerMismatch) {
+		t.Fatalf("err = %v, want ErrIssuerMismatch", err)
+	}
+}
+
+func TestStateStore(t *testing.T) {
+	store := NewStateStore()
+	state, err := store.Put(&Flow{Nonce: "n"})
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if flow := store.Take(state); flow == nil || flow.Nonce != "n" {
+		t.Fatalf("Take = %+v", flow)
+	}
+	if store.Take(state) != nil {
+		t.Fatal("state 只能使用一次")
+	}
+
+	expired, _ := store.Put(&Flow{})
+	store.flows[expired].ExpiresAt = time.Now().Add(-time.Second)
+	if store.Take(expired) != nil {
+		t.Fatal("过期的 state 不应可用")
+	}
+}
+
+func TestStateStoreLimit(t *testing.T) {
+	store := &StateStore{ttl: time.Minute, max: 2, flows: make(map[string]*Flow)}
+	first, _ := store.Put(&Flow{})
+	if _, err := store.Put(&Flow{}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := store.Put(&Flow{}); !errors.Is(err, ErrTooManyFlows) {
+		t.Fatalf("err = %v, want ErrTooManyFlows", err)
+	}
+
+	// 过期的流程在达到上限时被清理
+	store.flows[first].ExpiresAt = time.Now().Add(-time.Second)
+	if _, err := store.Put(&Flow{}); err != nil {
+		t.Fatalf("清理过期流程后应能保存: %v", err)
+	}
+	if len(store.flows) != 2 {
+		t.Fatalf("len = %d, want 2", len(store.flows))
+	}
+}
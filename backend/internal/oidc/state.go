@@ -0,0 +1,91 @@
+package oidc
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// stateTTL 登录流程的有效期
+	stateTTL = 10 * time.Minute
+	// maxFlows 同时进行中的登录流程上限，未完成的流程会一直占用内存直到过期
+	maxFlows = 1000
+)
+
+var ErrTooManyFlows = errors.New("too many pending oidc flows")
+
+// Flow 一次授权流程的上下文，以 state 为键保存在服务端
+type Flow struct {
+	Nonce        string
+	CodeVerifier string
+	Binding      string // 浏览器绑定值，存于 HttpOnly Cookie，防止登录 CSRF
+	LinkUserID   uint64 // 非 0 表示为已登录用户关联外部身份
+	ExpiresAt    time.Time
+}
+
+// StateStore 内存中的授权流程存储，state 只能使用一次
+type StateStore struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	max   int
+	flows map[string]*Flow
+}
+
+func NewStateStore() *StateStore {
+	return &StateStore{ttl: stateTTL, max: maxFlows, flows: make(map[string]*Flow)}
+}
+
+// Put 保存流程并返回新的 state，进行中的流程达到上限时返回 ErrTooManyFlows
+func (s *StateStore) Put(flow *Flow) (string, error) {
+	state := RandomString(32)
+	now := time.Now()
+	flow.ExpiresAt = now.Add(s.ttl)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.flows) >= s.max {
+		s.cleanup(now)
+		if len(s.flows) >= s.max {
+			return "", ErrTooManyFlows
+		}
+	}
+	s.flows[state] = flow
+	return state, nil
+}
+
+// Take 取出并删除流程，过期或不存在时返回 nil
+func (s *StateStore) Take(state string) *Flow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flow, ok := s.flows[state]
+	if !ok {
+		return nil
+	}
+	delete(s.flows, state)
+	if time.Now().After(flow.ExpiresAt) {
+		return nil
+	}
+	return flow
+}
+
+// cleanup 清理过期流程，调用方需持有 mu
+func (s *StateStore) cleanup(now time.Time) {
+	for state, flow := range s.flows {
+		if now.After(flow.ExpiresAt) {
+			delete(s.flows, state)
+		}
+	}
+}
+
+// RandomString 生成 URL 安全的随机串，n 为随机字节数
+func RandomString(n int) string {
+	bytes := make([]byte, n)
+	if _, err := cryptorand.Read(bytes); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
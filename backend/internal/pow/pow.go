@@ -0,0 +1,147 @@
+// Package pow 提供基于哈希的工作量证明（proof-of-work）题目，用于在不要求登录的接口上增加批量提交的成本。
+//
+// 服务端签发带签名和有效期的题目 challenge，客户端寻找字符串 solution，
+// 使 SHA-256(challenge + ":" + solution) 的前导零比特数不少于 difficulty。
+// 题目无需在服务端存储，只在验证通过后记录一次以防重放。
+package pow
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalid  = errors.New("invalid challenge")
+	ErrExpired  = errors.New("challenge expired")
+	ErrUsed     = errors.New("challenge already used")
+	ErrUnsolved = errors.New("challenge not solved")
+)
+
+// Challenge 签发给客户端的题目
+type Challenge struct {
+	Token      string    `json:"challenge"`
+	Difficulty int       `json:"difficulty"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// Issuer 签发和校验工作量证明题目
+type Issuer struct {
+	secret     []byte
+	difficulty int
+	ttl        time.Duration
+
+	mu   sync.Mutex
+	used map[string]time.Time // 已使用的题目及其过期时间
+}
+
+// NewIssuer 创建题目签发器，difficulty 为要求的前导零比特数
+func NewIssuer(secret string, difficulty int, ttl time.Duration) *Issuer {
+	return &Issuer{
+		secret:     []byte(secret),
+		difficulty: difficulty,
+		ttl:        ttl,
+		used:       make(map[string]time.Time),
+	}
+}
+
+// Issue 签发新题目
+func (i *Issuer) Issue() (*Challenge, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(i.ttl).Truncate(time.Second)
+	payload := fmt.Sprintf("%s.%d.%d", hex.EncodeToString(nonce), expiresAt.Unix(), i.difficulty)
+	return &Challenge{
+		Token:      payload + "." + i.sign(payload),
+		Difficulty: i.difficulty,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// Verify 校验题目签名、有效期和解答，通过后题目作废
+func (i *Issuer) Verify(token, solution string) error {
+	payload, sig, ok := cutLast(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(i.sign(payload))) {
+		return ErrInvalid
+	}
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 {
+		return ErrInvalid
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return ErrInvalid
+	}
+	difficulty, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return ErrInvalid
+	}
+	now := time.Now()
+	expiresAt := time.Unix(expires, 0)
+	if now.After(expiresAt) {
+		return ErrExpired
+	}
+	if solution == "" || len(solution) > 64 || LeadingZeroBits(token, solution) < difficulty {
+		return ErrUnsolved
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for t, exp := range i.used {
+		if now.After(exp) {
+			delete(i.used, t)
+		}
+	}
+	if _, ok := i.used[token]; ok {
+		return ErrUsed
+	}
+	i.used[token] = expiresAt
+	return nil
+}
+
+func (i *Issuer) sign(payload string) string {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte("pow:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// LeadingZeroBits 计算 SHA-256(token + ":" + solution) 的前导零比特数
+func LeadingZeroBits(token, solution string) int {
+	sum := sha256.Sum256([]byte(token + ":" + solution))
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
+
+// Solve 暴力求解题目，返回满足难度要求的解答，供客户端和调试使用
+func Solve(token string, difficulty int) string {
+	for n := 0; ; n++ {
+		solution := strconv.Itoa(n)
+		if LeadingZeroBits(token, solution) >= difficulty {
+			return solution
+		}
+	}
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,210 @@
+// Package realtime 进程内的实时事件分发，按用户推送事件并保留最近的事件用于断线续传
+package realtime
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTooManyConnections = errors.New("too many realtime connections")
+	ErrHubClosed          = errors.New("realtime hub closed")
+)
+
+// subscriptionBuffer 每个连接待发送事件的缓冲数，写满说明客户端消费太慢，断开后由客户端续传
+const subscriptionBuffer = 32
+
+// Event 推送给用户的事件
+type Event struct {
+	ID      uint64 // 进程内递增，初始值为启动时间（微秒），重启后不会与之前的ID重叠
+	Type    string
+	Payload any // 由订阅方按连接渲染，例如生成带域名的文件地址
+}
+
+// Subscription 一个实时连接的订阅
+type Subscription struct {
+	hub    *Hub
+	userID uint64
+	events chan Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Events 新事件
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Done 订阅被服务端结束时关闭：服务关闭，或客户端消费太慢
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close 取消订阅
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+func (s *Subscription) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// userState 用户的连接和最近事件
+type userState struct {
+	subs    map[*Subscription]struct{}
+	recent  []Event
+	evicted uint64 // 已从 recent 中淘汰的最大事件ID
+}
+
+// Hub 实时事件中心
+type Hub struct {
+	mu         sync.Mutex
+	seq        uint64
+	startID    uint64
+	maxConns   int
+	bufferSize int
+	users      map[uint64]*userState
+	closed     bool
+}
+
+// NewHub 创建事件中心，maxConns 为每个用户的最大连接数，bufferSize 为每个用户保留用于续传的最近事件数
+func NewHub(maxConns, bufferSize int) *Hub {
+	start := uint64(time.Now().UnixMicro())
+	return &Hub{
+		seq:        start,
+		startID:    start,
+		maxConns:   maxConns,
+		bufferSize: bufferSize,
+		users:      make(map[uint64]*userState),
+	}
+}
+
+// Subscribe 订阅用户的事件
+// lastEventID 为客户端收到的最后一个事件ID，不为 0 时返回之后错过的事件；
+// complete 为 false 表示错过的事件已不在缓冲中（或来自重启前的进程），客户端需要重新拉取完整数据
+func (h *Hub) Subscribe(userID, lastEventID uint64) (sub *Subscription, missed []Event, complete bool, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, nil, false, ErrHubClosed
+	}
+	state := h.user(userID)
+	if h.maxConns > 0 && len(state.subs) >= h.maxConns {
+		return nil, nil, false, ErrTooManyConnections
+	}
+
+	complete = true
+	if lastEventID != 0 {
+		complete = lastEventID >= h.startID && lastEventID >= state.evicted && lastEventID <= h.seq
+		if complete {
+			for _, event := range state.recent {
+				if event.ID > lastEventID {
+					missed = append(missed, event)
+				}
+			}
+		}
+	}
+
+	sub = &Subscription{
+		hub:    h,
+		userID: userID,
+		events: make(chan Event, subscriptionBuffer),
+		done:   make(chan struct{}),
+	}
+	state.subs[sub] = struct{}{}
+	return sub, missed, complete, nil
+}
+
+// Connections 用户当前的连接数
+func (h *Hub) Connections(userID uint64) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if state, ok := h.users[userID]; ok {
+		return len(state.subs)
+	}
+	return 0
+}
+
+// Publish 向用户推送事件，用户不在线时只保留用于续传
+func (h *Hub) Publish(userID uint64, eventType string, payload any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.seq++
+	h.deliver(h.user(userID), Event{ID: h.seq, Type: eventType, Payload: payload})
+}
+
+// Broadcast 向连接过的用户中 visible 返回 true 的用户推送同一个事件
+// 用于动态的评论数、点赞数等公共数据，从未连接过的用户上线时会拉取完整数据，不需要保留
+func (h *Hub) Broadcast(eventType string, payload any, visible func(userID uint64) bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.seq++
+	event := Event{ID: h.seq, Type: eventType, Payload: payload}
+	for userID, state := range h.users {
+		if visible(userID) {
+			h.deliver(state, event)
+		}
+	}
+}
+
+// Close 关闭事件中心，结束所有订阅
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for _, state := range h.users {
+		for sub := range state.subs {
+			sub.stop()
+		}
+		state.subs = nil
+	}
+}
+
+// user 调用方需持有 mu
+func (h *Hub) user(userID uint64) *userState {
+	state, ok := h.users[userID]
+	if !ok {
+		state = &userState{subs: make(map[*Subscription]struct{})}
+		h.users[userID] = state
+	}
+	return state
+}
+
+// deliver 保存事件并发送给用户的全部连接，调用方需持有 mu
+// 连接的缓冲已满时结束该订阅，客户端重连后按 Last-Event-ID 续传，避免慢连接阻塞其他用户
+func (h *Hub) deliver(state *userState, event Event) {
+	state.recent = append(state.recent, event)
+	if over := len(state.recent) - h.bufferSize; over > 0 {
+		state.evicted = state.recent[over-1].ID
+		state.recent = append(state.recent[:0:0], state.recent[over:]...)
+	}
+
+	for sub := range state.subs {
+		select {
+		case sub.events <- event:
+		default:
+			delete(state.subs, sub)
+			sub.stop()
+		}
+	}
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if state, ok := h.users[sub.userID]; ok {
+		delete(state.subs, sub)
+	}
+	sub.stop()
+}
@@ -0,0 +1,170 @@
+package realtime
+
+import (
+	"errors"
+	"testing"
+)
+
+func eventIDs(events []Event) []uint64 {
+	ids := make([]uint64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSubscribeResume(t *testing.T) {
+	hub := NewHub(0, 3)
+	const userID = 1
+
+	// 记录 5 个事件，缓冲只保留最后 3 个
+	var published []uint64
+	for i := 0; i < 5; i++ {
+		hub.Publish(userID, "notification", i)
+		published = append(published, hub.seq)
+	}
+
+	tests := []struct {
+		name         string
+		lastEventID  uint64
+		wantComplete bool
+		wantMissed   []uint64
+	}{
+		{name: "首次连接", lastEventID: 0, wantComplete: true},
+		{name: "已收到全部事件", lastEventID: published[4], wantComplete: true},
+		{name: "错过缓冲中的事件", lastEventID: published[2], wantComplete: true, wantMissed: published[3:]},
+		{name: "刚好是淘汰的最后一个事件", lastEventID: published[1], wantComplete: true, wantMissed: published[2:]},
+		{name: "错过已淘汰的事件", lastEventID: published[0], wantComplete: false},
+		{name: "重启前的事件", lastEventID: hub.startID - 1, wantComplete: false},
+		{name: "未来的事件", lastEventID: published[4] + 1, wantComplete: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, missed, complete, err := hub.Subscribe(userID, tt.lastEventID)
+			if err != nil {
+				t.Fatalf("Subscribe: %v", err)
+			}
+			defer sub.Close()
+			if complete != tt.wantComplete {
+				t.Fatalf("complete = %v, want %v", complete, tt.wantComplete)
+			}
+			if got := eventIDs(missed); !equalIDs(got, tt.wantMissed) {
+				t.Fatalf("missed = %v, want %v", got, tt.wantMissed)
+			}
+		})
+	}
+}
+
+func TestSubscribeResumeIsPerUser(t *testing.T) {
+	hub := NewHub(0, 10)
+	hub.Publish(1, "notification", nil)
+	lastID := hub.seq
+	hub.Publish(2, "notification", nil)
+	hub.Publish(1, "notification", nil)
+
+	sub, missed, complete, err := hub.Subscribe(1, lastID)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Close()
+	if !complete || len(missed) != 1 || missed[0].ID != hub.seq {
+		t.Fatalf("complete=%v missed=%v，应只补发用户 1 的事件", complete, eventIDs(missed))
+	}
+}
+
+func TestPublishDeliversToSubscribers(t *testing.T) {
+	hub := NewHub(0, 10)
+	sub, _, _, err := hub.Subscribe(1, 0)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	hub.Publish(1, "notification", "payload")
+	hub.Broadcast("reactions", nil, func(userID uint64) bool { return userID == 1 })
+	hub.Broadcast("reactions", nil, func(userID uint64) bool { return false })
+
+	for _, want := range []string{"notification", "reactions"} {
+		select {
+		case event := <-sub.Events():
+			if event.Type != want {
+				t.Fatalf("event type = %s, want %s", event.Type, want)
+			}
+		default:
+			t.Fatalf("未收到 %s 事件", want)
+		}
+	}
+	select {
+	case event := <-sub.Events():
+		t.Fatalf("不应收到事件 %+v", event)
+	default:
+	}
+}
+
+func TestSubscribeConnectionLimit(t *testing.T) {
+	hub := NewHub(2, 10)
+	first, _, _, _ := hub.Subscribe(1, 0)
+	second, _, _, _ := hub.Subscribe(1, 0)
+	if _, _, _, err := hub.Subscribe(1, 0); !errors.Is(err, ErrTooManyConnections) {
+		t.Fatalf("err = %v, want ErrTooManyConnections", err)
+	}
+	if _, _, _, err := hub.Subscribe(2, 0); err != nil {
+		t.Fatalf("其他用户不受影响: %v", err)
+	}
+
+	first.Close()
+	if hub.Connections(1) != 1 {
+		t.Fatalf("Connections = %d, want 1", hub.Connections(1))
+	}
+	third, _, _, err := hub.Subscribe(1, 0)
+	if err != nil {
+		t.Fatalf("关闭连接后应能重新订阅: %v", err)
+	}
+	second.Close()
+	third.Close()
+}
+
+func TestSlowSubscriberIsEvicted(t *testing.T) {
+	hub := NewHub(0, 100)
+	sub, _, _, err := hub.Subscribe(1, 0)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	for i := 0; i <= subscriptionBuffer; i++ {
+		hub.Publish(1, "notification", i)
+	}
+	select {
+	case <-sub.Done():
+	default:
+		t.Fatal("缓冲写满后应结束订阅")
+	}
+	if hub.Connections(1) != 0 {
+		t.Fatalf("Connections = %d, want 0", hub.Connections(1))
+	}
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub(0, 10)
+	sub, _, _, _ := hub.Subscribe(1, 0)
+	hub.Close()
+	select {
+	case <-sub.Done():
+	default:
+		t.Fatal("关闭后应结束订阅")
+	}
+	if _, _, _, err := hub.Subscribe(1, 0); !errors.Is(err, ErrHubClosed) {
+		t.Fatalf("err = %v, want ErrHubClosed", err)
+	}
+}
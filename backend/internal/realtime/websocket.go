@@ -0,0 +1,202 @@
+package realtime
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketGUID RFC 6455 握手使用的固定 GUID
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxClientPayload 客户端帧的最大长度，服务端只接收控制帧，数据帧读取后丢弃
+const maxClientPayload = 4096
+
+const (
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+)
+
+var ErrNotWebSocket = errors.New("not a websocket handshake")
+
+// WebSocket 只向客户端推送文本消息的 WebSocket 连接
+// 客户端发送的数据帧会被忽略，ping 帧回复 pong，close 帧回复 close 后结束连接
+type WebSocket struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	mu     sync.Mutex
+	closed chan struct{}
+	once   sync.Once
+}
+
+// IsWebSocketRequest 请求是否为 WebSocket 握手
+func IsWebSocketRequest(r *http.Request) bool {
+	return headerContains(r.Header, "Connection", "upgrade") && headerContains(r.Header, "Upgrade", "websocket")
+}
+
+// UpgradeWebSocket 完成 WebSocket 握手并接管连接，之后不能再使用 w
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request) (*WebSocket, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet || !IsWebSocketRequest(r) || key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, ErrNotWebSocket
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("接管连接失败: %w", err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n"
+	if _, err := rw.WriteString(response); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	ws := &WebSocket{conn: conn, reader: rw.Reader, closed: make(chan struct{})}
+	go ws.readLoop()
+	return ws, nil
+}
+
+// Closed 客户端关闭连接或连接出错时关闭
+func (ws *WebSocket) Closed() <-chan struct{} {
+	return ws.closed
+}
+
+// WriteText 发送文本消息
+func (ws *WebSocket) WriteText(data []byte) error {
+	return ws.writeFrame(opText, data)
+}
+
+// Ping 发送心跳
+func (ws *WebSocket) Ping() error {
+	return ws.writeFrame(opPing, nil)
+}
+
+// Close 发送 close 帧并关闭连接
+func (ws *WebSocket) Close() error {
+	_ = ws.writeFrame(opClose, []byte{0x03, 0xE8}) // 1000 正常关闭
+	ws.markClosed()
+	return ws.conn.Close()
+}
+
+func (ws *WebSocket) markClosed() {
+	ws.once.Do(func() { close(ws.closed) })
+}
+
+// writeFrame 服务端发送的帧不加掩码
+func (ws *WebSocket) writeFrame(opcode byte, payload []byte) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126, byte(n>>8), byte(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	_ = ws.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if _, err := ws.conn.Write(append(header, payload...)); err != nil {
+		ws.markClosed()
+		return err
+	}
+	return nil
+}
+
+// readLoop 读取客户端帧，处理 ping 和 close
+func (ws *WebSocket) readLoop() {
+	defer ws.markClosed()
+	for {
+		opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case opPing:
+			_ = ws.writeFrame(opPong, payload)
+		case opClose:
+			_ = ws.writeFrame(opClose, payload)
+			_ = ws.conn.Close()
+			return
+		}
+	}
+}
+
+// readFrame 读取一个客户端帧，客户端帧必须带掩码
+func (ws *WebSocket) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(ws.reader, head[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := head[0] & 0x0F
+	if head[1]&0x80 == 0 {
+		return 0, nil, errors.New("客户端帧未加掩码")
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(ws.reader, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(ws.reader, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxClientPayload {
+		return 0, nil, errors.New("客户端帧过大")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(ws.reader, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(ws.reader, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
+
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
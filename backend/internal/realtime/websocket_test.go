@@ -0,0 +1,232 @@
+package realtime
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clientFrame 构建客户端帧，masked 为 false 时不加掩码
+func clientFrame(opcode byte, payload []byte, masked bool) []byte {
+	frame := []byte{0x80 | opcode}
+	maskBit := byte(0)
+	if masked {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, maskBit|126, byte(n>>8), byte(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	if !masked {
+		return append(frame, payload...)
+	}
+	mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
+
+func newFrameReader(data []byte) *WebSocket {
+	return &WebSocket{reader: bufio.NewReader(bytes.NewReader(data)), closed: make(chan struct{})}
+}
+
+func TestReadFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		frame   []byte
+		opcode  byte
+		payload []byte
+		wantErr bool
+	}{
+		{name: "短帧", frame: clientFrame(opText, []byte("hello"), true), opcode: opText, payload: []byte("hello")},
+		{name: "空 ping", frame: clientFrame(opPing, nil, true), opcode: opPing, payload: []byte{}},
+		{name: "16 位长度", frame: clientFrame(opText, bytes.Repeat([]byte("a"), 300), true), opcode: opText, payload: bytes.Repeat([]byte("a"), 300)},
+		{name: "最大长度", frame: clientFrame(opText, bytes.Repeat([]byte("b"), maxClientPayload), true), opcode: opText, payload: bytes.Repeat([]byte("b"), maxClientPayload)},
+		{name: "未加掩码", frame: clientFrame(opText, []byte("hello"), false), wantErr: true},
+		{name: "超过最大长度", frame: clientFrame(opText, bytes.Repeat([]byte("c"), maxClientPayload+1), true), wantErr: true},
+		{name: "64 位长度", frame: clientFrame(opText, bytes.Repeat([]byte("d"), 0x10000), true), wantErr: true},
+		{name: "帧不完整", frame: clientFrame(opText, []byte("hello"), true)[:7], wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opcode, payload, err := newFrameReader(tt.frame).readFrame()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("应返回错误，实际 opcode=%d payload=%q", opcode, payload)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readFrame: %v", err)
+			}
+			if opcode != tt.opcode || !bytes.Equal(payload, tt.payload) {
+				t.Fatalf("got opcode=%d payload=%q, want opcode=%d payload=%q", opcode, payload, tt.opcode, tt.payload)
+			}
+		})
+	}
+}
+
+func TestReadFrameHugeLengthDoesNotAllocate(t *testing.T) {
+	// 声明 2^63 字节的帧，必须在分配内存前拒绝
+	frame := []byte{0x80 | opText, 0x80 | 127, 0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	if _, _, err := newFrameReader(frame).readFrame(); err == nil {
+		t.Fatal("超大帧应返回错误")
+	}
+}
+
+// dialWebSocket 连接测试服务器并完成握手
+func dialWebSocket(t *testing.T, server *httptest.Server) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	request := "GET / HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n" +
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n"
+	if _, err := io.WriteString(conn, request); err != nil {
+		t.Fatalf("write handshake: %v", err)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("read handshake: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	// RFC 6455 1.3 的示例
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Sec-WebSocket-Accept = %q", got)
+	}
+	return conn, reader
+}
+
+// readServerFrame 读取服务端帧，服务端帧不加掩码
+func readServerFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	if head[1]&0x80 != 0 {
+		t.Fatal("服务端帧不应加掩码")
+	}
+	length := int(head[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			t.Fatalf("read length: %v", err)
+		}
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatalf("read payload: %v", err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+func newWebSocketServer(t *testing.T, serve func(ws *WebSocket)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := UpgradeWebSocket(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		serve(ws)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebSocketRoundTrip(t *testing.T) {
+	server := newWebSocketServer(t, func(ws *WebSocket) {
+		_ = ws.WriteText([]byte(`{"type":"unread_count"}`))
+		<-ws.Closed()
+	})
+	conn, reader := dialWebSocket(t, server)
+
+	opcode, payload := readServerFrame(t, reader)
+	if opcode != opText || string(payload) != `{"type":"unread_count"}` {
+		t.Fatalf("got opcode=%d payload=%q", opcode, payload)
+	}
+
+	// ping 回复相同数据的 pong
+	if _, err := conn.Write(clientFrame(opPing, []byte("hi"), true)); err != nil {
+		t.Fatalf("write ping: %v", err)
+	}
+	opcode, payload = readServerFrame(t, reader)
+	if opcode != opPong || string(payload) != "hi" {
+		t.Fatalf("got opcode=%d payload=%q, want pong", opcode, payload)
+	}
+
+	// close 回复 close 后断开
+	if _, err := conn.Write(clientFrame(opClose, []byte{0x03, 0xE8}, true)); err != nil {
+		t.Fatalf("write close: %v", err)
+	}
+	if opcode, _ = readServerFrame(t, reader); opcode != opClose {
+		t.Fatalf("got opcode=%d, want close", opcode)
+	}
+}
+
+func TestWebSocketClosesOnInvalidFrames(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []byte
+	}{
+		{name: "未加掩码", frame: clientFrame(opText, []byte("hello"), false)},
+		{name: "超过最大长度", frame: clientFrame(opText, bytes.Repeat([]byte("x"), maxClientPayload+1), true)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			closed := make(chan struct{})
+			server := newWebSocketServer(t, func(ws *WebSocket) {
+				select {
+				case <-ws.Closed():
+					close(closed)
+				case <-time.After(5 * time.Second):
+				}
+				_ = ws.Close()
+			})
+			conn, _ := dialWebSocket(t, server)
+			if _, err := conn.Write(tt.frame); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			select {
+			case <-closed:
+			case <-time.After(5 * time.Second):
+				t.Fatal("服务端应结束连接")
+			}
+		})
+	}
+}
+
+func TestUpgradeRejectsPlainRequest(t *testing.T) {
+	server := newWebSocketServer(t, func(ws *WebSocket) {})
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", resp.StatusCode)
+	}
+}
@@ -131,10 +131,11 @@ func (r *AlbumRepo) AssociateFiles(ctx context.Context, albumID uint64, fileIDs
 //   - albumID: 相册ID
 //   - fileIDs: 文件ID列表
 //
-// 返回：如果关联成功返回nil，否则返回错误
+// 返回：新添加的文件ID列表，已在相册中的文件不包含在内
 // 说明：此方法只添加新的文件关联，不删除已存在的关联
-func (r *AlbumRepo) AppendFiles(ctx context.Context, albumID uint64, fileIDs []uint64) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+func (r *AlbumRepo) AppendFiles(ctx context.Context, albumID uint64, fileIDs []uint64) ([]uint64, error) {
+	var added []uint64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 查询已存在的文件ID
 		var existingFileIDs []uint64
 		if err := tx.Model(&model.EntityFile{}).
@@ -159,6 +160,9 @@ func (r *AlbumRepo) AppendFiles(ctx context.Context, albumID uint64, fileIDs []u
 					EntityType: "album",
 					FileID:     fileID,
 				})
+				// 请求中重复的文件只添加一次
+				existingIDMap[fileID] = true
+				added = append(added, fileID)
 				newCount++
 			}
 		}
@@ -179,6 +183,10 @@ func (r *AlbumRepo) AppendFiles(ctx context.Context, albumID uint64, fileIDs []u
 		}
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
 }
 
 // RemovePhoto 从相册删除某个图片（事务）
@@ -1,6 +1,8 @@
 package repo
 
 import (
+	"context"
+
 	"gorm.io/gorm"
 
 	"github.com/bookandmusic/love-girl/internal/model"
@@ -17,3 +19,18 @@ func NewAnniversaryRepo(dbCli *gorm.DB) *AnniversaryRepo {
 		BaseRepo: NewBaseRepo[model.Anniversary](dbCli),
 	}
 }
+
+// DeleteAndUnlinkMoments 删除纪念日，同时清空动态对该纪念日的引用（事务）
+//
+// 返回：如果删除成功返回nil，否则返回错误
+func (r *AnniversaryRepo) DeleteAndUnlinkMoments(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 动态本身保留，只去掉关联
+		if err := tx.Unscoped().Model(&model.Moment{}).
+			Where("anniversary_id = ?", id).
+			UpdateColumn("anniversary_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Anniversary{}, id).Error
+	})
+}
@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// APITokenRepo 个人访问令牌仓库
+type APITokenRepo struct {
+	*BaseRepo[model.APIToken]
+}
+
+// NewAPITokenRepo 创建新的个人访问令牌仓库实例
+func NewAPITokenRepo(dbCli *gorm.DB) *APITokenRepo {
+	return &APITokenRepo{
+		BaseRepo: NewBaseRepo[model.APIToken](dbCli),
+	}
+}
+
+// FindByHash 根据令牌哈希查找令牌
+func (r *APITokenRepo) FindByHash(ctx context.Context, tokenHash string) (*model.APIToken, error) {
+	return r.BaseRepo.FindOne(ctx, WithConditions(
+		FilterCondition{Field: "token_hash", Operator: "eq", Value: tokenHash},
+	))
+}
+
+// ListByUserID 查询用户的全部令牌，按创建时间倒序
+func (r *APITokenRepo) ListByUserID(ctx context.Context, userID uint64) ([]model.APIToken, error) {
+	return r.BaseRepo.List(ctx,
+		WithConditions(FilterCondition{Field: "user_id", Operator: "eq", Value: userID}),
+		WithOrder("created_at", true),
+	)
+}
+
+// TouchLastUsed 更新最后使用时间，不修改 updated_at
+func (r *APITokenRepo) TouchLastUsed(ctx context.Context, id uint64, usedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.APIToken{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", usedAt).Error
+}
+
+// Revoke 撤销用户自己的令牌
+//
+// 返回：令牌不存在或已撤销时返回 false
+func (r *APITokenRepo) Revoke(ctx context.Context, id, userID uint64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
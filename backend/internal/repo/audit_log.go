@@ -0,0 +1,36 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// AuditLogRepo 审计日志仓库
+// 说明：审计日志只追加，不提供修改方法，删除仅用于清理超出保留期限的记录
+type AuditLogRepo struct {
+	*BaseRepo[model.AuditLog]
+}
+
+// NewAuditLogRepo 创建新的审计日志仓库实例
+func NewAuditLogRepo(dbCli *gorm.DB) *AuditLogRepo {
+	return &AuditLogRepo{
+		BaseRepo: NewBaseRepo[model.AuditLog](dbCli),
+	}
+}
+
+// ListLogs 分页查询审计日志
+func (r *AuditLogRepo) ListLogs(ctx context.Context, page, size int, opts ...QueryOption) ([]model.AuditLog, int64, error) {
+	return r.BaseRepo.FindWithPagination(ctx, page, size, opts...)
+}
+
+// DeleteBefore 删除指定时间之前的审计日志
+//
+// 返回：删除的记录数
+func (r *AuditLogRepo) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&model.AuditLog{})
+	return result.RowsAffected, result.Error
+}
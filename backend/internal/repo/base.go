@@ -19,8 +19,10 @@ type QueryOptions struct {
 	OrderBy      string
 	Desc         bool
 	Preloads     []string
-	PreloadConds map[string][]interface{} // 预加载条件，key为关联名，value为where条件参数
-	ForUpdate    bool                     // 是否加锁
+	PreloadConds map[string][]interface{}  // 预加载条件，key为关联名，value为where条件参数
+	ForUpdate    bool                      // 是否加锁
+	Scopes       []func(*gorm.DB) *gorm.DB // 无法用 FilterCondition 表达的条件（如 OR 组合）
+	LeadOrders   []string                  // 优先于 OrderBy 的排序表达式（如置顶），不参与计数
 }
 
 // QueryOption 函数类型，用于设置查询选项
@@ -34,6 +36,13 @@ func WithOrder(orderBy string, desc bool) QueryOption {
 	}
 }
 
+// WithLeadOrder 添加优先于 WithOrder 的排序表达式，表达式原样拼接到 ORDER BY 中
+func WithLeadOrder(exprs ...string) QueryOption {
+	return func(opts *QueryOptions) {
+		opts.LeadOrders = append(opts.LeadOrders, exprs...)
+	}
+}
+
 // WithPreload 设置单个预加载关联
 func WithPreload(preload string) QueryOption {
 	return func(opts *QueryOptions) {
@@ -65,6 +74,13 @@ func WithForUpdate() QueryOption {
 	}
 }
 
+// WithScopes 设置自定义查询条件
+func WithScopes(scopes ...func(*gorm.DB) *gorm.DB) QueryOption {
+	return func(opts *QueryOptions) {
+		opts.Scopes = append(opts.Scopes, scopes...)
+	}
+}
+
 // WithConditions 设置过滤条件
 func WithConditions(conditions ...FilterCondition) QueryOption {
 	return func(opts *QueryOptions) {
@@ -108,6 +124,14 @@ func (r *BaseRepo[T]) ApplyFilters(db *gorm.DB, conditions []FilterCondition) *g
 	return db
 }
 
+// applyScopes 立即应用自定义条件，避免 gorm 延迟执行的 Scopes 在 Count 后被清空
+func applyScopes(db *gorm.DB, scopes []func(*gorm.DB) *gorm.DB) *gorm.DB {
+	for _, scope := range scopes {
+		db = scope(db)
+	}
+	return db
+}
+
 // NewBaseRepo 创建一个新的基础仓库实例
 func NewBaseRepo[T any](db *gorm.DB) *BaseRepo[T] {
 	return &BaseRepo[T]{db: db}
@@ -169,7 +193,7 @@ func (r *BaseRepo[T]) List(ctx context.Context, opts ...QueryOption) ([]T, error
 
 	var list []T
 	db := r.db.WithContext(ctx)
-	db = r.ApplyFilters(db, options.Conditions)
+	db = applyScopes(r.ApplyFilters(db, options.Conditions), options.Scopes)
 
 	// Apply preloads
 	for _, preload := range options.Preloads {
@@ -181,6 +205,9 @@ func (r *BaseRepo[T]) List(ctx context.Context, opts ...QueryOption) ([]T, error
 	}
 
 	// Apply order
+	for _, expr := range options.LeadOrders {
+		db = db.Order(expr)
+	}
 	if options.OrderBy != "" {
 		if options.Desc {
 			db = db.Order(options.OrderBy + " DESC")
@@ -207,7 +234,7 @@ func (r *BaseRepo[T]) FindOne(ctx context.Context, opts ...QueryOption) (*T, err
 	}
 
 	// Apply filters
-	db = r.ApplyFilters(db, options.Conditions)
+	db = applyScopes(r.ApplyFilters(db, options.Conditions), options.Scopes)
 
 	// Apply preloads
 	for _, preload := range options.Preloads {
@@ -241,7 +268,7 @@ func (r *BaseRepo[T]) FindWithPagination(ctx context.Context, page, size int, op
 	}
 
 	db := r.db.WithContext(ctx)
-	db = r.ApplyFilters(db, options.Conditions)
+	db = applyScopes(r.ApplyFilters(db, options.Conditions), options.Scopes)
 
 	// Count total
 	if err := db.Model(&list).Count(&total).Error; err != nil {
@@ -258,6 +285,9 @@ func (r *BaseRepo[T]) FindWithPagination(ctx context.Context, page, size int, op
 	}
 
 	// Apply order
+	for _, expr := range options.LeadOrders {
+		db = db.Order(expr)
+	}
 	if options.OrderBy != "" {
 		if options.Desc {
 			db = db.Order(options.OrderBy + " DESC")
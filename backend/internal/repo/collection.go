@@ -0,0 +1,208 @@
+package repo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// CollectionEntityType 合集封面在 entity_files 表中的实体类型
+const CollectionEntityType = "collection"
+
+// CollectionRepo 动态合集仓库，提供合集相关的数据操作
+// 功能：
+//   - 创建、更新、删除合集（同步维护封面图片的文件关联，删除时同时删除动态关联）
+//   - 查询合集（支持单查、分页）
+//   - 管理合集内的动态（替换并排序、追加、移除）
+//   - 按访问者可见范围统计动态数量、查询默认封面
+type CollectionRepo struct {
+	*BaseRepo[model.Collection]
+	entityFileRepo *EntityFileRepo
+}
+
+// NewCollectionRepo 创建新的合集仓库实例
+func NewCollectionRepo(dbCli *gorm.DB) *CollectionRepo {
+	return &CollectionRepo{
+		BaseRepo:       NewBaseRepo[model.Collection](dbCli),
+		entityFileRepo: NewEntityFileRepo(dbCli),
+	}
+}
+
+// FindByID 根据ID查找合集并预加载封面图片
+func (r *CollectionRepo) FindByID(ctx context.Context, id uint64) (*model.Collection, error) {
+	return r.BaseRepo.FindByID(ctx, id, WithPreloads("CoverImage"))
+}
+
+// ListCollections 分页查询合集，需要关联封面图片
+func (r *CollectionRepo) ListCollections(ctx context.Context, page, size int, opts ...QueryOption) ([]model.Collection, int64, error) {
+	allOpts := append(opts, WithPreloads("CoverImage"))
+	return r.BaseRepo.FindWithPagination(ctx, page, size, allOpts...)
+}
+
+// CreateWithMoments 创建合集，同时添加封面图片的文件关联和合集内的动态（事务）
+func (r *CollectionRepo) CreateWithMoments(ctx context.Context, collection *model.Collection, momentIDs []uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(collection).Error; err != nil {
+			return err
+		}
+		// 封面图片记录到文件关联表，以便跟踪图片使用情况
+		if collection.CoverImageID != nil {
+			if err := tx.Create(&model.EntityFile{
+				EntityID:   collection.ID,
+				EntityType: CollectionEntityType,
+				FileID:     *collection.CoverImageID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return createCollectionMoments(tx, collection.ID, momentIDs, 0)
+	})
+}
+
+// UpdateWithCover 修改合集，同步更新封面图片的文件关联
+func (r *CollectionRepo) UpdateWithCover(ctx context.Context, collection *model.Collection) error {
+	if err := r.BaseRepo.Update(ctx, collection); err != nil {
+		return err
+	}
+
+	var fileIDs []uint64
+	if collection.CoverImageID != nil {
+		fileIDs = []uint64{*collection.CoverImageID}
+	}
+	return r.entityFileRepo.UpdateEntityFiles(ctx, collection.ID, CollectionEntityType, fileIDs)
+}
+
+// DeleteWithMoments 删除合集及其封面文件关联和动态关联，动态本身保留（事务）
+func (r *CollectionRepo) DeleteWithMoments(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("entity_id = ? AND entity_type = ?", id, CollectionEntityType).Delete(&model.EntityFile{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("collection_id = ?", id).Delete(&model.CollectionMoment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Collection{}, id).Error
+	})
+}
+
+// ReplaceMoments 用给定的动态列表替换合集内的全部动态，列表顺序即展示顺序（事务）
+func (r *CollectionRepo) ReplaceMoments(ctx context.Context, collectionID uint64, momentIDs []uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", collectionID).Delete(&model.CollectionMoment{}).Error; err != nil {
+			return err
+		}
+		return createCollectionMoments(tx, collectionID, momentIDs, 0)
+	})
+}
+
+// AppendMoments 将动态追加到合集末尾，已在合集中的动态保持原位置（事务）
+func (r *CollectionRepo) AppendMoments(ctx context.Context, collectionID uint64, momentIDs []uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []model.CollectionMoment
+		if err := tx.Where("collection_id = ?", collectionID).Find(&existing).Error; err != nil {
+			return err
+		}
+
+		existingIDs := make(map[uint64]bool, len(existing))
+		next := 0
+		for _, link := range existing {
+			existingIDs[link.MomentID] = true
+			next = max(next, link.Position+1)
+		}
+
+		var newIDs []uint64
+		for _, id := range momentIDs {
+			if !existingIDs[id] {
+				existingIDs[id] = true
+				newIDs = append(newIDs, id)
+			}
+		}
+		return createCollectionMoments(tx, collectionID, newIDs, next)
+	})
+}
+
+func createCollectionMoments(tx *gorm.DB, collectionID uint64, momentIDs []uint64, startPosition int) error {
+	if len(momentIDs) == 0 {
+		return nil
+	}
+	links := make([]model.CollectionMoment, len(momentIDs))
+	for i, id := range momentIDs {
+		links[i] = model.CollectionMoment{
+			CollectionID: collectionID,
+			MomentID:     id,
+			Position:     startPosition + i,
+		}
+	}
+	return tx.Create(&links).Error
+}
+
+// RemoveMoment 从合集中移除动态
+//
+// 返回：动态是否在合集中、错误
+func (r *CollectionRepo) RemoveMoment(ctx context.Context, collectionID, momentID uint64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("collection_id = ? AND moment_id = ?", collectionID, momentID).
+		Delete(&model.CollectionMoment{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountVisibleMoments 统计每个合集中指定用户可见的动态数量
+func (r *CollectionRepo) CountVisibleMoments(ctx context.Context, collectionIDs []uint64, viewerID uint64) (map[uint64]int64, error) {
+	counts := make(map[uint64]int64, len(collectionIDs))
+	if len(collectionIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		CollectionID uint64
+		Count        int64
+	}
+	if err := r.db.WithContext(ctx).Model(&model.CollectionMoment{}).
+		Select("collection_id, COUNT(*) AS count").
+		Where("collection_id IN ?", collectionIDs).
+		Where("moment_id IN (?)", visibleMomentIDs(r.db, viewerID)).
+		Group("collection_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.CollectionID] = row.Count
+	}
+	return counts, nil
+}
+
+// FindDefaultCover 查询合集中第一条指定用户可见、且带图片的动态的第一张图片，用作未设置封面时的默认封面
+//
+// 返回：没有可用图片时返回 nil
+func (r *CollectionRepo) FindDefaultCover(ctx context.Context, collectionID uint64, viewerID uint64) (*model.File, error) {
+	var files []model.File
+	if err := r.db.WithContext(ctx).Model(&model.File{}).
+		Select("files.*").
+		Joins("JOIN entity_files ON entity_files.file_id = files.id AND entity_files.entity_type = ? AND entity_files.deleted_at IS NULL", MomentEntityType).
+		Joins("JOIN collection_moments ON collection_moments.moment_id = entity_files.entity_id").
+		Where("collection_moments.collection_id = ?", collectionID).
+		Where("collection_moments.moment_id IN (?)", visibleMomentIDs(r.db, viewerID)).
+		Order("collection_moments.position ASC").
+		Order("entity_files.id ASC").
+		Limit(1).
+		Find(&files).Error; err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	return &files[0], nil
+}
+
+// MomentInCollection 筛选合集中的动态，并按合集内的顺序排序
+func MomentInCollection(collectionID uint64) []QueryOption {
+	return []QueryOption{
+		WithScopes(func(db *gorm.DB) *gorm.DB {
+			return db.Joins("JOIN collection_moments ON collection_moments.moment_id = moments.id").
+				Where("collection_moments.collection_id = ?", collectionID)
+		}),
+		WithLeadOrder("collection_moments.position ASC"),
+	}
+}
@@ -2,6 +2,8 @@ package repo
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -33,34 +35,141 @@ func (r *CommentRepo) Create(ctx context.Context, comment *model.Comment) error
 	return r.db.WithContext(ctx).Create(comment).Error
 }
 
-func (r *CommentRepo) Delete(ctx context.Context, id uint64) error {
-	comment, err := r.FindByID(ctx, id)
-	if err != nil {
-		return err
+// CommentAlive 排除已删除、仅作为占位保留的评论
+func CommentAlive() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("comments.tombstoned_at IS NULL")
 	}
-	return r.db.WithContext(ctx).
-		Where("id = ? OR path LIKE ?", id, comment.Path+"/%").
-		Delete(&model.Comment{}).Error
 }
 
-func (r *CommentRepo) FindByMomentID(ctx context.Context, momentID uint64, page, size int) ([]model.Comment, int64, error) {
-	var comments []model.Comment
-	var total int64
+// CommentApproved 只保留已通过审核的评论
+func CommentApproved() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("comments.status = ?", model.CommentStatusApproved)
+	}
+}
 
-	db := r.db.WithContext(ctx).Model(&model.Comment{}).Where("moment_id = ?", momentID)
-	if err := db.Count(&total).Error; err != nil {
-		return nil, 0, err
+// CommentOnVisibleMoment 只保留指定用户可见动态下的评论
+func CommentOnVisibleMoment(viewerID uint64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("comments.moment_id IN (?)", visibleMomentIDs(db.Session(&gorm.Session{NewDB: true}), viewerID))
 	}
+}
 
-	offset := (page - 1) * size
-	if err := db.Preload("User").Preload("User.Avatar").
-		Order("created_at ASC").
-		Offset(offset).Limit(size).
+// DeleteOrTombstone 删除评论（事务）
+// 说明：
+//   - 评论下还有回复时只清空内容并标记为占位，回复保持原有层级
+//   - 没有回复时直接删除，并依次删除因此不再有回复的占位上级评论
+//
+// 返回：是否保留为占位、错误
+func (r *CommentRepo) DeleteOrTombstone(ctx context.Context, comment *model.Comment) (bool, error) {
+	tombstoned := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		replies, err := countDescendants(tx, comment.Path)
+		if err != nil {
+			return err
+		}
+		if replies > 0 {
+			tombstoned = true
+			return tx.Model(&model.Comment{}).Where("id = ?", comment.ID).UpdateColumns(map[string]any{
+				"content":       "",
+				"tombstoned_at": time.Now(),
+			}).Error
+		}
+
+		if err := tx.Delete(&model.Comment{}, comment.ID).Error; err != nil {
+			return err
+		}
+		// 路径形如 "1/5/9"，从最近的上级开始向上清理
+		segments := strings.Split(comment.Path, "/")
+		for i := len(segments) - 1; i > 0; i-- {
+			ancestorPath := strings.Join(segments[:i], "/")
+			var ancestor model.Comment
+			if err := tx.Where("path = ?", ancestorPath).Limit(1).Find(&ancestor).Error; err != nil {
+				return err
+			}
+			if ancestor.ID == 0 || !ancestor.IsTombstone() {
+				return nil
+			}
+			remaining, err := countDescendants(tx, ancestorPath)
+			if err != nil {
+				return err
+			}
+			if remaining > 0 {
+				return nil
+			}
+			if err := tx.Delete(&model.Comment{}, ancestor.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return tombstoned, err
+}
+
+// CountReplies 统计评论下的全部回复数量（含各级回复）
+func (r *CommentRepo) CountReplies(ctx context.Context, comment *model.Comment) (int64, error) {
+	return countDescendants(r.db.WithContext(ctx), comment.Path)
+}
+
+func countDescendants(tx *gorm.DB, path string) (int64, error) {
+	var count int64
+	err := tx.Model(&model.Comment{}).Where("path LIKE ?", path+"/%").Count(&count).Error
+	return count, err
+}
+
+// UpdateContent 保存编辑后的评论内容、编辑时间和重新审核的结果
+func (r *CommentRepo) UpdateContent(ctx context.Context, comment *model.Comment) error {
+	return r.db.WithContext(ctx).Model(&model.Comment{}).Where("id = ?", comment.ID).Updates(map[string]any{
+		"content":            comment.Content,
+		"edited_at":          comment.EditedAt,
+		"status":             comment.Status,
+		"content_hash":       comment.ContentHash,
+		"moderation_verdict": comment.ModerationVerdict,
+		"moderation_score":   comment.ModerationScore,
+		"moderation_reason":  comment.ModerationReason,
+	}).Error
+}
+
+// FindRootsByMomentID 按ID升序查询动态下 afterID 之后已通过审核的一级评论（含占位评论）
+func (r *CommentRepo) FindRootsByMomentID(ctx context.Context, momentID uint64, afterID uint64, limit int) ([]model.Comment, error) {
+	var comments []model.Comment
+	if err := r.db.WithContext(ctx).
+		Scopes(CommentApproved()).
+		Where("moment_id = ? AND parent_id IS NULL AND id > ?", momentID, afterID).
+		Preload("User").Preload("User.Avatar").
+		Order("id ASC").
+		Limit(limit).
 		Find(&comments).Error; err != nil {
-		return nil, 0, err
+		return nil, err
 	}
+	return comments, nil
+}
 
-	return comments, total, nil
+// FindThreadReplies 按ID升序查询一级评论下 afterID 之后已通过审核的各级回复（含占位评论）
+// 回复总是晚于上级评论创建，按ID升序取前若干条时，每条回复的上级都已在结果中或在之前的页中
+func (r *CommentRepo) FindThreadReplies(ctx context.Context, rootPath string, afterID uint64, limit int) ([]model.Comment, error) {
+	var comments []model.Comment
+	if err := r.db.WithContext(ctx).
+		Scopes(CommentApproved()).
+		Where("path LIKE ? AND id > ?", rootPath+"/%", afterID).
+		Preload("User").Preload("User.Avatar").
+		Order("id ASC").
+		Limit(limit).
+		Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// CountThreadReplies 统计一级评论下已通过审核的各级回复数量，不含占位评论
+func (r *CommentRepo) CountThreadReplies(ctx context.Context, rootPath string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Comment{}).
+		Scopes(CommentAlive(), CommentApproved()).
+		Where("path LIKE ?", rootPath+"/%").
+		Count(&count).Error
+	return count, err
 }
 
 func (r *CommentRepo) FindByParentID(ctx context.Context, parentID uint64) ([]model.Comment, error) {
@@ -87,9 +196,10 @@ func (r *CommentRepo) FindByPathPrefix(ctx context.Context, pathPrefix string) (
 	return comments, nil
 }
 
+// CountByMomentID 统计动态已通过审核的评论数量，不含已删除的占位评论
 func (r *CommentRepo) CountByMomentID(ctx context.Context, momentID uint64) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&model.Comment{}).Where("moment_id = ?", momentID).Count(&count).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&model.Comment{}).Scopes(CommentAlive(), CommentApproved()).Where("moment_id = ?", momentID).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -116,3 +226,48 @@ func (r *CommentRepo) FindByIDs(ctx context.Context, ids []uint64) ([]model.Comm
 	}
 	return comments, nil
 }
+
+// ListForModeration 分页查询审核队列中的评论，只包含 viewerID 可见动态下的评论
+func (r *CommentRepo) ListForModeration(ctx context.Context, viewerID uint64, page, size int, opts ...QueryOption) ([]model.Comment, int64, error) {
+	allOpts := append(opts,
+		WithScopes(CommentAlive(), CommentOnVisibleMoment(viewerID)),
+	)
+	allOpts = append(allOpts, WithCommentPreloads()...)
+	return r.BaseRepo.FindWithPagination(ctx, page, size, allOpts...)
+}
+
+// SetStatus 人工修改评论审核状态并记录审核时间
+func (r *CommentRepo) SetStatus(ctx context.Context, id uint64, status model.CommentStatus, reviewedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.Comment{}).Where("id = ?", id).Updates(map[string]any{
+		"status":      status,
+		"reviewed_at": reviewedAt,
+	}).Error
+}
+
+// CountDuplicates 统计 since 之后内容指纹相同的评论数，sameAuthor 为同一用户或同一访客 IP 发表的数量，不计入 excludeID
+func (r *CommentRepo) CountDuplicates(ctx context.Context, hash string, since time.Time, userID uint64, ip string, excludeID uint64) (total, sameAuthor int64, err error) {
+	db := r.db.WithContext(ctx).Model(&model.Comment{}).
+		Where("content_hash = ? AND created_at >= ? AND id <> ?", hash, since, excludeID).
+		Session(&gorm.Session{})
+	if err = db.Count(&total).Error; err != nil || total == 0 {
+		return total, 0, err
+	}
+
+	if userID != 0 {
+		err = db.Where("user_id = ?", userID).Count(&sameAuthor).Error
+	} else {
+		err = db.Where("user_id IS NULL AND guest_ip = ?", ip).Count(&sameAuthor).Error
+	}
+	return total, sameAuthor, err
+}
+
+// FindTrainingSamples 查询分类器的训练样本：人工标记的垃圾评论和已通过的评论，各取最近 limit 条内容
+func (r *CommentRepo) FindTrainingSamples(ctx context.Context, limit int) (spam, ham []string, err error) {
+	db := r.db.WithContext(ctx).Model(&model.Comment{}).Scopes(CommentAlive()).Order("id DESC").Limit(limit).
+		Session(&gorm.Session{})
+	if err = db.Where("status = ? AND reviewed_at IS NOT NULL", model.CommentStatusSpam).Pluck("content", &spam).Error; err != nil {
+		return nil, nil, err
+	}
+	err = db.Where("status = ?", model.CommentStatusApproved).Pluck("content", &ham).Error
+	return spam, ham, err
+}
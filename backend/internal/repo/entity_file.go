@@ -33,6 +33,10 @@ func (r *EntityFileRepo) AssociateFile(ctx context.Context, entityID uint64, ent
 
 // BatchAssociateFiles 批量关联文件到实体
 func (r *EntityFileRepo) BatchAssociateFiles(ctx context.Context, entityID uint64, entityType string, fileIDs []uint64) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+
 	var associations []model.EntityFile
 	for _, fileID := range fileIDs {
 		associations = append(associations, model.EntityFile{
@@ -0,0 +1,138 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// DayRange 往年同一天的时间范围 [Start, End)
+type DayRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains 时间是否落在范围内
+func (r DayRange) Contains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// SameDayInPreviousYears 计算 month 月 day 日在 sinceYear 至 thisYear 前一年之间每一年的时间范围，按年份倒序
+// 说明：
+//   - 2 月 29 日在平年没有对应日期，跳过该年
+//   - thisYear 为平年时，2 月 28 日同时包含往年闰年的 2 月 29 日，避免闰日的回忆永远不出现
+func SameDayInPreviousYears(thisYear int, month time.Month, day int, sinceYear int, loc *time.Location) []DayRange {
+	includeLeapDay := month == time.February && day == 28 && !isLeapYear(thisYear)
+
+	var ranges []DayRange
+	for year := thisYear - 1; year >= sinceYear; year-- {
+		if month == time.February && day == 29 && !isLeapYear(year) {
+			continue
+		}
+		start := time.Date(year, month, day, 0, 0, 0, 0, loc)
+		end := start.AddDate(0, 0, 1)
+		if includeLeapDay && isLeapYear(year) {
+			end = end.AddDate(0, 0, 1)
+		}
+		ranges = append(ranges, DayRange{Start: start, End: end})
+	}
+	return ranges
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// InDayRanges 时间列落在任一范围内的查询条件，column 可以是列名或表达式
+func InDayRanges(column string, ranges []DayRange) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(ranges) == 0 {
+			return db.Where("1 = 0")
+		}
+		clauses := make([]string, len(ranges))
+		args := make([]any, 0, len(ranges)*2)
+		for i, r := range ranges {
+			clauses[i] = "(" + column + " >= ? AND " + column + " < ?)"
+			args = append(args, r.Start, r.End)
+		}
+		return db.Where(strings.Join(clauses, " OR "), args...)
+	}
+}
+
+// dayRangeDates 范围内所有日期字符串，用于匹配以 YYYY-MM-DD 字符串保存日期的表
+func dayRangeDates(ranges []DayRange) []string {
+	var dates []string
+	for _, r := range ranges {
+		for d := r.Start; d.Before(r.End); d = d.AddDate(0, 0, 1) {
+			dates = append(dates, d.Format("2006-01-02"))
+		}
+	}
+	return dates
+}
+
+// MemoryPhoto 往年同一天拍摄或上传的相册照片
+type MemoryPhoto struct {
+	model.File `gorm:"embedded"`
+	AlbumID    uint64
+}
+
+// ListMemoryMoments 查询往年同一天发布、指定用户可见的动态，按动态时间倒序
+func (r *MomentRepo) ListMemoryMoments(ctx context.Context, viewerID uint64, ranges []DayRange, limit int) ([]model.Moment, error) {
+	opts := append([]QueryOption{
+		WithScopes(
+			MomentVisibleTo(viewerID),
+			func(db *gorm.DB) *gorm.DB { return db.Where("status = ?", model.MomentStatusPublished) },
+			InDayRanges("created_at", ranges),
+			func(db *gorm.DB) *gorm.DB { return db.Limit(limit) },
+		),
+		WithOrder("created_at", true),
+	}, WithMomentPreloads()...)
+	return r.BaseRepo.List(ctx, opts...)
+}
+
+// ListMemoryPhotos 查询往年同一天的相册照片，优先按 EXIF 拍摄时间，没有时按上传时间，按时间倒序
+// 说明：同一张照片在多个相册中时只返回一次
+func (r *AlbumRepo) ListMemoryPhotos(ctx context.Context, ranges []DayRange, limit int) ([]MemoryPhoto, error) {
+	const photoTime = "COALESCE(files.taken_at, files.created_at)"
+	var photos []MemoryPhoto
+	err := r.db.WithContext(ctx).Model(&model.File{}).
+		Select("files.*, MIN(entity_files.entity_id) AS album_id").
+		Joins("JOIN entity_files ON entity_files.file_id = files.id AND entity_files.entity_type = ? AND entity_files.deleted_at IS NULL", "album").
+		Scopes(InDayRanges(photoTime, ranges)).
+		Group("files.id").
+		Order(photoTime + " DESC").
+		Limit(limit).
+		Find(&photos).Error
+	return photos, err
+}
+
+// ListByDates 查询日期在给定往年同一天范围内的地点，按日期倒序
+func (r *PlaceRepo) ListByDates(ctx context.Context, ranges []DayRange) ([]model.Place, error) {
+	return r.BaseRepo.List(ctx,
+		WithScopes(func(db *gorm.DB) *gorm.DB { return db.Where("date IN ?", dayRangeDates(ranges)) }),
+		WithOrder("date", true),
+		WithPreloads("Image"),
+	)
+}
+
+// ListByDates 查询日期在给定往年同一天范围内的公历纪念日，按日期倒序
+// 说明：农历纪念日的日期是农历，不能按公历月日匹配，见 ListLunar
+func (r *AnniversaryRepo) ListByDates(ctx context.Context, ranges []DayRange) ([]model.Anniversary, error) {
+	return r.BaseRepo.List(ctx,
+		WithScopes(func(db *gorm.DB) *gorm.DB {
+			return db.Where("date IN ? AND (calendar = ? OR calendar IS NULL OR calendar = '')", dayRangeDates(ranges), "solar")
+		}),
+		WithOrder("date", true),
+	)
+}
+
+// ListLunar 查询全部农历纪念日，换算为公历后再按日期匹配
+func (r *AnniversaryRepo) ListLunar(ctx context.Context) ([]model.Anniversary, error) {
+	return r.BaseRepo.List(ctx, WithConditions(
+		FilterCondition{Field: "calendar", Operator: "eq", Value: model.AnniversaryCalendarLunar},
+	))
+}
@@ -14,9 +14,9 @@ import (
 //   - 创建动态（使用 CreateWithFiles）
 //   - 删除动态（同时删除文件关联）
 //   - 查询动态（支持单查）
-//   - 更新动态（支持同时修改关联图片）
-//   - 更新点赞数
-//   - 更新公开状态
+//   - 更新动态（支持同时修改关联图片，重新同步话题标签）
+//   - 更新可见范围
+//   - 定时发布
 type MomentRepo struct {
 	*BaseRepo[model.Moment]
 	entityFileRepo *EntityFileRepo
@@ -31,12 +31,31 @@ func NewMomentRepo(dbCli *gorm.DB) *MomentRepo {
 	}
 }
 
+// MomentVisibleTo 动态可见范围条件，viewerID 为 0 表示未登录访客
+//   - 访客：已发布的公开动态
+//   - 已登录用户：已发布的公开动态、情侣可见动态以及自己的全部动态（含草稿和定时动态）
+func MomentVisibleTo(viewerID uint64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == 0 {
+			return db.Where("status = ? AND visibility = ?", model.MomentStatusPublished, model.MomentVisibilityPublic)
+		}
+		return db.Where("((status = ? AND visibility IN ?) OR user_id = ?)", model.MomentStatusPublished,
+			[]model.MomentVisibility{model.MomentVisibilityPublic, model.MomentVisibilityCouple}, viewerID)
+	}
+}
+
+// visibleMomentIDs 指定用户可见的动态ID子查询，供其他表按 moment_id 过滤
+func visibleMomentIDs(db *gorm.DB, viewerID uint64) *gorm.DB {
+	return MomentVisibleTo(viewerID)(db.Model(&model.Moment{}).Select("id"))
+}
+
 func WithMomentPreloads() []QueryOption {
 	return []QueryOption{
 		WithPreloads("User"),
 		WithPreloads("EntityFiles"),
 		WithPreloadCond("EntityFiles", "entity_type = ?", MomentEntityType),
 		WithPreloads("EntityFiles.File"),
+		WithPreloads("Place", "Anniversary"),
 	}
 }
 
@@ -47,7 +66,7 @@ func (r *MomentRepo) FindByID(ctx context.Context, id uint64) (*model.Moment, er
 	return r.BaseRepo.FindByID(ctx, id, WithMomentPreloads()...)
 }
 
-// DeleteWithFiles 删除动态，需要删除动态和图片文件关联关系（事务）
+// DeleteWithFiles 删除动态，需要删除动态、图片文件关联关系、表情回应、话题标签关联、修订版本和合集关联（事务）
 //
 // 返回：如果删除成功返回nil，否则返回错误
 func (r *MomentRepo) DeleteWithFiles(ctx context.Context, id uint64) error {
@@ -56,20 +75,35 @@ func (r *MomentRepo) DeleteWithFiles(ctx context.Context, id uint64) error {
 		if err := tx.Where("entity_id = ? AND entity_type = ?", id, "moment").Delete(&model.EntityFile{}).Error; err != nil {
 			return err
 		}
+		// 删除表情回应和话题标签关联
+		if err := tx.Unscoped().Where("moment_id = ?", id).Delete(&model.Reaction{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("moment_id = ?", id).Delete(&model.MomentTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("moment_id = ?", id).Delete(&model.MomentRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("moment_id = ?", id).Delete(&model.CollectionMoment{}).Error; err != nil {
+			return err
+		}
 		// 再删除动态记录
 		return tx.Delete(&model.Moment{}, id).Error
 	})
 }
 
-// UpdateWithFiles 修改动态，支持同时修改关联图片（事务）
+// UpdateWithFiles 修改动态，支持同时修改关联图片、重新同步话题标签并记录修订版本（事务）
 //
 // 参数：
 //   - moment: 动态实体
 //   - fileIDs: 关联的文件ID列表
+//   - tags: 从内容中解析出的话题标签
 //   - newCreatedAt: 可选，新的创建时间，nil 表示不更新创建时间
+//   - revision: 修订版本的来源信息
 //
 // 返回：如果更新成功返回nil，否则返回错误
-func (r *MomentRepo) UpdateWithFiles(ctx context.Context, moment *model.Moment, fileIDs []uint64, newCreatedAt *time.Time) error {
+func (r *MomentRepo) UpdateWithFiles(ctx context.Context, moment *model.Moment, fileIDs []uint64, tags []string, newCreatedAt *time.Time, revision RevisionInfo) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 如果指定了新的创建时间，更新它
 		if newCreatedAt != nil {
@@ -97,14 +131,17 @@ func (r *MomentRepo) UpdateWithFiles(ctx context.Context, moment *model.Moment,
 				return err
 			}
 		}
-		return nil
+		if err := SyncMomentTags(tx, moment.ID, tags); err != nil {
+			return err
+		}
+		return AppendMomentRevision(tx, moment.ID, revision)
 	})
 }
 
-// CreateWithFiles 创建动态并关联文件（事务）
+// CreateWithFiles 创建动态并关联文件和话题标签，同时记录第一个修订版本（事务）
 //
 // 返回：如果创建成功返回nil，否则返回错误
-func (r *MomentRepo) CreateWithFiles(ctx context.Context, moment *model.Moment, fileIDs []uint64) error {
+func (r *MomentRepo) CreateWithFiles(ctx context.Context, moment *model.Moment, fileIDs []uint64, tags []string) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 创建动态记录
 		if err := tx.Create(moment).Error; err != nil {
@@ -124,27 +161,28 @@ func (r *MomentRepo) CreateWithFiles(ctx context.Context, moment *model.Moment,
 				return err
 			}
 		}
-		return nil
+		if err := SyncMomentTags(tx, moment.ID, tags); err != nil {
+			return err
+		}
+		return AppendMomentRevision(tx, moment.ID, RevisionInfo{Action: model.MomentRevisionCreate})
 	})
 }
 
-// UpdateLike 更新点赞数
-//
-// 返回：如果更新成功返回nil，否则返回错误
-func (r *MomentRepo) UpdateLike(ctx context.Context, id uint64) error {
-	return r.db.WithContext(ctx).Model(&model.Moment{}).Where("id = ?", id).UpdateColumn("likes", gorm.Expr("likes + ?", 1)).Error
-}
-
-// UpdatePublicStatus 更新公开状态
+// UpdateVisibility 更新可见范围并记录修订版本（事务）
 //
 // 返回：如果更新成功返回nil，否则返回错误
-func (r *MomentRepo) UpdatePublicStatus(ctx context.Context, id uint64, status bool) error {
-	moment, err := r.FindByID(ctx, id)
-	if err != nil {
-		return err
-	}
-	moment.IsPublic = status
-	return r.BaseRepo.Update(ctx, moment)
+func (r *MomentRepo) UpdateVisibility(ctx context.Context, id uint64, visibility model.MomentVisibility, editorID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var moment model.Moment
+		if err := tx.First(&moment, id).Error; err != nil {
+			return err
+		}
+		moment.Visibility = visibility
+		if err := tx.Save(&moment).Error; err != nil {
+			return err
+		}
+		return AppendMomentRevision(tx, id, RevisionInfo{EditorID: editorID, Action: model.MomentRevisionVisibility})
+	})
 }
 
 // ListMoments 分页查询动态列表
@@ -160,3 +198,55 @@ func (r *MomentRepo) ListMomentsWithOpts(ctx context.Context, page, size int, op
 	allOpts := append(opts, preloadOps...)
 	return r.BaseRepo.FindWithPagination(ctx, page, size, allOpts...)
 }
+
+// PinnedFirst 置顶动态排在最前，多条置顶按置顶时间倒序
+func PinnedFirst() QueryOption {
+	return WithLeadOrder("pinned_at IS NULL", "pinned_at DESC")
+}
+
+// SetPinned 设置或取消动态置顶，pinnedAt 为 nil 表示取消置顶
+// 说明：置顶不修改动态内容，不更新 updated_at，也不记录修订版本
+func (r *MomentRepo) SetPinned(ctx context.Context, id uint64, pinnedAt *time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.Moment{}).
+		Where("id = ?", id).
+		UpdateColumn("pinned_at", pinnedAt).Error
+}
+
+// ListDueScheduled 查询已到发布时间的定时动态
+func (r *MomentRepo) ListDueScheduled(ctx context.Context, now time.Time, limit int) ([]model.Moment, error) {
+	var moments []model.Moment
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND publish_at <= ?", model.MomentStatusScheduled, now).
+		Order("publish_at ASC").
+		Limit(limit).
+		Find(&moments).Error
+	return moments, err
+}
+
+// PublishScheduled 发布定时动态，动态时间取计划发布时间，并记录修订版本（事务）
+//
+// 返回：是否由本次调用完成发布（并发执行或状态已变更时返回 false）
+func (r *MomentRepo) PublishScheduled(ctx context.Context, moment *model.Moment) (bool, error) {
+	publishedAt := time.Now()
+	if moment.PublishAt != nil {
+		publishedAt = *moment.PublishAt
+	}
+	published := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Moment{}).
+			Where("id = ? AND status = ?", moment.ID, model.MomentStatusScheduled).
+			Updates(map[string]any{
+				"status":     model.MomentStatusPublished,
+				"created_at": publishedAt,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		published = true
+		return AppendMomentRevision(tx, moment.ID, RevisionInfo{Action: model.MomentRevisionPublish})
+	})
+	if err != nil {
+		return false, err
+	}
+	return published, nil
+}
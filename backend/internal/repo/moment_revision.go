@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// MomentRevisionRepo 动态修订版本仓库
+// 功能：
+//   - 记录动态每次变更后的快照
+//   - 分页查询动态的修订历史
+//   - 按版本号查询修订版本
+type MomentRevisionRepo struct {
+	*BaseRepo[model.MomentRevision]
+}
+
+// RevisionInfo 修订版本的来源信息
+type RevisionInfo struct {
+	EditorID     uint64 // 操作人，为 0 时取动态作者
+	Action       model.MomentRevisionAction
+	RestoredFrom int // 恢复自的版本号，仅 restore 操作需要
+}
+
+// NewMomentRevisionRepo 创建新的动态修订版本仓库实例
+func NewMomentRevisionRepo(dbCli *gorm.DB) *MomentRevisionRepo {
+	return &MomentRevisionRepo{
+		BaseRepo: NewBaseRepo[model.MomentRevision](dbCli),
+	}
+}
+
+// AppendMomentRevision 以动态当前状态追加一个修订版本，需在动态和图片关联写入后、同一事务中调用
+// 快照与最新版本一致时不追加（恢复操作除外），避免无实际变更的保存产生空版本
+func AppendMomentRevision(tx *gorm.DB, momentID uint64, info RevisionInfo) error {
+	var moment model.Moment
+	if err := tx.Unscoped().First(&moment, momentID).Error; err != nil {
+		return err
+	}
+	var imageIDs []uint64
+	if err := tx.Model(&model.EntityFile{}).
+		Where("entity_id = ? AND entity_type = ?", momentID, MomentEntityType).
+		Order("id ASC").
+		Pluck("file_id", &imageIDs).Error; err != nil {
+		return err
+	}
+
+	editorID := info.EditorID
+	if editorID == 0 {
+		editorID = moment.UserID
+	}
+	revision := &model.MomentRevision{
+		CreatedAt:    moment.UpdatedAt,
+		MomentID:     momentID,
+		Version:      1,
+		Action:       info.Action,
+		RestoredFrom: info.RestoredFrom,
+		EditorID:     editorID,
+		Content:      moment.Content,
+		ImageIDs:     imageIDs,
+		Visibility:   moment.Visibility,
+		MomentTime:   moment.CreatedAt,
+	}
+	if revision.ImageIDs == nil {
+		revision.ImageIDs = []uint64{}
+	}
+
+	var latest model.MomentRevision
+	err := tx.Where("moment_id = ?", momentID).Order("version DESC").Take(&latest).Error
+	switch {
+	case err == nil:
+		if info.Action != model.MomentRevisionRestore && latest.SameSnapshot(revision) {
+			return nil
+		}
+		revision.Version = latest.Version + 1
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+	return tx.Create(revision).Error
+}
+
+// ListByMomentID 分页查询动态的修订版本，按版本号倒序
+func (r *MomentRevisionRepo) ListByMomentID(ctx context.Context, momentID uint64, page, size int) ([]model.MomentRevision, int64, error) {
+	return r.FindWithPagination(ctx, page, size,
+		WithScopes(func(db *gorm.DB) *gorm.DB {
+			return db.Where("moment_id = ?", momentID)
+		}),
+		WithOrder("version", true),
+		WithPreloads("Editor", "Editor.Avatar"),
+	)
+}
+
+// FindByVersion 查询动态的指定版本，version 为 0 表示最新版本
+//
+// 返回：版本不存在时返回 gorm.ErrRecordNotFound
+func (r *MomentRevisionRepo) FindByVersion(ctx context.Context, momentID uint64, version int) (*model.MomentRevision, error) {
+	db := r.db.WithContext(ctx).Preload("Editor").Preload("Editor.Avatar").Where("moment_id = ?", momentID)
+	if version > 0 {
+		db = db.Where("version = ?", version)
+	} else {
+		db = db.Order("version DESC")
+	}
+	var revision model.MomentRevision
+	if err := db.Take(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -33,11 +34,36 @@ func (r *NotificationRepo) Create(ctx context.Context, notification *model.Notif
 	return r.db.WithContext(ctx).Create(notification).Error
 }
 
+// CreateWithDeliveries 创建通知，并在同一事务中为每个渠道写入待投递记录
+func (r *NotificationRepo) CreateWithDeliveries(ctx context.Context, notification *model.Notification, channelIDs []uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(notification).Error; err != nil {
+			return err
+		}
+		if len(channelIDs) == 0 {
+			return nil
+		}
+
+		deliveries := make([]model.NotificationDelivery, len(channelIDs))
+		for i, channelID := range channelIDs {
+			deliveries[i] = model.NotificationDelivery{
+				NotificationID: notification.ID,
+				ChannelID:      channelID,
+				Status:         model.DeliveryStatusPending,
+				NextAttemptAt:  notification.CreatedAt,
+			}
+		}
+		return tx.Create(&deliveries).Error
+	})
+}
+
 func (r *NotificationRepo) FindUnreadByUserID(ctx context.Context, userID uint64, page, size int) ([]model.Notification, int64, error) {
 	var notifications []model.Notification
 	var total int64
 
-	db := r.db.WithContext(ctx).Model(&model.Notification{}).Where("user_id = ? AND is_read = ?", userID, false)
+	db := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Where(visibleNotifications(r.db, userID))
 	if err := db.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -57,16 +83,87 @@ func (r *NotificationRepo) CountUnreadByUserID(ctx context.Context, userID uint6
 	var count int64
 	if err := r.db.WithContext(ctx).Model(&model.Notification{}).
 		Where("user_id = ? AND is_read = ?", userID, false).
+		Where(visibleNotifications(r.db, userID)).
 		Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (r *NotificationRepo) MarkAsRead(ctx context.Context, id uint64) error {
+// ExistsSince 用户自指定时间以来是否已收到过指定类型的通知，用于避免重复发送每日通知
+func (r *NotificationRepo) ExistsSince(ctx context.Context, userID uint64, notificationType model.NotificationType, since time.Time) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND type = ? AND created_at >= ?", userID, notificationType, since).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ExistsForEntitySince 用户自指定时间以来是否已收到过关联指定对象的通知，用于避免重复提醒
+func (r *NotificationRepo) ExistsForEntitySince(ctx context.Context, userID uint64, notificationType model.NotificationType, entityType model.NotificationEntityType, entityID uint64, since time.Time) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND type = ? AND entity_type = ? AND entity_id = ? AND created_at >= ?", userID, notificationType, entityType, entityID, since).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindForDigest 查询 until 及之前尚未汇总到摘要的未读通知，最新的在前，同时返回总数
+// 说明：until 与 MarkDigested 使用同一时间，查询之后新产生的通知留到下一次摘要
+func (r *NotificationRepo) FindForDigest(ctx context.Context, userID uint64, until time.Time, limit int) ([]model.Notification, int64, error) {
+	var notifications []model.Notification
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = ? AND digested_at IS NULL AND created_at <= ?", userID, false, until).
+		Where(visibleNotifications(r.db, userID))
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	if err := db.Preload("Sender").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+// MarkDigested 记录通知已汇总到摘要，超出摘要展示数量的通知一并记录，下次不再汇总
+func (r *NotificationRepo) MarkDigested(ctx context.Context, userID uint64, until, digestedAt time.Time) error {
 	return r.db.WithContext(ctx).Model(&model.Notification{}).
-		Where("id = ?", id).
-		Update("is_read", true).Error
+		Where("user_id = ? AND is_read = ? AND digested_at IS NULL AND created_at <= ?", userID, false, until).
+		UpdateColumn("digested_at", digestedAt).Error
+}
+
+// FindByUserID 分页查询用户的通知，包含已读通知，最新的在前
+func (r *NotificationRepo) FindByUserID(ctx context.Context, userID uint64, page, size int, opts ...QueryOption) ([]model.Notification, int64, error) {
+	opts = append(opts, WithNotificationPreloads()...)
+	opts = append(opts,
+		WithScopes(func(db *gorm.DB) *gorm.DB {
+			return db.Where("user_id = ?", userID).Where(visibleNotifications(r.db, userID))
+		}),
+		WithOrder("created_at", true),
+	)
+	return r.BaseRepo.FindWithPagination(ctx, page, size, opts...)
+}
+
+// MarkAsRead 将用户的指定通知标记为已读，其他用户的通知不受影响
+//
+// 返回：由未读变为已读的通知数
+func (r *NotificationRepo) MarkAsRead(ctx context.Context, userID uint64, ids []uint64) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND id IN ? AND is_read = ?", userID, ids, false).
+		Update("is_read", true)
+	return result.RowsAffected, result.Error
 }
 
 func (r *NotificationRepo) MarkAllAsRead(ctx context.Context, userID uint64) error {
@@ -74,3 +171,43 @@ func (r *NotificationRepo) MarkAllAsRead(ctx context.Context, userID uint64) err
 		Where("user_id = ?", userID).
 		Update("is_read", true).Error
 }
+
+// DeleteByIDs 删除用户的指定通知及其待投递记录，其他用户的通知不受影响
+//
+// 返回：删除的通知数
+func (r *NotificationRepo) DeleteByIDs(ctx context.Context, userID uint64, ids []uint64) (int64, error) {
+	return r.deleteWhere(ctx, "user_id = ? AND id IN ?", userID, ids)
+}
+
+// DeleteReadBefore 删除指定时间之前的已读通知及其待投递记录
+//
+// 返回：删除的通知数
+func (r *NotificationRepo) DeleteReadBefore(ctx context.Context, before time.Time) (int64, error) {
+	return r.deleteWhere(ctx, "is_read = ? AND created_at < ?", true, before)
+}
+
+// deleteWhere 物理删除通知，通知只是提醒，不保留软删除记录
+func (r *NotificationRepo) deleteWhere(ctx context.Context, query string, args ...any) (int64, error) {
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := tx.Unscoped().Model(&model.Notification{}).Select("id").Where(query, args...)
+		if err := tx.Unscoped().Where("notification_id IN (?)", ids).Delete(&model.NotificationDelivery{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where(query, args...).Delete(&model.Notification{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// visibleNotifications 动态已改为对接收者不可见时，不再展示关联该动态及其评论的通知
+// 关联其他对象或不关联对象的通知不受影响
+func visibleNotifications(db *gorm.DB, userID uint64) *gorm.DB {
+	moments := visibleMomentIDs(db, userID)
+	comments := db.Unscoped().Model(&model.Comment{}).Select("id").Where("moment_id IN (?)", moments)
+	return db.Session(&gorm.Session{NewDB: true}).
+		Where("entity_type NOT IN ?", []model.NotificationEntityType{model.NotificationEntityMoment, model.NotificationEntityComment}).
+		Or("entity_type = ? AND entity_id IN (?)", model.NotificationEntityMoment, moments).
+		Or("entity_type = ? AND entity_id IN (?)", model.NotificationEntityComment, comments)
+}
@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+type NotificationChannelRepo struct {
+	*BaseRepo[model.NotificationChannel]
+}
+
+func NewNotificationChannelRepo(dbCli *gorm.DB) *NotificationChannelRepo {
+	return &NotificationChannelRepo{
+		BaseRepo: NewBaseRepo[model.NotificationChannel](dbCli),
+	}
+}
+
+// ListByUserID 查询用户的全部渠道，按创建时间排序
+func (r *NotificationChannelRepo) ListByUserID(ctx context.Context, userID uint64) ([]model.NotificationChannel, error) {
+	return r.BaseRepo.List(ctx,
+		WithConditions(FilterCondition{Field: "user_id", Operator: "eq", Value: userID}),
+		WithOrder("id", false),
+	)
+}
+
+// ListEnabledByUserID 查询用户已启用的渠道
+func (r *NotificationChannelRepo) ListEnabledByUserID(ctx context.Context, userID uint64) ([]model.NotificationChannel, error) {
+	return r.BaseRepo.List(ctx,
+		WithConditions(
+			FilterCondition{Field: "user_id", Operator: "eq", Value: userID},
+			FilterCondition{Field: "enabled", Operator: "eq", Value: true},
+		),
+		WithOrder("id", false),
+	)
+}
+
+// FindByUser 查询用户自己的渠道
+func (r *NotificationChannelRepo) FindByUser(ctx context.Context, id, userID uint64) (*model.NotificationChannel, error) {
+	return r.BaseRepo.FindOne(ctx, WithConditions(
+		FilterCondition{Field: "id", Operator: "eq", Value: id},
+		FilterCondition{Field: "user_id", Operator: "eq", Value: userID},
+	))
+}
+
+// FindByTarget 查询用户投递目标相同的渠道，用于浏览器重复订阅时更新已有渠道
+func (r *NotificationChannelRepo) FindByTarget(ctx context.Context, userID uint64, kind, target string) (*model.NotificationChannel, error) {
+	return r.BaseRepo.FindOne(ctx, WithConditions(
+		FilterCondition{Field: "user_id", Operator: "eq", Value: userID},
+		FilterCondition{Field: "kind", Operator: "eq", Value: kind},
+		FilterCondition{Field: "target", Operator: "eq", Value: target},
+	))
+}
+
+// DeleteByUser 删除用户自己的渠道及其未完成的投递
+//
+// 返回：渠道不存在时返回 false
+func (r *NotificationChannelRepo) DeleteByUser(ctx context.Context, id, userID uint64) (bool, error) {
+	deleted := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&model.NotificationChannel{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		deleted = true
+		return tx.Where("channel_id = ? AND status = ?", id, model.DeliveryStatusPending).
+			Delete(&model.NotificationDelivery{}).Error
+	})
+	return deleted, err
+}
+
+// RecordSuccess 记录投递成功，不修改 updated_at
+func (r *NotificationChannelRepo) RecordSuccess(ctx context.Context, id uint64, deliveredAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.NotificationChannel{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]any{"last_error": "", "last_delivered_at": deliveredAt}).Error
+}
+
+// RecordFailure 记录投递失败，disable 为 true 时停用渠道
+func (r *NotificationChannelRepo) RecordFailure(ctx context.Context, id uint64, reason string, disable bool) error {
+	columns := map[string]any{"last_error": reason}
+	if disable {
+		columns["enabled"] = false
+	}
+	return r.db.WithContext(ctx).Model(&model.NotificationChannel{}).
+		Where("id = ?", id).
+		UpdateColumns(columns).Error
+}
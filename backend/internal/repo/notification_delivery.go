@@ -0,0 +1,70 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+type NotificationDeliveryRepo struct {
+	*BaseRepo[model.NotificationDelivery]
+}
+
+func NewNotificationDeliveryRepo(dbCli *gorm.DB) *NotificationDeliveryRepo {
+	return &NotificationDeliveryRepo{
+		BaseRepo: NewBaseRepo[model.NotificationDelivery](dbCli),
+	}
+}
+
+// FindDue 查询到期待投递的记录，按到期时间排序
+func (r *NotificationDeliveryRepo) FindDue(ctx context.Context, now time.Time, limit int) ([]model.NotificationDelivery, error) {
+	var deliveries []model.NotificationDelivery
+	if err := r.db.WithContext(ctx).
+		Preload("Notification").
+		Preload("Notification.Sender").
+		Preload("Channel").
+		Where("status = ? AND next_attempt_at <= ?", model.DeliveryStatusPending, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// MarkSent 记录投递成功
+func (r *NotificationDeliveryRepo) MarkSent(ctx context.Context, id uint64, attempts int, sentAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.NotificationDelivery{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":     model.DeliveryStatusSent,
+			"attempts":   attempts,
+			"last_error": "",
+			"sent_at":    sentAt,
+		}).Error
+}
+
+// MarkRetry 记录失败并安排下次重试
+func (r *NotificationDeliveryRepo) MarkRetry(ctx context.Context, id uint64, attempts int, nextAttemptAt time.Time, reason string) error {
+	return r.db.WithContext(ctx).Model(&model.NotificationDelivery{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"attempts":        attempts,
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      reason,
+		}).Error
+}
+
+// MarkFailed 放弃投递
+func (r *NotificationDeliveryRepo) MarkFailed(ctx context.Context, id uint64, attempts int, reason string) error {
+	return r.db.WithContext(ctx).Model(&model.NotificationDelivery{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":     model.DeliveryStatusFailed,
+			"attempts":   attempts,
+			"last_error": reason,
+		}).Error
+}
@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+type NotificationDigestRepo struct {
+	*BaseRepo[model.NotificationDigest]
+}
+
+func NewNotificationDigestRepo(dbCli *gorm.DB) *NotificationDigestRepo {
+	return &NotificationDigestRepo{
+		BaseRepo: NewBaseRepo[model.NotificationDigest](dbCli),
+	}
+}
+
+// FindByUserID 查询用户的摘要设置
+func (r *NotificationDigestRepo) FindByUserID(ctx context.Context, userID uint64) (*model.NotificationDigest, error) {
+	return r.BaseRepo.FindOne(ctx, WithConditions(
+		FilterCondition{Field: "user_id", Operator: "eq", Value: userID},
+	))
+}
+
+// FindByToken 根据退订令牌查询摘要设置
+func (r *NotificationDigestRepo) FindByToken(ctx context.Context, token string) (*model.NotificationDigest, error) {
+	return r.BaseRepo.FindOne(ctx, WithConditions(
+		FilterCondition{Field: "unsubscribe_token", Operator: "eq", Value: token},
+	))
+}
+
+// FindDue 查询到了发送时间的摘要，按发送时间排序
+func (r *NotificationDigestRepo) FindDue(ctx context.Context, now time.Time, limit int) ([]model.NotificationDigest, error) {
+	var digests []model.NotificationDigest
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("frequency IN ? AND next_send_at <= ?", []model.DigestFrequency{model.DigestFrequencyDaily, model.DigestFrequencyWeekly}, now).
+		Order("next_send_at ASC, id ASC").
+		Limit(limit).
+		Find(&digests).Error; err != nil {
+		return nil, err
+	}
+	return digests, nil
+}
+
+// UpdateSchedule 记录本次发送时间和下次发送时间，sentAt 为 nil 表示本次没有需要汇总的通知
+func (r *NotificationDigestRepo) UpdateSchedule(ctx context.Context, id uint64, sentAt *time.Time, nextSendAt time.Time) error {
+	updates := map[string]any{"next_send_at": nextSendAt}
+	if sentAt != nil {
+		updates["last_sent_at"] = *sentAt
+	}
+	return r.db.WithContext(ctx).Model(&model.NotificationDigest{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+// Unsubscribe 关闭摘要
+func (r *NotificationDigestRepo) Unsubscribe(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Model(&model.NotificationDigest{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"frequency": model.DigestFrequencyOff, "next_send_at": nil}).Error
+}
+
+// IsEnabled 用户是否开启了摘要，开启后邮件渠道不再逐条发送通知
+func (r *NotificationDigestRepo) IsEnabled(ctx context.Context, userID uint64) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.NotificationDigest{}).
+		Where("user_id = ? AND frequency IN ?", userID, []model.DigestFrequency{model.DigestFrequencyDaily, model.DigestFrequencyWeekly}).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -88,7 +88,7 @@ func (r *PlaceRepo) UpdateWithImage(ctx context.Context, place *model.Place) err
 	return r.entityFileRepo.UpdateEntityFiles(ctx, place.ID, "place", fileIDs)
 }
 
-// DeleteWithImage 删除地点，需要删除地点和图片文件关联关系
+// DeleteWithImage 删除地点，需要删除地点和图片文件关联关系，并清空动态对该地点的引用
 // 参数：
 //   - ctx: 上下文
 //   - id: 地点ID
@@ -100,6 +100,13 @@ func (r *PlaceRepo) DeleteWithImage(ctx context.Context, id uint64) error {
 		return err
 	}
 
+	// 清空动态对该地点的引用，动态本身保留
+	if err := r.db.WithContext(ctx).Unscoped().Model(&model.Moment{}).
+		Where("place_id = ?", id).
+		UpdateColumn("place_id", nil).Error; err != nil {
+		return err
+	}
+
 	// 再删除地点记录
 	return r.BaseRepo.DeleteByID(ctx, id)
 }
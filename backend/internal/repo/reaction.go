@@ -0,0 +1,111 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// ReactionRepo 动态表情回应仓库
+// 功能：
+//   - 切换回应（存在则取消，不存在则添加），点赞同步更新动态的点赞数
+//   - 按动态统计各类回应数量
+//   - 查询用户（或匿名访客）对动态的回应
+type ReactionRepo struct {
+	*BaseRepo[model.Reaction]
+}
+
+// NewReactionRepo 创建新的表情回应仓库实例
+func NewReactionRepo(dbCli *gorm.DB) *ReactionRepo {
+	return &ReactionRepo{
+		BaseRepo: NewBaseRepo[model.Reaction](dbCli),
+	}
+}
+
+// Toggle 切换回应（事务）
+//
+// 返回：true 表示添加了回应，false 表示取消了回应
+func (r *ReactionRepo) Toggle(ctx context.Context, reaction *model.Reaction) (bool, error) {
+	added := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing model.Reaction
+		err := tx.Where("moment_id = ? AND user_id = ? AND fingerprint = ? AND type = ?",
+			reaction.MomentID, reaction.UserID, reaction.Fingerprint, reaction.Type).
+			First(&existing).Error
+
+		switch {
+		case err == nil:
+			// 硬删除，否则软删除的记录仍占用唯一索引
+			if err := tx.Unscoped().Delete(&existing).Error; err != nil {
+				return err
+			}
+			if reaction.Type == model.ReactionLike {
+				return tx.Model(&model.Moment{}).
+					Where("id = ? AND likes > 0", reaction.MomentID).
+					UpdateColumn("likes", gorm.Expr("likes - ?", 1)).Error
+			}
+			return nil
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Create(reaction).Error; err != nil {
+				return err
+			}
+			added = true
+			if reaction.Type == model.ReactionLike {
+				return tx.Model(&model.Moment{}).
+					Where("id = ?", reaction.MomentID).
+					UpdateColumn("likes", gorm.Expr("likes + ?", 1)).Error
+			}
+			return nil
+		default:
+			return err
+		}
+	})
+	return added, err
+}
+
+// CountByMomentID 统计动态的各类回应数量
+func (r *ReactionRepo) CountByMomentID(ctx context.Context, momentID uint64) (map[model.ReactionType]int64, error) {
+	var rows []struct {
+		Type  model.ReactionType
+		Count int64
+	}
+	if err := r.db.WithContext(ctx).Model(&model.Reaction{}).
+		Select("type, COUNT(*) AS count").
+		Where("moment_id = ?", momentID).
+		Group("type").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[model.ReactionType]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+	return counts, nil
+}
+
+// FindActorTypes 查询用户（或匿名访客）对多条动态的回应类型
+//
+// 返回：动态ID到回应类型列表的映射
+func (r *ReactionRepo) FindActorTypes(ctx context.Context, momentIDs []uint64, userID uint64, fingerprint string) (map[uint64][]model.ReactionType, error) {
+	result := make(map[uint64][]model.ReactionType)
+	if len(momentIDs) == 0 {
+		return result, nil
+	}
+
+	var reactions []model.Reaction
+	if err := r.db.WithContext(ctx).
+		Where("moment_id IN ? AND user_id = ? AND fingerprint = ?", momentIDs, userID, fingerprint).
+		Order("created_at ASC").
+		Find(&reactions).Error; err != nil {
+		return nil, err
+	}
+
+	for _, reaction := range reactions {
+		result[reaction.MomentID] = append(result[reaction.MomentID], reaction.Type)
+	}
+	return result, nil
+}
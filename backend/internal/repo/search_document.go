@@ -0,0 +1,107 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// SearchDocumentRepo 全文检索文档仓库
+// 功能：
+//   - 保存文档（按类型和业务ID覆盖）
+//   - 删除文档（按业务ID或所属动态）
+//   - 按文档ID批量查询
+type SearchDocumentRepo struct {
+	*BaseRepo[model.SearchDocument]
+}
+
+// NewSearchDocumentRepo 创建新的全文检索文档仓库实例
+func NewSearchDocumentRepo(dbCli *gorm.DB) *SearchDocumentRepo {
+	return &SearchDocumentRepo{
+		BaseRepo: NewBaseRepo[model.SearchDocument](dbCli),
+	}
+}
+
+// SearchDocumentVisibleTo 检索文档可见范围条件，规则与 MomentVisibleTo 一致
+func SearchDocumentVisibleTo(viewerID uint64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == 0 {
+			return db.Where("audience = ?", model.MomentVisibilityPublic)
+		}
+		return db.Where("(audience IN ? OR owner_id = ?)",
+			[]model.MomentVisibility{model.MomentVisibilityPublic, model.MomentVisibilityCouple}, viewerID)
+	}
+}
+
+// Save 保存文档，同类型同业务ID的文档已存在时覆盖，保存后 doc.ID 为文档ID
+func (r *SearchDocumentRepo) Save(ctx context.Context, doc *model.SearchDocument) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing model.SearchDocument
+		err := tx.Where("doc_type = ? AND doc_id = ?", doc.DocType, doc.DocID).First(&existing).Error
+		switch {
+		case err == nil:
+			doc.ID = existing.ID
+			doc.CreatedAt = existing.CreatedAt
+			return tx.Save(doc).Error
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(doc).Error
+		default:
+			return err
+		}
+	})
+}
+
+// DeleteByDocs 删除指定类型的文档
+//
+// 返回：被删除的文档ID
+func (r *SearchDocumentRepo) DeleteByDocs(ctx context.Context, docType model.SearchDocumentType, docIDs []uint64) ([]uint64, error) {
+	if len(docIDs) == 0 {
+		return nil, nil
+	}
+	return r.deleteWhere(ctx, "doc_type = ? AND doc_id IN ?", docType, docIDs)
+}
+
+// DeleteByMomentID 删除动态及其评论的文档
+//
+// 返回：被删除的文档ID
+func (r *SearchDocumentRepo) DeleteByMomentID(ctx context.Context, momentID uint64) ([]uint64, error) {
+	return r.deleteWhere(ctx, "moment_id = ?", momentID)
+}
+
+func (r *SearchDocumentRepo) deleteWhere(ctx context.Context, query string, args ...any) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.SearchDocument{}).Where(query, args...).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&model.SearchDocument{}).Error
+	})
+	return ids, err
+}
+
+// FindByIDs 按文档ID批量查询，返回顺序与数据库一致
+func (r *SearchDocumentRepo) FindByIDs(ctx context.Context, ids []uint64) ([]model.SearchDocument, error) {
+	var docs []model.SearchDocument
+	if len(ids) == 0 {
+		return docs, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&docs).Error
+	return docs, err
+}
+
+// FindDocIDs 查询指定类型已建立索引的业务ID，可按所属动态筛选（momentID 为 0 表示不筛选）
+func (r *SearchDocumentRepo) FindDocIDs(ctx context.Context, docType model.SearchDocumentType, momentID uint64) ([]uint64, error) {
+	var docIDs []uint64
+	db := r.db.WithContext(ctx).Model(&model.SearchDocument{}).Where("doc_type = ?", docType)
+	if momentID != 0 {
+		db = db.Where("moment_id = ?", momentID)
+	}
+	err := db.Pluck("doc_id", &docIDs).Error
+	return docIDs, err
+}
@@ -0,0 +1,122 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+const ShareEntityType = "share"
+
+// ShareRepo 分享链接仓库
+// 功能：
+//   - 创建分享（photos 类型同时创建文件关联）
+//   - 根据令牌查询分享
+//   - 记录访问次数（受最大访问次数约束）
+//   - 撤销分享
+type ShareRepo struct {
+	*BaseRepo[model.Share]
+}
+
+// NewShareRepo 创建新的分享仓库实例
+func NewShareRepo(dbCli *gorm.DB) *ShareRepo {
+	return &ShareRepo{
+		BaseRepo: NewBaseRepo[model.Share](dbCli),
+	}
+}
+
+func WithSharePreloads() []QueryOption {
+	return []QueryOption{
+		WithPreloads("Creator"),
+		WithPreloads("EntityFiles"),
+		WithPreloadCond("EntityFiles", "entity_type = ?", ShareEntityType),
+		WithPreloads("EntityFiles.File"),
+	}
+}
+
+// FindByID 根据ID查找分享并预加载关联数据
+func (r *ShareRepo) FindByID(ctx context.Context, id uint64) (*model.Share, error) {
+	return r.BaseRepo.FindByID(ctx, id, WithSharePreloads()...)
+}
+
+// FindByToken 根据令牌查找分享
+func (r *ShareRepo) FindByToken(ctx context.Context, token string) (*model.Share, error) {
+	opts := append([]QueryOption{WithConditions(
+		FilterCondition{Field: "token", Operator: "eq", Value: token},
+	)}, WithSharePreloads()...)
+	return r.BaseRepo.FindOne(ctx, opts...)
+}
+
+// CreateWithFiles 创建分享并关联文件（事务）
+func (r *ShareRepo) CreateWithFiles(ctx context.Context, share *model.Share, fileIDs []uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(share).Error; err != nil {
+			return err
+		}
+		if len(fileIDs) > 0 {
+			var associations []model.EntityFile
+			for _, fileID := range fileIDs {
+				associations = append(associations, model.EntityFile{
+					EntityID:   share.ID,
+					EntityType: ShareEntityType,
+					FileID:     fileID,
+				})
+			}
+			if err := tx.Create(&associations).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// IncrementViews 访问次数加一
+//
+// 返回：访问次数已用尽时返回 false
+func (r *ShareRepo) IncrementViews(ctx context.Context, id uint64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.Share{}).
+		Where("id = ? AND (max_views = 0 OR view_count < max_views)", id).
+		UpdateColumns(map[string]any{
+			"view_count":     gorm.Expr("view_count + ?", 1),
+			"last_viewed_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ContainsFile 检查文件是否属于分享的内容（相册照片、动态图片或分享的照片集）
+func (r *ShareRepo) ContainsFile(ctx context.Context, share *model.Share, fileID uint64) (bool, error) {
+	entityID, entityType := share.ID, ShareEntityType
+	switch share.TargetType {
+	case model.ShareTargetAlbum:
+		entityID, entityType = share.TargetID, "album"
+	case model.ShareTargetMoment:
+		entityID, entityType = share.TargetID, MomentEntityType
+	}
+
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.EntityFile{}).
+		Where("entity_id = ? AND entity_type = ? AND file_id = ?", entityID, entityType, fileID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Revoke 撤销分享
+func (r *ShareRepo) Revoke(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Model(&model.Share{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// ListShares 分页查询分享列表
+func (r *ShareRepo) ListShares(ctx context.Context, page, size int, opts ...QueryOption) ([]model.Share, int64, error) {
+	allOpts := append(opts, WithSharePreloads()...)
+	return r.BaseRepo.FindWithPagination(ctx, page, size, allOpts...)
+}
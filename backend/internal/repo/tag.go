@@ -0,0 +1,107 @@
+package repo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// TagRepo 话题标签仓库
+// 功能：
+//   - 同步动态的话题标签
+//   - 查询动态的话题标签
+//   - 统计标签云
+type TagRepo struct {
+	*BaseRepo[model.Tag]
+}
+
+// TagCount 标签及其关联的动态数量
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// NewTagRepo 创建新的话题标签仓库实例
+func NewTagRepo(dbCli *gorm.DB) *TagRepo {
+	return &TagRepo{
+		BaseRepo: NewBaseRepo[model.Tag](dbCli),
+	}
+}
+
+// SyncMomentTags 将动态的话题标签同步为 names，需在事务中调用
+// 标签不存在时自动创建，不再使用的标签保留在 tags 表中
+func SyncMomentTags(tx *gorm.DB, momentID uint64, names []string) error {
+	if err := tx.Unscoped().Where("moment_id = ?", momentID).Delete(&model.MomentTag{}).Error; err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	tags := make([]model.Tag, len(names))
+	for i, name := range names {
+		tags[i] = model.Tag{Name: name}
+	}
+	// 并发创建同名标签时忽略冲突，随后统一按名称查询
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return err
+	}
+	var existing []model.Tag
+	if err := tx.Where("name IN ?", names).Find(&existing).Error; err != nil {
+		return err
+	}
+
+	tagIDs := make(map[string]uint64, len(existing))
+	for _, tag := range existing {
+		tagIDs[tag.Name] = tag.ID
+	}
+	// 按标签在内容中出现的顺序写入关联
+	links := make([]model.MomentTag, 0, len(names))
+	for _, name := range names {
+		if id, ok := tagIDs[name]; ok {
+			links = append(links, model.MomentTag{MomentID: momentID, TagID: id})
+		}
+	}
+	return tx.Create(&links).Error
+}
+
+// MomentHasTag 筛选带有指定话题标签的动态
+func MomentHasTag(name string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		sub := db.Session(&gorm.Session{NewDB: true}).
+			Model(&model.MomentTag{}).
+			Select("moment_tags.moment_id").
+			Joins("JOIN tags ON tags.id = moment_tags.tag_id AND tags.deleted_at IS NULL").
+			Where("tags.name = ?", name)
+		return db.Where("id IN (?)", sub)
+	}
+}
+
+// FindNamesByMomentID 查询动态的话题标签名称
+func (r *TagRepo) FindNamesByMomentID(ctx context.Context, momentID uint64) ([]string, error) {
+	names := make([]string, 0)
+	err := r.db.WithContext(ctx).Model(&model.MomentTag{}).
+		Select("tags.name").
+		Joins("JOIN tags ON tags.id = moment_tags.tag_id AND tags.deleted_at IS NULL").
+		Where("moment_tags.moment_id = ?", momentID).
+		Order("moment_tags.id ASC").
+		Pluck("tags.name", &names).Error
+	return names, err
+}
+
+// CountVisible 统计指定用户可见动态的标签云，按动态数量降序
+func (r *TagRepo) CountVisible(ctx context.Context, viewerID uint64, limit int) ([]TagCount, error) {
+	var counts []TagCount
+	err := r.db.WithContext(ctx).Model(&model.MomentTag{}).
+		Select("tags.name AS name, COUNT(*) AS count").
+		Joins("JOIN tags ON tags.id = moment_tags.tag_id AND tags.deleted_at IS NULL").
+		Where("moment_tags.moment_id IN (?)", visibleMomentIDs(r.db, viewerID)).
+		Group("tags.name").
+		Order("count DESC, name ASC").
+		Limit(limit).
+		Scan(&counts).Error
+	return counts, err
+}
@@ -179,6 +179,36 @@ func (s *UserRepo) UpdateUserInfo(ctx context.Context, user *model.User) error {
 	return s.updateAvatarAssociation(ctx, user.ID, oldUser.AvatarID, user.AvatarID)
 }
 
+// UpdatePassword 修改用户密码并递增令牌版本，使此前签发的登录令牌和个人访问令牌失效
+// 参数：
+//   - ctx: 上下文
+//   - userID: 用户ID
+//   - oldHash: 当前密码哈希，不一致时不修改（保证重置链接只能使用一次）
+//   - newHash: 新密码哈希
+//
+// 返回：是否修改成功、错误
+func (s *UserRepo) UpdatePassword(ctx context.Context, userID uint64, oldHash, newHash string) (bool, error) {
+	result := s.BaseRepo.DB().WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND password = ?", userID, oldHash).
+		Updates(map[string]any{
+			"password":      newHash,
+			"token_version": gorm.Expr("token_version + ?", 1),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindTokenVersion 查询用户当前的令牌版本
+func (s *UserRepo) FindTokenVersion(ctx context.Context, userID uint64) (int, error) {
+	var user model.User
+	if err := s.BaseRepo.DB().WithContext(ctx).Select("token_version").Take(&user, userID).Error; err != nil {
+		return 0, err
+	}
+	return user.TokenVersion, nil
+}
+
 // UpdateAvatar 更新用户头像
 // 参数：
 //   - ctx: 上下文
@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// UserIdentityRepo 外部身份仓库
+type UserIdentityRepo struct {
+	*BaseRepo[model.UserIdentity]
+}
+
+// NewUserIdentityRepo 创建新的外部身份仓库实例
+func NewUserIdentityRepo(dbCli *gorm.DB) *UserIdentityRepo {
+	return &UserIdentityRepo{
+		BaseRepo: NewBaseRepo[model.UserIdentity](dbCli),
+	}
+}
+
+// FindBySubject 根据身份提供方和外部用户标识查找关联
+func (r *UserIdentityRepo) FindBySubject(ctx context.Context, issuer, subject string) (*model.UserIdentity, error) {
+	return r.BaseRepo.FindOne(ctx, WithConditions(
+		FilterCondition{Field: "issuer", Operator: "eq", Value: issuer},
+		FilterCondition{Field: "subject", Operator: "eq", Value: subject},
+	))
+}
+
+// ListByUserID 查询用户关联的全部外部身份
+func (r *UserIdentityRepo) ListByUserID(ctx context.Context, userID uint64) ([]model.UserIdentity, error) {
+	return r.BaseRepo.List(ctx,
+		WithConditions(FilterCondition{Field: "user_id", Operator: "eq", Value: userID}),
+		WithOrder("created_at", true),
+	)
+}
+
+// DeleteByUser 解除用户自己的外部身份关联
+//
+// 硬删除，否则软删除的记录仍占用唯一索引，无法重新关联
+//
+// 返回：关联不存在时返回 false
+func (r *UserIdentityRepo) DeleteByUser(ctx context.Context, id, userID uint64) (bool, error) {
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&model.UserIdentity{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,110 @@
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+// 索引后端配置值
+const (
+	BackendAuto     = "auto"     // 按数据库自动选择，数据库不支持全文索引时使用内存索引
+	BackendDatabase = "database" // 使用数据库全文索引，不支持时启动失败
+	BackendMemory   = "memory"   // 使用内存倒排索引
+)
+
+// Query 检索条件
+type Query struct {
+	Terms    []string                   // QueryTerms 返回的检索词，文档需包含全部检索词
+	Types    []model.SearchDocumentType // 文档类型，为空表示全部
+	ViewerID uint64                     // 当前用户ID，0 表示未登录访客
+	Offset   int
+	Limit    int
+}
+
+// Hit 检索命中的文档
+type Hit struct {
+	DocumentID uint64
+	Score      float64 // 相关度，越大越相关，不同后端之间不可比较
+}
+
+// Index 全文索引后端
+// 文档元数据保存在 search_documents 表，后端只负责维护索引词并按相关度检索
+type Index interface {
+	// Name 后端名称
+	Name() string
+	// Init 创建索引结构，并补齐已有文档的索引
+	Init(ctx context.Context) error
+	// Put 写入或覆盖文档的索引，调用前文档已保存到 search_documents 表
+	Put(ctx context.Context, doc *model.SearchDocument) error
+	// Remove 删除文档的索引
+	Remove(ctx context.Context, ids []uint64) error
+	// Search 检索可见文档，按相关度降序返回一页命中结果和命中总数
+	Search(ctx context.Context, q Query) ([]Hit, int64, error)
+}
+
+// NewIndex 根据配置创建并初始化索引后端
+func NewIndex(ctx context.Context, db *gorm.DB, backend string, logger *log.Logger) (Index, error) {
+	if backend == BackendMemory {
+		return initIndex(ctx, NewMemoryIndex(db))
+	}
+
+	var index Index
+	switch db.Dialector.Name() {
+	case "sqlite":
+		index = NewSQLiteIndex(db)
+	case "postgres":
+		index = NewPostgresIndex(db)
+	case "mysql":
+		index = NewMySQLIndex(db)
+	default:
+		if backend == BackendDatabase {
+			return nil, fmt.Errorf("数据库 %s 不支持全文索引", db.Dialector.Name())
+		}
+		return initIndex(ctx, NewMemoryIndex(db))
+	}
+
+	if err := index.Init(ctx); err != nil {
+		if backend == BackendDatabase {
+			return nil, err
+		}
+		logger.Warn("数据库全文索引不可用，改用内存索引", "backend", index.Name(), "error", err)
+		return initIndex(ctx, NewMemoryIndex(db))
+	}
+	return index, nil
+}
+
+func initIndex(ctx context.Context, index Index) (Index, error) {
+	if err := index.Init(ctx); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// filterDocuments search_documents 表的通用检索条件：可见范围和文档类型
+func filterDocuments(db *gorm.DB, q Query) *gorm.DB {
+	db = repo.SearchDocumentVisibleTo(q.ViewerID)(db).Where("search_documents.deleted_at IS NULL")
+	if len(q.Types) > 0 {
+		db = db.Where("doc_type IN ?", q.Types)
+	}
+	return db
+}
+
+// hitRow 数据库后端检索结果
+type hitRow struct {
+	DocumentID uint64
+	Score      float64
+}
+
+func toHits(rows []hitRow) []Hit {
+	hits := make([]Hit, len(rows))
+	for i, row := range rows {
+		hits[i] = Hit(row)
+	}
+	return hits
+}
@@ -0,0 +1,200 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// BM25 参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// memoryDoc 内存索引中的文档，只保留检索和可见范围判断需要的字段
+type memoryDoc struct {
+	meta   model.SearchDocument
+	terms  map[string]int // 索引词 -> 出现次数
+	length int
+}
+
+// MemoryIndex 进程内倒排索引，启动时从 search_documents 表加载，适用于不支持全文索引的数据库
+// 多实例部署时各实例的索引只在启动时同步，不适合多实例
+type MemoryIndex struct {
+	db       *gorm.DB
+	mu       sync.RWMutex
+	docs     map[uint64]*memoryDoc
+	postings map[string]map[uint64]struct{} // 索引词 -> 文档ID集合
+	totalLen int
+}
+
+func NewMemoryIndex(db *gorm.DB) *MemoryIndex {
+	return &MemoryIndex{
+		db:       db,
+		docs:     make(map[uint64]*memoryDoc),
+		postings: make(map[string]map[uint64]struct{}),
+	}
+}
+
+func (i *MemoryIndex) Name() string {
+	return "memory"
+}
+
+func (i *MemoryIndex) Init(ctx context.Context) error {
+	var batch []model.SearchDocument
+	return i.db.WithContext(ctx).FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		for j := range batch {
+			i.put(&batch[j])
+		}
+		return nil
+	}).Error
+}
+
+func (i *MemoryIndex) Put(ctx context.Context, doc *model.SearchDocument) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.put(doc)
+	return nil
+}
+
+func (i *MemoryIndex) Remove(ctx context.Context, ids []uint64) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, id := range ids {
+		i.remove(id)
+	}
+	return nil
+}
+
+func (i *MemoryIndex) put(doc *model.SearchDocument) {
+	i.remove(doc.ID)
+
+	tokens := strings.Fields(doc.Tokens)
+	entry := &memoryDoc{
+		meta:   *doc,
+		terms:  make(map[string]int, len(tokens)),
+		length: len(tokens),
+	}
+	// 原文用于生成摘要，由调用方从文档表读取，内存中不重复保存
+	entry.meta.Content = ""
+	entry.meta.Tokens = ""
+
+	for _, token := range tokens {
+		entry.terms[token]++
+	}
+	for term := range entry.terms {
+		set, ok := i.postings[term]
+		if !ok {
+			set = make(map[uint64]struct{})
+			i.postings[term] = set
+		}
+		set[doc.ID] = struct{}{}
+	}
+	i.docs[doc.ID] = entry
+	i.totalLen += entry.length
+}
+
+func (i *MemoryIndex) remove(id uint64) {
+	entry, ok := i.docs[id]
+	if !ok {
+		return
+	}
+	for term := range entry.terms {
+		if set, ok := i.postings[term]; ok {
+			delete(set, id)
+			if len(set) == 0 {
+				delete(i.postings, term)
+			}
+		}
+	}
+	i.totalLen -= entry.length
+	delete(i.docs, id)
+}
+
+func (i *MemoryIndex) Search(ctx context.Context, q Query) ([]Hit, int64, error) {
+	if len(q.Terms) == 0 {
+		return []Hit{}, 0, nil
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	// 从最短的倒排表开始求交集
+	sets := make([]map[uint64]struct{}, 0, len(q.Terms))
+	for _, term := range q.Terms {
+		set, ok := i.postings[term]
+		if !ok {
+			return []Hit{}, 0, nil
+		}
+		sets = append(sets, set)
+	}
+	sort.Slice(sets, func(a, b int) bool { return len(sets[a]) < len(sets[b]) })
+
+	types := make(map[model.SearchDocumentType]struct{}, len(q.Types))
+	for _, t := range q.Types {
+		types[t] = struct{}{}
+	}
+
+	docCount := float64(len(i.docs))
+	avgLen := 1.0
+	if len(i.docs) > 0 && i.totalLen > 0 {
+		avgLen = float64(i.totalLen) / docCount
+	}
+
+	var hits []Hit
+	for id := range sets[0] {
+		entry := i.docs[id]
+		if !containsAll(sets[1:], id) {
+			continue
+		}
+		if len(types) > 0 {
+			if _, ok := types[entry.meta.DocType]; !ok {
+				continue
+			}
+		}
+		if !entry.meta.VisibleTo(q.ViewerID) {
+			continue
+		}
+
+		score := 0.0
+		for _, term := range q.Terms {
+			df := float64(len(i.postings[term]))
+			idf := math.Log(1 + (docCount-df+0.5)/(df+0.5))
+			tf := float64(entry.terms[term])
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(entry.length)/avgLen))
+		}
+		hits = append(hits, Hit{DocumentID: id, Score: score})
+	}
+
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].DocumentID > hits[b].DocumentID
+	})
+
+	total := int64(len(hits))
+	if q.Offset >= len(hits) {
+		return []Hit{}, total, nil
+	}
+	end := min(len(hits), q.Offset+q.Limit)
+	return hits[q.Offset:end], total, nil
+}
+
+func containsAll(sets []map[uint64]struct{}, id uint64) bool {
+	for _, set := range sets {
+		if _, ok := set[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
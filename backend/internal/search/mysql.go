@@ -0,0 +1,74 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+const mysqlFulltextIndex = "idx_search_documents_fulltext"
+
+// MySQLIndex 基于 MySQL FULLTEXT 的索引，使用 ngram 解析器（MySQL 5.7.6+）
+// 默认 ngram_token_size 为 2，单个汉字的查询无法命中
+type MySQLIndex struct {
+	db *gorm.DB
+}
+
+func NewMySQLIndex(db *gorm.DB) *MySQLIndex {
+	return &MySQLIndex{db: db}
+}
+
+func (i *MySQLIndex) Name() string {
+	return "mysql-fulltext"
+}
+
+func (i *MySQLIndex) Init(ctx context.Context) error {
+	db := i.db.WithContext(ctx)
+	if db.Migrator().HasIndex(&model.SearchDocument{}, mysqlFulltextIndex) {
+		return nil
+	}
+	return db.Exec("CREATE FULLTEXT INDEX " + mysqlFulltextIndex + " ON search_documents (tokens) WITH PARSER ngram").Error
+}
+
+// Put FULLTEXT 索引随文档行自动更新，无需额外处理
+func (i *MySQLIndex) Put(ctx context.Context, doc *model.SearchDocument) error {
+	return nil
+}
+
+// Remove FULLTEXT 索引随文档行自动删除，无需额外处理
+func (i *MySQLIndex) Remove(ctx context.Context, ids []uint64) error {
+	return nil
+}
+
+func (i *MySQLIndex) Search(ctx context.Context, q Query) ([]Hit, int64, error) {
+	expr := mysqlBooleanExpr(q.Terms)
+	db := i.db.WithContext(ctx).Table("search_documents").
+		Where("MATCH (tokens) AGAINST (? IN BOOLEAN MODE)", expr)
+	db = filterDocuments(db, q)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []hitRow
+	if err := db.Select("id AS document_id, MATCH (tokens) AGAINST (? IN BOOLEAN MODE) AS score", expr).
+		Order("score DESC, id DESC").
+		Offset(q.Offset).Limit(q.Limit).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return toHits(rows), total, nil
+}
+
+// mysqlBooleanExpr 将检索词转换为布尔模式查询：每个词作为必须包含的短语
+func mysqlBooleanExpr(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = `+"` + strings.ReplaceAll(term, `"`, "") + `"`
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// PostgresIndex 基于 PostgreSQL tsvector 的索引，在 search_documents 表上增加 tsv 列和 GIN 索引
+// 使用 simple 配置，不做词干化，中文依赖写入前的单字和二元组切分
+type PostgresIndex struct {
+	db *gorm.DB
+}
+
+func NewPostgresIndex(db *gorm.DB) *PostgresIndex {
+	return &PostgresIndex{db: db}
+}
+
+func (i *PostgresIndex) Name() string {
+	return "postgres-tsvector"
+}
+
+func (i *PostgresIndex) Init(ctx context.Context) error {
+	db := i.db.WithContext(ctx)
+	statements := []string{
+		"ALTER TABLE search_documents ADD COLUMN IF NOT EXISTS tsv tsvector",
+		"CREATE INDEX IF NOT EXISTS idx_search_documents_tsv ON search_documents USING GIN (tsv)",
+		"UPDATE search_documents SET tsv = to_tsvector('simple', tokens) WHERE tsv IS NULL",
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *PostgresIndex) Put(ctx context.Context, doc *model.SearchDocument) error {
+	return i.db.WithContext(ctx).
+		Exec("UPDATE search_documents SET tsv = to_tsvector('simple', tokens) WHERE id = ?", doc.ID).Error
+}
+
+// Remove 索引列随文档行一起删除，无需额外处理
+func (i *PostgresIndex) Remove(ctx context.Context, ids []uint64) error {
+	return nil
+}
+
+func (i *PostgresIndex) Search(ctx context.Context, q Query) ([]Hit, int64, error) {
+	text := JoinTokens(q.Terms)
+	db := i.db.WithContext(ctx).Table("search_documents").
+		Where("tsv @@ plainto_tsquery('simple', ?)", text)
+	db = filterDocuments(db, q)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []hitRow
+	if err := db.Select("id AS document_id, ts_rank(tsv, plainto_tsquery('simple', ?)) AS score", text).
+		Order("score DESC, id DESC").
+		Offset(q.Offset).Limit(q.Limit).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return toHits(rows), total, nil
+}
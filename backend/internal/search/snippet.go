@@ -0,0 +1,88 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	highlightOpen  = "<mark>"
+	highlightClose = "</mark>"
+)
+
+// Snippet 截取包含检索词的片段并用 <mark> 高亮，其余内容做 HTML 转义
+//
+// 参数：
+//   - text: 原文
+//   - terms: QueryTerms 返回的检索词
+//   - width: 片段最大字符数，0 表示返回全文
+func Snippet(text string, terms []string, width int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		termRunes := []rune(term)
+		if len(termRunes) == 0 {
+			continue
+		}
+		for i := 0; i+len(termRunes) <= len(lower); i++ {
+			if !runesEqual(lower[i:i+len(termRunes)], termRunes) {
+				continue
+			}
+			for j := i; j < i+len(termRunes); j++ {
+				marked[j] = true
+			}
+			if first == -1 || i < first {
+				first = i
+			}
+		}
+	}
+
+	start, end := 0, len(runes)
+	if width > 0 && len(runes) > width {
+		// 命中位置前保留约四分之一的上下文
+		if first > 0 {
+			start = max(0, first-width/4)
+		}
+		end = min(len(runes), start+width)
+		start = max(0, end-width)
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	inMark := false
+	for i := start; i < end; i++ {
+		if marked[i] && !inMark {
+			sb.WriteString(highlightOpen)
+			inMark = true
+		} else if !marked[i] && inMark {
+			sb.WriteString(highlightClose)
+			inMark = false
+		}
+		sb.WriteString(html.EscapeString(string(runes[i])))
+	}
+	if inMark {
+		sb.WriteString(highlightClose)
+	}
+	if end < len(runes) {
+		sb.WriteString("…")
+	}
+	return sb.String()
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// SQLiteIndex 基于 SQLite FTS5 的索引，FTS 表只保存索引词，rowid 对应 search_documents.id
+// 需要编译时启用 FTS5（go build -tags sqlite_fts5）
+type SQLiteIndex struct {
+	db *gorm.DB
+}
+
+func NewSQLiteIndex(db *gorm.DB) *SQLiteIndex {
+	return &SQLiteIndex{db: db}
+}
+
+func (i *SQLiteIndex) Name() string {
+	return "sqlite-fts5"
+}
+
+func (i *SQLiteIndex) Init(ctx context.Context) error {
+	db := i.db.WithContext(ctx)
+	// 索引词已在写入前切分好，使用 unicode61 按空格拆分即可
+	if err := db.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS search_fts USING fts5(tokens, tokenize = 'unicode61 remove_diacritics 0')").Error; err != nil {
+		return err
+	}
+
+	var docCount, ftsCount int64
+	if err := db.Model(&model.SearchDocument{}).Count(&docCount).Error; err != nil {
+		return err
+	}
+	if err := db.Table("search_fts").Count(&ftsCount).Error; err != nil {
+		return err
+	}
+	if docCount == ftsCount {
+		return nil
+	}
+	// 切换后端或中途失败时，按文档表重建 FTS 索引
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM search_fts").Error; err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO search_fts (rowid, tokens) SELECT id, tokens FROM search_documents WHERE deleted_at IS NULL").Error
+	})
+}
+
+func (i *SQLiteIndex) Put(ctx context.Context, doc *model.SearchDocument) error {
+	return i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM search_fts WHERE rowid = ?", doc.ID).Error; err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO search_fts (rowid, tokens) VALUES (?, ?)", doc.ID, doc.Tokens).Error
+	})
+}
+
+func (i *SQLiteIndex) Remove(ctx context.Context, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return i.db.WithContext(ctx).Exec("DELETE FROM search_fts WHERE rowid IN ?", ids).Error
+}
+
+func (i *SQLiteIndex) Search(ctx context.Context, q Query) ([]Hit, int64, error) {
+	db := i.db.WithContext(ctx).Table("search_fts").
+		Joins("JOIN search_documents ON search_documents.id = search_fts.rowid").
+		Where("search_fts MATCH ?", ftsMatchExpr(q.Terms))
+	db = filterDocuments(db, q)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []hitRow
+	// bm25 越小越相关，取反后与其他后端保持越大越相关
+	if err := db.Select("search_documents.id AS document_id, -bm25(search_fts) AS score").
+		Order("bm25(search_fts)").
+		Offset(q.Offset).Limit(q.Limit).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return toHits(rows), total, nil
+}
+
+// ftsMatchExpr 将检索词转换为 FTS5 查询：每个词作为短语，空格连接表示同时包含
+func ftsMatchExpr(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
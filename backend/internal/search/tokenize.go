@@ -0,0 +1,101 @@
+// Package search 全文检索，支持多种索引后端，中文按单字和二元组切分
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxWordLength 单个非中文词的最大长度，超出的截断，避免超长串占用索引
+const maxWordLength = 64
+
+// isCJK 是否为中日韩文字，这类文字没有空格分词，需要按字切分
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Tokenize 将文本切分为索引词：字母数字按连续串转小写成词，中文等按单字和相邻二元组成词
+//
+// 例如 "去海边 Travel" 切分为 去、去海、海、海边、边、travel
+func Tokenize(text string) []string {
+	return tokenize(text, true)
+}
+
+// QueryTerms 将查询串切分为检索词并去重
+// 与 Tokenize 不同，连续两个以上的中文只取二元组，单个中文字取单字，以保证检索精度
+func QueryTerms(query string) []string {
+	terms := tokenize(query, false)
+	seen := make(map[string]struct{}, len(terms))
+	result := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		result = append(result, term)
+	}
+	return result
+}
+
+func tokenize(text string, withUnigrams bool) []string {
+	var (
+		tokens []string
+		word   []rune
+		cjk    []rune
+	)
+
+	flushWord := func() {
+		if len(word) == 0 {
+			return
+		}
+		if len(word) > maxWordLength {
+			word = word[:maxWordLength]
+		}
+		tokens = append(tokens, string(word))
+		word = word[:0]
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 0:
+			return
+		case len(cjk) == 1:
+			tokens = append(tokens, string(cjk))
+		default:
+			for i := range cjk {
+				if withUnigrams {
+					tokens = append(tokens, string(cjk[i]))
+				}
+				if i+1 < len(cjk) {
+					tokens = append(tokens, string(cjk[i:i+2]))
+				}
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case isWordRune(r):
+			flushCJK()
+			word = append(word, unicode.ToLower(r))
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
+
+// JoinTokens 将索引词拼接为空格分隔的文本，供数据库全文索引使用
+func JoinTokens(tokens []string) string {
+	return strings.Join(tokens, " ")
+}
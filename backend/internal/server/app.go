@@ -93,6 +93,11 @@ func (a *App) Run(
 	return nil
 }
 
+// OnShutdown 注册关闭服务器时调用的函数，用于结束 SSE 等长连接，否则优雅关闭会一直等到超时
+func (a *App) OnShutdown(f func()) {
+	a.httpServer.RegisterOnShutdown(f)
+}
+
 func (a *App) GracefulShutdown() {
 	a.Logger.Info("正在优雅关闭服务器...")
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -25,10 +25,13 @@ func NewGinEngine(cfg *config.AppConfig, logger *log.Logger) *GinEngine {
 	// 设置文件上传大小限制为200MB
 	engine.MaxMultipartMemory = 200 << 20 // 200 MB
 
+	// Gin 日志，隐藏查询参数中的令牌和密码
+	ginLogger := gin.LoggerWithConfig(gin.LoggerConfig{Output: logger, Formatter: middleware.LogFormatter})
+
 	// 注册中间件（按顺序）
-	engine.Use(middleware.RequestID())       // RequestID 追踪
-	engine.Use(gin.LoggerWithWriter(logger)) // Gin 日志
-	engine.Use(middleware.Recovery())        // Panic 恢复（增强版）
+	engine.Use(middleware.RequestID()) // RequestID 追踪
+	engine.Use(ginLogger)              // Gin 日志
+	engine.Use(middleware.Recovery())  // Panic 恢复（增强版）
 
 	// CORS 配置
 	corsConfig := cors.DefaultConfig()
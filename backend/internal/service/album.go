@@ -13,6 +13,9 @@ import (
 	"github.com/bookandmusic/love-girl/internal/repo"
 )
 
+// albumNotificationPreviews 相册通知中保留的预览照片数量
+const albumNotificationPreviews = 4
+
 type AlbumQueryParams struct {
 	Page    int
 	Size    int
@@ -66,6 +69,7 @@ type AlbumPhoto struct {
 	AlbumID   uint64        `json:"albumId"`
 	File      *FileResponse `json:"file"`
 	Alt       string        `json:"alt,omitempty"`
+	TakenAt   string        `json:"takenAt,omitempty"` // EXIF 拍摄时间
 	CreatedAt string        `json:"createdAt"`
 }
 
@@ -92,16 +96,22 @@ type AlbumSetCoverRequest struct {
 // AlbumService 相册服务
 type AlbumService struct {
 	*BaseService
-	AlbumRepo   *repo.AlbumRepo
-	FileService *FileService
+	AlbumRepo       *repo.AlbumRepo
+	UserRepo        *repo.UserRepo
+	FileService     *FileService
+	Search          *SearchService
+	NotificationSvc *NotificationService
 }
 
 // NewAlbumService 创建相册服务实例
-func NewAlbumService(log *log.Logger, albumRepo *repo.AlbumRepo, fileService *FileService) *AlbumService {
+func NewAlbumService(log *log.Logger, albumRepo *repo.AlbumRepo, userRepo *repo.UserRepo, fileService *FileService, searchService *SearchService, notificationService *NotificationService) *AlbumService {
 	return &AlbumService{
-		BaseService: &BaseService{Log: log},
-		AlbumRepo:   albumRepo,
-		FileService: fileService,
+		BaseService:     &BaseService{Log: log},
+		AlbumRepo:       albumRepo,
+		UserRepo:        userRepo,
+		FileService:     fileService,
+		Search:          searchService,
+		NotificationSvc: notificationService,
 	}
 }
 
@@ -137,13 +147,17 @@ func (s *AlbumService) convertToAlbumPhoto(c *gin.Context, file *model.File, alb
 		return nil
 	}
 
-	return &AlbumPhoto{
+	photo := &AlbumPhoto{
 		ID:        file.ID,
 		AlbumID:   albumID,
 		File:      s.FileService.BuildFileResponse(c, file),
 		Alt:       file.OriginalName,
 		CreatedAt: file.CreatedAt.Format("2006-01-02 15:04:05"),
 	}
+	if file.TakenAt != nil {
+		photo.TakenAt = file.TakenAt.Format("2006-01-02 15:04:05")
+	}
+	return photo
 }
 
 // ListAlbums 获取相册列表
@@ -242,6 +256,8 @@ func (s *AlbumService) CreateAlbum(c *gin.Context, req *AlbumCreateRequest) (*Al
 		return nil, fmt.Errorf("系统内部错误")
 	}
 
+	s.Search.IndexAlbum(ctx, album.ID)
+
 	return s.convertToAlbum(c, album), nil
 }
 
@@ -273,6 +289,8 @@ func (s *AlbumService) UpdateAlbum(c *gin.Context, id uint64, req *AlbumUpdateRe
 		return nil, fmt.Errorf("系统内部错误")
 	}
 
+	s.Search.IndexAlbum(ctx, album.ID)
+
 	return s.convertToAlbum(c, album), nil
 }
 
@@ -301,6 +319,8 @@ func (s *AlbumService) DeleteAlbum(ctx context.Context, id uint64) (bool, error)
 		return false, fmt.Errorf("系统内部错误")
 	}
 
+	s.Search.Remove(ctx, model.SearchDocumentAlbum, id)
+
 	return true, nil
 }
 
@@ -354,11 +374,11 @@ func (s *AlbumService) ListAlbumPhotos(c *gin.Context, albumID uint64, page, siz
 	}, nil
 }
 
-// AddPhotosToAlbum 添加照片到相册
-func (s *AlbumService) AddPhotosToAlbum(c *gin.Context, albumID uint64, req *AlbumAddPhotosRequest) ([]*AlbumPhoto, error) {
+// AddPhotosToAlbum 添加照片到相册，并通知其他用户
+func (s *AlbumService) AddPhotosToAlbum(c *gin.Context, albumID, userID uint64, req *AlbumAddPhotosRequest) ([]*AlbumPhoto, error) {
 	ctx := c.Request.Context()
 	// 检查相册是否存在
-	_, err := s.AlbumRepo.FindByID(ctx, albumID)
+	album, err := s.AlbumRepo.FindByID(ctx, albumID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			s.Log.Info("相册不存在", "id", albumID)
@@ -369,10 +389,12 @@ func (s *AlbumService) AddPhotosToAlbum(c *gin.Context, albumID uint64, req *Alb
 	}
 
 	// 追加照片到相册（只添加新的文件关联，不删除已存在的关联）
-	if err := s.AlbumRepo.AppendFiles(ctx, albumID, req.PhotoIDs); err != nil {
+	added, err := s.AlbumRepo.AppendFiles(ctx, albumID, req.PhotoIDs)
+	if err != nil {
 		s.Log.Error("追加相册照片失败", "albumId", albumID, "error", err, "photoIds", req.PhotoIDs)
 		return nil, fmt.Errorf("系统内部错误")
 	}
+	s.notifyPhotosAdded(ctx, album, userID, added)
 
 	// 查询指定的照片并返回
 	var files []model.File
@@ -395,6 +417,32 @@ func (s *AlbumService) AddPhotosToAlbum(c *gin.Context, albumID uint64, req *Alb
 	return responsePhotos, nil
 }
 
+// notifyPhotosAdded 通知除添加者外的用户相册中有了新照片，已在相册中的照片不计入
+func (s *AlbumService) notifyPhotosAdded(ctx context.Context, album *model.Album, userID uint64, added []uint64) {
+	if len(added) == 0 {
+		return
+	}
+	users, err := s.UserRepo.List(ctx)
+	if err != nil {
+		s.Log.Error("查询通知接收用户失败", "error", err, "albumId", album.ID)
+		return
+	}
+
+	payload := model.AlbumPhotoPayload{
+		AlbumName: album.Name,
+		Count:     len(added),
+		FileIDs:   added[:min(len(added), albumNotificationPreviews)],
+	}
+	for _, user := range users {
+		if user.ID == userID {
+			continue
+		}
+		if err := s.NotificationSvc.CreateNotification(ctx, user.ID, userID, model.NotificationTypeAlbumPhoto, model.NotificationEntityAlbum, album.ID, payload); err != nil {
+			s.Log.Error("创建相册通知失败", "error", err, "albumId", album.ID, "receiverID", user.ID)
+		}
+	}
+}
+
 // SetAlbumCover 设置相册封面
 func (s *AlbumService) SetAlbumCover(c *gin.Context, albumID uint64, req *AlbumSetCoverRequest) (*Album, error) {
 	ctx := c.Request.Context()
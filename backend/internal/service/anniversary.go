@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/bookandmusic/love-girl/internal/log"
 	"github.com/bookandmusic/love-girl/internal/model"
@@ -23,7 +24,12 @@ type FrontendAnniversary struct {
 	Title       string `json:"title"`
 	Date        string `json:"date"` // MM-DD
 	Description string `json:"description"`
-	Calendar    string `json:"calendar"` // solar/lunar
+	Calendar    string `json:"calendar"`            // solar/lunar
+	LeapMonth   bool   `json:"leapMonth"`           // 农历闰月
+	LunarDate   string `json:"lunarDate,omitempty"` // 农历纪念日的中文日期，如 闰四月初八
+	NextDate    string `json:"nextDate,omitempty"`  // 下一次纪念日的公历日期 YYYY-MM-DD，今天是纪念日时为今天；日期无效或超出农历 2100 年时省略
+	DaysLeft    int    `json:"daysLeft"`            // 距离下一次纪念日的天数，当天为 0
+	Years       int    `json:"years"`               // 到今天为止已满的周年数
 }
 
 // 将model.Anniversary转换为前端期望的格式
@@ -35,24 +41,52 @@ func (s *AnniversaryService) convertToFrontendFormat(anniversary *model.Annivers
 	// 保持完整日期格式YYYY-MM-DD
 	date := anniversary.Date
 
-	return &FrontendAnniversary{
+	result := &FrontendAnniversary{
 		ID:          anniversary.ID,
 		Title:       anniversary.Title,
 		Date:        date,
 		Description: anniversary.Description,
 		Calendar:    anniversary.Calendar,
+		LeapMonth:   anniversary.IsLeapMonth,
 	}
+
+	if anniversary.Calendar == model.AnniversaryCalendarLunar {
+		if d, err := parseLunarDate(anniversary.Date, anniversary.IsLeapMonth); err == nil {
+			result.LunarDate = d.String()
+		}
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if next, years, ok := nextOccurrence(anniversary, today); ok {
+		result.NextDate = next.Format("2006-01-02")
+		result.DaysLeft = daysBetween(today, next)
+		// 下一次纪念日是第 years 周年，不在今天时已满的周年数少一年
+		if result.DaysLeft > 0 && years > 0 {
+			years--
+		}
+		result.Years = years
+	}
+	return result
 }
 
 type AnniversaryService struct {
 	*BaseService
-	AnniversaryRepo *repo.AnniversaryRepo
+	AnniversaryRepo  *repo.AnniversaryRepo
+	UserRepo         *repo.UserRepo
+	NotificationRepo *repo.NotificationRepo
+	Search           *SearchService
+	NotificationSvc  *NotificationService
 }
 
-func NewAnniversaryService(log *log.Logger, anniversaryRepo *repo.AnniversaryRepo) *AnniversaryService {
+func NewAnniversaryService(log *log.Logger, anniversaryRepo *repo.AnniversaryRepo, userRepo *repo.UserRepo, notificationRepo *repo.NotificationRepo, searchService *SearchService, notificationService *NotificationService) *AnniversaryService {
 	return &AnniversaryService{
-		BaseService:     &BaseService{Log: log},
-		AnniversaryRepo: anniversaryRepo,
+		BaseService:      &BaseService{Log: log},
+		AnniversaryRepo:  anniversaryRepo,
+		UserRepo:         userRepo,
+		NotificationRepo: notificationRepo,
+		Search:           searchService,
+		NotificationSvc:  notificationService,
 	}
 }
 
@@ -62,6 +96,7 @@ type AnniversaryCreateRequest struct {
 	Date        string `json:"date" binding:"required"` // YYYY-MM-DD格式
 	Description string `json:"description"`
 	Calendar    string `json:"calendar" binding:"required,oneof=solar lunar"`
+	LeapMonth   bool   `json:"leapMonth"` // 农历闰月，仅农历纪念日有效
 }
 
 // AnniversaryUpdateRequest 更新纪念日请求
@@ -70,6 +105,7 @@ type AnniversaryUpdateRequest struct {
 	Date        *string `json:"date"` // YYYY-MM-DD格式
 	Description *string `json:"description"`
 	Calendar    *string `json:"calendar" binding:"omitempty,oneof=solar lunar"`
+	LeapMonth   *bool   `json:"leapMonth"`
 }
 
 // AnniversaryListResponse 纪念日列表响应
@@ -158,6 +194,10 @@ func (s *AnniversaryService) CreateAnniversary(ctx context.Context, req *Anniver
 		Date:        req.Date,
 		Description: req.Description,
 		Calendar:    req.Calendar,
+		IsLeapMonth: req.LeapMonth && req.Calendar == model.AnniversaryCalendarLunar,
+	}
+	if err := validateAnniversaryDate(anniversary.Date, anniversary.Calendar, anniversary.IsLeapMonth); err != nil {
+		return nil, err
 	}
 
 	if err := s.AnniversaryRepo.BaseRepo.Create(ctx, anniversary); err != nil {
@@ -165,6 +205,8 @@ func (s *AnniversaryService) CreateAnniversary(ctx context.Context, req *Anniver
 		return nil, fmt.Errorf("系统内部错误")
 	}
 
+	s.Search.IndexAnniversary(ctx, anniversary.ID)
+
 	return s.convertToFrontendFormat(anniversary), nil
 }
 
@@ -199,12 +241,24 @@ func (s *AnniversaryService) UpdateAnniversary(ctx context.Context, id uint64, r
 		anniversary.Calendar = *req.Calendar
 	}
 
+	if req.LeapMonth != nil {
+		anniversary.IsLeapMonth = *req.LeapMonth
+	}
+	if anniversary.Calendar != model.AnniversaryCalendarLunar {
+		anniversary.IsLeapMonth = false
+	}
+	if err := validateAnniversaryDate(anniversary.Date, anniversary.Calendar, anniversary.IsLeapMonth); err != nil {
+		return nil, err
+	}
+
 	// 保存更新
 	if err := s.AnniversaryRepo.BaseRepo.Update(ctx, anniversary); err != nil {
 		s.Log.Error("更新纪念日失败", "error", err, "id", id)
 		return nil, fmt.Errorf("系统内部错误")
 	}
 
+	s.Search.IndexAnniversary(ctx, anniversary.ID)
+
 	return s.convertToFrontendFormat(anniversary), nil
 }
 
@@ -222,10 +276,12 @@ func (s *AnniversaryService) DeleteAnniversary(ctx context.Context, id uint64) e
 	}
 
 	// 删除纪念日
-	if err := s.AnniversaryRepo.BaseRepo.DeleteByID(ctx, id); err != nil {
+	if err := s.AnniversaryRepo.DeleteAndUnlinkMoments(ctx, id); err != nil {
 		s.Log.Error("删除纪念日失败", "error", err, "id", id)
 		return fmt.Errorf("系统内部错误")
 	}
 
+	s.Search.Remove(ctx, model.SearchDocumentAnniversary, id)
+
 	return nil
 }
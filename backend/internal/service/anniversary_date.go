@@ -0,0 +1,129 @@
+package service
+
+import (
+	"strconv"
+	"time"
+
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/lunar"
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// validateAnniversaryDate 校验纪念日日期，农历日期需要在 1900–2100 年范围内且该月存在这一天
+func validateAnniversaryDate(date, calendar string, leapMonth bool) error {
+	if calendar == model.AnniversaryCalendarLunar {
+		if _, err := parseLunarDate(date, leapMonth); err != nil {
+			return errMsg.ErrInvalidAnniversaryDate
+		}
+		return nil
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return errMsg.ErrInvalidAnniversaryDate
+	}
+	return nil
+}
+
+// parseLunarDate 解析 YYYY-MM-DD 格式的农历日期，日可以为 30
+func parseLunarDate(date string, leapMonth bool) (lunar.Date, error) {
+	var d lunar.Date
+	if len(date) != len("2006-01-02") || date[4] != '-' || date[7] != '-' {
+		return d, lunar.ErrInvalidDate
+	}
+	year, yearErr := strconv.Atoi(date[:4])
+	month, monthErr := strconv.Atoi(date[5:7])
+	day, dayErr := strconv.Atoi(date[8:])
+	if yearErr != nil || monthErr != nil || dayErr != nil {
+		return d, lunar.ErrInvalidDate
+	}
+	d.Year, d.Month, d.Day = year, month, day
+	d.IsLeap = leapMonth
+	if d.Year < lunar.MinYear || d.Year > lunar.MaxYear {
+		return d, lunar.ErrOutOfRange
+	}
+	if !d.Valid() {
+		return d, lunar.ErrInvalidDate
+	}
+	return d, nil
+}
+
+// anniversaryOrigin 纪念日最初那一天的公历日期，农历纪念日换算为公历
+func anniversaryOrigin(anniversary *model.Anniversary, loc *time.Location) (time.Time, bool) {
+	if anniversary.Calendar == model.AnniversaryCalendarLunar {
+		d, err := parseLunarDate(anniversary.Date, anniversary.IsLeapMonth)
+		if err != nil {
+			return time.Time{}, false
+		}
+		origin, err := lunar.ToSolar(d, loc)
+		return origin, err == nil
+	}
+	origin, err := time.ParseInLocation("2006-01-02", anniversary.Date, loc)
+	return origin, err == nil
+}
+
+// nextOccurrence 纪念日在 today 当天或之后的下一次公历日期，以及届时是第几周年
+func nextOccurrence(anniversary *model.Anniversary, today time.Time) (time.Time, int, bool) {
+	if anniversary.Calendar == model.AnniversaryCalendarLunar {
+		return nextLunarOccurrence(anniversary.Date, anniversary.IsLeapMonth, today)
+	}
+	return nextSolarOccurrence(anniversary.Date, today)
+}
+
+// nextSolarOccurrence 公历纪念日在 today 当天或之后的下一次日期，以及届时是第几周年
+// 说明：2 月 29 日的纪念日在平年按 2 月 28 日计算；日期在未来的纪念日返回其本身，周年为 0
+func nextSolarOccurrence(date string, today time.Time) (time.Time, int, bool) {
+	origin, err := time.ParseInLocation("2006-01-02", date, today.Location())
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	for year := max(today.Year(), origin.Year()); ; year++ {
+		day := origin.Day()
+		if origin.Month() == time.February && day == 29 && !isLeapYear(year) {
+			day = 28
+		}
+		next := time.Date(year, origin.Month(), day, 0, 0, 0, 0, today.Location())
+		if !next.Before(today) {
+			return next, year - origin.Year(), true
+		}
+	}
+}
+
+// nextLunarOccurrence 农历纪念日在 today 当天或之后的下一次公历日期，以及届时是第几周年
+// 说明：闰月的纪念日在没有该闰月的年份按同名的普通月计算；三十日在小月按二十九日计算；
+// 超出农历 2100 年时返回 false
+func nextLunarOccurrence(date string, leapMonth bool, today time.Time) (time.Time, int, bool) {
+	origin, err := parseLunarDate(date, leapMonth)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	current, err := lunar.FromSolar(today)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	// 农历年跨越公历年，从上一个农历年开始查找
+	for year := max(current.Year-1, origin.Year); year <= lunar.MaxYear; year++ {
+		d := lunar.Date{Year: year, Month: origin.Month, Day: origin.Day, IsLeap: origin.IsLeap}
+		if d.IsLeap && lunar.LeapMonth(year) != d.Month {
+			d.IsLeap = false
+		}
+		d.Day = min(d.Day, lunar.MonthDays(year, d.Month, d.IsLeap))
+		next, err := lunar.ToSolar(d, today.Location())
+		if err != nil {
+			return time.Time{}, 0, false
+		}
+		if !next.Before(today) {
+			return next, year - origin.Year, true
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// daysBetween 两个日期相差的天数，不受夏令时影响
+func daysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextLunarOccurrence(t *testing.T) {
+	loc := time.FixedZone("CST", 8*60*60)
+	day := func(s string) time.Time {
+		d, err := time.ParseInLocation("2006-01-02", s, loc)
+		if err != nil {
+			t.Fatalf("parse %s: %v", s, err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name      string
+		date      string
+		leapMonth bool
+		today     string
+		want      string
+		years     int
+		ok        bool
+	}{
+		{name: "闰月当年", date: "2023-02-15", leapMonth: true, today: "2023-04-01", want: "2023-04-05", years: 0, ok: true},
+		{name: "纪念日当天", date: "2023-02-15", leapMonth: true, today: "2023-04-05", want: "2023-04-05", years: 0, ok: true},
+		{name: "次年没有该闰月按普通月计算", date: "2023-02-15", leapMonth: true, today: "2023-04-06", want: "2024-03-24", years: 1, ok: true},
+		{name: "再次出现同一闰月", date: "2004-02-01", leapMonth: true, today: "2023-03-01", want: "2023-03-22", years: 19, ok: true},
+		{name: "三十日在大月", date: "2023-12-30", today: "2024-02-01", want: "2024-02-09", years: 0, ok: true},
+		{name: "三十日在小月按二十九日计算", date: "2023-12-30", today: "2024-03-01", want: "2025-01-28", years: 1, ok: true},
+		{name: "腊月跨公历年", date: "2000-12-01", today: "2024-12-20", want: "2024-12-31", years: 24, ok: true},
+		{name: "已过本农历年的腊月", date: "2000-12-01", today: "2025-01-05", want: "2026-01-19", years: 25, ok: true},
+		{name: "超出农历 2100 年", date: "2100-12-01", today: "2101-02-01", ok: false},
+		{name: "日期不存在", date: "2024-12-30", today: "2025-01-01", ok: false},
+		{name: "闰月不存在", date: "2024-06-01", leapMonth: true, today: "2025-01-01", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, years, ok := nextLunarOccurrence(tt.date, tt.leapMonth, day(tt.today))
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if !next.Equal(day(tt.want)) || next.Location() != loc {
+				t.Fatalf("next = %s, want %s", next, tt.want)
+			}
+			if years != tt.years {
+				t.Fatalf("years = %d, want %d", years, tt.years)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/bookandmusic/love-girl/internal/lunar"
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// anniversaryNotifyHour 每天发送纪念日提醒的最早时间（服务器本地时间）
+const anniversaryNotifyHour = 8
+
+// anniversaryRemindDays 提前提醒的天数，0 表示纪念日当天
+var anniversaryRemindDays = []int{7, 1, 0}
+
+// NotifyUpcoming 纪念日前 7 天、前 1 天和当天提醒全部用户，由后台任务定期调用
+// 说明：每天 anniversaryNotifyHour 点之后才发送，当天已提醒过的纪念日不再重复提醒
+func (s *AnniversaryService) NotifyUpcoming(ctx context.Context) error {
+	now := time.Now()
+	if now.Hour() < anniversaryNotifyHour {
+		return nil
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	anniversaries, err := s.AnniversaryRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	var users []model.User
+	for i := range anniversaries {
+		anniversary := &anniversaries[i]
+		next, years, ok := nextOccurrence(anniversary, today)
+		if !ok {
+			continue
+		}
+		daysLeft := daysBetween(today, next)
+		if !slices.Contains(anniversaryRemindDays, daysLeft) {
+			continue
+		}
+
+		if users == nil {
+			if users, err = s.UserRepo.List(ctx); err != nil {
+				return err
+			}
+		}
+		payload := model.AnniversaryPayload{
+			Title:    anniversary.Title,
+			Date:     next.Format("2006-01-02"),
+			Calendar: anniversary.Calendar,
+			DaysLeft: daysLeft,
+			Years:    years,
+		}
+		if anniversary.Calendar == model.AnniversaryCalendarLunar {
+			if d, err := lunar.FromSolar(next); err == nil {
+				payload.LunarDate = d.String()
+			}
+		}
+		for _, user := range users {
+			sent, err := s.NotificationRepo.ExistsForEntitySince(ctx, user.ID, model.NotificationTypeAnniversary, model.NotificationEntityAnniversary, anniversary.ID, today)
+			if err != nil {
+				s.Log.Error("查询纪念日提醒失败", "error", err, "userID", user.ID, "anniversaryID", anniversary.ID)
+				continue
+			}
+			if sent {
+				continue
+			}
+			// 纪念日提醒由系统发出，发送者记为接收者本人
+			if err := s.NotificationSvc.CreateNotification(ctx, user.ID, user.ID, model.NotificationTypeAnniversary, model.NotificationEntityAnniversary, anniversary.ID, payload); err != nil {
+				s.Log.Error("创建纪念日提醒失败", "error", err, "userID", user.ID, "anniversaryID", anniversary.ID)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/audit"
+	"github.com/bookandmusic/love-girl/internal/auth"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+// apiTokenTouchInterval 最后使用时间的更新间隔，避免每个请求都写库
+const apiTokenTouchInterval = time.Minute
+
+type APITokenService struct {
+	*BaseService
+	APITokenRepo *repo.APITokenRepo
+	UserRepo     *repo.UserRepo
+	Audit        *AuditService
+}
+
+func NewAPITokenService(log *log.Logger, apiTokenRepo *repo.APITokenRepo, userRepo *repo.UserRepo, auditService *AuditService) *APITokenService {
+	return &APITokenService{
+		BaseService:  &BaseService{Log: log},
+		APITokenRepo: apiTokenRepo,
+		UserRepo:     userRepo,
+		Audit:        auditService,
+	}
+}
+
+// APITokenCreateRequest 创建个人访问令牌请求
+type APITokenCreateRequest struct {
+	Name      string  `json:"name" binding:"required,max=64"`
+	Scope     string  `json:"scope" binding:"required,oneof=read upload full"`
+	ExpiresAt *string `json:"expiresAt"` // 可选，格式: "2006-01-02 15:04:05"
+}
+
+// FrontendAPIToken 管理后台的令牌数据结构
+type FrontendAPIToken struct {
+	ID         uint64 `json:"id"`
+	Name       string `json:"name"`
+	Prefix     string `json:"prefix"`
+	Scope      string `json:"scope"`
+	Token      string `json:"token,omitempty"` // 令牌明文，仅创建时返回
+	ExpiresAt  string `json:"expiresAt,omitempty"`
+	LastUsedAt string `json:"lastUsedAt,omitempty"`
+	Revoked    bool   `json:"revoked"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+// generateAPIToken 生成带前缀的随机令牌
+func generateAPIToken() (string, error) {
+	bytes := make([]byte, 20)
+	if _, err := cryptorand.Read(bytes); err != nil {
+		return "", err
+	}
+	return auth.APITokenPrefix + hex.EncodeToString(bytes), nil
+}
+
+func (s *APITokenService) convertToFrontendFormat(token *model.APIToken) *FrontendAPIToken {
+	if token == nil {
+		return nil
+	}
+
+	result := &FrontendAPIToken{
+		ID:        token.ID,
+		Name:      token.Name,
+		Prefix:    token.Prefix,
+		Scope:     token.Scope,
+		Revoked:   token.RevokedAt != nil,
+		CreatedAt: token.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if token.ExpiresAt != nil {
+		result.ExpiresAt = token.ExpiresAt.Format("2006-01-02 15:04:05")
+	}
+	if token.LastUsedAt != nil {
+		result.LastUsedAt = token.LastUsedAt.Format("2006-01-02 15:04:05")
+	}
+	return result
+}
+
+// CreateToken 创建个人访问令牌，明文只在返回值中出现一次
+func (s *APITokenService) CreateToken(ctx context.Context, userID uint64, req *APITokenCreateRequest) (*FrontendAPIToken, error) {
+	plain, err := generateAPIToken()
+	if err != nil {
+		s.Log.Error("生成访问令牌失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	version, err := s.UserRepo.FindTokenVersion(ctx, userID)
+	if err != nil {
+		s.Log.Error("查询用户令牌版本失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	token := &model.APIToken{
+		UserID:       userID,
+		Name:         req.Name,
+		Prefix:       plain[:len(auth.APITokenPrefix)+6],
+		TokenHash:    auth.HashAPIToken(plain),
+		Scope:        req.Scope,
+		TokenVersion: version,
+	}
+
+	if req.ExpiresAt != nil && *req.ExpiresAt != "" {
+		parsedTime, err := time.ParseInLocation("2006-01-02 15:04:05", *req.ExpiresAt, time.Local)
+		if err != nil {
+			s.Log.Error("解析过期时间失败", "error", err, "expiresAt", *req.ExpiresAt)
+			return nil, fmt.Errorf("过期时间格式错误，应为: 2006-01-02 15:04:05")
+		}
+		token.ExpiresAt = &parsedTime
+	}
+
+	if err := s.APITokenRepo.Create(ctx, token); err != nil {
+		s.Log.Error("创建访问令牌失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	s.Audit.Record(ctx, AuditEntry{
+		Action:     audit.ActionAPITokenCreate,
+		ActorID:    userID,
+		TargetType: "api_token",
+		TargetID:   token.ID,
+		After: map[string]any{
+			"name":      token.Name,
+			"prefix":    token.Prefix,
+			"scope":     token.Scope,
+			"expiresAt": req.ExpiresAt,
+		},
+	})
+
+	result := s.convertToFrontendFormat(token)
+	result.Token = plain
+	return result, nil
+}
+
+// ListTokens 获取用户的个人访问令牌列表
+func (s *APITokenService) ListTokens(ctx context.Context, userID uint64) ([]*FrontendAPIToken, error) {
+	tokens, err := s.APITokenRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		s.Log.Error("获取访问令牌列表失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	result := make([]*FrontendAPIToken, len(tokens))
+	for i := range tokens {
+		result[i] = s.convertToFrontendFormat(&tokens[i])
+	}
+	return result, nil
+}
+
+// RevokeToken 撤销用户自己的令牌
+func (s *APITokenService) RevokeToken(ctx context.Context, userID, id uint64) (bool, error) {
+	revoked, err := s.APITokenRepo.Revoke(ctx, id, userID)
+	if err != nil {
+		s.Log.Error("撤销访问令牌失败", "error", err, "id", id, "userID", userID)
+		return false, fmt.Errorf("系统内部错误")
+	}
+	if revoked {
+		s.Audit.Record(ctx, AuditEntry{
+			Action:     audit.ActionAPITokenRevoke,
+			ActorID:    userID,
+			TargetType: "api_token",
+			TargetID:   id,
+		})
+	}
+	return revoked, nil
+}
+
+// VerifyToken 实现 auth.TokenVerifier，校验令牌并记录最后使用时间
+func (s *APITokenService) VerifyToken(ctx context.Context, plain string) (*auth.Claims, error) {
+	token, err := s.APITokenRepo.FindByHash(ctx, auth.HashAPIToken(plain))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errMsg.ErrInvalidToken
+		}
+		s.Log.Error("查询访问令牌失败", "error", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil {
+		return nil, errMsg.ErrInvalidToken
+	}
+	if token.ExpiresAt != nil && now.After(*token.ExpiresAt) {
+		return nil, errMsg.ErrExpiredToken
+	}
+	if err := s.VerifyVersion(ctx, token.UserID, token.TokenVersion); err != nil {
+		return nil, err
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenTouchInterval {
+		if err := s.APITokenRepo.TouchLastUsed(ctx, token.ID, now); err != nil {
+			// 更新失败不影响本次认证
+			s.Log.Warn("更新访问令牌使用时间失败", "error", err, "id", token.ID)
+		}
+	}
+
+	return &auth.Claims{
+		UserID:  token.UserID,
+		Role:    "user",
+		TokenID: token.ID,
+		Scope:   token.Scope,
+		Version: token.TokenVersion,
+	}, nil
+}
+
+// VerifyVersion 实现 auth.TokenVerifier，用户重置密码后此前签发的凭证失效
+func (s *APITokenService) VerifyVersion(ctx context.Context, userID uint64, version int) error {
+	current, err := s.UserRepo.FindTokenVersion(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errMsg.ErrInvalidToken
+		}
+		s.Log.Error("查询用户令牌版本失败", "error", err, "userID", userID)
+		return err
+	}
+	if version != current {
+		return errMsg.ErrInvalidToken
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bookandmusic/love-girl/internal/audit"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+const (
+	// AuditRetentionSettingKey 审计日志保留天数的设置项，0 表示永久保留
+	AuditRetentionSettingKey = "auditRetentionDays"
+	// defaultAuditRetentionDays 未设置时的默认保留天数
+	defaultAuditRetentionDays = 180
+)
+
+type AuditService struct {
+	*BaseService
+	AuditLogRepo *repo.AuditLogRepo
+	UserRepo     *repo.UserRepo
+	SettingRepo  *repo.SettingRepo
+}
+
+func NewAuditService(log *log.Logger, auditLogRepo *repo.AuditLogRepo, userRepo *repo.UserRepo, settingRepo *repo.SettingRepo) *AuditService {
+	return &AuditService{
+		BaseService:  &BaseService{Log: log},
+		AuditLogRepo: auditLogRepo,
+		UserRepo:     userRepo,
+		SettingRepo:  settingRepo,
+	}
+}
+
+// AuditEntry 一条待记录的审计事件
+type AuditEntry struct {
+	Action     string
+	ActorID    uint64 // 为 0 时使用请求上下文中的登录用户
+	ActorName  string // 为空时根据 ActorID 查询用户名
+	TargetType string
+	TargetID   uint64
+	Failed     bool
+	Before     map[string]any
+	After      map[string]any
+}
+
+// FrontendAuditLog 管理后台的审计日志数据结构
+type FrontendAuditLog struct {
+	ID         uint64 `json:"id"`
+	Action     string `json:"action"`
+	ActorID    uint64 `json:"actorId"`
+	ActorName  string `json:"actorName"`
+	TargetType string `json:"targetType"`
+	TargetID   uint64 `json:"targetId"`
+	Success    bool   `json:"success"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"userAgent"`
+	RequestID  string `json:"requestId"`
+	Before     string `json:"before,omitempty"`
+	After      string `json:"after,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+// AuditLogListResponse 审计日志列表响应
+type AuditLogListResponse struct {
+	Logs       []*FrontendAuditLog `json:"logs"`
+	Page       int                 `json:"page"`
+	Size       int                 `json:"size"`
+	Total      int64               `json:"total"`
+	TotalPages int                 `json:"totalPages"`
+}
+
+// AuditQueryParams 审计日志查询参数
+type AuditQueryParams struct {
+	Page    int
+	Size    int
+	Filters []repo.FilterCondition
+}
+
+// Record 记录审计事件
+// 说明：审计写入失败只记录错误日志，不影响业务操作
+func (s *AuditService) Record(ctx context.Context, entry AuditEntry) {
+	meta := audit.MetaFrom(ctx)
+
+	actorID := entry.ActorID
+	if actorID == 0 {
+		actorID = meta.UserID
+	}
+	actorName := entry.ActorName
+	if actorName == "" && actorID != 0 {
+		if user, err := s.UserRepo.FindByID(ctx, actorID); err == nil {
+			actorName = user.Name
+		}
+	}
+
+	before, after := audit.Diff(entry.Before, entry.After)
+	record := &model.AuditLog{
+		Action:     entry.Action,
+		ActorID:    actorID,
+		ActorName:  actorName,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Success:    !entry.Failed,
+		IP:         meta.IP,
+		UserAgent:  meta.UserAgent,
+		RequestID:  meta.RequestID,
+		Before:     audit.Encode(before),
+		After:      audit.Encode(after),
+	}
+	if err := s.AuditLogRepo.Create(ctx, record); err != nil {
+		s.Log.Error("写入审计日志失败", "error", err, "action", entry.Action, "actorID", actorID)
+	}
+}
+
+func (s *AuditService) convertToFrontendFormat(record *model.AuditLog) *FrontendAuditLog {
+	return &FrontendAuditLog{
+		ID:         record.ID,
+		Action:     record.Action,
+		ActorID:    record.ActorID,
+		ActorName:  record.ActorName,
+		TargetType: record.TargetType,
+		TargetID:   record.TargetID,
+		Success:    record.Success,
+		IP:         record.IP,
+		UserAgent:  record.UserAgent,
+		RequestID:  record.RequestID,
+		Before:     record.Before,
+		After:      record.After,
+		CreatedAt:  record.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// ListLogs 分页查询审计日志，按时间倒序
+func (s *AuditService) ListLogs(ctx context.Context, params *AuditQueryParams) (*AuditLogListResponse, error) {
+	opts := []repo.QueryOption{repo.WithOrder("id", true)}
+	if len(params.Filters) > 0 {
+		opts = append(opts, repo.WithConditions(params.Filters...))
+	}
+
+	records, total, err := s.AuditLogRepo.ListLogs(ctx, params.Page, params.Size, opts...)
+	if err != nil {
+		s.Log.Error("获取审计日志失败", "error", err, "page", params.Page, "size", params.Size)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	logs := make([]*FrontendAuditLog, len(records))
+	for i := range records {
+		logs[i] = s.convertToFrontendFormat(&records[i])
+	}
+
+	return &AuditLogListResponse{
+		Logs:       logs,
+		Page:       params.Page,
+		Size:       params.Size,
+		Total:      total,
+		TotalPages: int((total + int64(params.Size) - 1) / int64(params.Size)),
+	}, nil
+}
+
+// retentionDays 读取审计日志保留天数
+func (s *AuditService) retentionDays(ctx context.Context) int {
+	setting, err := s.SettingRepo.GetSettingByKey(ctx, AuditRetentionSettingKey)
+	if err != nil {
+		return defaultAuditRetentionDays
+	}
+	days, err := strconv.Atoi(setting.Value)
+	if err != nil || days < 0 {
+		s.Log.Warn("审计日志保留天数设置无效，使用默认值", "value", setting.Value)
+		return defaultAuditRetentionDays
+	}
+	return days
+}
+
+// PurgeExpired 清理超出保留期限的审计日志，由后台任务定期调用
+func (s *AuditService) PurgeExpired(ctx context.Context) error {
+	days := s.retentionDays(ctx)
+	if days == 0 {
+		return nil
+	}
+
+	deleted, err := s.AuditLogRepo.DeleteBefore(ctx, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		s.Log.Info("已清理过期审计日志", "count", deleted, "retentionDays", days)
+		s.Record(ctx, AuditEntry{
+			Action:    audit.ActionAuditLogsPurged,
+			ActorName: "system",
+			After:     map[string]any{"deleted": deleted, "retentionDays": days},
+		})
+	}
+	return nil
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+type CollectionQueryParams struct {
+	Page    int
+	Size    int
+	SortBy  string
+	Order   string
+	Filters []repo.FilterCondition
+}
+
+// CollectionCoverImage 合集封面图片结构
+type CollectionCoverImage struct {
+	ID           uint64        `json:"id"`
+	CollectionID uint64        `json:"collectionId"`
+	File         *FileResponse `json:"file"`
+}
+
+// CollectionResponse 合集响应结构
+type CollectionResponse struct {
+	ID          uint64                `json:"id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	CoverImage  *CollectionCoverImage `json:"coverImage,omitempty"` // 未设置封面时为合集内第一张可见图片
+	MomentCount int64                 `json:"momentCount"`          // 当前访问者可见的动态数量
+	CreatedAt   string                `json:"createdAt"`
+}
+
+// CollectionListResponse 合集列表响应
+type CollectionListResponse struct {
+	Collections []*CollectionResponse `json:"collections"`
+	Page        int                   `json:"page"`
+	Size        int                   `json:"size"`
+	Total       int64                 `json:"total"`
+	TotalPages  int                   `json:"totalPages"`
+}
+
+// CollectionCreateRequest 创建合集请求
+type CollectionCreateRequest struct {
+	Name         string   `json:"name" binding:"required,max=255"`
+	Description  string   `json:"description" binding:"max=512"`
+	CoverImageID *uint64  `json:"coverImageId"`
+	MomentIDs    []uint64 `json:"momentIds"` // 合集内的动态，按展示顺序
+}
+
+// CollectionUpdateRequest 更新合集请求
+type CollectionUpdateRequest struct {
+	Name         *string `json:"name" binding:"omitempty,max=255"`
+	Description  *string `json:"description" binding:"omitempty,max=512"`
+	CoverImageID *uint64 `json:"coverImageId"` // 为 0 时取消封面，使用合集内第一张可见图片
+}
+
+// CollectionMomentsRequest 设置或追加合集动态请求
+type CollectionMomentsRequest struct {
+	MomentIDs []uint64 `json:"momentIds" binding:"required"`
+}
+
+// CollectionService 动态合集服务
+type CollectionService struct {
+	*BaseService
+	CollectionRepo *repo.CollectionRepo
+	MomentRepo     *repo.MomentRepo
+	MomentService  *MomentService
+	FileService    *FileService
+}
+
+// NewCollectionService 创建合集服务实例
+func NewCollectionService(log *log.Logger, collectionRepo *repo.CollectionRepo, momentRepo *repo.MomentRepo, momentService *MomentService, fileService *FileService) *CollectionService {
+	return &CollectionService{
+		BaseService:    &BaseService{Log: log},
+		CollectionRepo: collectionRepo,
+		MomentRepo:     momentRepo,
+		MomentService:  momentService,
+		FileService:    fileService,
+	}
+}
+
+// convertToResponses 将合集转换为响应格式，动态数量和默认封面按访问者的可见范围计算
+func (s *CollectionService) convertToResponses(c *gin.Context, collections []model.Collection, viewerID uint64) ([]*CollectionResponse, error) {
+	ctx := c.Request.Context()
+	ids := make([]uint64, len(collections))
+	for i := range collections {
+		ids[i] = collections[i].ID
+	}
+	counts, err := s.CollectionRepo.CountVisibleMoments(ctx, ids, viewerID)
+	if err != nil {
+		s.Log.Error("统计合集动态数量失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	responses := make([]*CollectionResponse, len(collections))
+	for i := range collections {
+		collection := &collections[i]
+		response := &CollectionResponse{
+			ID:          collection.ID,
+			Name:        collection.Name,
+			Description: collection.Description,
+			MomentCount: counts[collection.ID],
+			CreatedAt:   collection.CreatedAt.Format("2006-01-02"),
+		}
+
+		cover := collection.CoverImage
+		if cover == nil && response.MomentCount > 0 {
+			if cover, err = s.CollectionRepo.FindDefaultCover(ctx, collection.ID, viewerID); err != nil {
+				s.Log.Error("查询合集默认封面失败", "error", err, "id", collection.ID)
+				return nil, fmt.Errorf("系统内部错误")
+			}
+		}
+		if cover != nil {
+			response.CoverImage = &CollectionCoverImage{
+				ID:           cover.ID,
+				CollectionID: collection.ID,
+				File:         s.FileService.BuildFileResponse(c, cover),
+			}
+		}
+		responses[i] = response
+	}
+	return responses, nil
+}
+
+func (s *CollectionService) convertToResponse(c *gin.Context, collection *model.Collection, viewerID uint64) (*CollectionResponse, error) {
+	responses, err := s.convertToResponses(c, []model.Collection{*collection}, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return responses[0], nil
+}
+
+// findCollection 查询合集
+//
+// 返回：合集不存在时返回 nil
+func (s *CollectionService) findCollection(ctx context.Context, id uint64) (*model.Collection, error) {
+	collection, err := s.CollectionRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		s.Log.Error("查询合集失败", "error", err, "id", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return collection, nil
+}
+
+// checkMoments 校验动态都存在且对操作人可见，返回去重后保持原顺序的动态ID
+func (s *CollectionService) checkMoments(ctx context.Context, momentIDs []uint64, userID uint64) ([]uint64, error) {
+	seen := make(map[uint64]bool, len(momentIDs))
+	unique := make([]uint64, 0, len(momentIDs))
+	for _, id := range momentIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return unique, nil
+	}
+
+	moments, err := s.MomentRepo.List(ctx,
+		repo.WithConditions(repo.FilterCondition{Field: "id", Operator: "in", Value: unique}),
+		repo.WithScopes(repo.MomentVisibleTo(userID)),
+	)
+	if err != nil {
+		s.Log.Error("查询合集动态失败", "error", err, "momentIDs", unique)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	if len(moments) != len(unique) {
+		return nil, errMsg.ErrCollectionMomentNotFound
+	}
+	return unique, nil
+}
+
+// checkCover 校验封面图片存在
+func (s *CollectionService) checkCover(ctx context.Context, fileID uint64) error {
+	if _, err := s.FileService.FileRepo.FindByID(ctx, fileID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errMsg.ErrCollectionCoverNotFound
+		}
+		s.Log.Error("查询合集封面失败", "error", err, "fileID", fileID)
+		return fmt.Errorf("系统内部错误")
+	}
+	return nil
+}
+
+// ListCollections 分页获取合集列表
+func (s *CollectionService) ListCollections(c *gin.Context, params *CollectionQueryParams, viewerID uint64) (*CollectionListResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Size < 1 {
+		params.Size = 10
+	} else if params.Size > 100 {
+		params.Size = 100
+	}
+
+	var opts []repo.QueryOption
+	if len(params.Filters) > 0 {
+		opts = append(opts, repo.WithConditions(params.Filters...))
+	}
+	if params.SortBy != "" {
+		opts = append(opts, repo.WithOrder(params.SortBy, params.Order == "desc"))
+	}
+
+	collections, total, err := s.CollectionRepo.ListCollections(c.Request.Context(), params.Page, params.Size, opts...)
+	if err != nil {
+		s.Log.Error("获取合集列表失败", "error", err, "params", params)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	responses, err := s.convertToResponses(c, collections, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CollectionListResponse{
+		Collections: responses,
+		Page:        params.Page,
+		Size:        params.Size,
+		Total:       total,
+		TotalPages:  int((total + int64(params.Size) - 1) / int64(params.Size)),
+	}, nil
+}
+
+// GetCollection 获取合集详情
+//
+// 返回：合集不存在时返回 nil
+func (s *CollectionService) GetCollection(c *gin.Context, id uint64, viewerID uint64) (*CollectionResponse, error) {
+	collection, err := s.findCollection(c.Request.Context(), id)
+	if err != nil || collection == nil {
+		return nil, err
+	}
+	return s.convertToResponse(c, collection, viewerID)
+}
+
+// ListCollectionMoments 按合集内的顺序分页获取合集中当前用户可见的动态
+//
+// 返回：合集不存在时返回 nil
+func (s *CollectionService) ListCollectionMoments(c *gin.Context, id uint64, params *MomentQueryParams, isLoggedIn bool, userID uint64) (*MomentListResponse, error) {
+	collection, err := s.findCollection(c.Request.Context(), id)
+	if err != nil || collection == nil {
+		return nil, err
+	}
+	// 合集内的动态只按手动顺序展示
+	params.SortBy = ""
+	params.Options = append(params.Options, repo.MomentInCollection(id)...)
+	return s.MomentService.ListMomentsWithQuery(c, params, isLoggedIn, userID)
+}
+
+// CreateCollection 创建合集
+func (s *CollectionService) CreateCollection(c *gin.Context, req *CollectionCreateRequest, userID uint64) (*CollectionResponse, error) {
+	ctx := c.Request.Context()
+	momentIDs, err := s.checkMoments(ctx, req.MomentIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &model.Collection{
+		Name:        req.Name,
+		Description: req.Description,
+		UserID:      userID,
+	}
+	if req.CoverImageID != nil && *req.CoverImageID != 0 {
+		if err := s.checkCover(ctx, *req.CoverImageID); err != nil {
+			return nil, err
+		}
+		collection.CoverImageID = req.CoverImageID
+	}
+
+	if err := s.CollectionRepo.CreateWithMoments(ctx, collection, momentIDs); err != nil {
+		s.Log.Error("创建合集失败", "error", err, "name", req.Name)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	created, err := s.findCollection(ctx, collection.ID)
+	if err != nil || created == nil {
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return s.convertToResponse(c, created, userID)
+}
+
+// UpdateCollection 更新合集名称、描述和封面
+//
+// 返回：合集不存在时返回 nil
+func (s *CollectionService) UpdateCollection(c *gin.Context, id uint64, req *CollectionUpdateRequest, userID uint64) (*CollectionResponse, error) {
+	ctx := c.Request.Context()
+	collection, err := s.findCollection(ctx, id)
+	if err != nil || collection == nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		collection.Name = *req.Name
+	}
+	if req.Description != nil {
+		collection.Description = *req.Description
+	}
+	if req.CoverImageID != nil {
+		// 清空已预加载的封面，否则保存时 gorm 会按旧封面回填外键
+		collection.CoverImage = nil
+		collection.CoverImageID = nil
+		if *req.CoverImageID != 0 {
+			if err := s.checkCover(ctx, *req.CoverImageID); err != nil {
+				return nil, err
+			}
+			coverID := *req.CoverImageID
+			collection.CoverImageID = &coverID
+		}
+	}
+
+	if err := s.CollectionRepo.UpdateWithCover(ctx, collection); err != nil {
+		s.Log.Error("更新合集失败", "error", err, "id", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	updated, err := s.findCollection(ctx, id)
+	if err != nil || updated == nil {
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return s.convertToResponse(c, updated, userID)
+}
+
+// DeleteCollection 删除合集，合集内的动态保留
+//
+// 返回：合集是否存在、错误
+func (s *CollectionService) DeleteCollection(ctx context.Context, id uint64) (bool, error) {
+	collection, err := s.findCollection(ctx, id)
+	if err != nil || collection == nil {
+		return false, err
+	}
+	if err := s.CollectionRepo.DeleteWithMoments(ctx, id); err != nil {
+		s.Log.Error("删除合集失败", "error", err, "id", id)
+		return false, fmt.Errorf("系统内部错误")
+	}
+	return true, nil
+}
+
+// SetCollectionMoments 设置合集内的全部动态，列表顺序即展示顺序
+//
+// 返回：合集不存在时返回 nil
+func (s *CollectionService) SetCollectionMoments(c *gin.Context, id uint64, req *CollectionMomentsRequest, userID uint64) (*CollectionResponse, error) {
+	return s.updateMoments(c, id, req.MomentIDs, userID, s.CollectionRepo.ReplaceMoments)
+}
+
+// AppendCollectionMoments 将动态追加到合集末尾，已在合集中的动态位置不变
+//
+// 返回：合集不存在时返回 nil
+func (s *CollectionService) AppendCollectionMoments(c *gin.Context, id uint64, req *CollectionMomentsRequest, userID uint64) (*CollectionResponse, error) {
+	return s.updateMoments(c, id, req.MomentIDs, userID, s.CollectionRepo.AppendMoments)
+}
+
+func (s *CollectionService) updateMoments(c *gin.Context, id uint64, momentIDs []uint64, userID uint64, apply func(ctx context.Context, collectionID uint64, momentIDs []uint64) error) (*CollectionResponse, error) {
+	ctx := c.Request.Context()
+	collection, err := s.findCollection(ctx, id)
+	if err != nil || collection == nil {
+		return nil, err
+	}
+
+	momentIDs, err = s.checkMoments(ctx, momentIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(ctx, id, momentIDs); err != nil {
+		s.Log.Error("更新合集动态失败", "error", err, "id", id, "momentIDs", momentIDs)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return s.convertToResponse(c, collection, userID)
+}
+
+// RemoveCollectionMoment 从合集中移除动态
+//
+// 返回：合集或动态关联是否存在、错误
+func (s *CollectionService) RemoveCollectionMoment(ctx context.Context, id, momentID uint64) (bool, error) {
+	collection, err := s.findCollection(ctx, id)
+	if err != nil || collection == nil {
+		return false, err
+	}
+	removed, err := s.CollectionRepo.RemoveMoment(ctx, id, momentID)
+	if err != nil {
+		s.Log.Error("从合集移除动态失败", "error", err, "id", id, "momentID", momentID)
+		return false, fmt.Errorf("系统内部错误")
+	}
+	return removed, nil
+}
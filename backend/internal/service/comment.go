@@ -2,16 +2,22 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/bookandmusic/love-girl/internal/config"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
 	"github.com/bookandmusic/love-girl/internal/log"
 	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/moderation"
+	"github.com/bookandmusic/love-girl/internal/pow"
 	"github.com/bookandmusic/love-girl/internal/repo"
 )
 
@@ -22,9 +28,13 @@ type CommentService struct {
 	NotificationRepo *repo.NotificationRepo
 	FileService      *FileService
 	NotificationSvc  *NotificationService
+	Search           *SearchService
+	Moderation       *ModerationService
+	Challenge        *pow.Issuer
+	commentCfg       *config.CommentConfig
 }
 
-func NewCommentService(log *log.Logger, commentRepo *repo.CommentRepo, momentRepo *repo.MomentRepo, notificationRepo *repo.NotificationRepo, fileService *FileService, notificationService *NotificationService) *CommentService {
+func NewCommentService(log *log.Logger, commentRepo *repo.CommentRepo, momentRepo *repo.MomentRepo, notificationRepo *repo.NotificationRepo, fileService *FileService, notificationService *NotificationService, searchService *SearchService, moderationService *ModerationService, challenge *pow.Issuer, appCfg *config.AppConfig) *CommentService {
 	return &CommentService{
 		BaseService:      &BaseService{Log: log},
 		CommentRepo:      commentRepo,
@@ -32,6 +42,10 @@ func NewCommentService(log *log.Logger, commentRepo *repo.CommentRepo, momentRep
 		NotificationRepo: notificationRepo,
 		FileService:      fileService,
 		NotificationSvc:  notificationService,
+		Search:           searchService,
+		Moderation:       moderationService,
+		Challenge:        challenge,
+		commentCfg:       &appCfg.Comment,
 	}
 }
 
@@ -52,7 +66,15 @@ type FrontendComment struct {
 	Author    FrontendAuthor      `json:"author"`
 	Depth     int                 `json:"depth"`
 	CreatedAt string              `json:"createdAt"`
+	Guest     bool                `json:"guest"`              // 是否为访客评论，访客的 author.id 为 0
+	Status    string              `json:"status"`             // 审核状态：pending、approved、spam
+	Edited    bool                `json:"edited"`             // 是否编辑过
+	EditedAt  string              `json:"editedAt,omitempty"` // 最后一次编辑时间
+	Deleted   bool                `json:"deleted"`            // 已删除，仅作为回复的占位保留，内容和作者为空
 	Children  []*FrontendComment  `json:"children,omitempty"`
+	// 仅列表中的一级评论返回：回复总数和加载更多回复的游标，没有更多回复时游标为空
+	ReplyCount    int64  `json:"replyCount,omitempty"`
+	RepliesCursor string `json:"repliesCursor,omitempty"`
 }
 
 type CommentCreateRequest struct {
@@ -63,68 +85,149 @@ type CommentCreateRequest struct {
 	UserID    uint64  `json:"userId"`
 }
 
+// CommentUpdateRequest 编辑评论请求
+type CommentUpdateRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// CommentListResponse 评论列表，按一级评论分页，每条一级评论带前若干条回复
 type CommentListResponse struct {
-	Comments []*FrontendComment `json:"comments"`
-	Total    int64              `json:"total"`
-	Page     int                `json:"page"`
-	Size     int                `json:"size"`
+	Comments   []*FrontendComment `json:"comments"`
+	Total      int64              `json:"total"` // 动态下已通过审核的评论总数（含回复）
+	Size       int                `json:"size"`
+	NextCursor string             `json:"nextCursor,omitempty"` // 下一页的游标，没有更多时为空
+	HasMore    bool               `json:"hasMore"`
 }
 
-func (s *CommentService) convertToFrontendFormat(c *gin.Context, comment *model.Comment, replyToUsersMap map[uint64]*model.User) *FrontendComment {
+// CommentRepliesResponse 评论下的回复，按时间升序平铺，客户端按 parentId 挂到上级评论下
+type CommentRepliesResponse struct {
+	Replies    []*FrontendComment `json:"replies"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+	HasMore    bool               `json:"hasMore"`
+}
+
+// convertToFrontendFormat 转换为前端格式，replyTos 为被回复的评论，用于展示被回复者
+func (s *CommentService) convertToFrontendFormat(c *gin.Context, comment *model.Comment, replyTos map[uint64]*model.Comment) *FrontendComment {
 	if comment == nil {
 		return nil
 	}
 
+	// 占位评论不再展示内容和作者
+	if comment.IsTombstone() {
+		return &FrontendComment{
+			ID:        comment.ID,
+			MomentID:  comment.MomentID,
+			ParentID:  comment.ParentID,
+			ReplyToID: comment.ReplyToID,
+			Depth:     comment.Depth,
+			CreatedAt: comment.CreatedAt.Format("2006-01-02 15:04:05"),
+			Status:    string(comment.Status),
+			Deleted:   true,
+		}
+	}
+
 	author := FrontendAuthor{}
 	if comment.User != nil {
 		author.ID = comment.User.ID
 		author.Name = comment.User.Name
 		author.Avatar = s.FileService.BuildFileResponse(c, comment.User.Avatar)
+	} else if comment.IsGuest() {
+		author.Name = comment.GuestName
 	}
 
 	var replyTo *FrontendAuthorInfo
 	if comment.ReplyToID != nil {
-		if replyToUser, ok := replyToUsersMap[*comment.ReplyToID]; ok && replyToUser != nil {
-			replyTo = &FrontendAuthorInfo{
-				ID:     replyToUser.ID,
-				Name:   replyToUser.Name,
-				Avatar: s.FileService.BuildFileResponse(c, replyToUser.Avatar),
+		if target, ok := replyTos[*comment.ReplyToID]; ok && !target.IsTombstone() {
+			switch {
+			case target.User != nil:
+				replyTo = &FrontendAuthorInfo{
+					ID:     target.User.ID,
+					Name:   target.User.Name,
+					Avatar: s.FileService.BuildFileResponse(c, target.User.Avatar),
+				}
+			case target.IsGuest():
+				replyTo = &FrontendAuthorInfo{Name: target.GuestName}
 			}
 		}
 	}
 
-	return &FrontendComment{
+	result := &FrontendComment{
 		ID:        comment.ID,
 		Content:   comment.Content,
 		MomentID:  comment.MomentID,
 		ParentID:  comment.ParentID,
 		ReplyToID: comment.ReplyToID,
 		ReplyTo:   replyTo,
-		UserID:    comment.UserID,
+		UserID:    comment.AuthorID(),
 		Author:    author,
 		Depth:     comment.Depth,
 		CreatedAt: comment.CreatedAt.Format("2006-01-02 15:04:05"),
+		Guest:     comment.IsGuest(),
+		Status:    string(comment.Status),
+		Edited:    comment.EditedAt != nil,
 	}
+	if comment.EditedAt != nil {
+		result.EditedAt = comment.EditedAt.Format("2006-01-02 15:04:05")
+	}
+	return result
 }
 
 func (s *CommentService) CreateComment(c *gin.Context, req *CommentCreateRequest) (*FrontendComment, error) {
 	ctx := c.Request.Context()
 
+	if _, err := s.findVisibleMoment(ctx, req.MomentID, req.UserID); err != nil {
+		return nil, err
+	}
+
 	comment := &model.Comment{
 		Content:   req.Content,
 		MomentID:  req.MomentID,
 		ParentID:  req.ParentID,
 		ReplyToID: req.ReplyToID,
-		UserID:    req.UserID,
+		UserID:    &req.UserID,
+	}
+	// 登录用户的评论审核通过后直接展示，可疑的进入审核队列
+	switch s.Moderation.Moderate(ctx, comment) {
+	case moderation.VerdictSpam:
+		comment.Status = model.CommentStatusSpam
+	case moderation.VerdictReview:
+		comment.Status = model.CommentStatusPending
+	default:
+		comment.Status = model.CommentStatusApproved
+	}
+	if err := s.insertComment(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	createdComment, err := s.CommentRepo.FindByID(ctx, comment.ID)
+	if err != nil {
+		s.Log.Error("查询刚创建的评论失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	// 未通过审核的评论不通知，也不进入检索
+	if createdComment.Status == model.CommentStatusApproved {
+		s.createNotification(ctx, req, createdComment)
+		s.Search.IndexComment(ctx, createdComment.ID)
+		s.publishCommentCount(ctx, createdComment.MomentID)
 	}
 
-	if req.ParentID != nil {
-		parentComment, err := s.CommentRepo.FindByID(ctx, *req.ParentID)
+	return s.convertToFrontendFormat(c, createdComment, s.findReplyTos(ctx, []model.Comment{*createdComment})), nil
+}
+
+// insertComment 校验父评论和被回复的评论，保存评论并生成层级路径
+// 说明：只能回复同一动态下已通过审核、未删除的评论
+func (s *CommentService) insertComment(ctx context.Context, comment *model.Comment) error {
+	if comment.ParentID != nil {
+		parentComment, err := s.CommentRepo.FindByID(ctx, *comment.ParentID)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, fmt.Errorf("父评论不存在")
+				return fmt.Errorf("父评论不存在")
 			}
-			return nil, fmt.Errorf("系统内部错误")
+			return fmt.Errorf("系统内部错误")
+		}
+		if !replyable(parentComment, comment.MomentID) {
+			return fmt.Errorf("父评论不存在")
 		}
 		comment.Depth = parentComment.Depth + 1
 		comment.Path = parentComment.Path + "/" + strconv.FormatUint(comment.ID, 10)
@@ -132,41 +235,91 @@ func (s *CommentService) CreateComment(c *gin.Context, req *CommentCreateRequest
 		comment.Depth = 0
 	}
 
+	if comment.ReplyToID != nil {
+		replyToComment, err := s.CommentRepo.FindByID(ctx, *comment.ReplyToID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("系统内部错误")
+		}
+		if err != nil || !replyable(replyToComment, comment.MomentID) {
+			return fmt.Errorf("回复的评论不存在")
+		}
+	}
+
 	if err := s.CommentRepo.Create(ctx, comment); err != nil {
 		s.Log.Error("创建评论失败", "error", err)
-		return nil, fmt.Errorf("系统内部错误")
+		return fmt.Errorf("系统内部错误")
 	}
 
 	if comment.ParentID == nil {
 		comment.Path = strconv.FormatUint(comment.ID, 10)
-		if err := s.CommentRepo.Update(ctx, comment); err != nil {
-			s.Log.Error("更新评论路径失败", "error", err)
-		}
 	} else {
 		comment.Path = comment.Path[:strings.LastIndex(comment.Path, "/")+1] + strconv.FormatUint(comment.ID, 10)
-		if err := s.CommentRepo.Update(ctx, comment); err != nil {
-			s.Log.Error("更新评论路径失败", "error", err)
+	}
+	if err := s.CommentRepo.Update(ctx, comment); err != nil {
+		s.Log.Error("更新评论路径失败", "error", err)
+	}
+	return nil
+}
+
+// replyable 评论是否可以被回复：属于指定动态、已通过审核且未删除
+func replyable(comment *model.Comment, momentID uint64) bool {
+	return comment.MomentID == momentID && comment.Status == model.CommentStatusApproved && !comment.IsTombstone()
+}
+
+// findReplyTos 查询评论回复的目标评论，按ID索引
+func (s *CommentService) findReplyTos(ctx context.Context, comments []model.Comment) map[uint64]*model.Comment {
+	replyToIDs := make([]uint64, 0)
+	for _, comment := range comments {
+		if comment.ReplyToID != nil {
+			replyToIDs = append(replyToIDs, *comment.ReplyToID)
 		}
 	}
 
-	createdComment, err := s.CommentRepo.FindByID(ctx, comment.ID)
+	replyTos := make(map[uint64]*model.Comment)
+	if len(replyToIDs) == 0 {
+		return replyTos
+	}
+	replyToComments, err := s.CommentRepo.FindByIDs(ctx, replyToIDs)
 	if err != nil {
-		s.Log.Error("查询刚创建的评论失败", "error", err)
-		return nil, fmt.Errorf("系统内部错误")
+		s.Log.Error("查询回复目标评论失败", "error", err)
+		return replyTos
 	}
-
-	replyToUsersMap := make(map[uint64]*model.User)
-	if req.ReplyToID != nil {
-		replyToComment, err := s.CommentRepo.FindByID(ctx, *req.ReplyToID)
-		if err == nil && replyToComment.User != nil {
-			replyToUsersMap[*req.ReplyToID] = replyToComment.User
-		}
+	for i := range replyToComments {
+		replyTos[replyToComments[i].ID] = &replyToComments[i]
 	}
+	return replyTos
+}
 
-	// 创建通知
-	s.createNotification(ctx, req, createdComment)
+// publishCommentCount 向可以查看动态的在线用户推送最新的评论数
+func (s *CommentService) publishCommentCount(ctx context.Context, momentID uint64) {
+	moment, err := s.MomentRepo.FindByID(ctx, momentID)
+	if err != nil {
+		s.Log.Error("查询动态失败", "error", err, "momentID", momentID)
+		return
+	}
+	count, err := s.CommentRepo.CountByMomentID(ctx, momentID)
+	if err != nil {
+		s.Log.Error("统计评论数量失败", "error", err, "momentID", momentID)
+		return
+	}
+	s.NotificationSvc.PublishMomentEvent(moment, EventCommentCount, CommentCountEvent{MomentID: momentID, CommentCount: count})
+}
 
-	return s.convertToFrontendFormat(c, createdComment, replyToUsersMap), nil
+// findVisibleMoment 查询对用户可见的动态，不存在或不可见时统一返回"动态不存在"
+func (s *CommentService) findVisibleMoment(ctx context.Context, momentID, viewerID uint64) (*model.Moment, error) {
+	moment, err := s.MomentRepo.FindByID(ctx, momentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("动态不存在")
+		}
+		s.Log.Error("查询动态失败", "error", err, "momentID", momentID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	if !moment.VisibleTo(viewerID) {
+		s.Log.Info("动态对当前用户不可见", "momentID", momentID, "viewerID", viewerID)
+		return nil, fmt.Errorf("动态不存在")
+	}
+	return moment, nil
 }
 
 func (s *CommentService) createNotification(ctx context.Context, req *CommentCreateRequest, comment *model.Comment) {
@@ -209,7 +362,7 @@ func (s *CommentService) createNotification(ctx context.Context, req *CommentCre
 				s.Log.Error("找不到被回复的评论", "replyToID", *req.ReplyToID, "error", err)
 				return
 			}
-			receiverID = replyToComment.UserID
+			receiverID = replyToComment.AuthorID()
 			s.Log.Info("回复评论：通知被回复者", "receiverID", receiverID)
 		} else {
 			parentComment, err := s.CommentRepo.FindByID(ctx, *req.ParentID)
@@ -217,29 +370,106 @@ func (s *CommentService) createNotification(ctx context.Context, req *CommentCre
 				s.Log.Error("找不到父评论", "parentID", *req.ParentID, "error", err)
 				return
 			}
-			receiverID = parentComment.UserID
+			receiverID = parentComment.AuthorID()
 			s.Log.Info("回复评论：通知父评论作者", "receiverID", receiverID)
 		}
 	}
 
-	// 不给自己发通知
+	// 不给自己和访客发通知
+	if receiverID == 0 {
+		s.Log.Info("跳过通知：被回复者为访客")
+		return
+	}
 	if receiverID == req.UserID {
 		s.Log.Info("跳过通知：回复自己的评论", "userID", req.UserID)
 		return
 	}
 
-	// 截取评论内容作为通知内容
-	content := req.Content
-	if len(content) > 100 {
-		content = content[:100] + "..."
+	// 接收者看不到该动态时不发通知
+	if !moment.VisibleTo(receiverID) {
+		s.Log.Info("跳过通知：接收者无权查看动态", "receiverID", receiverID, "momentID", req.MomentID)
+		return
+	}
+
+	// 截取评论内容作为通知摘要
+	payload := model.CommentPayload{
+		MomentID: req.MomentID,
+		Excerpt:  summarizeContent(req.Content, notificationExcerptLength),
 	}
 
 	s.Log.Info("创建通知", "receiverID", receiverID, "senderID", req.UserID, "type", notificationType)
-	if err := s.NotificationSvc.CreateNotification(ctx, receiverID, req.UserID, req.MomentID, comment.ID, notificationType, content); err != nil {
+	if err := s.NotificationSvc.CreateNotification(ctx, receiverID, req.UserID, notificationType, model.NotificationEntityComment, comment.ID, payload); err != nil {
 		s.Log.Error("创建通知失败", "error", err)
 	}
 }
 
+// UpdateComment 编辑评论内容，只有评论作者可以编辑
+func (s *CommentService) UpdateComment(c *gin.Context, id uint64, userID uint64, req *CommentUpdateRequest) (*FrontendComment, error) {
+	ctx := c.Request.Context()
+
+	comment, err := s.CommentRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("评论不存在")
+		}
+		s.Log.Error("查询评论失败", "error", err, "id", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	if comment.IsTombstone() {
+		return nil, fmt.Errorf("评论不存在")
+	}
+	if comment.AuthorID() != userID {
+		return nil, fmt.Errorf("无权限编辑此评论")
+	}
+	if _, err := s.findVisibleMoment(ctx, comment.MomentID, userID); err != nil {
+		return nil, err
+	}
+
+	// 内容未变化时不记录编辑
+	if req.Content != comment.Content {
+		previous := comment.Status
+		comment.Content = req.Content
+		// 编辑后的内容重新审核，审核结果只会撤回展示，不会让待审核或垃圾评论通过
+		switch s.Moderation.Moderate(ctx, comment) {
+		case moderation.VerdictSpam:
+			comment.Status = model.CommentStatusSpam
+		case moderation.VerdictReview:
+			if comment.Status == model.CommentStatusApproved {
+				comment.Status = model.CommentStatusPending
+			}
+		}
+		if previous == model.CommentStatusApproved && comment.Status != model.CommentStatusApproved {
+			// 已有回复的评论撤回后回复会失去上级，不接受这次编辑
+			replies, err := s.CommentRepo.CountReplies(ctx, comment)
+			if err != nil {
+				s.Log.Error("查询评论回复失败", "error", err, "id", id)
+				return nil, fmt.Errorf("系统内部错误")
+			}
+			if replies > 0 {
+				return nil, errMsg.ErrCommentEditRejected
+			}
+		}
+
+		now := time.Now()
+		comment.EditedAt = &now
+		if err := s.CommentRepo.UpdateContent(ctx, comment); err != nil {
+			s.Log.Error("编辑评论失败", "error", err, "id", id)
+			return nil, fmt.Errorf("系统内部错误")
+		}
+		s.Search.IndexComment(ctx, id)
+		if comment.Status != previous {
+			s.Log.Info("编辑后的评论未通过审核", "id", id, "from", previous, "to", comment.Status)
+			s.publishCommentCount(ctx, comment.MomentID)
+		}
+	}
+
+	return s.convertToFrontendFormat(c, comment, s.findReplyTos(ctx, []model.Comment{*comment})), nil
+}
+
+// DeleteComment 删除评论，评论作者和动态作者都可以删除
+// 说明：评论下还有回复时保留为占位，回复不受影响
+//
+// 返回：评论是否存在、错误
 func (s *CommentService) DeleteComment(ctx context.Context, id uint64, userID uint64) (bool, error) {
 	comment, err := s.CommentRepo.FindByID(ctx, id)
 	if err != nil {
@@ -250,61 +480,185 @@ func (s *CommentService) DeleteComment(ctx context.Context, id uint64, userID ui
 		s.Log.Error("查询评论失败", "error", err, "id", id)
 		return false, fmt.Errorf("系统内部错误")
 	}
+	if comment.IsTombstone() {
+		return false, nil
+	}
 
-	if comment.UserID != userID {
-		return false, fmt.Errorf("无权限删除此评论")
+	if comment.AuthorID() != userID {
+		// 动态作者可以管理自己动态下的所有评论
+		moment, err := s.MomentRepo.FindByID(ctx, comment.MomentID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.Log.Error("查询动态失败", "error", err, "momentID", comment.MomentID)
+			return false, fmt.Errorf("系统内部错误")
+		}
+		if err != nil || moment.UserID != userID {
+			return false, fmt.Errorf("无权限删除此评论")
+		}
 	}
 
-	if err := s.CommentRepo.Delete(ctx, id); err != nil {
+	tombstoned, err := s.CommentRepo.DeleteOrTombstone(ctx, comment)
+	if err != nil {
 		s.Log.Error("删除评论失败", "error", err, "id", id)
 		return false, fmt.Errorf("系统内部错误")
 	}
+	s.Log.Info("删除评论", "id", id, "operatorID", userID, "authorID", comment.AuthorID(), "tombstoned", tombstoned)
+	// 占位评论和被清理的上级评论需要从索引中移除，按动态重新同步评论索引
+	s.Search.IndexMoment(ctx, comment.MomentID)
+	s.publishCommentCount(ctx, comment.MomentID)
 
 	return true, nil
 }
 
-func (s *CommentService) ListComments(c *gin.Context, momentID uint64, page, size int) (*CommentListResponse, error) {
+// ListComments 获取动态的评论列表，viewerID 为 0 表示未登录访客
+// 说明：
+//   - 按一级评论分页，cursor 为上一页返回的 nextCursor，为空时从第一条开始
+//   - 每条一级评论带前 replies 条回复，更多回复通过 ListReplies 加载
+//   - 回复都已删除或未通过审核的占位评论不再展示
+//
+// 返回：动态不存在或对当前用户不可见时返回 nil
+func (s *CommentService) ListComments(c *gin.Context, momentID uint64, viewerID uint64, cursor string, size, replies int) (*CommentListResponse, error) {
 	ctx := c.Request.Context()
-	comments, total, err := s.CommentRepo.FindByMomentID(ctx, momentID, page, size)
+
+	afterID, err := decodeCommentCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	moment, err := s.MomentRepo.FindByID(ctx, momentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		s.Log.Error("查询动态失败", "error", err, "momentID", momentID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	if !moment.VisibleTo(viewerID) {
+		return nil, nil
+	}
+
+	roots, err := s.CommentRepo.FindRootsByMomentID(ctx, momentID, afterID, size+1)
 	if err != nil {
 		s.Log.Error("获取评论列表失败", "error", err, "momentID", momentID)
 		return nil, fmt.Errorf("系统内部错误")
 	}
+	response := &CommentListResponse{Comments: []*FrontendComment{}, Size: size}
+	if len(roots) > size {
+		roots = roots[:size]
+		response.HasMore = true
+		response.NextCursor = encodeCommentCursor(roots[size-1].ID)
+	}
 
-	replyToIDs := make([]uint64, 0)
-	for _, comment := range comments {
-		if comment.ReplyToID != nil {
-			replyToIDs = append(replyToIDs, *comment.ReplyToID)
-		}
+	total, err := s.CommentRepo.CountByMomentID(ctx, momentID)
+	if err != nil {
+		s.Log.Error("统计评论数量失败", "error", err, "momentID", momentID)
+		return nil, fmt.Errorf("系统内部错误")
 	}
+	response.Total = total
 
-	replyToUsersMap := make(map[uint64]*model.User)
-	if len(replyToIDs) > 0 {
-		replyToComments, err := s.CommentRepo.FindByIDs(ctx, replyToIDs)
+	// 一级评论和各自的回复按顺序放在一起，回复总是排在上级评论之后，便于组装成树
+	var (
+		comments      []model.Comment
+		replyCounts   = make(map[uint64]int64)
+		repliesCursor = make(map[uint64]string)
+	)
+	for _, root := range roots {
+		threadReplies, err := s.CommentRepo.FindThreadReplies(ctx, root.Path, 0, replies+1)
 		if err != nil {
-			s.Log.Error("查询回复目标评论失败", "error", err)
-		} else {
-			for _, rc := range replyToComments {
-				if rc.User != nil {
-					replyToUsersMap[rc.ID] = rc.User
-				}
-			}
+			s.Log.Error("获取评论回复失败", "error", err, "commentID", root.ID)
+			return nil, fmt.Errorf("系统内部错误")
+		}
+		if root.IsTombstone() && len(threadReplies) == 0 {
+			continue
+		}
+		if len(threadReplies) > replies {
+			threadReplies = threadReplies[:replies]
+			repliesCursor[root.ID] = encodeCommentCursor(threadReplies[replies-1].ID)
 		}
+		if replyCounts[root.ID], err = s.CommentRepo.CountThreadReplies(ctx, root.Path); err != nil {
+			s.Log.Error("统计评论回复数量失败", "error", err, "commentID", root.ID)
+			return nil, fmt.Errorf("系统内部错误")
+		}
+		comments = append(comments, root)
+		comments = append(comments, threadReplies...)
 	}
 
+	replyTos := s.findReplyTos(ctx, comments)
 	allComments := make([]*FrontendComment, len(comments))
-	for i, comment := range comments {
-		allComments[i] = s.convertToFrontendFormat(c, &comment, replyToUsersMap)
+	for i := range comments {
+		allComments[i] = s.convertToFrontendFormat(c, &comments[i], replyTos)
 	}
 
-	tree := s.buildCommentTree(allComments)
+	for _, comment := range s.buildCommentTree(allComments) {
+		comment.ReplyCount = replyCounts[comment.ID]
+		comment.RepliesCursor = repliesCursor[comment.ID]
+		response.Comments = append(response.Comments, comment)
+	}
+	return response, nil
+}
+
+// ListReplies 加载评论下更多的各级回复，cursor 为上一次返回的游标
+func (s *CommentService) ListReplies(c *gin.Context, id uint64, viewerID uint64, cursor string, size int) (*CommentRepliesResponse, error) {
+	ctx := c.Request.Context()
 
-	return &CommentListResponse{
-		Comments: tree,
-		Total:    total,
-		Page:     page,
-		Size:     size,
-	}, nil
+	afterID, err := decodeCommentCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.CommentRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("评论不存在")
+		}
+		s.Log.Error("查询评论失败", "error", err, "id", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	if comment.Status != model.CommentStatusApproved {
+		return nil, fmt.Errorf("评论不存在")
+	}
+	if _, err := s.findVisibleMoment(ctx, comment.MomentID, viewerID); err != nil {
+		return nil, fmt.Errorf("评论不存在")
+	}
+
+	replies, err := s.CommentRepo.FindThreadReplies(ctx, comment.Path, afterID, size+1)
+	if err != nil {
+		s.Log.Error("获取评论回复失败", "error", err, "commentID", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	response := &CommentRepliesResponse{}
+	if len(replies) > size {
+		replies = replies[:size]
+		response.HasMore = true
+		response.NextCursor = encodeCommentCursor(replies[size-1].ID)
+	}
+
+	replyTos := s.findReplyTos(ctx, replies)
+	response.Replies = make([]*FrontendComment, len(replies))
+	for i := range replies {
+		response.Replies[i] = s.convertToFrontendFormat(c, &replies[i], replyTos)
+	}
+	return response, nil
+}
+
+// encodeCommentCursor 游标为最后一条评论ID的编码，对客户端不透明
+func encodeCommentCursor(id uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(id, 10)))
+}
+
+// decodeCommentCursor 解析游标，空游标表示从头开始
+func decodeCommentCursor(cursor string) (uint64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errMsg.ErrInvalidCommentCursor
+	}
+	id, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil {
+		return 0, errMsg.ErrInvalidCommentCursor
+	}
+	return id, nil
 }
 
 func (s *CommentService) buildCommentTree(comments []*FrontendComment) []*FrontendComment {
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/moderation"
+	"github.com/bookandmusic/love-girl/internal/pow"
+)
+
+const (
+	// guestNotificationLength 访客评论通知中保留的评论字符数
+	guestNotificationLength = 50
+	// defaultGuestRateLimit 未配置时每个 IP 每小时最多提交的访客评论数
+	defaultGuestRateLimit = 10
+)
+
+// GuestCommentCreateRequest 访客评论请求
+type GuestCommentCreateRequest struct {
+	Content   string  `json:"content" binding:"required,max=2000"`
+	Nickname  string  `json:"nickname" binding:"required,max=32"`
+	Email     string  `json:"email" binding:"omitempty,email,max=255"`
+	ParentID  *uint64 `json:"parentId"`
+	ReplyToID *uint64 `json:"replyToId"`
+	Challenge string  `json:"challenge" binding:"required"` // GET /comments/challenge 返回的题目
+	Solution  string  `json:"solution" binding:"required"`  // 题目的解答
+	MomentID  uint64  `json:"-"`
+	IP        string  `json:"-"`
+}
+
+// GuestEnabled 是否允许访客评论
+func (s *CommentService) GuestEnabled() bool {
+	return s.commentCfg.GuestEnabled
+}
+
+// GuestRateLimit 每个 IP 每小时最多提交的访客评论数
+func (s *CommentService) GuestRateLimit() int {
+	if s.commentCfg.GuestRateLimit <= 0 {
+		return defaultGuestRateLimit
+	}
+	return s.commentCfg.GuestRateLimit
+}
+
+// IssueGuestChallenge 签发访客评论的工作量证明题目
+func (s *CommentService) IssueGuestChallenge() (*pow.Challenge, error) {
+	if !s.GuestEnabled() {
+		return nil, errMsg.ErrGuestCommentDisabled
+	}
+	challenge, err := s.Challenge.Issue()
+	if err != nil {
+		s.Log.Error("签发访客评论题目失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return challenge, nil
+}
+
+// CreateGuestComment 创建访客评论
+// 说明：
+//   - 只能评论已发布的公开动态，需要先完成工作量证明
+//   - 评论进入审核队列，通过审核前不会展示，并通知动态作者审核
+//   - 内容审核判定为垃圾的评论直接标记为垃圾评论
+func (s *CommentService) CreateGuestComment(c *gin.Context, req *GuestCommentCreateRequest) (*FrontendComment, error) {
+	ctx := c.Request.Context()
+
+	if !s.GuestEnabled() {
+		return nil, errMsg.ErrGuestCommentDisabled
+	}
+	if err := s.Challenge.Verify(req.Challenge, req.Solution); err != nil {
+		s.Log.Info("访客评论验证失败", "error", err, "ip", req.IP)
+		return nil, errMsg.ErrGuestChallengeFailed
+	}
+
+	content := strings.TrimSpace(req.Content)
+	nickname := strings.TrimSpace(req.Nickname)
+	if content == "" || nickname == "" {
+		return nil, fmt.Errorf("评论内容和昵称不能为空")
+	}
+
+	moment, err := s.findVisibleMoment(ctx, req.MomentID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &model.Comment{
+		Content:    content,
+		MomentID:   req.MomentID,
+		ParentID:   req.ParentID,
+		ReplyToID:  req.ReplyToID,
+		Status:     model.CommentStatusPending,
+		GuestName:  nickname,
+		GuestEmail: strings.TrimSpace(req.Email),
+		GuestIP:    req.IP,
+	}
+	// 判定为垃圾的访客评论直接标记，不进入待审核队列，也不通知动态作者
+	if s.Moderation.Moderate(ctx, comment) == moderation.VerdictSpam {
+		comment.Status = model.CommentStatusSpam
+	}
+	if err := s.insertComment(ctx, comment); err != nil {
+		return nil, err
+	}
+	if comment.Status == model.CommentStatusSpam {
+		s.Log.Info("访客评论判定为垃圾评论", "commentID", comment.ID, "momentID", req.MomentID, "ip", req.IP)
+		return s.convertToFrontendFormat(c, comment, s.findReplyTos(ctx, []model.Comment{*comment})), nil
+	}
+	s.Log.Info("访客评论待审核", "commentID", comment.ID, "momentID", req.MomentID, "ip", req.IP)
+
+	// 访客没有账号，通知的发送者记为动态作者本人
+	payload := model.CommentPayload{
+		MomentID:  moment.ID,
+		Excerpt:   summarizeContent(content, guestNotificationLength),
+		GuestName: nickname,
+	}
+	if err := s.NotificationSvc.CreateNotification(ctx, moment.UserID, moment.UserID, model.NotificationTypeGuest, model.NotificationEntityComment, comment.ID, payload); err != nil {
+		s.Log.Error("创建访客评论通知失败", "error", err, "commentID", comment.ID)
+	}
+
+	return s.convertToFrontendFormat(c, comment, s.findReplyTos(ctx, []model.Comment{*comment})), nil
+}
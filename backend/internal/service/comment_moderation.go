@@ -0,0 +1,151 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+// CommentQueryParams 审核队列查询参数
+type CommentQueryParams struct {
+	Page    int
+	Size    int
+	SortBy  string
+	Order   string
+	Filters []repo.FilterCondition
+}
+
+// ModerationComment 审核队列中的评论，额外返回访客的联系方式和内容审核结果
+type ModerationComment struct {
+	*FrontendComment
+	GuestEmail       string  `json:"guestEmail,omitempty"`
+	GuestIP          string  `json:"guestIp,omitempty"`
+	Verdict          string  `json:"verdict,omitempty"`          // 内容审核结论：pass、review、spam
+	Score            float64 `json:"score"`                      // 内容审核风险分，0 到 1
+	ModerationReason string  `json:"moderationReason,omitempty"` // 内容审核原因
+}
+
+// ModerationListResponse 审核队列响应
+type ModerationListResponse struct {
+	Comments   []*ModerationComment `json:"comments"`
+	Page       int                  `json:"page"`
+	Size       int                  `json:"size"`
+	Total      int64                `json:"total"`
+	TotalPages int                  `json:"totalPages"`
+}
+
+// CommentStatusRequest 修改评论审核状态请求
+type CommentStatusRequest struct {
+	Status model.CommentStatus `json:"status" binding:"required,oneof=pending approved spam"`
+}
+
+// ListModerationQueue 获取审核队列，只包含当前用户可见动态下的评论
+// 说明：未指定 status 过滤条件时只返回待审核的评论
+func (s *CommentService) ListModerationQueue(c *gin.Context, params *CommentQueryParams, viewerID uint64) (*ModerationListResponse, error) {
+	ctx := c.Request.Context()
+
+	hasStatus := false
+	var opts []repo.QueryOption
+	for _, filter := range params.Filters {
+		hasStatus = hasStatus || filter.Field == "status"
+		opts = append(opts, repo.WithConditions(filter))
+	}
+	if !hasStatus {
+		opts = append(opts, repo.WithConditions(repo.FilterCondition{
+			Field: "status", Operator: "eq", Value: model.CommentStatusPending,
+		}))
+	}
+	sortBy := params.SortBy
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	opts = append(opts, repo.WithOrder(sortBy, params.Order != "asc"))
+
+	comments, total, err := s.CommentRepo.ListForModeration(ctx, viewerID, params.Page, params.Size, opts...)
+	if err != nil {
+		s.Log.Error("获取审核队列失败", "error", err, "viewerID", viewerID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	replyTos := s.findReplyTos(ctx, comments)
+	items := make([]*ModerationComment, len(comments))
+	for i := range comments {
+		items[i] = &ModerationComment{
+			FrontendComment:  s.convertToFrontendFormat(c, &comments[i], replyTos),
+			GuestEmail:       comments[i].GuestEmail,
+			GuestIP:          comments[i].GuestIP,
+			Verdict:          comments[i].ModerationVerdict,
+			Score:            comments[i].ModerationScore,
+			ModerationReason: comments[i].ModerationReason,
+		}
+	}
+
+	return &ModerationListResponse{
+		Comments:   items,
+		Page:       params.Page,
+		Size:       params.Size,
+		Total:      total,
+		TotalPages: int((total + int64(params.Size) - 1) / int64(params.Size)),
+	}, nil
+}
+
+// SetCommentStatus 修改评论审核状态，只能审核当前用户可见动态下的评论
+// 说明：已有回复的评论不能撤回审核，否则回复会失去上级，需要时直接删除
+func (s *CommentService) SetCommentStatus(c *gin.Context, id uint64, viewerID uint64, status model.CommentStatus) (*FrontendComment, error) {
+	ctx := c.Request.Context()
+
+	comment, err := s.CommentRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("评论不存在")
+		}
+		s.Log.Error("查询评论失败", "error", err, "id", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	if comment.IsTombstone() {
+		return nil, fmt.Errorf("评论不存在")
+	}
+	if _, err := s.findVisibleMoment(ctx, comment.MomentID, viewerID); err != nil {
+		return nil, fmt.Errorf("评论不存在")
+	}
+
+	if comment.Status != status {
+		if status != model.CommentStatusApproved {
+			replies, err := s.CommentRepo.CountReplies(ctx, comment)
+			if err != nil {
+				s.Log.Error("查询评论回复失败", "error", err, "id", id)
+				return nil, fmt.Errorf("系统内部错误")
+			}
+			if replies > 0 {
+				return nil, errMsg.ErrCommentHasReplies
+			}
+		}
+		now := time.Now()
+		if err := s.CommentRepo.SetStatus(ctx, id, status, now); err != nil {
+			s.Log.Error("修改评论审核状态失败", "error", err, "id", id)
+			return nil, fmt.Errorf("系统内部错误")
+		}
+		s.Log.Info("评论审核", "id", id, "from", comment.Status, "to", status, "operatorID", viewerID)
+		previous := comment.Status
+		comment.Status = status
+		comment.ReviewedAt = &now
+		s.Search.IndexComment(ctx, id)
+		if previous == model.CommentStatusApproved || status == model.CommentStatusApproved {
+			s.publishCommentCount(ctx, comment.MomentID)
+		}
+
+		// 垃圾评论样本有变化时重新训练分类器
+		if previous == model.CommentStatusSpam || status == model.CommentStatusSpam {
+			s.Moderation.Retrain(ctx)
+		}
+	}
+
+	return s.convertToFrontendFormat(c, comment, s.findReplyTos(ctx, []model.Comment{*comment})), nil
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/config"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+const (
+	// feedEntryLimit 订阅源最多包含的动态数量
+	feedEntryLimit = 20
+	// feedTitleLength 条目标题最多保留的字符数
+	feedTitleLength = 40
+	// feedMomentPath 前台动态页面路径，条目链接指向该页面中的对应动态
+	feedMomentPath = "/moments"
+)
+
+// Feed 订阅源，与具体格式（Atom、RSS、JSON Feed）无关
+type Feed struct {
+	Title       string
+	Description string
+	SiteURL     string // 站点首页地址
+	FeedURL     string // 当前订阅源地址
+	Updated     time.Time
+	Entries     []*FeedEntry
+}
+
+// FeedEntry 订阅源中的一条动态
+type FeedEntry struct {
+	ID          string // 全局唯一且不变的条目标识
+	URL         string
+	Title       string
+	ContentText string
+	ContentHTML string // 正文和图片，已转义
+	Author      string
+	Tags        []string
+	Images      []*FileResponse
+	Published   time.Time
+	Updated     time.Time
+}
+
+// FeedService 订阅源服务，根据公开动态生成 Atom、RSS 和 JSON Feed
+type FeedService struct {
+	*BaseService
+	MomentRepo  *repo.MomentRepo
+	SettingRepo *repo.SettingRepo
+	TagRepo     *repo.TagRepo
+	FileService *FileService
+	appCfg      *config.AppConfig
+}
+
+// NewFeedService 创建订阅源服务实例
+func NewFeedService(log *log.Logger, momentRepo *repo.MomentRepo, settingRepo *repo.SettingRepo, tagRepo *repo.TagRepo, fileService *FileService, appCfg *config.AppConfig) *FeedService {
+	return &FeedService{
+		BaseService: &BaseService{Log: log},
+		MomentRepo:  momentRepo,
+		SettingRepo: settingRepo,
+		TagRepo:     tagRepo,
+		FileService: fileService,
+		appCfg:      appCfg,
+	}
+}
+
+// BuildFeed 生成最近发布的公开动态订阅源
+// 说明：只包含已发布的公开动态，与未登录访客看到的动态列表一致；
+// Updated 取动态和站点设置的最后修改时间，用于条件请求
+func (s *FeedService) BuildFeed(c *gin.Context) (*Feed, error) {
+	ctx := c.Request.Context()
+
+	moments, _, err := s.MomentRepo.ListMomentsWithOpts(ctx, 1, feedEntryLimit,
+		repo.WithScopes(repo.MomentVisibleTo(0)),
+		repo.WithOrder("created_at", true),
+	)
+	if err != nil {
+		s.Log.Error("查询订阅源动态失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	siteURL := s.siteURL(c)
+	feed := &Feed{
+		SiteURL: siteURL,
+		FeedURL: siteURL + c.Request.URL.Path,
+		Entries: make([]*FeedEntry, 0, len(moments)),
+	}
+	feed.Title, feed.Description = s.siteInfo(ctx, &feed.Updated)
+
+	for i := range moments {
+		entry := s.buildEntry(c, siteURL, &moments[i])
+		feed.Entries = append(feed.Entries, entry)
+		if entry.Updated.After(feed.Updated) {
+			feed.Updated = entry.Updated
+		}
+	}
+	if feed.Updated.IsZero() {
+		feed.Updated = time.Now()
+	}
+	return feed, nil
+}
+
+// siteInfo 读取站点标题和描述，并将设置的修改时间合并到 updated
+func (s *FeedService) siteInfo(ctx context.Context, updated *time.Time) (title, description string) {
+	title = s.appCfg.App.Name
+	if setting, err := s.SettingRepo.GetSettingByKey(ctx, "siteTitle"); err == nil {
+		if setting.Value != "" {
+			title = setting.Value
+		}
+		if setting.UpdatedAt.After(*updated) {
+			*updated = setting.UpdatedAt
+		}
+	}
+	if setting, err := s.SettingRepo.GetSettingByKey(ctx, "siteDescription"); err == nil {
+		description = setting.Value
+		if setting.UpdatedAt.After(*updated) {
+			*updated = setting.UpdatedAt
+		}
+	}
+	return title, description
+}
+
+// siteURL 获取订阅源中链接使用的站点地址，优先使用配置的站点公开地址
+func (s *FeedService) siteURL(c *gin.Context) string {
+	if s.appCfg.Mail.SiteURL != "" {
+		return strings.TrimRight(s.appCfg.Mail.SiteURL, "/")
+	}
+	return s.FileService.getDynamicBaseURL(c)
+}
+
+// buildEntry 将动态转换为订阅源条目
+func (s *FeedService) buildEntry(c *gin.Context, siteURL string, moment *model.Moment) *FeedEntry {
+	entry := &FeedEntry{
+		ID:          fmt.Sprintf("%s%s/%d", siteURL, feedMomentPath, moment.ID),
+		URL:         fmt.Sprintf("%s%s#moment-%d", siteURL, feedMomentPath, moment.ID),
+		Title:       feedEntryTitle(moment),
+		ContentText: moment.Content,
+		Published:   moment.CreatedAt,
+		Updated:     moment.UpdatedAt,
+	}
+	if moment.User != nil {
+		entry.Author = moment.User.Name
+	}
+	if entry.Updated.Before(entry.Published) {
+		entry.Updated = entry.Published
+	}
+
+	tags, err := s.TagRepo.FindNamesByMomentID(c.Request.Context(), moment.ID)
+	if err != nil {
+		s.Log.Warn("查询动态标签失败", "error", err, "momentID", moment.ID)
+	}
+	entry.Tags = tags
+
+	var content strings.Builder
+	if moment.Content != "" {
+		content.WriteString("<p>")
+		content.WriteString(strings.ReplaceAll(html.EscapeString(moment.Content), "\n", "<br>"))
+		content.WriteString("</p>")
+	}
+	for _, ef := range moment.EntityFiles {
+		if ef.File == nil {
+			continue
+		}
+		image := s.FileService.BuildFileResponse(c, ef.File)
+		entry.Images = append(entry.Images, image)
+		fmt.Fprintf(&content, `<p><img src="%s" alt=""></p>`, html.EscapeString(image.URL))
+	}
+	entry.ContentHTML = content.String()
+	return entry
+}
+
+// feedEntryTitle 取动态内容的第一行作为条目标题，过长时截断；没有文字时使用发布日期
+func feedEntryTitle(moment *model.Moment) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(moment.Content), "\n")
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return moment.CreatedAt.Format("2006-01-02 15:04")
+	}
+	if utf8.RuneCountInString(line) <= feedTitleLength {
+		return line
+	}
+	return string([]rune(line)[:feedTitleLength]) + "…"
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// atomFeed Atom 1.0 (RFC 4287)
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+// rssFeed RSS 2.0
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	XmlnsAtom string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssAtomLink struct {
+	XMLName xml.Name `xml:"atom:link"`
+	Href    string   `xml:"href,attr"`
+	Rel     string   `xml:"rel,attr"`
+	Type    string   `xml:"type,attr"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	LastBuildDate string      `xml:"lastBuildDate"`
+	AtomLink      rssAtomLink `xml:"atom:link"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+// jsonFeed JSON Feed 1.1
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	SizeInBytes int64  `json:"size_in_bytes,omitempty"`
+}
+
+type jsonFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Image         string               `json:"image,omitempty"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Authors       []jsonFeedAuthor     `json:"authors,omitempty"`
+	Tags          []string             `json:"tags,omitempty"`
+	Attachments   []jsonFeedAttachment `json:"attachments,omitempty"`
+}
+
+// Atom 渲染为 Atom 1.0 文档
+func (f *Feed) Atom() ([]byte, error) {
+	doc := atomFeed{
+		Xmlns:    "http://www.w3.org/2005/Atom",
+		Title:    f.Title,
+		Subtitle: f.Description,
+		ID:       f.FeedURL,
+		Updated:  f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.FeedURL},
+			{Rel: "alternate", Type: "text/html", Href: f.SiteURL},
+		},
+		Entries: make([]atomEntry, 0, len(f.Entries)),
+	}
+	for _, e := range f.Entries {
+		entry := atomEntry{
+			Title:     e.Title,
+			ID:        e.ID,
+			Published: e.Published.UTC().Format(time.RFC3339),
+			Updated:   e.Updated.UTC().Format(time.RFC3339),
+			Links:     []atomLink{{Rel: "alternate", Type: "text/html", Href: e.URL}},
+			Content:   atomContent{Type: "html", Body: e.ContentHTML},
+		}
+		if e.Author != "" {
+			entry.Author = &atomPerson{Name: e.Author}
+		}
+		for _, tag := range e.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return marshalFeedXML(doc)
+}
+
+// RSS 渲染为 RSS 2.0 文档
+func (f *Feed) RSS() ([]byte, error) {
+	doc := rssFeed{
+		Version:   "2.0",
+		XmlnsAtom: "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.SiteURL,
+			Description:   f.Description,
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+			AtomLink:      rssAtomLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+			Items:         make([]rssItem, 0, len(f.Entries)),
+		},
+	}
+	for _, e := range f.Entries {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.URL,
+			GUID:        rssGUID{IsPermaLink: false, Value: e.ID},
+			PubDate:     e.Published.UTC().Format(time.RFC1123Z),
+			Categories:  e.Tags,
+			Description: e.ContentHTML,
+		})
+	}
+	return marshalFeedXML(doc)
+}
+
+// JSON 渲染为 JSON Feed 1.1 文档
+func (f *Feed) JSON() ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.SiteURL,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Items:       make([]jsonFeedItem, 0, len(f.Entries)),
+	}
+	for _, e := range f.Entries {
+		item := jsonFeedItem{
+			ID:            e.ID,
+			URL:           e.URL,
+			Title:         e.Title,
+			ContentHTML:   e.ContentHTML,
+			ContentText:   e.ContentText,
+			DatePublished: e.Published.Format(time.RFC3339),
+			DateModified:  e.Updated.Format(time.RFC3339),
+			Tags:          e.Tags,
+		}
+		if e.Author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: e.Author}}
+		}
+		for i, image := range e.Images {
+			if i == 0 {
+				item.Image = image.URL
+			}
+			item.Attachments = append(item.Attachments, jsonFeedAttachment{
+				URL:         image.URL,
+				MimeType:    image.MimeType,
+				SizeInBytes: image.Size,
+			})
+		}
+		doc.Items = append(doc.Items, item)
+	}
+	return json.Marshal(doc)
+}
+
+func marshalFeedXML(doc any) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
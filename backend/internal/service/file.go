@@ -1,6 +1,7 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -16,6 +17,7 @@ import (
 	"github.com/bookandmusic/love-girl/internal/model"
 	"github.com/bookandmusic/love-girl/internal/repo"
 	"github.com/bookandmusic/love-girl/internal/storage"
+	"github.com/bookandmusic/love-girl/internal/utils"
 )
 
 type FileService struct {
@@ -54,6 +56,21 @@ func (s *FileService) SaveFile(ctx context.Context, filename, path, mimeType, ha
 	if path != "" {
 		fullPath = fmt.Sprintf("%s/%s", path, uniqueFileName)
 	}
+	// 图片先读取文件头解析 EXIF 拍摄时间，再与剩余部分拼接写入存储
+	var takenAt *time.Time
+	if strings.HasPrefix(mimeType, "image/") {
+		head := make([]byte, utils.ExifHeadSize)
+		n, readErr := io.ReadFull(r, head)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			s.Log.Error("读取上传文件失败", "filename", filename, "error", readErr)
+			return nil, fmt.Errorf("系统内部错误")
+		}
+		head = head[:n]
+		if t, ok := utils.ExifTakenAt(head); ok {
+			takenAt = &t
+		}
+		r = io.MultiReader(bytes.NewReader(head), r)
+	}
 	err = s.Storage.Save(ctx, fullPath, r)
 	if err != nil {
 		s.Log.Error("上传文件失败", "filename", filename, "error", err)
@@ -66,6 +83,7 @@ func (s *FileService) SaveFile(ctx context.Context, filename, path, mimeType, ha
 		Size:         size,
 		MimeType:     mimeType,
 		Hash:         hash,
+		TakenAt:      takenAt,
 	}
 	err = s.FileRepo.BaseRepo.Create(ctx, file)
 	if err != nil {
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+const (
+	// memoryLookbackYears 回忆最多回溯的年数
+	memoryLookbackYears = 50
+	// memoryMomentLimit、memoryPhotoLimit 每天最多返回的动态和照片数量
+	memoryMomentLimit = 50
+	memoryPhotoLimit  = 100
+	// memoryNotifyHour 每天发送回忆通知的最早时间（服务器本地时间）
+	memoryNotifyHour = 8
+)
+
+// MemoryYear 某一年的回忆
+type MemoryYear struct {
+	Year          int                    `json:"year"`
+	YearsAgo      int                    `json:"yearsAgo"`
+	Moments       []*FrontendMoment      `json:"moments"`
+	Photos        []*AlbumPhoto          `json:"photos"`
+	Places        []*PlaceResponse       `json:"places"`
+	Anniversaries []*FrontendAnniversary `json:"anniversaries"`
+}
+
+// MemoryResponse 那年今日响应，按年份倒序分组
+type MemoryResponse struct {
+	Date  string        `json:"date"` // MM-DD
+	Years []*MemoryYear `json:"years"`
+	Total int           `json:"total"`
+}
+
+// memories 往年同一天的原始数据
+type memories struct {
+	moments       []model.Moment
+	photos        []repo.MemoryPhoto
+	places        []model.Place
+	anniversaries []model.Anniversary
+}
+
+func (m *memories) total() int {
+	return len(m.moments) + len(m.photos) + len(m.places) + len(m.anniversaries)
+}
+
+// MemoryService 那年今日服务，汇总往年同一天的动态、相册照片、去过的地点和纪念日
+type MemoryService struct {
+	*BaseService
+	MomentRepo       *repo.MomentRepo
+	AlbumRepo        *repo.AlbumRepo
+	PlaceRepo        *repo.PlaceRepo
+	AnniversaryRepo  *repo.AnniversaryRepo
+	UserRepo         *repo.UserRepo
+	NotificationRepo *repo.NotificationRepo
+	MomentService    *MomentService
+	AlbumService     *AlbumService
+	PlaceService     *PlaceService
+	AnniversarySvc   *AnniversaryService
+	NotificationSvc  *NotificationService
+}
+
+// NewMemoryService 创建那年今日服务实例
+func NewMemoryService(log *log.Logger, momentRepo *repo.MomentRepo, albumRepo *repo.AlbumRepo, placeRepo *repo.PlaceRepo, anniversaryRepo *repo.AnniversaryRepo, userRepo *repo.UserRepo, notificationRepo *repo.NotificationRepo, momentService *MomentService, albumService *AlbumService, placeService *PlaceService, anniversaryService *AnniversaryService, notificationService *NotificationService) *MemoryService {
+	return &MemoryService{
+		BaseService:      &BaseService{Log: log},
+		MomentRepo:       momentRepo,
+		AlbumRepo:        albumRepo,
+		PlaceRepo:        placeRepo,
+		AnniversaryRepo:  anniversaryRepo,
+		UserRepo:         userRepo,
+		NotificationRepo: notificationRepo,
+		MomentService:    momentService,
+		AlbumService:     albumService,
+		PlaceService:     placeService,
+		AnniversarySvc:   anniversaryService,
+		NotificationSvc:  notificationService,
+	}
+}
+
+// collect 查询 month 月 day 日在往年的回忆，动态按 viewerID 的可见范围过滤
+func (s *MemoryService) collect(ctx context.Context, now time.Time, month time.Month, day int, viewerID uint64) (*memories, error) {
+	ranges := repo.SameDayInPreviousYears(now.Year(), month, day, now.Year()-memoryLookbackYears, now.Location())
+
+	var (
+		result memories
+		err    error
+	)
+	if result.moments, err = s.MomentRepo.ListMemoryMoments(ctx, viewerID, ranges, memoryMomentLimit); err != nil {
+		s.Log.Error("查询往年动态失败", "error", err, "viewerID", viewerID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	if result.photos, err = s.AlbumRepo.ListMemoryPhotos(ctx, ranges, memoryPhotoLimit); err != nil {
+		s.Log.Error("查询往年照片失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	if result.places, err = s.PlaceRepo.ListByDates(ctx, ranges); err != nil {
+		s.Log.Error("查询往年地点失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	if result.anniversaries, err = s.AnniversaryRepo.ListByDates(ctx, ranges); err != nil {
+		s.Log.Error("查询往年纪念日失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	lunarAnniversaries, err := s.AnniversaryRepo.ListLunar(ctx)
+	if err != nil {
+		s.Log.Error("查询农历纪念日失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	for i := range lunarAnniversaries {
+		origin, ok := anniversaryOrigin(&lunarAnniversaries[i], now.Location())
+		if ok && slices.ContainsFunc(ranges, func(r repo.DayRange) bool { return r.Contains(origin) }) {
+			result.anniversaries = append(result.anniversaries, lunarAnniversaries[i])
+		}
+	}
+	return &result, nil
+}
+
+// GetMemories 获取往年 month 月 day 日的回忆，按年份倒序分组，没有回忆的年份不返回
+func (s *MemoryService) GetMemories(c *gin.Context, month time.Month, day int, userID uint64) (*MemoryResponse, error) {
+	ctx := c.Request.Context()
+	now := time.Now()
+	found, err := s.collect(ctx, now, month, day, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	years := make(map[int]*MemoryYear)
+	yearOf := func(year int) *MemoryYear {
+		if y, ok := years[year]; ok {
+			return y
+		}
+		y := &MemoryYear{
+			Year:          year,
+			YearsAgo:      now.Year() - year,
+			Moments:       []*FrontendMoment{},
+			Photos:        []*AlbumPhoto{},
+			Places:        []*PlaceResponse{},
+			Anniversaries: []*FrontendAnniversary{},
+		}
+		years[year] = y
+		return y
+	}
+
+	var moments []*FrontendMoment
+	for i := range found.moments {
+		moment := s.MomentService.convertToFrontendFormat(c, &found.moments[i])
+		y := yearOf(found.moments[i].CreatedAt.In(now.Location()).Year())
+		y.Moments = append(y.Moments, moment)
+		moments = append(moments, moment)
+	}
+	s.MomentService.fillMyReactions(ctx, moments, NewUserReactionActor(userID))
+
+	for i := range found.photos {
+		photo := &found.photos[i]
+		takenAt := photo.CreatedAt
+		if photo.TakenAt != nil {
+			takenAt = *photo.TakenAt
+		}
+		y := yearOf(takenAt.In(now.Location()).Year())
+		y.Photos = append(y.Photos, s.AlbumService.convertToAlbumPhoto(c, &photo.File, photo.AlbumID))
+	}
+	for i := range found.places {
+		y := yearOf(dateYear(found.places[i].Date))
+		y.Places = append(y.Places, s.PlaceService.convertToResponse(c, &found.places[i]))
+	}
+	for i := range found.anniversaries {
+		// 农历纪念日按换算后的公历年份分组
+		origin, _ := anniversaryOrigin(&found.anniversaries[i], now.Location())
+		y := yearOf(origin.Year())
+		y.Anniversaries = append(y.Anniversaries, s.AnniversarySvc.convertToFrontendFormat(&found.anniversaries[i]))
+	}
+
+	result := &MemoryResponse{
+		Date:  fmt.Sprintf("%02d-%02d", int(month), day),
+		Years: make([]*MemoryYear, 0, len(years)),
+		Total: found.total(),
+	}
+	for _, y := range years {
+		result.Years = append(result.Years, y)
+	}
+	slices.SortFunc(result.Years, func(a, b *MemoryYear) int { return b.Year - a.Year })
+	return result, nil
+}
+
+// dateYear 解析 YYYY-MM-DD 格式日期的年份
+func dateYear(date string) int {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return 0
+	}
+	return t.Year()
+}
+
+// NotifyMemories 每天为有回忆的用户发送一条那年今日通知，由后台任务定期调用
+// 说明：每天 memoryNotifyHour 点之后才发送，当天已发送过的用户不再重复发送
+func (s *MemoryService) NotifyMemories(ctx context.Context) error {
+	now := time.Now()
+	if now.Hour() < memoryNotifyHour {
+		return nil
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	users, err := s.UserRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		sent, err := s.NotificationRepo.ExistsSince(ctx, user.ID, model.NotificationTypeMemory, today)
+		if err != nil {
+			s.Log.Error("查询回忆通知失败", "error", err, "userID", user.ID)
+			continue
+		}
+		if sent {
+			continue
+		}
+
+		found, err := s.collect(ctx, now, now.Month(), now.Day(), user.ID)
+		if err != nil || found.total() == 0 {
+			continue
+		}
+		// 回忆通知不关联对象，发送者记为接收者本人
+		payload := model.MemoryPayload{
+			Moments:       len(found.moments),
+			Photos:        len(found.photos),
+			Places:        len(found.places),
+			Anniversaries: len(found.anniversaries),
+		}
+		if err := s.NotificationSvc.CreateNotification(ctx, user.ID, user.ID, model.NotificationTypeMemory, model.NotificationEntityNone, 0, payload); err != nil {
+			s.Log.Error("创建回忆通知失败", "error", err, "userID", user.ID)
+		}
+	}
+	return nil
+}
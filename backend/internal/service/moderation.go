@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bookandmusic/love-girl/internal/config"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/moderation"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+const (
+	// trainingSampleLimit 分类器每类最多使用的训练样本数
+	trainingSampleLimit = 5000
+	// defaultDuplicateWindow 未配置时重复内容检测的时间窗口
+	defaultDuplicateWindow = 24 * time.Hour
+)
+
+// ModerationService 评论内容审核
+// 依次执行屏蔽词、链接数量、重复内容和贝叶斯分类器检查，分类器在首次审核时用已有评论训练，人工审核后重新训练
+type ModerationService struct {
+	*BaseService
+	CommentRepo *repo.CommentRepo
+	pipeline    *moderation.Pipeline
+	classifier  *moderation.Bayes
+	enabled     bool
+
+	trainMu sync.Mutex
+	trained bool
+}
+
+func NewModerationService(log *log.Logger, commentRepo *repo.CommentRepo, appCfg *config.AppConfig) *ModerationService {
+	cfg := appCfg.Comment.Moderation
+	window := defaultDuplicateWindow
+	if cfg.DuplicateWindow > 0 {
+		window = time.Duration(cfg.DuplicateWindow) * time.Second
+	}
+
+	classifier := moderation.NewBayes()
+	return &ModerationService{
+		BaseService: &BaseService{Log: log},
+		CommentRepo: commentRepo,
+		pipeline: moderation.NewPipeline(cfg.SpamThreshold, cfg.ReviewThreshold,
+			moderation.NewBannedWords(cfg.BannedWords),
+			moderation.NewLinkLimit(cfg.MaxLinks),
+			moderation.NewDuplicate(commentRepo, window),
+			classifier,
+		),
+		classifier: classifier,
+		enabled:    cfg.Enabled,
+	}
+}
+
+// Moderate 审核待保存的评论，把结论、风险分和原因写入评论
+// 说明：审核关闭时只计算内容指纹，结论为空；单个检查器出错时记录日志，按其余检查器的结果处理
+func (s *ModerationService) Moderate(ctx context.Context, comment *model.Comment) moderation.Verdict {
+	comment.ContentHash = moderation.ContentHash(comment.Content)
+	if !s.enabled {
+		return moderation.VerdictPass
+	}
+
+	s.ensureTrained(ctx)
+	result, err := s.pipeline.Moderate(ctx, &moderation.Input{
+		Content:   comment.Content,
+		UserID:    comment.AuthorID(),
+		IP:        comment.GuestIP,
+		CommentID: comment.ID,
+	})
+	if err != nil {
+		s.Log.Error("评论内容审核出错", "error", err)
+	}
+
+	comment.ModerationVerdict = string(result.Verdict)
+	comment.ModerationScore = result.Score
+	comment.ModerationReason = summarizeContent(result.Reason(), 200)
+	if result.Verdict != moderation.VerdictPass {
+		s.Log.Info("评论内容审核未通过", "verdict", result.Verdict, "score", result.Score, "reason", comment.ModerationReason, "userID", comment.AuthorID(), "ip", comment.GuestIP)
+	}
+	return result.Verdict
+}
+
+// Retrain 用人工标记的垃圾评论和已通过的评论重新训练分类器
+func (s *ModerationService) Retrain(ctx context.Context) {
+	if !s.enabled {
+		return
+	}
+	s.trainMu.Lock()
+	defer s.trainMu.Unlock()
+	s.train(ctx)
+}
+
+func (s *ModerationService) ensureTrained(ctx context.Context) {
+	s.trainMu.Lock()
+	defer s.trainMu.Unlock()
+	if !s.trained {
+		s.train(ctx)
+	}
+}
+
+// train 调用方需持有 trainMu
+func (s *ModerationService) train(ctx context.Context) {
+	spam, ham, err := s.CommentRepo.FindTrainingSamples(ctx, trainingSampleLimit)
+	if err != nil {
+		s.Log.Error("查询分类器训练样本失败", "error", err)
+		return
+	}
+	s.classifier.Train(spam, ham)
+	s.trained = true
+	s.Log.Info("评论分类器已训练", "spam", len(spam), "ham", len(ham))
+}
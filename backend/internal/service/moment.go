@@ -9,29 +9,68 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/bookandmusic/love-girl/internal/audit"
+	"github.com/bookandmusic/love-girl/internal/config"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
 	"github.com/bookandmusic/love-girl/internal/log"
 	"github.com/bookandmusic/love-girl/internal/model"
 	"github.com/bookandmusic/love-girl/internal/repo"
+	"github.com/bookandmusic/love-girl/internal/utils"
 )
 
+// MomentTagFilterField 按话题标签筛选动态的过滤字段，如 filter=tag:eq:travel
+const MomentTagFilterField = "tag"
+
 type MomentQueryParams struct {
 	Page    int
 	Size    int
 	SortBy  string
 	Order   string
 	Filters []repo.FilterCondition
+	Actor   ReactionActor // 当前访问者，用于返回其对各条动态的回应
+	// PinnedFirst 置顶动态排在最前，仅动态主列表使用
+	PinnedFirst bool
+	// Options 额外的查询选项，如限定合集内的动态
+	Options []repo.QueryOption
 }
 
 // FrontendMoment 前端期望的Moment数据结构
 type FrontendMoment struct {
-	ID           uint64          `json:"id"`
-	Content      string          `json:"content"`
-	Images       []FrontendPhoto `json:"images"`
-	Likes        int             `json:"likes"`
-	CommentCount int64           `json:"commentCount"`
-	CreatedAt    string          `json:"createdAt"`
-	Author       FrontendAuthor  `json:"author"`
-	IsPublic     bool            `json:"isPublic"`
+	ID           uint64             `json:"id"`
+	Content      string             `json:"content"`
+	Images       []FrontendPhoto    `json:"images"`
+	Likes        int                `json:"likes"`
+	Reactions    map[string]int64   `json:"reactions"`             // 各类表情回应数量
+	MyReactions  []string           `json:"myReactions,omitempty"` // 当前访问者的回应，仅列表接口返回
+	CommentCount int64              `json:"commentCount"`
+	CreatedAt    string             `json:"createdAt"`
+	Author       FrontendAuthor     `json:"author"`
+	Visibility   string             `json:"visibility"`
+	IsPublic     bool               `json:"isPublic"` // 兼容旧客户端，等价于 visibility == public
+	Tags         []string           `json:"tags"`     // 从内容中解析的话题标签
+	Status       string             `json:"status"`
+	PublishAt    string             `json:"publishAt,omitempty"`   // 定时发布时间，仅 scheduled 状态返回
+	Pinned       bool               `json:"pinned"`                // 是否置顶
+	PinnedAt     string             `json:"pinnedAt,omitempty"`    // 置顶时间
+	Place        *MomentPlace       `json:"place,omitempty"`       // 关联的地点
+	Anniversary  *MomentAnniversary `json:"anniversary,omitempty"` // 关联的纪念日
+}
+
+// MomentPlace 动态关联的地点摘要
+type MomentPlace struct {
+	ID        uint64  `json:"id"`
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// MomentAnniversary 动态关联的纪念日摘要
+type MomentAnniversary struct {
+	ID        uint64 `json:"id"`
+	Title     string `json:"title"`
+	Date      string `json:"date"`
+	Calendar  string `json:"calendar"`
+	LeapMonth bool   `json:"leapMonth"`
 }
 
 // FrontendPhoto 前端期望的Photo数据结构
@@ -80,59 +119,190 @@ func (s *MomentService) convertToFrontendFormat(c *gin.Context, moment *model.Mo
 	// 获取评论数量
 	commentCount, _ := s.CommentRepo.CountByMomentID(ctx, moment.ID)
 
-	return &FrontendMoment{
+	tags, err := s.TagRepo.FindNamesByMomentID(ctx, moment.ID)
+	if err != nil {
+		s.Log.Error("查询动态话题标签失败", "error", err, "momentID", moment.ID)
+		tags = []string{}
+	}
+
+	result := &FrontendMoment{
 		ID:           moment.ID,
 		Content:      moment.Content,
 		Images:       photos,
 		Likes:        moment.Likes,
+		Reactions:    s.countReactions(ctx, moment.ID),
 		CommentCount: commentCount,
 		CreatedAt:    moment.CreatedAt.Format("2006-01-02 15:04:05"),
 		Author:       author,
-		IsPublic:     moment.IsPublic,
+		Visibility:   string(moment.Visibility),
+		IsPublic:     moment.Visibility == model.MomentVisibilityPublic,
+		Tags:         tags,
+		Status:       string(moment.Status),
+		PublishAt:    formatPublishAt(moment),
+		Pinned:       moment.PinnedAt != nil,
+	}
+	if moment.PinnedAt != nil {
+		result.PinnedAt = moment.PinnedAt.Format("2006-01-02 15:04:05")
+	}
+	if moment.Place != nil {
+		result.Place = &MomentPlace{
+			ID:        moment.Place.ID,
+			Name:      moment.Place.Name,
+			Latitude:  moment.Place.Latitude,
+			Longitude: moment.Place.Longitude,
+		}
 	}
+	if moment.Anniversary != nil {
+		result.Anniversary = &MomentAnniversary{
+			ID:        moment.Anniversary.ID,
+			Title:     moment.Anniversary.Title,
+			Date:      moment.Anniversary.Date,
+			Calendar:  moment.Anniversary.Calendar,
+			LeapMonth: moment.Anniversary.IsLeapMonth,
+		}
+	}
+	return result
+}
+
+func formatPublishAt(moment *model.Moment) string {
+	if moment.Status != model.MomentStatusScheduled || moment.PublishAt == nil {
+		return ""
+	}
+	return moment.PublishAt.Format("2006-01-02 15:04:05")
 }
 
 type MomentService struct {
 	*BaseService
-	MomentRepo  *repo.MomentRepo
-	CommentRepo *repo.CommentRepo
-	FileService *FileService
+	MomentRepo      *repo.MomentRepo
+	CommentRepo     *repo.CommentRepo
+	ReactionRepo    *repo.ReactionRepo
+	UserRepo        *repo.UserRepo
+	TagRepo         *repo.TagRepo
+	RevisionRepo    *repo.MomentRevisionRepo
+	PlaceRepo       *repo.PlaceRepo
+	AnniversaryRepo *repo.AnniversaryRepo
+	FileService     *FileService
+	NotificationSvc *NotificationService
+	Search          *SearchService
+	Audit           *AuditService
+	appCfg          *config.AppConfig
 }
 
-func NewMomentService(log *log.Logger, momentRepo *repo.MomentRepo, commentRepo *repo.CommentRepo, fileService *FileService) *MomentService {
+func NewMomentService(log *log.Logger, momentRepo *repo.MomentRepo, commentRepo *repo.CommentRepo, reactionRepo *repo.ReactionRepo, userRepo *repo.UserRepo, tagRepo *repo.TagRepo, revisionRepo *repo.MomentRevisionRepo, placeRepo *repo.PlaceRepo, anniversaryRepo *repo.AnniversaryRepo, fileService *FileService, notificationService *NotificationService, searchService *SearchService, auditService *AuditService, appCfg *config.AppConfig) *MomentService {
 	return &MomentService{
-		BaseService: &BaseService{Log: log},
-		MomentRepo:  momentRepo,
-		CommentRepo: commentRepo,
-		FileService: fileService,
+		BaseService:     &BaseService{Log: log},
+		MomentRepo:      momentRepo,
+		CommentRepo:     commentRepo,
+		ReactionRepo:    reactionRepo,
+		UserRepo:        userRepo,
+		TagRepo:         tagRepo,
+		RevisionRepo:    revisionRepo,
+		PlaceRepo:       placeRepo,
+		AnniversaryRepo: anniversaryRepo,
+		FileService:     fileService,
+		NotificationSvc: notificationService,
+		Search:          searchService,
+		Audit:           auditService,
+		appCfg:          appCfg,
 	}
 }
 
 // MomentCreateRequest 创建动态请求
 type MomentCreateRequest struct {
-	Content   string   `json:"content" binding:"required"`
-	ImageIds  []uint64 `json:"imageIds"`
-	IsPublic  bool     `json:"isPublic"`
-	UserID    uint64   `json:"userId" binding:"required,gt=0"`
-	CreatedAt *string  `json:"createdAt"` // 可选，格式: "2006-01-02 15:04:05"
+	Content       string   `json:"content" binding:"required"`
+	ImageIds      []uint64 `json:"imageIds"`
+	Visibility    string   `json:"visibility" binding:"omitempty,oneof=private couple public"` // 为空时默认仅自己可见
+	IsPublic      *bool    `json:"isPublic"`                                                   // 兼容旧客户端，未指定 visibility 时使用
+	Status        string   `json:"status" binding:"omitempty,oneof=draft scheduled published"` // 为空时直接发布
+	PublishAt     *string  `json:"publishAt"`                                                  // 定时发布时间，status 为 scheduled 时必填，格式: "2006-01-02 15:04:05"
+	UserID        uint64   `json:"userId" binding:"required,gt=0"`
+	CreatedAt     *string  `json:"createdAt"`     // 可选，格式: "2006-01-02 15:04:05"
+	PlaceID       *uint64  `json:"placeId"`       // 可选，关联的地点
+	AnniversaryID *uint64  `json:"anniversaryId"` // 可选，关联的纪念日
 }
 
 // MomentUpdateRequest 更新动态请求
 type MomentUpdateRequest struct {
-	Content   *string  `json:"content"`
-	ImageIds  []uint64 `json:"imageIds"`
-	IsPublic  *bool    `json:"isPublic"`
-	CreatedAt *string  `json:"createdAt"` // 可选，格式: "2006-01-02 15:04:05"
+	Content       *string  `json:"content"`
+	ImageIds      []uint64 `json:"imageIds"`
+	Visibility    string   `json:"visibility" binding:"omitempty,oneof=private couple public"`
+	IsPublic      *bool    `json:"isPublic"`                                                   // 兼容旧客户端，未指定 visibility 时使用
+	Status        string   `json:"status" binding:"omitempty,oneof=draft scheduled published"` // 为空时保持不变
+	PublishAt     *string  `json:"publishAt"`                                                  // 定时发布时间，格式: "2006-01-02 15:04:05"
+	CreatedAt     *string  `json:"createdAt"`                                                  // 可选，格式: "2006-01-02 15:04:05"
+	PlaceID       *uint64  `json:"placeId"`                                                    // 为空时保持不变，为 0 时取消关联
+	AnniversaryID *uint64  `json:"anniversaryId"`                                              // 为空时保持不变，为 0 时取消关联
 }
 
-// MomentPublicRequest 动态公开状态请求
-type MomentPublicRequest struct {
-	IsPublic bool `json:"isPublic"`
+// MomentVisibilityRequest 动态可见范围请求
+type MomentVisibilityRequest struct {
+	Visibility string `json:"visibility" binding:"omitempty,oneof=private couple public"`
+	IsPublic   *bool  `json:"isPublic"` // 兼容旧客户端，未指定 visibility 时使用
+}
+
+// ResolveVisibility 解析请求中的可见范围，visibility 优先，其次是旧的 isPublic 字段
+//
+// 返回：可见范围、是否指定
+func ResolveVisibility(visibility string, isPublic *bool) (model.MomentVisibility, bool) {
+	if visibility != "" {
+		return model.MomentVisibility(visibility), true
+	}
+	if isPublic != nil {
+		if *isPublic {
+			return model.MomentVisibilityPublic, true
+		}
+		return model.MomentVisibilityPrivate, true
+	}
+	return "", false
+}
+
+// applyPublishState 根据请求设置动态的发布状态
+//
+// 参数：
+//   - status: 目标状态，为空时保持当前状态
+//   - publishAt: 定时发布时间，仅在 scheduled 状态下使用
+//
+// 返回：是否由未发布变为已发布、错误
+func applyPublishState(moment *model.Moment, status string, publishAt *string) (bool, error) {
+	wasPublished := moment.IsPublished()
+	if status != "" {
+		moment.Status = model.MomentStatus(status)
+	}
+
+	switch moment.Status {
+	case model.MomentStatusScheduled:
+		changed := status != ""
+		if publishAt != nil && *publishAt != "" {
+			parsedTime, err := time.ParseInLocation("2006-01-02 15:04:05", *publishAt, time.Local)
+			if err != nil {
+				return false, errMsg.ErrMomentPublishAtInvalid
+			}
+			moment.PublishAt = &parsedTime
+			changed = true
+		}
+		if moment.PublishAt == nil {
+			return false, errMsg.ErrMomentPublishAtRequired
+		}
+		// 仅修改内容时不校验，避免到期但尚未被调度发布的动态无法编辑
+		if changed && !moment.PublishAt.After(time.Now()) {
+			return false, errMsg.ErrMomentPublishAtPast
+		}
+	default:
+		moment.PublishAt = nil
+	}
+
+	return !wasPublished && moment.IsPublished(), nil
+}
+
+// TagCloudResponse 标签云响应
+type TagCloudResponse struct {
+	Tags []repo.TagCount `json:"tags"`
 }
 
 // MomentLikeResponse 点赞响应
 type MomentLikeResponse struct {
-	Likes int `json:"likes"`
+	Likes int  `json:"likes"`
+	Liked bool `json:"liked"` // 本次操作后是否处于已点赞状态
 }
 
 // MomentListResponse 动态列表响应
@@ -151,9 +321,21 @@ func (s *MomentService) CreateMoment(c *gin.Context, req *MomentCreateRequest) (
 	s.Log.Info("创建动态请求", "userID", req.UserID, "content", req.Content)
 
 	moment := &model.Moment{
-		Content:  req.Content,
-		IsPublic: req.IsPublic,
-		UserID:   req.UserID,
+		Content:    req.Content,
+		Visibility: model.MomentVisibilityPrivate,
+		UserID:     req.UserID,
+	}
+	if visibility, ok := ResolveVisibility(req.Visibility, req.IsPublic); ok {
+		moment.Visibility = visibility
+	}
+	if req.Status == "" {
+		moment.Status = model.MomentStatusPublished
+	}
+	if _, err := applyPublishState(moment, req.Status, req.PublishAt); err != nil {
+		return nil, err
+	}
+	if err := s.applyLinks(ctx, moment, req.PlaceID, req.AnniversaryID); err != nil {
+		return nil, err
 	}
 
 	// 如果指定了创建时间，则使用指定的时间
@@ -167,7 +349,7 @@ func (s *MomentService) CreateMoment(c *gin.Context, req *MomentCreateRequest) (
 	}
 
 	// 使用事务创建动态和文件关联
-	if err := s.MomentRepo.CreateWithFiles(ctx, moment, req.ImageIds); err != nil {
+	if err := s.MomentRepo.CreateWithFiles(ctx, moment, req.ImageIds, utils.ParseHashtags(moment.Content)); err != nil {
 		s.Log.Error("创建动态失败", "error", err, "content", req.Content)
 		return nil, fmt.Errorf("系统内部错误")
 	}
@@ -178,15 +360,20 @@ func (s *MomentService) CreateMoment(c *gin.Context, req *MomentCreateRequest) (
 		s.Log.Error("查询刚创建的动态失败", "error", err, "momentId", moment.ID)
 		return nil, fmt.Errorf("系统内部错误")
 	}
+	s.Search.IndexMoment(ctx, moment.ID)
+	// 草稿和定时动态在发布时再通知
+	if createdMoment.IsPublished() {
+		s.notifyPublished(ctx, createdMoment)
+	}
 
 	return s.convertToFrontendFormat(c, createdMoment), nil
 }
 
-// ListMoments 获取动态列表（仅返回公开的动态）
+// ListMoments 获取动态列表（仅返回已发布的公开动态）
 func (s *MomentService) ListMoments(c *gin.Context, page, size int) (*MomentListResponse, error) {
 	ctx := c.Request.Context()
-	moments, total, err := s.MomentRepo.ListMoments(ctx, page, size,
-		repo.FilterCondition{Field: "is_public", Operator: "eq", Value: true},
+	moments, total, err := s.MomentRepo.ListMomentsWithOpts(ctx, page, size,
+		repo.WithScopes(repo.MomentVisibleTo(0)),
 	)
 	if err != nil {
 		s.Log.Error("获取动态列表失败", "error", err, "page", page, "size", size)
@@ -212,26 +399,17 @@ func (s *MomentService) ListMoments(c *gin.Context, page, size int) (*MomentList
 }
 
 // ListMomentsByAuthStatus 根据认证状态获取动态列表
-// 如果用户已登录，返回当前用户的所有动态；否则，只返回公开的动态
+// 如果用户已登录，返回自己的全部动态（含草稿）以及对方已发布的情侣可见和公开动态；否则，只返回已发布的公开动态
 func (s *MomentService) ListMomentsByAuthStatus(c *gin.Context, page, size int, isLoggedIn bool, userID uint64) (*MomentListResponse, error) {
 	ctx := c.Request.Context()
-	var (
-		moments []model.Moment
-		total   int64
-		err     error
-	)
 
+	var viewerID uint64
 	if isLoggedIn {
-		// 用户已登录，只返回当前用户的所有动态（包括公开和私有的）
-		moments, total, err = s.MomentRepo.ListMoments(ctx, page, size,
-			repo.FilterCondition{Field: "user_id", Operator: "eq", Value: userID},
-		)
-	} else {
-		// 用户未登录，只返回公开的动态
-		moments, total, err = s.MomentRepo.ListMoments(ctx, page, size,
-			repo.FilterCondition{Field: "is_public", Operator: "eq", Value: true},
-		)
+		viewerID = userID
 	}
+	moments, total, err := s.MomentRepo.ListMomentsWithOpts(ctx, page, size,
+		repo.WithScopes(repo.MomentVisibleTo(viewerID)),
+	)
 
 	if err != nil {
 		s.Log.Error("获取动态列表失败", "error", err, "page", page, "size", size, "isLoggedIn", isLoggedIn)
@@ -265,17 +443,29 @@ func (s *MomentService) ListMomentsWithQuery(c *gin.Context, params *MomentQuery
 		err     error
 	)
 
-	var conditions []repo.FilterCondition
+	// 已登录用户可以看到自己的全部动态（含草稿）以及对方已发布的情侣可见和公开动态，访客只能看到已发布的公开动态
+	var viewerID uint64
 	if isLoggedIn {
-		conditions = append(conditions, repo.FilterCondition{Field: "user_id", Operator: "eq", Value: userID})
-	} else {
-		conditions = append(conditions, repo.FilterCondition{Field: "is_public", Operator: "eq", Value: true})
+		viewerID = userID
 	}
-	conditions = append(conditions, params.Filters...)
 
 	var opts []repo.QueryOption
-	opts = append(opts, repo.WithConditions(conditions...))
+	opts = append(opts, repo.WithScopes(repo.MomentVisibleTo(viewerID)))
+	// tag 不是动态表的字段，转换为关联表子查询
+	for _, filter := range params.Filters {
+		if filter.Field != MomentTagFilterField {
+			opts = append(opts, repo.WithConditions(filter))
+			continue
+		}
+		// 查询参数可能被解析为布尔值或数字，统一转回字符串
+		tag := utils.NormalizeHashtag(fmt.Sprint(filter.Value))
+		opts = append(opts, repo.WithScopes(repo.MomentHasTag(tag)))
+	}
 
+	opts = append(opts, params.Options...)
+	if params.PinnedFirst {
+		opts = append(opts, repo.PinnedFirst())
+	}
 	if params.SortBy != "" {
 		opts = append(opts, repo.WithOrder(params.SortBy, params.Order == "desc"))
 	}
@@ -293,6 +483,7 @@ func (s *MomentService) ListMomentsWithQuery(c *gin.Context, params *MomentQuery
 		momentPtr := &moment
 		frontendMoments[i] = s.convertToFrontendFormat(c, momentPtr)
 	}
+	s.fillMyReactions(ctx, frontendMoments, params.Actor)
 
 	return &MomentListResponse{
 		Moments:    frontendMoments,
@@ -334,12 +525,23 @@ func (s *MomentService) UpdateMoment(c *gin.Context, id uint64, userID uint64, r
 	if req.Content != nil {
 		moment.Content = *req.Content
 	}
-	if req.IsPublic != nil {
-		moment.IsPublic = *req.IsPublic
+	if visibility, ok := ResolveVisibility(req.Visibility, req.IsPublic); ok {
+		moment.Visibility = visibility
+	}
+	justPublished, err := applyPublishState(moment, req.Status, req.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyLinks(ctx, moment, req.PlaceID, req.AnniversaryID); err != nil {
+		return nil, err
 	}
 
-	// 如果指定了创建时间，则更新
+	// 如果指定了创建时间，则更新；草稿直接发布时动态时间取发布时刻
 	var newCreatedAt *time.Time
+	if justPublished {
+		now := time.Now()
+		newCreatedAt = &now
+	}
 	if req.CreatedAt != nil && *req.CreatedAt != "" {
 		parsedTime, err := time.ParseInLocation("2006-01-02 15:04:05", *req.CreatedAt, time.Local)
 		if err != nil {
@@ -350,7 +552,10 @@ func (s *MomentService) UpdateMoment(c *gin.Context, id uint64, userID uint64, r
 	}
 
 	// 更新动态信息
-	if err := s.MomentRepo.UpdateWithFiles(ctx, moment, req.ImageIds, newCreatedAt); err != nil {
+	if err := s.MomentRepo.UpdateWithFiles(ctx, moment, req.ImageIds, utils.ParseHashtags(moment.Content), newCreatedAt, repo.RevisionInfo{
+		EditorID: userID,
+		Action:   model.MomentRevisionUpdate,
+	}); err != nil {
 		s.Log.Error("更新动态失败", "error", err, "id", id)
 		return nil, fmt.Errorf("系统内部错误")
 	}
@@ -360,6 +565,10 @@ func (s *MomentService) UpdateMoment(c *gin.Context, id uint64, userID uint64, r
 		s.Log.Error("查询更新后的动态失败", "error", err, "id", id)
 		return nil, fmt.Errorf("系统内部错误")
 	}
+	s.Search.IndexMoment(ctx, id)
+	if justPublished {
+		s.notifyPublished(ctx, updatedMoment)
+	}
 
 	return s.convertToFrontendFormat(c, updatedMoment), nil
 }
@@ -379,12 +588,30 @@ func (s *MomentService) DeleteMoment(ctx context.Context, id uint64, userID uint
 		s.Log.Error("删除动态失败", "error", err, "id", id)
 		return false, fmt.Errorf("系统内部错误")
 	}
+	s.Search.RemoveMoment(ctx, id)
+
+	imageIDs := make([]uint64, 0, len(moment.EntityFiles))
+	for _, ef := range moment.EntityFiles {
+		imageIDs = append(imageIDs, ef.FileID)
+	}
+	s.Audit.Record(ctx, AuditEntry{
+		Action:     audit.ActionMomentDelete,
+		TargetType: repo.MomentEntityType,
+		TargetID:   id,
+		Before: map[string]any{
+			"content":    moment.Content,
+			"visibility": moment.Visibility,
+			"userId":     moment.UserID,
+			"imageIds":   imageIDs,
+			"createdAt":  moment.CreatedAt.Format("2006-01-02 15:04:05"),
+		},
+	})
 
 	return true, nil
 }
 
-// UpdatePublicStatus 更新动态公开状态
-func (s *MomentService) UpdatePublicStatus(c *gin.Context, id uint64, userID uint64, status bool) (*FrontendMoment, error) {
+// UpdateVisibility 更新动态可见范围
+func (s *MomentService) UpdateVisibility(c *gin.Context, id uint64, userID uint64, visibility model.MomentVisibility) (*FrontendMoment, error) {
 	ctx := c.Request.Context()
 	moment, err := s.checkMomentOwnership(ctx, id, userID)
 	if err != nil {
@@ -395,8 +622,8 @@ func (s *MomentService) UpdatePublicStatus(c *gin.Context, id uint64, userID uin
 		return nil, nil
 	}
 
-	if err := s.MomentRepo.UpdatePublicStatus(ctx, id, status); err != nil {
-		s.Log.Error("更新动态公开状态失败", "error", err, "id", id, "status", status)
+	if err := s.MomentRepo.UpdateVisibility(ctx, id, visibility, userID); err != nil {
+		s.Log.Error("更新动态可见范围失败", "error", err, "id", id, "visibility", visibility)
 		return nil, fmt.Errorf("系统内部错误")
 	}
 
@@ -405,33 +632,20 @@ func (s *MomentService) UpdatePublicStatus(c *gin.Context, id uint64, userID uin
 		s.Log.Error("查询更新后的动态失败", "error", err, "id", id)
 		return nil, fmt.Errorf("系统内部错误")
 	}
+	s.Search.IndexMoment(ctx, id)
 
 	return s.convertToFrontendFormat(c, updatedMoment), nil
 }
 
-// LikeMoment 点赞动态
-func (s *MomentService) LikeMoment(c *gin.Context, id uint64) (*FrontendMoment, error) {
-	ctx := c.Request.Context()
-	_, err := s.MomentRepo.FindByID(ctx, id)
+// ListTagCloud 获取当前用户可见动态的标签云
+func (s *MomentService) ListTagCloud(c *gin.Context, viewerID uint64, limit int) (*TagCloudResponse, error) {
+	counts, err := s.TagRepo.CountVisible(c.Request.Context(), viewerID, limit)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.Log.Info("动态不存在", "id", id)
-			return nil, nil
-		}
-		s.Log.Error("查询动态失败", "error", err, "id", id)
-		return nil, fmt.Errorf("系统内部错误")
-	}
-
-	if err := s.MomentRepo.UpdateLike(ctx, id); err != nil {
-		s.Log.Error("更新动态点赞数失败", "error", err, "id", id)
+		s.Log.Error("统计标签云失败", "error", err, "viewerID", viewerID)
 		return nil, fmt.Errorf("系统内部错误")
 	}
-
-	likedMoment, err := s.MomentRepo.FindByID(ctx, id)
-	if err != nil {
-		s.Log.Error("查询点赞后的动态失败", "error", err, "id", id)
-		return nil, fmt.Errorf("系统内部错误")
+	if counts == nil {
+		counts = []repo.TagCount{}
 	}
-
-	return s.convertToFrontendFormat(c, likedMoment), nil
+	return &TagCloudResponse{Tags: counts}, nil
 }
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+// applyLinks 设置动态关联的地点和纪念日，参数为 nil 时保持不变，为 0 时取消关联
+func (s *MomentService) applyLinks(ctx context.Context, moment *model.Moment, placeID, anniversaryID *uint64) error {
+	if placeID != nil {
+		// 清空已预加载的关联，否则保存时 gorm 会按旧关联回填外键
+		moment.Place = nil
+		moment.PlaceID = nil
+		if *placeID != 0 {
+			if _, err := s.PlaceRepo.FindByID(ctx, *placeID); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errMsg.ErrMomentPlaceNotFound
+				}
+				s.Log.Error("查询关联地点失败", "error", err, "placeID", *placeID)
+				return fmt.Errorf("系统内部错误")
+			}
+			id := *placeID
+			moment.PlaceID = &id
+		}
+	}
+	if anniversaryID != nil {
+		moment.Anniversary = nil
+		moment.AnniversaryID = nil
+		if *anniversaryID != 0 {
+			if _, err := s.AnniversaryRepo.FindByID(ctx, *anniversaryID); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errMsg.ErrMomentAnniversaryNotFound
+				}
+				s.Log.Error("查询关联纪念日失败", "error", err, "anniversaryID", *anniversaryID)
+				return fmt.Errorf("系统内部错误")
+			}
+			id := *anniversaryID
+			moment.AnniversaryID = &id
+		}
+	}
+	return nil
+}
+
+// ListPlaceMoments 获取发生在指定地点、且当前用户可见的动态，按动态时间倒序
+//
+// 返回：地点不存在时返回 nil
+func (s *MomentService) ListPlaceMoments(c *gin.Context, placeID uint64, params *MomentQueryParams, isLoggedIn bool, userID uint64) (*MomentListResponse, error) {
+	if _, err := s.PlaceRepo.FindByID(c.Request.Context(), placeID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		s.Log.Error("查询地点失败", "error", err, "id", placeID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return s.listLinkedMoments(c, "place_id", placeID, params, isLoggedIn, userID)
+}
+
+// ListAnniversaryMoments 获取关联指定纪念日、且当前用户可见的动态，按动态时间倒序
+//
+// 返回：纪念日不存在时返回 nil
+func (s *MomentService) ListAnniversaryMoments(c *gin.Context, anniversaryID uint64, params *MomentQueryParams, isLoggedIn bool, userID uint64) (*MomentListResponse, error) {
+	if _, err := s.AnniversaryRepo.FindByID(c.Request.Context(), anniversaryID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		s.Log.Error("查询纪念日失败", "error", err, "id", anniversaryID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return s.listLinkedMoments(c, "anniversary_id", anniversaryID, params, isLoggedIn, userID)
+}
+
+func (s *MomentService) listLinkedMoments(c *gin.Context, field string, id uint64, params *MomentQueryParams, isLoggedIn bool, userID uint64) (*MomentListResponse, error) {
+	params.Filters = append(params.Filters, repo.FilterCondition{
+		Field:    field,
+		Operator: "eq",
+		Value:    id,
+	})
+	if params.SortBy == "" {
+		params.SortBy = "created_at"
+		params.Order = "desc"
+	}
+	return s.ListMomentsWithQuery(c, params, isLoggedIn, userID)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+)
+
+// SetPinned 置顶或取消置顶动态，只有作者可以操作，草稿和定时动态不能置顶
+//
+// 返回：动态不存在时返回 nil
+func (s *MomentService) SetPinned(c *gin.Context, id uint64, userID uint64, pinned bool) (*FrontendMoment, error) {
+	ctx := c.Request.Context()
+	moment, err := s.checkMomentOwnership(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if moment == nil {
+		return nil, nil
+	}
+
+	var pinnedAt *time.Time
+	if pinned {
+		if !moment.IsPublished() {
+			return nil, errMsg.ErrMomentPinUnpublished
+		}
+		now := time.Now()
+		pinnedAt = &now
+	}
+	if err := s.MomentRepo.SetPinned(ctx, id, pinnedAt); err != nil {
+		s.Log.Error("更新动态置顶状态失败", "error", err, "id", id, "pinned", pinned)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	moment.PinnedAt = pinnedAt
+	return s.convertToFrontendFormat(c, moment), nil
+}
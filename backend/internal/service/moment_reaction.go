@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// ReactionActor 表情回应的发起者：登录用户或匿名访客
+type ReactionActor struct {
+	UserID      uint64 // 登录用户ID，匿名访客为 0
+	Fingerprint string // 匿名访客指纹哈希，登录用户为空
+}
+
+// NewUserReactionActor 登录用户
+func NewUserReactionActor(userID uint64) ReactionActor {
+	return ReactionActor{UserID: userID}
+}
+
+// VisitorReactionActor 匿名访客，由服务端根据 IP + User-Agent 计算指纹，不接受客户端提供的标识，
+// 避免更换标识重复回应。使用服务端密钥计算 HMAC，只保存哈希，不保存原始 IP
+func (s *MomentService) VisitorReactionActor(ip, userAgent string) ReactionActor {
+	mac := hmac.New(sha256.New, []byte(s.appCfg.JWT.Secret))
+	mac.Write([]byte("reaction-visitor:" + ip + "|" + userAgent))
+	return ReactionActor{Fingerprint: hex.EncodeToString(mac.Sum(nil))}
+}
+
+// IsAnonymous 是否为匿名访客
+func (a ReactionActor) IsAnonymous() bool {
+	return a.UserID == 0
+}
+
+// MomentReactionRequest 表情回应请求
+type MomentReactionRequest struct {
+	Type string `json:"type" binding:"required"`
+}
+
+// MomentReactionResponse 表情回应结果
+type MomentReactionResponse struct {
+	Reacted     bool             `json:"reacted"` // 本次操作后是否处于已回应状态
+	Type        string           `json:"type,omitempty"`
+	Likes       int              `json:"likes"`
+	Reactions   map[string]int64 `json:"reactions"`
+	MyReactions []string         `json:"myReactions"`
+}
+
+// findReactableMoment 查询可回应的动态，匿名访客只能回应公开动态
+//
+// 返回：动态不存在或不可见时返回 nil
+func (s *MomentService) findReactableMoment(ctx context.Context, id uint64, actor ReactionActor) (*model.Moment, error) {
+	moment, err := s.MomentRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.Log.Info("动态不存在", "id", id)
+			return nil, nil
+		}
+		s.Log.Error("查询动态失败", "error", err, "id", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	// 不可见的动态按不存在处理，避免泄露动态是否存在
+	if !moment.VisibleTo(actor.UserID) {
+		s.Log.Info("动态对当前用户不可见", "id", id, "viewerID", actor.UserID)
+		return nil, nil
+	}
+	return moment, nil
+}
+
+// ToggleReaction 切换表情回应：已回应则取消，未回应则添加，添加时通知动态作者
+//
+// 返回：动态不存在或不可见时返回 nil
+func (s *MomentService) ToggleReaction(c *gin.Context, id uint64, actor ReactionActor, reactionType model.ReactionType) (*MomentReactionResponse, error) {
+	ctx := c.Request.Context()
+
+	if !reactionType.Valid() {
+		return nil, fmt.Errorf("不支持的回应类型")
+	}
+
+	moment, err := s.findReactableMoment(ctx, id, actor)
+	if err != nil || moment == nil {
+		return nil, err
+	}
+
+	added, err := s.ReactionRepo.Toggle(ctx, &model.Reaction{
+		MomentID:    id,
+		UserID:      actor.UserID,
+		Fingerprint: actor.Fingerprint,
+		Type:        reactionType,
+	})
+	if err != nil {
+		s.Log.Error("切换表情回应失败", "error", err, "id", id, "type", reactionType)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	if added {
+		s.notifyReaction(ctx, moment, actor, reactionType)
+	}
+
+	resp, err := s.buildReactionResponse(ctx, id, actor)
+	if err != nil {
+		return nil, err
+	}
+	s.NotificationSvc.PublishMomentEvent(moment, EventReactions, ReactionsEvent{MomentID: id, Likes: resp.Likes, Reactions: resp.Reactions})
+	resp.Reacted = added
+	resp.Type = string(reactionType)
+	return resp, nil
+}
+
+// GetReactions 获取动态的回应统计以及当前用户的回应
+//
+// 返回：动态不存在或不可见时返回 nil
+func (s *MomentService) GetReactions(c *gin.Context, id uint64, actor ReactionActor) (*MomentReactionResponse, error) {
+	ctx := c.Request.Context()
+
+	moment, err := s.findReactableMoment(ctx, id, actor)
+	if err != nil || moment == nil {
+		return nil, err
+	}
+	return s.buildReactionResponse(ctx, id, actor)
+}
+
+func (s *MomentService) buildReactionResponse(ctx context.Context, id uint64, actor ReactionActor) (*MomentReactionResponse, error) {
+	moment, err := s.MomentRepo.FindByID(ctx, id)
+	if err != nil {
+		s.Log.Error("查询动态失败", "error", err, "id", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	mine, err := s.ReactionRepo.FindActorTypes(ctx, []uint64{id}, actor.UserID, actor.Fingerprint)
+	if err != nil {
+		s.Log.Error("查询用户回应失败", "error", err, "id", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	return &MomentReactionResponse{
+		Likes:       moment.Likes,
+		Reactions:   s.countReactions(ctx, id),
+		MyReactions: reactionTypeStrings(mine[id]),
+	}, nil
+}
+
+// countReactions 统计动态的各类回应数量，查询失败时返回空统计
+func (s *MomentService) countReactions(ctx context.Context, momentID uint64) map[string]int64 {
+	result := make(map[string]int64)
+	counts, err := s.ReactionRepo.CountByMomentID(ctx, momentID)
+	if err != nil {
+		s.Log.Error("统计表情回应失败", "error", err, "momentID", momentID)
+		return result
+	}
+	for reactionType, count := range counts {
+		result[string(reactionType)] = count
+	}
+	return result
+}
+
+// fillMyReactions 批量填充当前用户对列表中动态的回应
+func (s *MomentService) fillMyReactions(ctx context.Context, moments []*FrontendMoment, actor ReactionActor) {
+	if actor.UserID == 0 && actor.Fingerprint == "" {
+		return
+	}
+
+	ids := make([]uint64, len(moments))
+	for i, moment := range moments {
+		ids[i] = moment.ID
+	}
+
+	mine, err := s.ReactionRepo.FindActorTypes(ctx, ids, actor.UserID, actor.Fingerprint)
+	if err != nil {
+		s.Log.Error("查询用户回应失败", "error", err)
+		return
+	}
+	for _, moment := range moments {
+		moment.MyReactions = reactionTypeStrings(mine[moment.ID])
+	}
+}
+
+// notifyReaction 通知动态作者收到回应，匿名访客的回应不发通知
+func (s *MomentService) notifyReaction(ctx context.Context, moment *model.Moment, actor ReactionActor, reactionType model.ReactionType) {
+	if actor.IsAnonymous() || actor.UserID == moment.UserID {
+		return
+	}
+	if err := s.NotificationSvc.CreateNotification(ctx, moment.UserID, actor.UserID, model.NotificationTypeReaction, model.NotificationEntityMoment, moment.ID, model.ReactionPayload{Reaction: reactionType}); err != nil {
+		s.Log.Error("创建回应通知失败", "error", err, "momentID", moment.ID)
+	}
+}
+
+func reactionTypeStrings(types []model.ReactionType) []string {
+	result := make([]string, len(types))
+	for i, t := range types {
+		result[i] = string(t)
+	}
+	return result
+}
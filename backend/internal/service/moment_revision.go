@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+	"github.com/bookandmusic/love-girl/internal/utils"
+)
+
+// MomentRevisionResponse 动态修订版本
+type MomentRevisionResponse struct {
+	Version      int             `json:"version"`
+	Action       string          `json:"action"`                 // create/update/visibility/publish/restore
+	RestoredFrom int             `json:"restoredFrom,omitempty"` // 恢复自的版本号，仅 restore 操作返回
+	Current      bool            `json:"current"`                // 是否为动态当前所处的版本
+	Content      string          `json:"content"`
+	ImageIds     []uint64        `json:"imageIds"`
+	Images       []FrontendPhoto `json:"images"` // 已被删除的图片不返回
+	Visibility   string          `json:"visibility"`
+	MomentTime   string          `json:"momentTime"` // 该版本的动态时间
+	Editor       FrontendAuthor  `json:"editor"`
+	CreatedAt    string          `json:"createdAt"` // 修订时间
+}
+
+// MomentRevisionListResponse 动态修订历史
+type MomentRevisionListResponse struct {
+	Revisions  []*MomentRevisionResponse `json:"revisions"`
+	Page       int                       `json:"page"`
+	Size       int                       `json:"size"`
+	Total      int64                     `json:"total"`
+	TotalPages int                       `json:"totalPages"`
+}
+
+// MomentRevisionDiffResponse 两个修订版本的差异
+type MomentRevisionDiffResponse struct {
+	From              *MomentRevisionResponse `json:"from"` // 较早的版本，与第一个版本比较时为 null
+	To                *MomentRevisionResponse `json:"to"`
+	Content           []utils.DiffSegment     `json:"content"`
+	ImagesAdded       []uint64                `json:"imagesAdded"`
+	ImagesRemoved     []uint64                `json:"imagesRemoved"`
+	ImagesReordered   bool                    `json:"imagesReordered"` // 图片集合相同但顺序变化
+	VisibilityChanged bool                    `json:"visibilityChanged"`
+	MomentTimeChanged bool                    `json:"momentTimeChanged"`
+}
+
+// ListRevisions 分页查询动态的修订历史，仅动态作者可查看
+//
+// 返回：动态不存在时返回 nil
+func (s *MomentService) ListRevisions(c *gin.Context, id uint64, userID uint64, page, size int) (*MomentRevisionListResponse, error) {
+	ctx := c.Request.Context()
+	moment, err := s.checkMomentOwnership(ctx, id, userID)
+	if err != nil || moment == nil {
+		return nil, err
+	}
+
+	revisions, total, err := s.RevisionRepo.ListByMomentID(ctx, id, page, size)
+	if err != nil {
+		s.Log.Error("查询动态修订历史失败", "error", err, "id", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	latest, err := s.RevisionRepo.FindByVersion(ctx, id, 0)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.Log.Error("查询动态最新版本失败", "error", err, "id", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	revisionPtrs := make([]*model.MomentRevision, len(revisions))
+	for i := range revisions {
+		revisionPtrs[i] = &revisions[i]
+	}
+	items := s.convertRevisions(c, latest, revisionPtrs...)
+
+	return &MomentRevisionListResponse{
+		Revisions:  items,
+		Page:       page,
+		Size:       size,
+		Total:      total,
+		TotalPages: int((total + int64(size) - 1) / int64(size)),
+	}, nil
+}
+
+// DiffRevisions 比较动态的两个修订版本，仅动态作者可查看
+// to 为 0 时取最新版本，from 为 0 时取 to 的上一个版本
+//
+// 返回：动态不存在时返回 nil
+func (s *MomentService) DiffRevisions(c *gin.Context, id uint64, userID uint64, from, to int) (*MomentRevisionDiffResponse, error) {
+	ctx := c.Request.Context()
+	moment, err := s.checkMomentOwnership(ctx, id, userID)
+	if err != nil || moment == nil {
+		return nil, err
+	}
+
+	latest, err := s.findRevision(ctx, id, 0)
+	if err != nil {
+		return nil, err
+	}
+	toRevision := latest
+	if to > 0 && to != latest.Version {
+		if toRevision, err = s.findRevision(ctx, id, to); err != nil {
+			return nil, err
+		}
+	}
+	if from == 0 {
+		from = toRevision.Version - 1
+	}
+	var fromRevision *model.MomentRevision
+	if from > 0 {
+		if fromRevision, err = s.findRevision(ctx, id, from); err != nil {
+			return nil, err
+		}
+	}
+
+	// 与第一个版本比较时以空版本为基准
+	base := &model.MomentRevision{MomentTime: toRevision.MomentTime, Visibility: toRevision.Visibility}
+	if fromRevision != nil {
+		base = fromRevision
+	}
+	diff := &MomentRevisionDiffResponse{
+		Content:           utils.DiffText(base.Content, toRevision.Content),
+		ImagesAdded:       subtractIDs(toRevision.ImageIDs, base.ImageIDs),
+		ImagesRemoved:     subtractIDs(base.ImageIDs, toRevision.ImageIDs),
+		VisibilityChanged: base.Visibility != toRevision.Visibility,
+		MomentTimeChanged: !base.MomentTime.Equal(toRevision.MomentTime),
+	}
+	diff.ImagesReordered = len(diff.ImagesAdded) == 0 && len(diff.ImagesRemoved) == 0 &&
+		!slices.Equal(base.ImageIDs, toRevision.ImageIDs)
+
+	if fromRevision != nil {
+		converted := s.convertRevisions(c, latest, fromRevision, toRevision)
+		diff.From, diff.To = converted[0], converted[1]
+	} else {
+		diff.To = s.convertRevisions(c, latest, toRevision)[0]
+	}
+	return diff, nil
+}
+
+// RestoreRevision 将动态的内容、图片、可见范围和动态时间恢复到指定版本，并记录为新版本
+// 发布状态不随版本恢复；该版本引用的图片已被删除时跳过
+//
+// 返回：动态不存在时返回 nil
+func (s *MomentService) RestoreRevision(c *gin.Context, id uint64, userID uint64, version int) (*FrontendMoment, error) {
+	ctx := c.Request.Context()
+	moment, err := s.checkMomentOwnership(ctx, id, userID)
+	if err != nil || moment == nil {
+		return nil, err
+	}
+
+	revision, err := s.findRevision(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	imageIDs, err := s.existingFileIDs(ctx, revision.ImageIDs)
+	if err != nil {
+		s.Log.Error("查询版本图片失败", "error", err, "id", id, "version", version)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	moment.Content = revision.Content
+	moment.Visibility = revision.Visibility
+	momentTime := revision.MomentTime
+	if err := s.MomentRepo.UpdateWithFiles(ctx, moment, imageIDs, utils.ParseHashtags(moment.Content), &momentTime, repo.RevisionInfo{
+		EditorID:     userID,
+		Action:       model.MomentRevisionRestore,
+		RestoredFrom: revision.Version,
+	}); err != nil {
+		s.Log.Error("恢复动态版本失败", "error", err, "id", id, "version", version)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	restored, err := s.MomentRepo.FindByID(ctx, id)
+	if err != nil {
+		s.Log.Error("查询恢复后的动态失败", "error", err, "id", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	s.Search.IndexMoment(ctx, id)
+
+	return s.convertToFrontendFormat(c, restored), nil
+}
+
+// findRevision 查询修订版本，version 为 0 表示最新版本
+func (s *MomentService) findRevision(ctx context.Context, id uint64, version int) (*model.MomentRevision, error) {
+	revision, err := s.RevisionRepo.FindByVersion(ctx, id, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errMsg.ErrMomentRevisionNotFound
+		}
+		s.Log.Error("查询动态修订版本失败", "error", err, "id", id, "version", version)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return revision, nil
+}
+
+// existingFileIDs 过滤掉已删除的文件，保持原有顺序
+func (s *MomentService) existingFileIDs(ctx context.Context, ids []uint64) ([]uint64, error) {
+	files, err := s.loadFiles(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	existing := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := files[id]; ok {
+			existing = append(existing, id)
+		}
+	}
+	return existing, nil
+}
+
+func (s *MomentService) loadFiles(ctx context.Context, ids []uint64) (map[uint64]*model.File, error) {
+	result := make(map[uint64]*model.File, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	files, err := s.FileService.FileRepo.List(ctx, repo.WithScopes(func(db *gorm.DB) *gorm.DB {
+		return db.Where("id IN ?", ids)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		result[files[i].ID] = &files[i]
+	}
+	return result, nil
+}
+
+// convertRevisions 转换为前端格式，批量加载各版本引用的图片
+func (s *MomentService) convertRevisions(c *gin.Context, latest *model.MomentRevision, revisions ...*model.MomentRevision) []*MomentRevisionResponse {
+	var fileIDs []uint64
+	for _, revision := range revisions {
+		fileIDs = append(fileIDs, revision.ImageIDs...)
+	}
+	files, err := s.loadFiles(c.Request.Context(), fileIDs)
+	if err != nil {
+		s.Log.Error("查询版本图片失败", "error", err)
+		files = map[uint64]*model.File{}
+	}
+
+	items := make([]*MomentRevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		images := make([]FrontendPhoto, 0, len(revision.ImageIDs))
+		for _, fileID := range revision.ImageIDs {
+			if file, ok := files[fileID]; ok {
+				images = append(images, FrontendPhoto{
+					ID:       file.ID,
+					MomentID: revision.MomentID,
+					File:     s.FileService.BuildFileResponse(c, file),
+				})
+			}
+		}
+		editor := FrontendAuthor{ID: revision.EditorID}
+		if revision.Editor != nil {
+			editor.Name = revision.Editor.Name
+			editor.Avatar = s.FileService.BuildFileResponse(c, revision.Editor.Avatar)
+		}
+		imageIDs := revision.ImageIDs
+		if imageIDs == nil {
+			imageIDs = []uint64{}
+		}
+		items = append(items, &MomentRevisionResponse{
+			Version:      revision.Version,
+			Action:       string(revision.Action),
+			RestoredFrom: revision.RestoredFrom,
+			Current:      latest != nil && revision.Version == latest.Version,
+			Content:      revision.Content,
+			ImageIds:     imageIDs,
+			Images:       images,
+			Visibility:   string(revision.Visibility),
+			MomentTime:   revision.MomentTime.Format("2006-01-02 15:04:05"),
+			Editor:       editor,
+			CreatedAt:    revision.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return items
+}
+
+// subtractIDs 返回在 a 中但不在 b 中的ID，保持 a 的顺序
+func subtractIDs(a, b []uint64) []uint64 {
+	result := make([]uint64, 0)
+	for _, id := range a {
+		if !slices.Contains(b, id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+// scheduledPublishBatch 每次调度最多发布的动态数量，剩余的留到下一轮
+const scheduledPublishBatch = 100
+
+// PublishDueMoments 发布已到时间的定时动态并通知能看到它的其他用户，由后台任务定期调用
+func (s *MomentService) PublishDueMoments(ctx context.Context) error {
+	moments, err := s.MomentRepo.ListDueScheduled(ctx, time.Now(), scheduledPublishBatch)
+	if err != nil {
+		return err
+	}
+
+	for i := range moments {
+		moment := &moments[i]
+		published, err := s.MomentRepo.PublishScheduled(ctx, moment)
+		if err != nil {
+			s.Log.Error("发布定时动态失败", "error", err, "momentID", moment.ID)
+			continue
+		}
+		if !published {
+			continue
+		}
+		moment.Status = model.MomentStatusPublished
+		s.Log.Info("定时动态已发布", "momentID", moment.ID, "userID", moment.UserID)
+		s.Search.IndexMoment(ctx, moment.ID)
+		s.notifyPublished(ctx, moment)
+	}
+	return nil
+}
+
+// notifyPublished 通知除作者外能看到该动态的用户
+func (s *MomentService) notifyPublished(ctx context.Context, moment *model.Moment) {
+	users, err := s.UserRepo.List(ctx)
+	if err != nil {
+		s.Log.Error("查询通知接收用户失败", "error", err, "momentID", moment.ID)
+		return
+	}
+
+	payload := model.MomentPayload{Excerpt: summarizeContent(moment.Content, 50)}
+	for _, user := range users {
+		if user.ID == moment.UserID || !moment.VisibleTo(user.ID) {
+			continue
+		}
+		if err := s.NotificationSvc.CreateNotification(ctx, user.ID, moment.UserID, model.NotificationTypePublish, model.NotificationEntityMoment, moment.ID, payload); err != nil {
+			s.Log.Error("创建发布通知失败", "error", err, "momentID", moment.ID, "receiverID", user.ID)
+		}
+	}
+}
+
+// summarizeContent 截取内容摘要，按字符而非字节截断
+func summarizeContent(content string, limit int) string {
+	runes := []rune(content)
+	if len(runes) <= limit {
+		return content
+	}
+	return string(runes[:limit]) + "…"
+}
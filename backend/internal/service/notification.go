@@ -2,26 +2,48 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/bookandmusic/love-girl/internal/log"
 	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/notify"
+	"github.com/bookandmusic/love-girl/internal/realtime"
 	"github.com/bookandmusic/love-girl/internal/repo"
 )
 
+const (
+	// NotificationRetentionSettingKey 已读通知保留天数的设置项，0 表示永久保留
+	NotificationRetentionSettingKey = "notificationRetentionDays"
+	// defaultNotificationRetentionDays 未设置时的默认保留天数
+	defaultNotificationRetentionDays = 90
+)
+
 type NotificationService struct {
 	*BaseService
 	NotificationRepo *repo.NotificationRepo
+	ChannelRepo      *repo.NotificationChannelRepo
+	DigestRepo       *repo.NotificationDigestRepo
+	SettingRepo      *repo.SettingRepo
 	FileService      *FileService
+	Hub              *realtime.Hub
+	Channels         notify.Channels
 }
 
-func NewNotificationService(log *log.Logger, notificationRepo *repo.NotificationRepo, fileService *FileService) *NotificationService {
+func NewNotificationService(log *log.Logger, notificationRepo *repo.NotificationRepo, channelRepo *repo.NotificationChannelRepo, digestRepo *repo.NotificationDigestRepo, settingRepo *repo.SettingRepo, fileService *FileService, hub *realtime.Hub, channels notify.Channels) *NotificationService {
 	return &NotificationService{
 		BaseService:      &BaseService{Log: log},
 		NotificationRepo: notificationRepo,
+		ChannelRepo:      channelRepo,
+		DigestRepo:       digestRepo,
+		SettingRepo:      settingRepo,
 		FileService:      fileService,
+		Hub:              hub,
+		Channels:         channels,
 	}
 }
 
@@ -32,14 +54,17 @@ type FrontendNotificationSender struct {
 }
 
 type FrontendNotification struct {
-	ID        uint64                     `json:"id"`
-	Type      string                     `json:"type"`
-	MomentID  uint64                     `json:"momentId"`
-	CommentID uint64                     `json:"commentId"`
-	Sender    FrontendNotificationSender `json:"sender"`
-	Content   string                     `json:"content"`
-	IsRead    bool                       `json:"isRead"`
-	CreatedAt string                     `json:"createdAt"`
+	ID         uint64                     `json:"id"`
+	Type       string                     `json:"type"`
+	EntityType string                     `json:"entityType"` // 关联的对象类型，不关联对象时为空
+	EntityID   uint64                     `json:"entityId"`
+	Payload    json.RawMessage            `json:"payload" swaggertype:"object"`
+	Sender     FrontendNotificationSender `json:"sender"`
+	Title      string                     `json:"title"`
+	Content    string                     `json:"content"`
+	Link       string                     `json:"link"` // 站内页面路径，没有可跳转的页面时为空
+	IsRead     bool                       `json:"isRead"`
+	CreatedAt  string                     `json:"createdAt"`
 }
 
 type NotificationListResponse struct {
@@ -49,6 +74,18 @@ type NotificationListResponse struct {
 	Size          int                     `json:"size"`
 }
 
+// NotificationQueryParams 通知历史查询参数
+type NotificationQueryParams struct {
+	Page    int
+	Size    int
+	Filters []repo.FilterCondition
+}
+
+// NotificationIDsRequest 批量操作通知请求
+type NotificationIDsRequest struct {
+	IDs []uint64 `json:"ids" binding:"required,min=1,max=100"`
+}
+
 func (s *NotificationService) convertToFrontendFormat(c *gin.Context, notification *model.Notification) *FrontendNotification {
 	if notification == nil {
 		return nil
@@ -61,46 +98,87 @@ func (s *NotificationService) convertToFrontendFormat(c *gin.Context, notificati
 		sender.Avatar = s.FileService.BuildFileResponse(c, notification.Sender.Avatar)
 	}
 
+	payload := json.RawMessage(notification.Payload)
+	if !json.Valid(payload) {
+		payload = json.RawMessage("{}")
+	}
+	title, content := describeNotification(notification)
+
 	return &FrontendNotification{
-		ID:        notification.ID,
-		Type:      string(notification.Type),
-		MomentID:  notification.MomentID,
-		CommentID: notification.CommentID,
-		Sender:    sender,
-		Content:   notification.Content,
-		IsRead:    notification.IsRead,
-		CreatedAt: notification.CreatedAt.Format("2006-01-02 15:04:05"),
+		ID:         notification.ID,
+		Type:       string(notification.Type),
+		EntityType: string(notification.EntityType),
+		EntityID:   notification.EntityID,
+		Payload:    payload,
+		Sender:     sender,
+		Title:      title,
+		Content:    content,
+		Link:       notificationLink(notification),
+		IsRead:     notification.IsRead,
+		CreatedAt:  notification.CreatedAt.Format("2006-01-02 15:04:05"),
 	}
 }
 
-func (s *NotificationService) CreateNotification(ctx context.Context, userID, senderID, momentID, commentID uint64, notificationType model.NotificationType, content string) error {
+// CreateNotification 创建通知，payload 为通知类型对应的 model.*Payload
+func (s *NotificationService) CreateNotification(ctx context.Context, userID, senderID uint64, notificationType model.NotificationType, entityType model.NotificationEntityType, entityID uint64, payload any) error {
 	notification := &model.Notification{
-		UserID:    userID,
-		SenderID:  senderID,
-		MomentID:  momentID,
-		CommentID: commentID,
-		Type:      notificationType,
-		Content:   content,
-		IsRead:    false,
+		UserID:     userID,
+		SenderID:   senderID,
+		Type:       notificationType,
+		EntityType: entityType,
+		EntityID:   entityID,
+		IsRead:     false,
+	}
+	if err := notification.SetPayload(payload); err != nil {
+		s.Log.Error("序列化通知数据失败", "error", err, "type", notificationType)
+		return fmt.Errorf("创建通知失败")
 	}
 
 	s.Log.Info("正在创建通知",
 		"receiverID", userID,
 		"senderID", senderID,
-		"momentID", momentID,
-		"commentID", commentID,
 		"type", notificationType,
+		"entityType", entityType,
+		"entityID", entityID,
 	)
 
-	if err := s.NotificationRepo.Create(ctx, notification); err != nil {
+	// 通知与站外投递记录在同一事务中写入，由后台任务投递
+	channelIDs := s.deliveryChannels(ctx, userID, notificationType)
+	if err := s.NotificationRepo.CreateWithDeliveries(ctx, notification, channelIDs); err != nil {
 		s.Log.Error("创建通知失败", "error", err)
 		return fmt.Errorf("创建通知失败")
 	}
 
-	s.Log.Info("通知创建成功", "notificationID", notification.ID, "receiverID", userID)
+	s.Log.Info("通知创建成功", "notificationID", notification.ID, "receiverID", userID, "deliveries", len(channelIDs))
+	s.publishNotification(ctx, notification)
 	return nil
 }
 
+// deliveryChannels 接收者已启用且接收该类型通知的站外渠道，查询失败时只发送站内通知
+// 说明：开启通知摘要后邮件渠道不再逐条投递，改为定期汇总发送
+func (s *NotificationService) deliveryChannels(ctx context.Context, userID uint64, notificationType model.NotificationType) []uint64 {
+	channels, err := s.ChannelRepo.ListEnabledByUserID(ctx, userID)
+	if err != nil {
+		s.Log.Error("查询通知渠道失败", "error", err, "userID", userID)
+		return nil
+	}
+	digested, err := s.DigestRepo.IsEnabled(ctx, userID)
+	if err != nil {
+		s.Log.Error("查询通知摘要设置失败", "error", err, "userID", userID)
+	}
+
+	var ids []uint64
+	for i := range channels {
+		if digested && channels[i].Kind == notify.KindEmail {
+			continue
+		}
+		if _, ok := s.Channels[channels[i].Kind]; ok && channels[i].Accepts(notificationType) {
+			ids = append(ids, channels[i].ID)
+		}
+	}
+	return ids
+}
+
 func (s *NotificationService) ListUnreadNotifications(c *gin.Context, userID uint64, page, size int) (*NotificationListResponse, error) {
 	ctx := c.Request.Context()
 	s.Log.Info("获取未读通知列表", "userID", userID, "page", page, "size", size)
@@ -129,10 +207,106 @@ func (s *NotificationService) GetUnreadCount(ctx context.Context, userID uint64)
 	return s.NotificationRepo.CountUnreadByUserID(ctx, userID)
 }
 
+// ListNotifications 分页查询通知历史，包含已读通知，可按类型和已读状态过滤
+func (s *NotificationService) ListNotifications(c *gin.Context, userID uint64, params *NotificationQueryParams) (*NotificationListResponse, error) {
+	var opts []repo.QueryOption
+	if len(params.Filters) > 0 {
+		opts = append(opts, repo.WithConditions(params.Filters...))
+	}
+
+	notifications, total, err := s.NotificationRepo.FindByUserID(c.Request.Context(), userID, params.Page, params.Size, opts...)
+	if err != nil {
+		s.Log.Error("获取通知历史失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	result := make([]*FrontendNotification, len(notifications))
+	for i := range notifications {
+		result[i] = s.convertToFrontendFormat(c, &notifications[i])
+	}
+
+	return &NotificationListResponse{
+		Notifications: result,
+		Total:         total,
+		Page:          params.Page,
+		Size:          params.Size,
+	}, nil
+}
+
 func (s *NotificationService) MarkAsRead(ctx context.Context, id uint64, userID uint64) error {
-	return s.NotificationRepo.MarkAsRead(ctx, id)
+	if _, err := s.NotificationRepo.MarkAsRead(ctx, userID, []uint64{id}); err != nil {
+		return err
+	}
+	s.publishUnreadCount(ctx, userID)
+	return nil
+}
+
+// MarkManyAsRead 批量标记已读，不属于当前用户的通知会被忽略
+//
+// 返回：由未读变为已读的通知数
+func (s *NotificationService) MarkManyAsRead(ctx context.Context, userID uint64, ids []uint64) (int64, error) {
+	updated, err := s.NotificationRepo.MarkAsRead(ctx, userID, ids)
+	if err != nil {
+		s.Log.Error("批量标记已读失败", "error", err, "userID", userID)
+		return 0, fmt.Errorf("系统内部错误")
+	}
+	if updated > 0 {
+		s.publishUnreadCount(ctx, userID)
+	}
+	return updated, nil
+}
+
+// DeleteNotifications 批量删除通知，不属于当前用户的通知会被忽略
+//
+// 返回：删除的通知数
+func (s *NotificationService) DeleteNotifications(ctx context.Context, userID uint64, ids []uint64) (int64, error) {
+	deleted, err := s.NotificationRepo.DeleteByIDs(ctx, userID, ids)
+	if err != nil {
+		s.Log.Error("删除通知失败", "error", err, "userID", userID)
+		return 0, fmt.Errorf("系统内部错误")
+	}
+	if deleted > 0 {
+		s.Log.Info("通知已删除", "userID", userID, "count", deleted)
+		s.publishUnreadCount(ctx, userID)
+	}
+	return deleted, nil
 }
 
 func (s *NotificationService) MarkAllAsRead(ctx context.Context, userID uint64) error {
-	return s.NotificationRepo.MarkAllAsRead(ctx, userID)
+	if err := s.NotificationRepo.MarkAllAsRead(ctx, userID); err != nil {
+		return err
+	}
+	s.publishUnreadCount(ctx, userID)
+	return nil
+}
+
+// retentionDays 读取已读通知保留天数
+func (s *NotificationService) retentionDays(ctx context.Context) int {
+	setting, err := s.SettingRepo.GetSettingByKey(ctx, NotificationRetentionSettingKey)
+	if err != nil || setting.Value == "" {
+		return defaultNotificationRetentionDays
+	}
+	days, err := strconv.Atoi(setting.Value)
+	if err != nil || days < 0 {
+		s.Log.Warn("已读通知保留天数设置无效，使用默认值", "value", setting.Value)
+		return defaultNotificationRetentionDays
+	}
+	return days
+}
+
+// PurgeExpired 清理超出保留期限的已读通知，未读通知始终保留，由后台任务定期调用
+func (s *NotificationService) PurgeExpired(ctx context.Context) error {
+	days := s.retentionDays(ctx)
+	if days == 0 {
+		return nil
+	}
+
+	deleted, err := s.NotificationRepo.DeleteReadBefore(ctx, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		s.Log.Info("已清理过期通知", "count", deleted, "retentionDays", days)
+	}
+	return nil
 }
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/config"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/notify"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+// NotificationChannelService 用户站外通知渠道的管理与投递
+type NotificationChannelService struct {
+	*BaseService
+	ChannelRepo  *repo.NotificationChannelRepo
+	DeliveryRepo *repo.NotificationDeliveryRepo
+	UserRepo     *repo.UserRepo
+	SettingRepo  *repo.SettingRepo
+	Channels     notify.Channels
+	appCfg       *config.AppConfig
+}
+
+func NewNotificationChannelService(log *log.Logger, channelRepo *repo.NotificationChannelRepo, deliveryRepo *repo.NotificationDeliveryRepo, userRepo *repo.UserRepo, settingRepo *repo.SettingRepo, channels notify.Channels, appCfg *config.AppConfig) *NotificationChannelService {
+	return &NotificationChannelService{
+		BaseService:  &BaseService{Log: log},
+		ChannelRepo:  channelRepo,
+		DeliveryRepo: deliveryRepo,
+		UserRepo:     userRepo,
+		SettingRepo:  settingRepo,
+		Channels:     channels,
+		appCfg:       appCfg,
+	}
+}
+
+// NotificationChannelRequest 创建通知渠道请求
+type NotificationChannelRequest struct {
+	Kind    string                   `json:"kind" binding:"required,oneof=email webhook telegram webpush"`
+	Name    string                   `json:"name" binding:"max=64"`
+	Target  json.RawMessage          `json:"target" swaggertype:"object"` // 投递目标，格式随渠道类型不同，邮件渠道省略时使用账号邮箱
+	Types   []model.NotificationType `json:"types"`                       // 投递的通知类型，为空表示全部类型
+	Enabled *bool                    `json:"enabled"`                     // 默认启用
+}
+
+// NotificationChannelUpdateRequest 修改通知渠道请求，省略的字段保持不变
+type NotificationChannelUpdateRequest struct {
+	Name    *string                   `json:"name" binding:"omitempty,max=64"`
+	Target  json.RawMessage           `json:"target" swaggertype:"object"`
+	Types   *[]model.NotificationType `json:"types"`
+	Enabled *bool                     `json:"enabled"`
+}
+
+// FrontendNotificationChannel 通知渠道，不包含投递目标中的密钥
+type FrontendNotificationChannel struct {
+	ID              uint64   `json:"id"`
+	Kind            string   `json:"kind"`
+	Name            string   `json:"name"`
+	Summary         string   `json:"summary"` // 投递目标的简短描述，如邮箱地址、Webhook 地址
+	Types           []string `json:"types"`
+	Enabled         bool     `json:"enabled"`
+	Available       bool     `json:"available"` // 站点是否已配置该渠道，未配置时不会投递
+	LastError       string   `json:"lastError,omitempty"`
+	LastDeliveredAt string   `json:"lastDeliveredAt,omitempty"`
+	CreatedAt       string   `json:"createdAt"`
+}
+
+// NotificationChannelOptions 创建渠道时可选的渠道类型和通知类型
+type NotificationChannelOptions struct {
+	Kinds          []NotificationChannelKind `json:"kinds"`
+	Types          []string                  `json:"types"`
+	VAPIDPublicKey string                    `json:"vapidPublicKey,omitempty"` // 浏览器订阅推送时使用的 applicationServerKey
+}
+
+type NotificationChannelKind struct {
+	Kind      string `json:"kind"`
+	Available bool   `json:"available"`
+}
+
+func (s *NotificationChannelService) convertToFrontendFormat(channel *model.NotificationChannel) *FrontendNotificationChannel {
+	impl, available := s.Channels[channel.Kind]
+
+	result := &FrontendNotificationChannel{
+		ID:        channel.ID,
+		Kind:      channel.Kind,
+		Name:      channel.Name,
+		Types:     make([]string, len(channel.Types)),
+		Enabled:   channel.Enabled,
+		Available: available,
+		LastError: channel.LastError,
+		CreatedAt: channel.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if available {
+		result.Summary = impl.Summary([]byte(channel.Target))
+	}
+	for i, t := range channel.Types {
+		result.Types[i] = string(t)
+	}
+	if channel.LastDeliveredAt != nil {
+		result.LastDeliveredAt = channel.LastDeliveredAt.Format("2006-01-02 15:04:05")
+	}
+	return result
+}
+
+// GetOptions 获取可用的渠道类型
+func (s *NotificationChannelService) GetOptions() *NotificationChannelOptions {
+	options := &NotificationChannelOptions{}
+	for _, kind := range notify.Kinds {
+		_, available := s.Channels[kind]
+		options.Kinds = append(options.Kinds, NotificationChannelKind{Kind: kind, Available: available})
+	}
+	for _, t := range model.NotificationTypes {
+		options.Types = append(options.Types, string(t))
+	}
+	if webPush, ok := s.Channels[notify.KindWebPush].(*notify.WebPush); ok {
+		options.VAPIDPublicKey = webPush.PublicKey()
+	}
+	return options
+}
+
+// ListChannels 获取用户的通知渠道
+func (s *NotificationChannelService) ListChannels(ctx context.Context, userID uint64) ([]*FrontendNotificationChannel, error) {
+	channels, err := s.ChannelRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		s.Log.Error("获取通知渠道失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	result := make([]*FrontendNotificationChannel, len(channels))
+	for i := range channels {
+		result[i] = s.convertToFrontendFormat(&channels[i])
+	}
+	return result, nil
+}
+
+// CreateChannel 创建通知渠道，投递目标相同的渠道已存在时更新该渠道（浏览器重复订阅推送时不产生重复渠道）
+func (s *NotificationChannelService) CreateChannel(ctx context.Context, userID uint64, req *NotificationChannelRequest) (*FrontendNotificationChannel, error) {
+	impl, ok := s.Channels[req.Kind]
+	if !ok {
+		return nil, errMsg.ErrNotificationChannelUnavailable
+	}
+	if err := validateNotificationTypes(req.Types); err != nil {
+		return nil, err
+	}
+
+	rawTarget := req.Target
+	if req.Kind == notify.KindEmail && emailTargetMissing(rawTarget) {
+		address, err := s.accountEmail(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		rawTarget, _ = json.Marshal(notify.EmailTarget{Address: address})
+	}
+	target, err := impl.Validate(rawTarget)
+	if err != nil {
+		s.Log.Info("通知渠道投递目标无效", "error", err, "kind", req.Kind, "userID", userID)
+		return nil, errMsg.ErrInvalidNotificationTarget
+	}
+
+	channel, err := s.ChannelRepo.FindByTarget(ctx, userID, req.Kind, string(target))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.Log.Error("查询通知渠道失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	if channel == nil {
+		channel = &model.NotificationChannel{UserID: userID, Kind: req.Kind, Target: string(target)}
+	}
+	channel.Name = req.Name
+	channel.Types = req.Types
+	channel.Enabled = req.Enabled == nil || *req.Enabled
+	channel.LastError = ""
+
+	if err := s.ChannelRepo.Update(ctx, channel); err != nil {
+		s.Log.Error("保存通知渠道失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	s.Log.Info("通知渠道已保存", "channelID", channel.ID, "kind", channel.Kind, "userID", userID)
+	return s.convertToFrontendFormat(channel), nil
+}
+
+// UpdateChannel 修改用户自己的通知渠道
+func (s *NotificationChannelService) UpdateChannel(ctx context.Context, userID, id uint64, req *NotificationChannelUpdateRequest) (*FrontendNotificationChannel, error) {
+	channel, err := s.findChannel(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		channel.Name = *req.Name
+	}
+	if req.Types != nil {
+		if err := validateNotificationTypes(*req.Types); err != nil {
+			return nil, err
+		}
+		channel.Types = *req.Types
+	}
+	if len(req.Target) > 0 {
+		impl, ok := s.Channels[channel.Kind]
+		if !ok {
+			return nil, errMsg.ErrNotificationChannelUnavailable
+		}
+		target, err := impl.Validate(req.Target)
+		if err != nil {
+			s.Log.Info("通知渠道投递目标无效", "error", err, "kind", channel.Kind, "userID", userID)
+			return nil, errMsg.ErrInvalidNotificationTarget
+		}
+		channel.Target = string(target)
+		channel.LastError = ""
+	}
+	if req.Enabled != nil {
+		channel.Enabled = *req.Enabled
+		if channel.Enabled {
+			channel.LastError = ""
+		}
+	}
+
+	if err := s.ChannelRepo.Update(ctx, channel); err != nil {
+		s.Log.Error("保存通知渠道失败", "error", err, "channelID", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return s.convertToFrontendFormat(channel), nil
+}
+
+// DeleteChannel 删除用户自己的通知渠道，未完成的投递一并取消
+func (s *NotificationChannelService) DeleteChannel(ctx context.Context, userID, id uint64) error {
+	deleted, err := s.ChannelRepo.DeleteByUser(ctx, id, userID)
+	if err != nil {
+		s.Log.Error("删除通知渠道失败", "error", err, "channelID", id)
+		return fmt.Errorf("系统内部错误")
+	}
+	if !deleted {
+		return errMsg.ErrNotificationChannelNotFound
+	}
+	s.Log.Info("通知渠道已删除", "channelID", id, "userID", userID)
+	return nil
+}
+
+// TestChannel 立即向渠道发送一条测试通知，不经过发件箱，返回发送失败的原因
+func (s *NotificationChannelService) TestChannel(ctx context.Context, userID, id uint64) error {
+	channel, err := s.findChannel(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	impl, ok := s.Channels[channel.Kind]
+	if !ok {
+		return errMsg.ErrNotificationChannelUnavailable
+	}
+
+	msg := &notify.Message{
+		Type:  "test",
+		Site:  s.siteTitle(ctx),
+		Title: "测试通知",
+		Body:  "收到这条消息说明通知渠道配置正确。",
+		URL:   s.siteURL(),
+		Time:  time.Now(),
+	}
+	sendCtx, cancel := context.WithTimeout(ctx, s.deliveryTimeout())
+	defer cancel()
+	if err := impl.Send(sendCtx, []byte(channel.Target), msg); err != nil {
+		s.Log.Info("测试通知发送失败", "error", err, "channelID", id)
+		_ = s.ChannelRepo.RecordFailure(ctx, id, err.Error(), false)
+		return err
+	}
+	_ = s.ChannelRepo.RecordSuccess(ctx, id, time.Now())
+	return nil
+}
+
+func (s *NotificationChannelService) findChannel(ctx context.Context, userID, id uint64) (*model.NotificationChannel, error) {
+	channel, err := s.ChannelRepo.FindByUser(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errMsg.ErrNotificationChannelNotFound
+		}
+		s.Log.Error("查询通知渠道失败", "error", err, "channelID", id)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return channel, nil
+}
+
+// accountEmail 用户账号的邮箱，邮件渠道未填写地址时使用
+func (s *NotificationChannelService) accountEmail(ctx context.Context, userID uint64) (string, error) {
+	user, err := s.UserRepo.FindByID(ctx, userID)
+	if err != nil {
+		s.Log.Error("查询用户失败", "error", err, "userID", userID)
+		return "", fmt.Errorf("系统内部错误")
+	}
+	if user.Email == nil || *user.Email == "" {
+		return "", errMsg.ErrInvalidNotificationTarget
+	}
+	return *user.Email, nil
+}
+
+func emailTargetMissing(raw json.RawMessage) bool {
+	var target notify.EmailTarget
+	return len(raw) == 0 || string(raw) == "null" || (json.Unmarshal(raw, &target) == nil && target.Address == "")
+}
+
+func validateNotificationTypes(types []model.NotificationType) error {
+	for _, t := range types {
+		if !t.Valid() {
+			return errMsg.ErrInvalidNotificationType
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/notify"
+)
+
+const (
+	// outboxBatchSize 每次处理的待投递记录数
+	outboxBatchSize = 50
+	// defaultDeliveryAttempts 未配置时每条投递的最大尝试次数
+	defaultDeliveryAttempts = 6
+	// defaultDeliveryRetryDelay 未配置时的首次重试间隔，之后每次翻倍
+	defaultDeliveryRetryDelay = 30 * time.Second
+	// defaultDeliveryTimeout 未配置时单次投递的超时
+	defaultDeliveryTimeout = 15 * time.Second
+)
+
+// ProcessOutbox 投递发件箱中到期的记录，失败时按指数退避安排重试
+// 目标已失效（如推送订阅已取消）时停用渠道，不再重试
+func (s *NotificationChannelService) ProcessOutbox(ctx context.Context) error {
+	deliveries, err := s.DeliveryRepo.FindDue(ctx, time.Now(), outboxBatchSize)
+	if err != nil {
+		return fmt.Errorf("查询待投递通知失败: %w", err)
+	}
+
+	site := ""
+	if len(deliveries) > 0 {
+		site = s.siteTitle(ctx)
+	}
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return nil
+		}
+		s.deliver(ctx, &deliveries[i], site)
+	}
+	return nil
+}
+
+func (s *NotificationChannelService) deliver(ctx context.Context, delivery *model.NotificationDelivery, site string) {
+	attempts := delivery.Attempts + 1
+	channel := delivery.Channel
+
+	// 通知或渠道已删除、渠道已停用时放弃
+	var reason string
+	switch {
+	case delivery.Notification == nil:
+		reason = "通知已删除"
+	case channel == nil:
+		reason = "渠道已删除"
+	case !channel.Enabled:
+		reason = "渠道已停用"
+	}
+	if reason != "" {
+		s.markFailed(ctx, delivery, attempts, reason)
+		return
+	}
+
+	impl, ok := s.Channels[channel.Kind]
+	if !ok {
+		s.markFailed(ctx, delivery, attempts, "站点未配置该渠道")
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, s.deliveryTimeout())
+	err := impl.Send(sendCtx, []byte(channel.Target), s.buildMessage(delivery.Notification, site))
+	cancel()
+
+	now := time.Now()
+	if err == nil {
+		if err := s.DeliveryRepo.MarkSent(ctx, delivery.ID, attempts, now); err != nil {
+			s.Log.Error("更新投递状态失败", "error", err, "deliveryID", delivery.ID)
+		}
+		if err := s.ChannelRepo.RecordSuccess(ctx, channel.ID, now); err != nil {
+			s.Log.Error("更新通知渠道失败", "error", err, "channelID", channel.ID)
+		}
+		s.Log.Info("通知投递成功", "deliveryID", delivery.ID, "channelID", channel.ID, "kind", channel.Kind, "attempts", attempts)
+		return
+	}
+	if ctx.Err() != nil {
+		// 服务停止导致的失败不计入尝试次数
+		return
+	}
+
+	gone := errors.Is(err, notify.ErrGone)
+	if gone || errors.Is(err, notify.ErrPermanent) || attempts >= s.maxAttempts() {
+		s.Log.Warn("通知投递失败，已放弃", "error", err, "deliveryID", delivery.ID, "channelID", channel.ID, "kind", channel.Kind, "attempts", attempts)
+		s.markFailed(ctx, delivery, attempts, err.Error())
+		if err := s.ChannelRepo.RecordFailure(ctx, channel.ID, err.Error(), gone); err != nil {
+			s.Log.Error("更新通知渠道失败", "error", err, "channelID", channel.ID)
+		}
+		return
+	}
+
+	next := now.Add(s.retryDelay() << (attempts - 1))
+	s.Log.Info("通知投递失败，稍后重试", "error", err, "deliveryID", delivery.ID, "channelID", channel.ID, "attempt", attempts, "next", next)
+	if err := s.DeliveryRepo.MarkRetry(ctx, delivery.ID, attempts, next, err.Error()); err != nil {
+		s.Log.Error("更新投递状态失败", "error", err, "deliveryID", delivery.ID)
+	}
+}
+
+func (s *NotificationChannelService) markFailed(ctx context.Context, delivery *model.NotificationDelivery, attempts int, reason string) {
+	if err := s.DeliveryRepo.MarkFailed(ctx, delivery.ID, attempts, reason); err != nil {
+		s.Log.Error("更新投递状态失败", "error", err, "deliveryID", delivery.ID)
+	}
+}
+
+// buildMessage 生成站外通知，链接使用配置的站点地址
+func (s *NotificationChannelService) buildMessage(notification *model.Notification, site string) *notify.Message {
+	title, body := describeNotification(notification)
+
+	url := s.siteURL()
+	if link := notificationLink(notification); url != "" && link != "" {
+		url += link
+	}
+
+	return &notify.Message{
+		ID:    notification.ID,
+		Type:  string(notification.Type),
+		Site:  site,
+		Title: title,
+		Body:  body,
+		URL:   url,
+		Time:  notification.CreatedAt,
+	}
+}
+
+// siteTitle 获取站点标题，用于通知标题
+func (s *NotificationChannelService) siteTitle(ctx context.Context) string {
+	setting, err := s.SettingRepo.GetSettingByKey(ctx, "siteTitle")
+	if err != nil || setting.Value == "" {
+		return s.appCfg.App.Name
+	}
+	return setting.Value
+}
+
+// siteURL 站外通知中的链接只能使用配置的站点地址，后台任务没有请求可以推断域名
+func (s *NotificationChannelService) siteURL() string {
+	return strings.TrimRight(s.appCfg.Mail.SiteURL, "/")
+}
+
+func (s *NotificationChannelService) maxAttempts() int {
+	if s.appCfg.Notify.MaxAttempts > 0 {
+		return s.appCfg.Notify.MaxAttempts
+	}
+	return defaultDeliveryAttempts
+}
+
+func (s *NotificationChannelService) retryDelay() time.Duration {
+	if s.appCfg.Notify.RetryDelay > 0 {
+		return time.Duration(s.appCfg.Notify.RetryDelay) * time.Second
+	}
+	return defaultDeliveryRetryDelay
+}
+
+func (s *NotificationChannelService) deliveryTimeout() time.Duration {
+	if s.appCfg.Notify.Timeout > 0 {
+		return time.Duration(s.appCfg.Notify.Timeout) * time.Second
+	}
+	return defaultDeliveryTimeout
+}
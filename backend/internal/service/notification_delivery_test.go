@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/bookandmusic/love-girl/internal/config"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/notify"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+// webhookStub 本地 Webhook 接收端，按顺序返回 statuses 中的状态码，用完后返回 204
+type webhookStub struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	payloads []notify.WebhookPayload
+}
+
+func newWebhookStub(t *testing.T, statuses ...int) *webhookStub {
+	t.Helper()
+	stub := &webhookStub{statuses: statuses}
+	stub.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload notify.WebhookPayload
+		_ = json.Unmarshal(body, &payload)
+
+		stub.mu.Lock()
+		stub.payloads = append(stub.payloads, payload)
+		code := http.StatusNoContent
+		if len(stub.statuses) > 0 {
+			code, stub.statuses = stub.statuses[0], stub.statuses[1:]
+		}
+		stub.mu.Unlock()
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+func (s *webhookStub) calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.payloads)
+}
+
+type outboxTest struct {
+	svc        *NotificationChannelService
+	db         *gorm.DB
+	deliveryID uint64
+	channelID  uint64
+}
+
+// newOutboxTest 创建一条指向 stub 的 Webhook 投递；测试服务器在回环地址上，使用不限制地址的客户端
+func newOutboxTest(t *testing.T, stub *webhookStub, notifyCfg config.NotifyConfig) *outboxTest {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.Setting{}, &model.Notification{}, &model.NotificationChannel{}, &model.NotificationDelivery{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	user := &model.User{Name: "partner", Password: "x"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	notification := &model.Notification{
+		UserID:     user.ID,
+		SenderID:   user.ID,
+		Type:       model.NotificationTypePublish,
+		EntityType: model.NotificationEntityMoment,
+		EntityID:   7,
+	}
+	if err := db.Create(notification).Error; err != nil {
+		t.Fatalf("create notification: %v", err)
+	}
+	target, _ := json.Marshal(notify.WebhookTarget{URL: stub.server.URL})
+	channel := &model.NotificationChannel{UserID: user.ID, Kind: "webhook", Target: string(target), Enabled: true}
+	if err := db.Create(channel).Error; err != nil {
+		t.Fatalf("create channel: %v", err)
+	}
+	delivery := &model.NotificationDelivery{
+		NotificationID: notification.ID,
+		ChannelID:      channel.ID,
+		Status:         model.DeliveryStatusPending,
+		NextAttemptAt:  time.Now().Add(-time.Second),
+	}
+	if err := db.Create(delivery).Error; err != nil {
+		t.Fatalf("create delivery: %v", err)
+	}
+
+	cfg := &config.AppConfig{
+		App:    config.AppConfigApp{Name: "love-girl"},
+		Mail:   config.MailConfig{SiteURL: "https://love.example.com/"},
+		Notify: notifyCfg,
+	}
+	channels := notify.Channels{"webhook": notify.NewWebhook(stub.server.Client())}
+	svc := NewNotificationChannelService(log.NewLogger(config.LogConfig{Level: "error"}),
+		repo.NewNotificationChannelRepo(db), repo.NewNotificationDeliveryRepo(db), repo.NewUserRepo(db, nil),
+		repo.NewSettingRepo(db), channels, cfg)
+	return &outboxTest{svc: svc, db: db, deliveryID: delivery.ID, channelID: channel.ID}
+}
+
+func (o *outboxTest) process(t *testing.T) {
+	t.Helper()
+	if err := o.svc.ProcessOutbox(context.Background()); err != nil {
+		t.Fatalf("ProcessOutbox: %v", err)
+	}
+}
+
+func (o *outboxTest) delivery(t *testing.T) model.NotificationDelivery {
+	t.Helper()
+	var delivery model.NotificationDelivery
+	if err := o.db.First(&delivery, o.deliveryID).Error; err != nil {
+		t.Fatalf("load delivery: %v", err)
+	}
+	return delivery
+}
+
+func (o *outboxTest) channel(t *testing.T) model.NotificationChannel {
+	t.Helper()
+	var channel model.NotificationChannel
+	if err := o.db.First(&channel, o.channelID).Error; err != nil {
+		t.Fatalf("load channel: %v", err)
+	}
+	return channel
+}
+
+// makeDue 模拟重试时间已到
+func (o *outboxTest) makeDue(t *testing.T) {
+	t.Helper()
+	if err := o.db.Model(&model.NotificationDelivery{}).Where("id = ?", o.deliveryID).
+		Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatalf("update delivery: %v", err)
+	}
+}
+
+func TestProcessOutboxRetryBackoff(t *testing.T) {
+	stub := newWebhookStub(t, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusTooManyRequests)
+	o := newOutboxTest(t, stub, config.NotifyConfig{MaxAttempts: 3, RetryDelay: 60})
+
+	for attempt, wantDelay := range []time.Duration{time.Minute, 2 * time.Minute} {
+		before := time.Now()
+		o.process(t)
+		delivery := o.delivery(t)
+		if delivery.Status != model.DeliveryStatusPending || delivery.Attempts != attempt+1 {
+			t.Fatalf("第 %d 次失败后 status=%s attempts=%d", attempt+1, delivery.Status, delivery.Attempts)
+		}
+		if delay := delivery.NextAttemptAt.Sub(before); delay < wantDelay || delay > wantDelay+5*time.Second {
+			t.Fatalf("第 %d 次失败后重试间隔 = %v, want %v", attempt+1, delay, wantDelay)
+		}
+		if !strings.Contains(delivery.LastError, "status") {
+			t.Fatalf("LastError = %q", delivery.LastError)
+		}
+
+		// 未到重试时间时不投递
+		o.process(t)
+		if stub.calls() != attempt+1 {
+			t.Fatalf("未到重试时间不应投递，调用次数 = %d", stub.calls())
+		}
+		o.makeDue(t)
+	}
+
+	// 达到最大尝试次数后放弃，渠道保持启用
+	o.process(t)
+	delivery := o.delivery(t)
+	if delivery.Status != model.DeliveryStatusFailed || delivery.Attempts != 3 {
+		t.Fatalf("达到最大次数后 status=%s attempts=%d", delivery.Status, delivery.Attempts)
+	}
+	channel := o.channel(t)
+	if !channel.Enabled || channel.LastError == "" {
+		t.Fatalf("channel enabled=%v last_error=%q", channel.Enabled, channel.LastError)
+	}
+}
+
+func TestProcessOutboxRetryThenSuccess(t *testing.T) {
+	stub := newWebhookStub(t, http.StatusInternalServerError)
+	o := newOutboxTest(t, stub, config.NotifyConfig{MaxAttempts: 3, RetryDelay: 1})
+
+	o.process(t)
+	o.makeDue(t)
+	o.process(t)
+
+	delivery := o.delivery(t)
+	if delivery.Status != model.DeliveryStatusSent || delivery.Attempts != 2 || delivery.SentAt == nil || delivery.LastError != "" {
+		t.Fatalf("delivery = %+v", delivery)
+	}
+	channel := o.channel(t)
+	if channel.LastDeliveredAt == nil || channel.LastError != "" {
+		t.Fatalf("channel = %+v", channel)
+	}
+	if payload := stub.payloads[1]; payload.URL != "https://love.example.com/moments#moment-7" {
+		t.Fatalf("通知链接应使用配置的站点地址，实际为 %q", payload.URL)
+	}
+}
+
+func TestProcessOutboxGivesUp(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		wantEnabled bool
+	}{
+		{name: "目标拒绝请求", status: http.StatusBadRequest, wantEnabled: true},
+		{name: "目标已失效", status: http.StatusGone, wantEnabled: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newWebhookStub(t, tt.status)
+			o := newOutboxTest(t, stub, config.NotifyConfig{MaxAttempts: 5, RetryDelay: 1})
+
+			o.process(t)
+			delivery := o.delivery(t)
+			if delivery.Status != model.DeliveryStatusFailed || delivery.Attempts != 1 {
+				t.Fatalf("应立即放弃，status=%s attempts=%d", delivery.Status, delivery.Attempts)
+			}
+			if channel := o.channel(t); channel.Enabled != tt.wantEnabled {
+				t.Fatalf("channel enabled = %v, want %v", channel.Enabled, tt.wantEnabled)
+			}
+		})
+	}
+}
+
+func TestProcessOutboxSkipsDisabledChannel(t *testing.T) {
+	stub := newWebhookStub(t)
+	o := newOutboxTest(t, stub, config.NotifyConfig{})
+	if err := o.db.Model(&model.NotificationChannel{}).Where("id = ?", o.channelID).Update("enabled", false).Error; err != nil {
+		t.Fatalf("disable channel: %v", err)
+	}
+
+	o.process(t)
+	if delivery := o.delivery(t); delivery.Status != model.DeliveryStatusFailed {
+		t.Fatalf("status = %s, want failed", delivery.Status)
+	}
+	if stub.calls() != 0 {
+		t.Fatal("已停用的渠道不应投递")
+	}
+}
@@ -0,0 +1,409 @@
+package service
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/config"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/mail"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+const (
+	// digestBatchSize 每次处理的到期摘要数
+	digestBatchSize = 20
+	// digestItemLimit 每封摘要最多列出的通知数，其余只计入总数
+	digestItemLimit = 50
+	// defaultDigestSendTime 未指定时的发送时间
+	defaultDigestSendTime = "08:00"
+	// digestUnsubscribePath 邮件中退订链接的路径
+	digestUnsubscribePath = "/api/v1/notification-digest/unsubscribe"
+)
+
+// NotificationDigestService 通知摘要：按用户设置的时间将未读通知汇总为一封邮件
+type NotificationDigestService struct {
+	*BaseService
+	DigestRepo       *repo.NotificationDigestRepo
+	NotificationRepo *repo.NotificationRepo
+	UserRepo         *repo.UserRepo
+	SettingRepo      *repo.SettingRepo
+	MailQueue        *mail.Queue
+	appCfg           *config.AppConfig
+}
+
+func NewNotificationDigestService(log *log.Logger, digestRepo *repo.NotificationDigestRepo, notificationRepo *repo.NotificationRepo, userRepo *repo.UserRepo, settingRepo *repo.SettingRepo, mailQueue *mail.Queue, appCfg *config.AppConfig) *NotificationDigestService {
+	return &NotificationDigestService{
+		BaseService:      &BaseService{Log: log},
+		DigestRepo:       digestRepo,
+		NotificationRepo: notificationRepo,
+		UserRepo:         userRepo,
+		SettingRepo:      settingRepo,
+		MailQueue:        mailQueue,
+		appCfg:           appCfg,
+	}
+}
+
+// NotificationDigestRequest 修改通知摘要设置请求
+type NotificationDigestRequest struct {
+	Frequency string `json:"frequency" binding:"required,oneof=off daily weekly"`
+	SendTime  string `json:"sendTime"`                                // 本地时间 HH:MM，默认 08:00
+	Weekday   *int   `json:"weekday" binding:"omitempty,min=0,max=6"` // 每周摘要的发送日，0 为周日，默认周一
+	Timezone  string `json:"timezone" binding:"omitempty,max=64"`     // IANA 时区，如 Asia/Shanghai，为空时使用服务器时区
+}
+
+// FrontendNotificationDigest 通知摘要设置
+type FrontendNotificationDigest struct {
+	Frequency  string `json:"frequency"`
+	SendTime   string `json:"sendTime"`
+	Weekday    int    `json:"weekday"`
+	Timezone   string `json:"timezone"`
+	Available  bool   `json:"available"` // 站点是否已配置邮件服务和站点地址
+	Email      string `json:"email"`     // 摘要发送到账号邮箱
+	NextSendAt string `json:"nextSendAt,omitempty"`
+	LastSentAt string `json:"lastSentAt,omitempty"`
+}
+
+// digestItem 摘要邮件中的一条通知
+type digestItem struct {
+	Title   string
+	Content string
+	Time    string
+	URL     string
+}
+
+func (s *NotificationDigestService) convertToFrontendFormat(digest *model.NotificationDigest, user *model.User) *FrontendNotificationDigest {
+	result := &FrontendNotificationDigest{
+		Frequency: string(digest.Frequency),
+		SendTime:  digest.SendTime,
+		Weekday:   digest.Weekday,
+		Timezone:  digest.Timezone,
+		Available: s.available(),
+	}
+	if user != nil && user.Email != nil {
+		result.Email = *user.Email
+	}
+	if digest.Enabled() && digest.NextSendAt != nil {
+		result.NextSendAt = digest.NextSendAt.In(digestLocation(digest.Timezone)).Format("2006-01-02 15:04")
+	}
+	if digest.LastSentAt != nil {
+		result.LastSentAt = digest.LastSentAt.In(digestLocation(digest.Timezone)).Format("2006-01-02 15:04")
+	}
+	return result
+}
+
+// GetSettings 获取用户的摘要设置，未设置过时返回默认值
+func (s *NotificationDigestService) GetSettings(ctx context.Context, userID uint64) (*FrontendNotificationDigest, error) {
+	digest, err := s.findOrDefault(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.UserRepo.FindByID(ctx, userID)
+	if err != nil {
+		s.Log.Error("查询用户失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return s.convertToFrontendFormat(digest, user), nil
+}
+
+// UpdateSettings 修改摘要设置，开启时从当前时间起计算下次发送时间
+func (s *NotificationDigestService) UpdateSettings(ctx context.Context, userID uint64, req *NotificationDigestRequest) (*FrontendNotificationDigest, error) {
+	digest, err := s.findOrDefault(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.UserRepo.FindByID(ctx, userID)
+	if err != nil {
+		s.Log.Error("查询用户失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	digest.Frequency = model.DigestFrequency(req.Frequency)
+	if req.SendTime != "" {
+		sendTime, err := time.Parse("15:04", req.SendTime)
+		if err != nil {
+			return nil, errMsg.ErrInvalidDigestSchedule
+		}
+		digest.SendTime = sendTime.Format("15:04")
+	}
+	if req.Weekday != nil {
+		digest.Weekday = *req.Weekday
+	}
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			return nil, errMsg.ErrInvalidDigestSchedule
+		}
+	}
+	digest.Timezone = req.Timezone
+
+	if digest.Enabled() {
+		if !s.MailQueue.Enabled() {
+			return nil, errMsg.ErrNotificationChannelUnavailable
+		}
+		if s.siteURL() == "" {
+			return nil, errMsg.ErrDigestSiteURLRequired
+		}
+		if user.Email == nil || *user.Email == "" {
+			return nil, errMsg.ErrDigestEmailRequired
+		}
+		next := nextDigestTime(digest, time.Now())
+		digest.NextSendAt = &next
+	} else {
+		digest.NextSendAt = nil
+	}
+
+	if err := s.DigestRepo.Update(ctx, digest); err != nil {
+		s.Log.Error("保存通知摘要设置失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	s.Log.Info("通知摘要设置已更新", "userID", userID, "frequency", digest.Frequency, "nextSendAt", digest.NextSendAt)
+	return s.convertToFrontendFormat(digest, user), nil
+}
+
+// CheckUnsubscribeToken 校验退订令牌
+func (s *NotificationDigestService) CheckUnsubscribeToken(ctx context.Context, token string) error {
+	_, err := s.findByToken(ctx, token)
+	return err
+}
+
+// Unsubscribe 通过邮件中的退订链接关闭摘要，令牌保持不变，重复退订同样成功
+func (s *NotificationDigestService) Unsubscribe(ctx context.Context, token string) error {
+	digest, err := s.findByToken(ctx, token)
+	if err != nil {
+		return err
+	}
+	if err := s.DigestRepo.Unsubscribe(ctx, digest.ID); err != nil {
+		s.Log.Error("退订通知摘要失败", "error", err, "userID", digest.UserID)
+		return fmt.Errorf("系统内部错误")
+	}
+	s.Log.Info("通知摘要已退订", "userID", digest.UserID)
+	return nil
+}
+
+// SendDueDigests 发送到期的摘要，由后台任务定期调用
+// 说明：没有新的未读通知时不发送邮件，只安排下次发送时间；站点地址未配置时不发送，摘要必须带退订链接
+func (s *NotificationDigestService) SendDueDigests(ctx context.Context) error {
+	if !s.available() {
+		return nil
+	}
+
+	now := time.Now()
+	digests, err := s.DigestRepo.FindDue(ctx, now, digestBatchSize)
+	if err != nil {
+		return fmt.Errorf("查询待发送摘要失败: %w", err)
+	}
+
+	site := ""
+	if len(digests) > 0 {
+		site = s.siteTitle(ctx)
+	}
+	for i := range digests {
+		if ctx.Err() != nil {
+			return nil
+		}
+		s.send(ctx, &digests[i], site, now)
+	}
+	return nil
+}
+
+func (s *NotificationDigestService) send(ctx context.Context, digest *model.NotificationDigest, site string, now time.Time) {
+	next := nextDigestTime(digest, now)
+	skip := func(reason string) {
+		s.Log.Debug("跳过通知摘要", "userID", digest.UserID, "reason", reason, "next", next)
+		if err := s.DigestRepo.UpdateSchedule(ctx, digest.ID, nil, next); err != nil {
+			s.Log.Error("更新摘要发送时间失败", "error", err, "userID", digest.UserID)
+		}
+	}
+
+	user := digest.User
+	if user == nil || user.Email == nil || *user.Email == "" {
+		skip("账号没有邮箱")
+		return
+	}
+	notifications, total, err := s.NotificationRepo.FindForDigest(ctx, digest.UserID, now, digestItemLimit)
+	if err != nil {
+		s.Log.Error("查询摘要通知失败", "error", err, "userID", digest.UserID)
+		return
+	}
+	if total == 0 {
+		skip("没有新的未读通知")
+		return
+	}
+
+	msg, err := s.render(digest, user, site, notifications, total)
+	if err != nil {
+		s.Log.Error("渲染通知摘要失败", "error", err, "userID", digest.UserID)
+		return
+	}
+	msg.To = []string{*user.Email}
+	if err := s.MailQueue.Enqueue(msg); err != nil {
+		// 队列已满时保持到期状态，下次任务执行时重试
+		s.Log.Warn("通知摘要加入队列失败", "error", err, "userID", digest.UserID)
+		return
+	}
+
+	if err := s.NotificationRepo.MarkDigested(ctx, digest.UserID, now, now); err != nil {
+		s.Log.Error("记录已汇总通知失败", "error", err, "userID", digest.UserID)
+	}
+	if err := s.DigestRepo.UpdateSchedule(ctx, digest.ID, &now, next); err != nil {
+		s.Log.Error("更新摘要发送时间失败", "error", err, "userID", digest.UserID)
+	}
+	s.Log.Info("通知摘要已加入发送队列", "userID", digest.UserID, "notifications", total, "next", next)
+}
+
+// render 渲染摘要邮件，包含退订链接和一键退订邮件头
+func (s *NotificationDigestService) render(digest *model.NotificationDigest, user *model.User, site string, notifications []model.Notification, total int64) (*mail.Message, error) {
+	baseURL := s.siteURL()
+	loc := digestLocation(digest.Timezone)
+
+	items := make([]digestItem, len(notifications))
+	for i := range notifications {
+		title, content := describeNotification(&notifications[i])
+		items[i] = digestItem{
+			Title:   title,
+			Content: content,
+			Time:    notifications[i].CreatedAt.In(loc).Format("01-02 15:04"),
+		}
+		if link := notificationLink(&notifications[i]); link != "" {
+			items[i].URL = baseURL + link
+		}
+	}
+
+	period := "今日"
+	if digest.Frequency == model.DigestFrequencyWeekly {
+		period = "本周"
+	}
+	unsubscribeURL := baseURL + digestUnsubscribePath + "?token=" + url.QueryEscape(digest.UnsubscribeToken)
+
+	msg, err := mail.Render("notification_digest", map[string]any{
+		"Site":           site,
+		"UserName":       user.Name,
+		"Period":         period,
+		"Total":          total,
+		"Items":          items,
+		"More":           total - int64(len(items)),
+		"SiteURL":        baseURL,
+		"UnsubscribeURL": unsubscribeURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// RFC 8058 一键退订，邮件客户端以 POST 请求退订链接
+	msg.Headers = map[string]string{
+		"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+	return msg, nil
+}
+
+// findOrDefault 查询用户的摘要设置，不存在时返回未保存的默认设置
+func (s *NotificationDigestService) findOrDefault(ctx context.Context, userID uint64) (*model.NotificationDigest, error) {
+	digest, err := s.DigestRepo.FindByUserID(ctx, userID)
+	if err == nil {
+		return digest, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.Log.Error("查询通知摘要设置失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	token, err := generateUnsubscribeToken()
+	if err != nil {
+		s.Log.Error("生成退订令牌失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return &model.NotificationDigest{
+		UserID:           userID,
+		Frequency:        model.DigestFrequencyOff,
+		SendTime:         defaultDigestSendTime,
+		Weekday:          int(time.Monday),
+		UnsubscribeToken: token,
+	}, nil
+}
+
+func (s *NotificationDigestService) findByToken(ctx context.Context, token string) (*model.NotificationDigest, error) {
+	if token == "" {
+		return nil, errMsg.ErrInvalidUnsubscribeToken
+	}
+	digest, err := s.DigestRepo.FindByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errMsg.ErrInvalidUnsubscribeToken
+		}
+		s.Log.Error("查询通知摘要设置失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return digest, nil
+}
+
+// siteTitle 获取站点标题，用于邮件主题
+func (s *NotificationDigestService) siteTitle(ctx context.Context) string {
+	setting, err := s.SettingRepo.GetSettingByKey(ctx, "siteTitle")
+	if err != nil || setting.Value == "" {
+		return s.appCfg.App.Name
+	}
+	return setting.Value
+}
+
+// nextDigestTime 在 after 之后的下一个发送时间，按用户时区计算，不受夏令时影响
+func nextDigestTime(digest *model.NotificationDigest, after time.Time) time.Time {
+	loc := digestLocation(digest.Timezone)
+	sendTime, err := time.Parse("15:04", digest.SendTime)
+	if err != nil {
+		sendTime, _ = time.Parse("15:04", defaultDigestSendTime)
+	}
+
+	local := after.In(loc)
+	for i := 0; i <= 7; i++ {
+		candidate := time.Date(local.Year(), local.Month(), local.Day()+i, sendTime.Hour(), sendTime.Minute(), 0, 0, loc)
+		if !candidate.After(after) {
+			continue
+		}
+		if digest.Frequency == model.DigestFrequencyWeekly && int(candidate.Weekday()) != digest.Weekday {
+			continue
+		}
+		return candidate
+	}
+	return after.Add(24 * time.Hour)
+}
+
+// digestLocation 用户设置的时区，无效或为空时使用服务器时区
+func digestLocation(timezone string) *time.Location {
+	if timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+func generateUnsubscribeToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := cryptorand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// available 是否可以开启摘要：需要邮件服务，邮件中的链接和退订地址只使用配置的站点地址
+func (s *NotificationDigestService) available() bool {
+	return s.MailQueue.Enabled() && s.siteURL() != ""
+}
+
+// siteURL 邮件链接使用的站点地址，不从请求推断，避免请求头中伪造的域名进入邮件
+func (s *NotificationDigestService) siteURL() string {
+	return strings.TrimRight(s.appCfg.Mail.SiteURL, "/")
+}
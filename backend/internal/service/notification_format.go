@@ -0,0 +1,150 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+const (
+	// albumPagePath、anniversaryPagePath 前台相册页和纪念日页的路径
+	albumPagePath       = "/albums"
+	anniversaryPagePath = "/anniversaries"
+	// notificationExcerptLength 通知中评论和动态摘要的最大字符数
+	notificationExcerptLength = 100
+)
+
+// reactionLabels 表情回应在通知中的展示
+var reactionLabels = map[model.ReactionType]string{
+	model.ReactionLike: "👍",
+	model.ReactionLove: "❤️",
+	model.ReactionHaha: "😄",
+	model.ReactionWow:  "😮",
+	model.ReactionSad:  "😢",
+	model.ReactionHug:  "🤗",
+}
+
+// alertTitles 系统告警类别对应的标题
+var alertTitles = map[string]string{
+	AlertStorageError: "存储服务异常",
+}
+
+// describeNotification 根据通知类型和数据生成标题和正文，站内列表和站外渠道使用相同的文案
+func describeNotification(notification *model.Notification) (string, string) {
+	sender := "有人"
+	if notification.Sender != nil {
+		sender = notification.Sender.Name
+	}
+
+	switch notification.Type {
+	case model.NotificationTypeComment, model.NotificationTypeReply, model.NotificationTypeGuest:
+		var payload model.CommentPayload
+		_ = notification.DecodePayload(&payload)
+		switch notification.Type {
+		case model.NotificationTypeComment:
+			return sender + " 评论了你的动态", payload.Excerpt
+		case model.NotificationTypeReply:
+			return sender + " 回复了你的评论", payload.Excerpt
+		default:
+			return "有新的访客评论待审核", fmt.Sprintf("访客 %s：%s", payload.GuestName, payload.Excerpt)
+		}
+	case model.NotificationTypeReaction:
+		var payload model.ReactionPayload
+		_ = notification.DecodePayload(&payload)
+		label, ok := reactionLabels[payload.Reaction]
+		if !ok {
+			label = string(payload.Reaction)
+		}
+		return sender + " 回应了你的动态", label
+	case model.NotificationTypePublish:
+		var payload model.MomentPayload
+		_ = notification.DecodePayload(&payload)
+		return sender + " 发布了新动态", payload.Excerpt
+	case model.NotificationTypeMemory:
+		var payload model.MemoryPayload
+		_ = notification.DecodePayload(&payload)
+		return "那年今日", summarizeMemories(&payload)
+	case model.NotificationTypeAlbumPhoto:
+		var payload model.AlbumPhotoPayload
+		_ = notification.DecodePayload(&payload)
+		return fmt.Sprintf("%s 向相册「%s」添加了 %d 张照片", sender, payload.AlbumName, payload.Count), ""
+	case model.NotificationTypeAnniversary:
+		var payload model.AnniversaryPayload
+		_ = notification.DecodePayload(&payload)
+		return describeAnniversary(&payload)
+	case model.NotificationTypeSystem:
+		var payload model.SystemAlertPayload
+		_ = notification.DecodePayload(&payload)
+		title, ok := alertTitles[payload.Code]
+		if !ok {
+			title = "系统告警"
+		}
+		return title, payload.Message
+	default:
+		return "新通知", ""
+	}
+}
+
+// summarizeMemories 生成回忆通知内容，如 "那年今日：2 条动态、5 张照片"
+func summarizeMemories(payload *model.MemoryPayload) string {
+	var parts []string
+	if n := payload.Moments; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d 条动态", n))
+	}
+	if n := payload.Photos; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d 张照片", n))
+	}
+	if n := payload.Places; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d 个地点", n))
+	}
+	if n := payload.Anniversaries; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d 个纪念日", n))
+	}
+	return "那年今日：" + strings.Join(parts, "、")
+}
+
+// describeAnniversary 生成纪念日提醒，如 "距离「在一起」3 周年还有 7 天"、"今天是「在一起」3 周年"
+func describeAnniversary(payload *model.AnniversaryPayload) (string, string) {
+	name := "「" + payload.Title + "」"
+	if payload.Years > 0 {
+		name += fmt.Sprintf("%d 周年", payload.Years)
+	}
+
+	title := "今天是" + name
+	switch payload.DaysLeft {
+	case 0:
+	case 1:
+		title = "明天是" + name
+	default:
+		title = fmt.Sprintf("距离%s还有 %d 天", name, payload.DaysLeft)
+	}
+
+	if payload.Calendar == model.AnniversaryCalendarLunar {
+		if payload.LunarDate != "" {
+			return title, "农历" + payload.LunarDate + "，公历 " + payload.Date
+		}
+		return title, "农历纪念日，公历 " + payload.Date
+	}
+	return title, payload.Date
+}
+
+// notificationLink 通知对应的站内页面路径，没有可跳转的页面时返回空字符串
+func notificationLink(notification *model.Notification) string {
+	switch notification.EntityType {
+	case model.NotificationEntityMoment:
+		return fmt.Sprintf("%s#moment-%d", feedMomentPath, notification.EntityID)
+	case model.NotificationEntityComment:
+		var payload model.CommentPayload
+		if err := notification.DecodePayload(&payload); err != nil || payload.MomentID == 0 {
+			return feedMomentPath
+		}
+		return fmt.Sprintf("%s#moment-%d", feedMomentPath, payload.MomentID)
+	case model.NotificationEntityAlbum:
+		return albumPagePath
+	case model.NotificationEntityAnniversary:
+		return anniversaryPagePath
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/realtime"
+)
+
+// 实时推送的事件类型
+const (
+	EventNotification = "notification"  // 新通知，数据为 FrontendNotification
+	EventUnreadCount  = "unread_count"  // 未读通知数变化
+	EventCommentCount = "comment_count" // 动态的评论数变化
+	EventReactions    = "reactions"     // 动态的点赞和表情回应变化
+	EventResync       = "resync"        // 断线期间的事件无法补发，客户端需要重新拉取数据
+)
+
+// UnreadCountEvent 未读通知数
+type UnreadCountEvent struct {
+	Count int64 `json:"count"`
+}
+
+// CommentCountEvent 动态的评论数
+type CommentCountEvent struct {
+	MomentID     uint64 `json:"momentId"`
+	CommentCount int64  `json:"commentCount"`
+}
+
+// ReactionsEvent 动态的点赞和表情回应统计
+type ReactionsEvent struct {
+	MomentID  uint64           `json:"momentId"`
+	Likes     int              `json:"likes"`
+	Reactions map[string]int64 `json:"reactions"`
+}
+
+// Subscribe 订阅用户的实时事件，参见 realtime.Hub.Subscribe
+func (s *NotificationService) Subscribe(userID, lastEventID uint64) (*realtime.Subscription, []realtime.Event, bool, error) {
+	return s.Hub.Subscribe(userID, lastEventID)
+}
+
+// RenderEvent 生成推送给客户端的事件数据，通知中的头像地址按当前连接的域名生成
+func (s *NotificationService) RenderEvent(c *gin.Context, event realtime.Event) any {
+	if notification, ok := event.Payload.(*model.Notification); ok {
+		return s.convertToFrontendFormat(c, notification)
+	}
+	return event.Payload
+}
+
+// PublishMomentEvent 向可以查看动态的在线用户推送动态数据的变化
+func (s *NotificationService) PublishMomentEvent(moment *model.Moment, eventType string, payload any) {
+	s.Hub.Broadcast(eventType, payload, moment.VisibleTo)
+}
+
+// publishNotification 推送新通知和最新的未读数，查询失败时只记录日志，客户端仍可轮询获取
+func (s *NotificationService) publishNotification(ctx context.Context, notification *model.Notification) {
+	created, err := s.NotificationRepo.FindByID(ctx, notification.ID)
+	if err != nil {
+		s.Log.Error("查询新通知失败", "error", err, "notificationID", notification.ID)
+		return
+	}
+	s.Hub.Publish(notification.UserID, EventNotification, created)
+	s.publishUnreadCount(ctx, notification.UserID)
+}
+
+func (s *NotificationService) publishUnreadCount(ctx context.Context, userID uint64) {
+	count, err := s.NotificationRepo.CountUnreadByUserID(ctx, userID)
+	if err != nil {
+		s.Log.Error("统计未读通知失败", "error", err, "userID", userID)
+		return
+	}
+	s.Hub.Publish(userID, EventUnreadCount, UnreadCountEvent{Count: count})
+}
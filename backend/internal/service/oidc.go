@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/audit"
+	"github.com/bookandmusic/love-girl/internal/auth"
+	"github.com/bookandmusic/love-girl/internal/config"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/oidc"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+// OIDCService OpenID Connect 登录：外部身份与本站用户关联后签发本站 JWT
+type OIDCService struct {
+	*BaseService
+	Client       *oidc.Client
+	UserRepo     *repo.UserRepo
+	IdentityRepo *repo.UserIdentityRepo
+	JWT          auth.JWT
+	Audit        *AuditService
+	cfg          *config.OIDCConfig
+	states       *oidc.StateStore
+}
+
+func NewOIDCService(log *log.Logger, client *oidc.Client, userRepo *repo.UserRepo, identityRepo *repo.UserIdentityRepo, cfg *config.OIDCConfig, jwt auth.JWT, auditService *AuditService) *OIDCService {
+	return &OIDCService{
+		BaseService:  &BaseService{Log: log},
+		Client:       client,
+		UserRepo:     userRepo,
+		IdentityRepo: identityRepo,
+		JWT:          jwt,
+		Audit:        auditService,
+		cfg:          cfg,
+		states:       oidc.NewStateStore(),
+	}
+}
+
+// OIDCConfigResponse 登录页展示 OIDC 登录按钮所需的信息
+type OIDCConfigResponse struct {
+	Enabled      bool   `json:"enabled"`
+	ProviderName string `json:"providerName,omitempty"`
+}
+
+// OIDCCallbackResult 回调处理结果，登录时返回令牌，关联时 Linked 为 true
+type OIDCCallbackResult struct {
+	Token  string
+	Linked bool
+}
+
+// FrontendUserIdentity 管理后台的外部身份数据结构
+type FrontendUserIdentity struct {
+	ID        uint64 `json:"id"`
+	Issuer    string `json:"issuer"`
+	Email     string `json:"email,omitempty"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// GetConfig 获取 OIDC 登录配置
+func (s *OIDCService) GetConfig() *OIDCConfigResponse {
+	if !s.Client.Enabled() {
+		return &OIDCConfigResponse{Enabled: false}
+	}
+	return &OIDCConfigResponse{
+		Enabled:      true,
+		ProviderName: s.Client.ProviderName(),
+	}
+}
+
+// FrontendRedirect 回调处理完成后跳转的前端页面
+func (s *OIDCService) FrontendRedirect() string {
+	return s.cfg.FrontendRedirect
+}
+
+// BeginAuth 开始授权流程，linkUserID 非 0 时为该用户关联外部身份
+//
+// 返回：授权地址、浏览器绑定值（需写入 Cookie，回调时校验）
+func (s *OIDCService) BeginAuth(ctx context.Context, linkUserID uint64) (string, string, error) {
+	if !s.Client.Enabled() {
+		return "", "", errMsg.ErrOIDCDisabled
+	}
+
+	flow := &oidc.Flow{
+		Nonce:        oidc.RandomString(16),
+		CodeVerifier: oidc.RandomString(32),
+		Binding:      oidc.RandomString(16),
+		LinkUserID:   linkUserID,
+	}
+	state, err := s.states.Put(flow)
+	if err != nil {
+		s.Log.Warn("进行中的OIDC登录流程过多", "error", err)
+		return "", "", errMsg.ErrOIDCTooManyFlows
+	}
+
+	authURL, err := s.Client.AuthCodeURL(ctx, state, flow.Nonce, oidc.CodeChallenge(flow.CodeVerifier))
+	if err != nil {
+		s.Log.Error("构建OIDC授权地址失败", "error", err)
+		return "", "", errMsg.ErrOIDCProviderFailed
+	}
+	return authURL, flow.Binding, nil
+}
+
+// HandleCallback 处理身份提供方回调：校验 state 与浏览器绑定、换取并校验 ID Token，然后登录或关联
+func (s *OIDCService) HandleCallback(ctx context.Context, state, code, binding string) (*OIDCCallbackResult, error) {
+	if !s.Client.Enabled() {
+		return nil, errMsg.ErrOIDCDisabled
+	}
+
+	flow := s.states.Take(state)
+	if flow == nil || subtle.ConstantTimeCompare([]byte(flow.Binding), []byte(binding)) != 1 {
+		s.Log.Warn("OIDC回调state无效或浏览器不匹配")
+		return nil, errMsg.ErrOIDCInvalidState
+	}
+
+	token, err := s.Client.Exchange(ctx, code, flow.CodeVerifier)
+	if err != nil {
+		s.Log.Error("OIDC授权码换取令牌失败", "error", err)
+		return nil, errMsg.ErrOIDCProviderFailed
+	}
+
+	claims, err := s.Client.VerifyIDToken(ctx, token.IDToken, flow.Nonce)
+	if err != nil {
+		s.Log.Warn("OIDC ID Token校验失败", "error", err)
+		return nil, errMsg.ErrOIDCProviderFailed
+	}
+
+	identity, err := s.IdentityRepo.FindBySubject(ctx, claims.Issuer, claims.Subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.Log.Error("查询外部身份失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	if err != nil {
+		identity = nil
+	}
+
+	if flow.LinkUserID != 0 {
+		return s.link(ctx, flow.LinkUserID, identity, claims)
+	}
+	return s.login(ctx, identity, claims)
+}
+
+// link 为已登录用户关联外部身份
+func (s *OIDCService) link(ctx context.Context, userID uint64, identity *model.UserIdentity, claims *oidc.IDTokenClaims) (*OIDCCallbackResult, error) {
+	if identity != nil {
+		if identity.UserID != userID {
+			s.Log.Warn("外部身份已关联其他用户", "userID", userID, "subject", claims.Subject)
+			return nil, errMsg.ErrOIDCAlreadyLinked
+		}
+		return &OIDCCallbackResult{Linked: true}, nil
+	}
+
+	if _, err := s.createIdentity(ctx, userID, claims); err != nil {
+		return nil, err
+	}
+	return &OIDCCallbackResult{Linked: true}, nil
+}
+
+// login 使用外部身份登录，未关联时按配置尝试通过已验证邮箱自动关联
+func (s *OIDCService) login(ctx context.Context, identity *model.UserIdentity, claims *oidc.IDTokenClaims) (*OIDCCallbackResult, error) {
+	var user *model.User
+	if identity != nil {
+		found, err := s.UserRepo.FindByID(ctx, identity.UserID)
+		if err != nil {
+			s.Log.Error("查询关联用户失败", "error", err, "userID", identity.UserID)
+			return nil, fmt.Errorf("系统内部错误")
+		}
+		user = found
+	} else {
+		found, err := s.findUserByEmail(ctx, claims)
+		if err != nil {
+			s.Audit.Record(ctx, AuditEntry{
+				Action:    audit.ActionLoginFailed,
+				ActorName: claims.Email,
+				Failed:    true,
+				After:     map[string]any{"method": "oidc", "issuer": claims.Issuer, "subject": claims.Subject},
+			})
+			return nil, err
+		}
+		if _, err := s.createIdentity(ctx, found.ID, claims); err != nil {
+			return nil, err
+		}
+		user = found
+	}
+
+	token, err := s.JWT.Generate(&auth.Claims{
+		Role:    "user",
+		UserID:  user.ID,
+		Version: user.TokenVersion,
+	})
+	if err != nil {
+		s.Log.Error("用户生成token失败", "error", err, "userID", user.ID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	s.Audit.Record(ctx, AuditEntry{
+		Action:     audit.ActionLogin,
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		TargetType: "user",
+		TargetID:   user.ID,
+		After:      map[string]any{"method": "oidc", "issuer": claims.Issuer},
+	})
+	return &OIDCCallbackResult{Token: token}, nil
+}
+
+// findUserByEmail 按身份提供方已验证的邮箱查找用户，未开启自动关联时直接返回未关联
+func (s *OIDCService) findUserByEmail(ctx context.Context, claims *oidc.IDTokenClaims) (*model.User, error) {
+	if !s.cfg.AutoLinkByEmail || claims.Email == "" || !claims.EmailVerified {
+		s.Log.Info("外部身份未关联本站用户", "issuer", claims.Issuer, "subject", claims.Subject)
+		return nil, errMsg.ErrOIDCNotLinked
+	}
+
+	user, err := s.UserRepo.FindOneByEmail(ctx, claims.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.Log.Info("已验证邮箱未匹配本站用户", "email", claims.Email)
+			return nil, errMsg.ErrOIDCEmailMismatch
+		}
+		s.Log.Error("用户查询失败", "error", err, "email", claims.Email)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	return user, nil
+}
+
+func (s *OIDCService) createIdentity(ctx context.Context, userID uint64, claims *oidc.IDTokenClaims) (*model.UserIdentity, error) {
+	identity := &model.UserIdentity{
+		UserID:  userID,
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Email:   claims.Email,
+	}
+	if err := s.IdentityRepo.Create(ctx, identity); err != nil {
+		s.Log.Error("关联外部身份失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	s.Audit.Record(ctx, AuditEntry{
+		Action:     audit.ActionIdentityLink,
+		ActorID:    userID,
+		TargetType: "user_identity",
+		TargetID:   identity.ID,
+		After: map[string]any{
+			"issuer":  identity.Issuer,
+			"subject": identity.Subject,
+			"email":   identity.Email,
+		},
+	})
+	return identity, nil
+}
+
+// ListIdentities 获取用户关联的外部身份
+func (s *OIDCService) ListIdentities(ctx context.Context, userID uint64) ([]*FrontendUserIdentity, error) {
+	identities, err := s.IdentityRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		s.Log.Error("获取外部身份列表失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	result := make([]*FrontendUserIdentity, len(identities))
+	for i, identity := range identities {
+		result[i] = &FrontendUserIdentity{
+			ID:        identity.ID,
+			Issuer:    identity.Issuer,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+	return result, nil
+}
+
+// UnlinkIdentity 解除用户自己的外部身份关联
+func (s *OIDCService) UnlinkIdentity(ctx context.Context, userID, id uint64) (bool, error) {
+	deleted, err := s.IdentityRepo.DeleteByUser(ctx, id, userID)
+	if err != nil {
+		s.Log.Error("解除外部身份关联失败", "error", err, "id", id, "userID", userID)
+		return false, fmt.Errorf("系统内部错误")
+	}
+	if deleted {
+		s.Audit.Record(ctx, AuditEntry{
+			Action:     audit.ActionIdentityUnlink,
+			ActorID:    userID,
+			TargetType: "user_identity",
+			TargetID:   id,
+		})
+	}
+	return deleted, nil
+}
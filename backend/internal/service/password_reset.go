@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/audit"
+	"github.com/bookandmusic/love-girl/internal/config"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/mail"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+	"github.com/bookandmusic/love-girl/internal/utils"
+)
+
+const (
+	// passwordResetTTL 重置链接有效期
+	passwordResetTTL = 30 * time.Minute
+	// passwordResetPath 管理后台的重置密码页面
+	passwordResetPath = "/admin/reset-password"
+)
+
+type PasswordResetService struct {
+	*BaseService
+	UserRepo    *repo.UserRepo
+	SettingRepo *repo.SettingRepo
+	MailQueue   *mail.Queue
+	Audit       *AuditService
+	appCfg      *config.AppConfig
+}
+
+func NewPasswordResetService(log *log.Logger, userRepo *repo.UserRepo, settingRepo *repo.SettingRepo, mailQueue *mail.Queue, appCfg *config.AppConfig, auditService *AuditService) *PasswordResetService {
+	return &PasswordResetService{
+		BaseService: &BaseService{Log: log},
+		UserRepo:    userRepo,
+		SettingRepo: settingRepo,
+		MailQueue:   mailQueue,
+		Audit:       auditService,
+		appCfg:      appCfg,
+	}
+}
+
+// signResetToken 计算重置令牌签名
+// 签名包含用户当前的密码哈希，密码修改后旧令牌自动失效，因此令牌只能使用一次
+func (s *PasswordResetService) signResetToken(payload, passwordHash string) string {
+	mac := hmac.New(sha256.New, []byte(s.appCfg.JWT.Secret))
+	mac.Write([]byte(payload + "." + passwordHash))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// generateResetToken 生成重置令牌，格式: {用户ID}.{过期时间戳}.{签名}
+func (s *PasswordResetService) generateResetToken(user *model.User, expiresAt time.Time) string {
+	payload := strconv.FormatUint(user.ID, 10) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + s.signResetToken(payload, user.Password)
+}
+
+// parseResetToken 校验重置令牌，返回令牌对应的用户
+func (s *PasswordResetService) parseResetToken(ctx context.Context, token string) (*model.User, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errMsg.ErrInvalidResetToken
+	}
+
+	userID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return nil, errMsg.ErrInvalidResetToken
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return nil, errMsg.ErrInvalidResetToken
+	}
+
+	user, err := s.UserRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errMsg.ErrInvalidResetToken
+		}
+		s.Log.Error("用户查询失败", "error", err, "userID", userID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	expected := s.signResetToken(parts[0]+"."+parts[1], user.Password)
+	if !hmac.Equal([]byte(parts[2]), []byte(expected)) {
+		return nil, errMsg.ErrInvalidResetToken
+	}
+	return user, nil
+}
+
+// siteTitle 获取站点标题，用于邮件主题
+func (s *PasswordResetService) siteTitle(ctx context.Context) string {
+	setting, err := s.SettingRepo.GetSettingByKey(ctx, "siteTitle")
+	if err != nil || setting.Value == "" {
+		return s.appCfg.App.Name
+	}
+	return setting.Value
+}
+
+// RequestReset 发送重置密码邮件
+// 说明：邮箱不存在时同样返回成功，避免泄露账号信息；
+// 重置链接只使用配置的站点地址（mail.site_url），不信任请求头中的 Host，防止链接被篡改为第三方域名
+func (s *PasswordResetService) RequestReset(ctx context.Context, email string) error {
+	if !s.MailQueue.Enabled() {
+		s.Log.Warn("未配置邮件服务，无法发送重置密码邮件")
+		return fmt.Errorf("邮件服务未配置")
+	}
+	siteURL := strings.TrimRight(s.appCfg.Mail.SiteURL, "/")
+	if siteURL == "" {
+		s.Log.Warn("未配置站点地址（mail.site_url），无法发送重置密码邮件")
+		return fmt.Errorf("站点地址未配置")
+	}
+
+	user, err := s.UserRepo.FindOneByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.Log.Info("重置密码的邮箱不存在", "email", email)
+			return nil
+		}
+		s.Log.Error("用户查询失败", "error", err, "email", email)
+		return fmt.Errorf("系统内部错误")
+	}
+
+	token := s.generateResetToken(user, time.Now().Add(passwordResetTTL))
+	msg, err := mail.Render("password_reset", map[string]any{
+		"SiteTitle":     s.siteTitle(ctx),
+		"UserName":      user.Name,
+		"ResetURL":      siteURL + passwordResetPath + "?token=" + url.QueryEscape(token),
+		"ExpireMinutes": int(passwordResetTTL.Minutes()),
+	})
+	if err != nil {
+		s.Log.Error("渲染重置密码邮件失败", "error", err)
+		return fmt.Errorf("系统内部错误")
+	}
+	msg.To = []string{email}
+
+	if err := s.MailQueue.Enqueue(msg); err != nil {
+		s.Log.Error("重置密码邮件加入队列失败", "error", err, "userID", user.ID)
+		return fmt.Errorf("邮件发送繁忙，请稍后重试")
+	}
+	return nil
+}
+
+// ResetPassword 使用重置令牌设置新密码
+func (s *PasswordResetService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	user, err := s.parseResetToken(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.EncryptPassword(newPassword)
+	if err != nil {
+		s.Log.Error("用户密码加密失败", "error", err, "userID", user.ID)
+		return fmt.Errorf("系统内部错误")
+	}
+
+	updated, err := s.UserRepo.UpdatePassword(ctx, user.ID, user.Password, hashedPassword)
+	if err != nil {
+		s.Log.Error("重置密码失败", "error", err, "userID", user.ID)
+		return fmt.Errorf("系统内部错误")
+	}
+	if !updated {
+		// 并发使用同一令牌时，只有第一次生效
+		return errMsg.ErrInvalidResetToken
+	}
+
+	s.Audit.Record(ctx, AuditEntry{
+		Action:     audit.ActionPasswordReset,
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Before:     map[string]any{"password": audit.Redacted},
+		After:      map[string]any{"password": "changed"},
+	})
+
+	s.Log.Info("用户通过邮件重置了密码", "userID", user.ID)
+	return nil
+}
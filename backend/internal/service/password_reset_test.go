@@ -0,0 +1,249 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/bookandmusic/love-girl/internal/auth"
+	"github.com/bookandmusic/love-girl/internal/config"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/mail"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+	"github.com/bookandmusic/love-girl/internal/utils"
+)
+
+// smtpSink 进程内的 SMTP 收件服务，收到的邮件正文写入 messages
+type smtpSink struct {
+	listener net.Listener
+	messages chan string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	sink := &smtpSink{listener: listener, messages: make(chan string, 10)}
+	t.Cleanup(func() { listener.Close() })
+	go sink.serve()
+	return sink
+}
+
+func (s *smtpSink) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpSink) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+
+	reply("220 sink ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 sink")
+		case cmd == "DATA":
+			reply("354 end with .")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			s.messages <- data.String()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (s *smtpSink) wait(t *testing.T) string {
+	t.Helper()
+	select {
+	case msg := <-s.messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("未收到邮件")
+		return ""
+	}
+}
+
+func newPasswordResetTestService(t *testing.T, siteURL string) (*PasswordResetService, *smtpSink, *model.User) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.Setting{}, &model.AuditLog{}, &model.APIToken{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	hash, err := utils.EncryptPassword("old-password")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	email := "partner@example.com"
+	user := &model.User{Name: "partner", Email: &email, Password: hash}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	sink := newSMTPSink(t)
+	cfg := &config.AppConfig{
+		App: config.AppConfigApp{Name: "love-girl"},
+		JWT: config.JWTConfig{Secret: "test-secret"},
+		Mail: config.MailConfig{
+			Host:       "127.0.0.1",
+			Port:       sink.port(),
+			From:       "noreply@example.com",
+			Encryption: "none",
+			SiteURL:    siteURL,
+		},
+	}
+	logger := log.NewLogger(config.LogConfig{Level: "error"})
+	queue := mail.NewQueue(mail.NewSMTPSender(&cfg.Mail), logger, 10, 0)
+	queue.Start()
+	t.Cleanup(queue.Close)
+
+	userRepo := repo.NewUserRepo(db, nil)
+	settingRepo := repo.NewSettingRepo(db)
+	audit := NewAuditService(logger, repo.NewAuditLogRepo(db), userRepo, settingRepo)
+	return NewPasswordResetService(logger, userRepo, settingRepo, queue, cfg, audit), sink, user
+}
+
+var resetLinkPattern = regexp.MustCompile(`https://love\.example\.com/admin/reset-password\?token=([0-9]+\.[0-9]+\.[A-Za-z0-9_-]+)`)
+
+// requestResetToken 申请重置密码并从邮件中取出重置令牌
+func requestResetToken(t *testing.T, svc *PasswordResetService, sink *smtpSink, email string) string {
+	t.Helper()
+	if err := svc.RequestReset(context.Background(), email); err != nil {
+		t.Fatalf("RequestReset: %v", err)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(sink.wait(t))))
+	if err != nil {
+		t.Fatalf("decode mail: %v", err)
+	}
+	match := resetLinkPattern.FindSubmatch(body)
+	if match == nil {
+		t.Fatalf("邮件中没有重置链接:\n%s", body)
+	}
+	return string(match[1])
+}
+
+func TestPasswordResetTokenIsSingleUse(t *testing.T) {
+	svc, sink, user := newPasswordResetTestService(t, "https://love.example.com/")
+	ctx := context.Background()
+	token := requestResetToken(t, svc, sink, *user.Email)
+
+	if err := svc.ResetPassword(ctx, token, "new-password"); err != nil {
+		t.Fatalf("第一次重置: %v", err)
+	}
+	if err := svc.ResetPassword(ctx, token, "another-password"); !errors.Is(err, errMsg.ErrInvalidResetToken) {
+		t.Fatalf("第二次重置应返回 ErrInvalidResetToken，实际为 %v", err)
+	}
+
+	updated, err := svc.UserRepo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if !utils.VerifyPassword(updated.Password, "new-password") {
+		t.Fatal("密码应为第一次重置的新密码")
+	}
+}
+
+// TestPasswordResetRevokesCredentials 重置密码后，此前签发的登录令牌和个人访问令牌失效
+func TestPasswordResetRevokesCredentials(t *testing.T) {
+	svc, sink, user := newPasswordResetTestService(t, "https://love.example.com/")
+	ctx := context.Background()
+	tokens := NewAPITokenService(svc.Log, repo.NewAPITokenRepo(svc.UserRepo.DB()), svc.UserRepo, svc.Audit)
+
+	oldToken, err := tokens.CreateToken(ctx, user.ID, &APITokenCreateRequest{Name: "desktop", Scope: auth.ScopeFull})
+	if err != nil {
+		t.Fatalf("CreateToken: %v", err)
+	}
+	if err := tokens.VerifyVersion(ctx, user.ID, user.TokenVersion); err != nil {
+		t.Fatalf("重置前登录令牌应有效: %v", err)
+	}
+
+	if err := svc.ResetPassword(ctx, requestResetToken(t, svc, sink, *user.Email), "new-password"); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+
+	if err := tokens.VerifyVersion(ctx, user.ID, user.TokenVersion); !errors.Is(err, errMsg.ErrInvalidToken) {
+		t.Fatalf("重置前签发的登录令牌应失效，err = %v", err)
+	}
+	if _, err := tokens.VerifyToken(ctx, oldToken.Token); !errors.Is(err, errMsg.ErrInvalidToken) {
+		t.Fatalf("重置前创建的个人访问令牌应失效，err = %v", err)
+	}
+
+	// 重置后重新登录和新建的令牌有效
+	updated, err := svc.UserRepo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if err := tokens.VerifyVersion(ctx, user.ID, updated.TokenVersion); err != nil {
+		t.Fatalf("重置后的登录令牌应有效: %v", err)
+	}
+	newToken, err := tokens.CreateToken(ctx, user.ID, &APITokenCreateRequest{Name: "desktop", Scope: auth.ScopeFull})
+	if err != nil {
+		t.Fatalf("CreateToken: %v", err)
+	}
+	claims, err := tokens.VerifyToken(ctx, newToken.Token)
+	if err != nil || claims.Version != updated.TokenVersion {
+		t.Fatalf("重置后创建的个人访问令牌应有效: %+v, %v", claims, err)
+	}
+}
+
+func TestPasswordResetRequiresSiteURL(t *testing.T) {
+	svc, sink, user := newPasswordResetTestService(t, "")
+
+	if err := svc.RequestReset(context.Background(), *user.Email); err == nil {
+		t.Fatal("未配置站点地址时应拒绝发送重置邮件")
+	}
+	select {
+	case msg := <-sink.messages:
+		t.Fatalf("不应发送邮件:\n%s", msg)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -74,14 +74,16 @@ type PlaceService struct {
 	*BaseService
 	PlaceRepo   *repo.PlaceRepo
 	FileService *FileService
+	Search      *SearchService
 }
 
 // NewPlaceService 创建地点服务实例
-func NewPlaceService(log *log.Logger, placeRepo *repo.PlaceRepo, fileService *FileService) *PlaceService {
+func NewPlaceService(log *log.Logger, placeRepo *repo.PlaceRepo, fileService *FileService, searchService *SearchService) *PlaceService {
 	return &PlaceService{
 		BaseService: &BaseService{Log: log},
 		PlaceRepo:   placeRepo,
 		FileService: fileService,
+		Search:      searchService,
 	}
 }
 
@@ -212,6 +214,8 @@ func (s *PlaceService) CreatePlace(c *gin.Context, req *PlaceCreateRequest) (*Pl
 		return nil, fmt.Errorf("系统内部错误")
 	}
 
+	s.Search.IndexPlace(ctx, place.ID)
+
 	return s.convertToResponse(c, createdPlace), nil
 }
 
@@ -268,6 +272,8 @@ func (s *PlaceService) UpdatePlace(c *gin.Context, id uint64, req *PlaceUpdateRe
 		return nil, fmt.Errorf("系统内部错误")
 	}
 
+	s.Search.IndexPlace(ctx, id)
+
 	return s.convertToResponse(c, updatedPlace), nil
 }
 
@@ -290,5 +296,7 @@ func (s *PlaceService) DeletePlace(ctx context.Context, id uint64) (bool, error)
 		return false, fmt.Errorf("系统内部错误")
 	}
 
+	s.Search.Remove(ctx, model.SearchDocumentPlace, id)
+
 	return true, nil
 }
@@ -0,0 +1,439 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+	"github.com/bookandmusic/love-girl/internal/search"
+)
+
+const (
+	// MaxSearchQueryLength 检索关键词最大字符数
+	MaxSearchQueryLength = 100
+	// searchSnippetWidth 摘要片段的字符数
+	searchSnippetWidth = 80
+)
+
+// SearchService 全文检索服务
+// 业务数据变更时由对应服务调用 IndexXxx/RemoveXxx 增量更新索引，索引失败只记录日志，不影响业务操作；
+// 后台任务定期全量重建，修复遗漏的增量更新
+type SearchService struct {
+	*BaseService
+	Index           search.Index
+	DocumentRepo    *repo.SearchDocumentRepo
+	MomentRepo      *repo.MomentRepo
+	CommentRepo     *repo.CommentRepo
+	AlbumRepo       *repo.AlbumRepo
+	PlaceRepo       *repo.PlaceRepo
+	AnniversaryRepo *repo.AnniversaryRepo
+}
+
+func NewSearchService(log *log.Logger, index search.Index, documentRepo *repo.SearchDocumentRepo, momentRepo *repo.MomentRepo, commentRepo *repo.CommentRepo, albumRepo *repo.AlbumRepo, placeRepo *repo.PlaceRepo, anniversaryRepo *repo.AnniversaryRepo) *SearchService {
+	return &SearchService{
+		BaseService:     &BaseService{Log: log},
+		Index:           index,
+		DocumentRepo:    documentRepo,
+		MomentRepo:      momentRepo,
+		CommentRepo:     commentRepo,
+		AlbumRepo:       albumRepo,
+		PlaceRepo:       placeRepo,
+		AnniversaryRepo: anniversaryRepo,
+	}
+}
+
+// SearchRequest 检索请求
+type SearchRequest struct {
+	Query string
+	Types []model.SearchDocumentType // 为空表示全部类型
+	Page  int
+	Size  int
+}
+
+// SearchResult 检索结果
+type SearchResult struct {
+	Type     string  `json:"type"`               // moment/comment/album/place/anniversary
+	ID       uint64  `json:"id"`                 // 对应业务数据的ID
+	MomentID uint64  `json:"momentId,omitempty"` // 评论所属的动态ID
+	Title    string  `json:"title,omitempty"`    // 标题，命中部分以 <mark> 标记，已做 HTML 转义
+	Snippet  string  `json:"snippet"`            // 内容摘要，命中部分以 <mark> 标记，已做 HTML 转义
+	Score    float64 `json:"score"`
+}
+
+// SearchResponse 检索响应
+type SearchResponse struct {
+	Results    []*SearchResult `json:"results"`
+	Page       int             `json:"page"`
+	Size       int             `json:"size"`
+	Total      int64           `json:"total"`
+	TotalPages int             `json:"totalPages"`
+	Backend    string          `json:"backend"` // 当前使用的索引后端
+}
+
+// Search 检索当前用户可见的内容，按相关度排序
+func (s *SearchService) Search(c *gin.Context, req *SearchRequest, viewerID uint64) (*SearchResponse, error) {
+	ctx := c.Request.Context()
+
+	query := strings.TrimSpace(req.Query)
+	if utf8.RuneCountInString(query) > MaxSearchQueryLength {
+		query = string([]rune(query)[:MaxSearchQueryLength])
+	}
+	terms := search.QueryTerms(query)
+	if len(terms) == 0 {
+		return nil, errMsg.ErrSearchQueryEmpty
+	}
+
+	hits, total, err := s.Index.Search(ctx, search.Query{
+		Terms:    terms,
+		Types:    req.Types,
+		ViewerID: viewerID,
+		Offset:   (req.Page - 1) * req.Size,
+		Limit:    req.Size,
+	})
+	if err != nil {
+		s.Log.Error("全文检索失败", "error", err, "query", query, "backend", s.Index.Name())
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	ids := make([]uint64, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.DocumentID
+	}
+	docs, err := s.DocumentRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		s.Log.Error("查询检索文档失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	docMap := make(map[uint64]*model.SearchDocument, len(docs))
+	for i := range docs {
+		docMap[docs[i].ID] = &docs[i]
+	}
+
+	// 摘要使用原文生成，相邻的二元组命中会合并为一段高亮
+	results := make([]*SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		doc, ok := docMap[hit.DocumentID]
+		// 索引与文档表之间的短暂不一致时跳过，并再次校验可见范围
+		if !ok || !doc.VisibleTo(viewerID) {
+			continue
+		}
+		result := &SearchResult{
+			Type:    string(doc.DocType),
+			ID:      doc.DocID,
+			Snippet: search.Snippet(doc.Content, terms, searchSnippetWidth),
+			Score:   hit.Score,
+		}
+		if doc.DocType == model.SearchDocumentComment {
+			result.MomentID = doc.MomentID
+		}
+		if doc.Title != "" {
+			result.Title = search.Snippet(doc.Title, terms, 0)
+		}
+		results = append(results, result)
+	}
+
+	return &SearchResponse{
+		Results:    results,
+		Page:       req.Page,
+		Size:       req.Size,
+		Total:      total,
+		TotalPages: int((total + int64(req.Size) - 1) / int64(req.Size)),
+		Backend:    s.Index.Name(),
+	}, nil
+}
+
+// IndexMoment 更新动态及其全部评论的索引，动态的内容、可见范围或发布状态变化时调用
+func (s *SearchService) IndexMoment(ctx context.Context, momentID uint64) {
+	if err := s.indexMoment(ctx, momentID); err != nil {
+		s.Log.Error("更新动态索引失败", "error", err, "momentID", momentID)
+	}
+}
+
+// RemoveMoment 删除动态及其评论的索引
+func (s *SearchService) RemoveMoment(ctx context.Context, momentID uint64) {
+	ids, err := s.DocumentRepo.DeleteByMomentID(ctx, momentID)
+	if err == nil {
+		err = s.Index.Remove(ctx, ids)
+	}
+	if err != nil {
+		s.Log.Error("删除动态索引失败", "error", err, "momentID", momentID)
+	}
+}
+
+// IndexComment 更新单条评论的索引
+func (s *SearchService) IndexComment(ctx context.Context, commentID uint64) {
+	comment, err := s.CommentRepo.FindByID(ctx, commentID)
+	// 占位评论和未通过审核的评论不进入索引
+	if err == nil && (comment.IsTombstone() || comment.Status != model.CommentStatusApproved) {
+		err = s.remove(ctx, model.SearchDocumentComment, []uint64{commentID})
+	} else if err == nil {
+		var moment *model.Moment
+		moment, err = s.MomentRepo.FindByID(ctx, comment.MomentID)
+		if err == nil {
+			err = s.save(ctx, commentDocument(comment, moment))
+		}
+	}
+	if err != nil {
+		s.Log.Error("更新评论索引失败", "error", err, "commentID", commentID)
+	}
+}
+
+// IndexAlbum 更新相册索引
+func (s *SearchService) IndexAlbum(ctx context.Context, albumID uint64) {
+	album, err := s.AlbumRepo.FindByID(ctx, albumID)
+	if err == nil {
+		err = s.save(ctx, albumDocument(album))
+	}
+	if err != nil {
+		s.Log.Error("更新相册索引失败", "error", err, "albumID", albumID)
+	}
+}
+
+// IndexPlace 更新地点索引
+func (s *SearchService) IndexPlace(ctx context.Context, placeID uint64) {
+	place, err := s.PlaceRepo.FindByID(ctx, placeID)
+	if err == nil {
+		err = s.save(ctx, placeDocument(place))
+	}
+	if err != nil {
+		s.Log.Error("更新地点索引失败", "error", err, "placeID", placeID)
+	}
+}
+
+// IndexAnniversary 更新纪念日索引
+func (s *SearchService) IndexAnniversary(ctx context.Context, anniversaryID uint64) {
+	anniversary, err := s.AnniversaryRepo.FindByID(ctx, anniversaryID)
+	if err == nil {
+		err = s.save(ctx, anniversaryDocument(anniversary))
+	}
+	if err != nil {
+		s.Log.Error("更新纪念日索引失败", "error", err, "anniversaryID", anniversaryID)
+	}
+}
+
+// Remove 删除指定类型业务数据的索引
+func (s *SearchService) Remove(ctx context.Context, docType model.SearchDocumentType, docIDs ...uint64) {
+	if err := s.remove(ctx, docType, docIDs); err != nil {
+		s.Log.Error("删除索引失败", "error", err, "type", docType, "ids", docIDs)
+	}
+}
+
+// Reindex 全量重建索引，由后台任务定期调用
+func (s *SearchService) Reindex(ctx context.Context) error {
+	moments, err := s.MomentRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	momentIDs := make([]uint64, 0, len(moments))
+	for i := range moments {
+		if err := s.indexMoment(ctx, moments[i].ID); err != nil {
+			return err
+		}
+		momentIDs = append(momentIDs, moments[i].ID)
+	}
+	if err := s.removeMissing(ctx, model.SearchDocumentMoment, momentIDs); err != nil {
+		return err
+	}
+
+	albums, err := s.AlbumRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	albumIDs := make([]uint64, len(albums))
+	for i := range albums {
+		if err := s.save(ctx, albumDocument(&albums[i])); err != nil {
+			return err
+		}
+		albumIDs[i] = albums[i].ID
+	}
+	if err := s.removeMissing(ctx, model.SearchDocumentAlbum, albumIDs); err != nil {
+		return err
+	}
+
+	places, err := s.PlaceRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	placeIDs := make([]uint64, len(places))
+	for i := range places {
+		if err := s.save(ctx, placeDocument(&places[i])); err != nil {
+			return err
+		}
+		placeIDs[i] = places[i].ID
+	}
+	if err := s.removeMissing(ctx, model.SearchDocumentPlace, placeIDs); err != nil {
+		return err
+	}
+
+	anniversaries, err := s.AnniversaryRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	anniversaryIDs := make([]uint64, len(anniversaries))
+	for i := range anniversaries {
+		if err := s.save(ctx, anniversaryDocument(&anniversaries[i])); err != nil {
+			return err
+		}
+		anniversaryIDs[i] = anniversaries[i].ID
+	}
+	if err := s.removeMissing(ctx, model.SearchDocumentAnniversary, anniversaryIDs); err != nil {
+		return err
+	}
+
+	s.Log.Info("全文索引重建完成", "backend", s.Index.Name(), "moments", len(moments),
+		"albums", len(albums), "places", len(places), "anniversaries", len(anniversaries))
+	return nil
+}
+
+// indexMoment 同步动态及其评论的索引，并删除已不存在的评论的索引
+func (s *SearchService) indexMoment(ctx context.Context, momentID uint64) error {
+	moment, err := s.MomentRepo.FindByID(ctx, momentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.RemoveMoment(ctx, momentID)
+			return nil
+		}
+		return err
+	}
+	if err := s.save(ctx, momentDocument(moment)); err != nil {
+		return err
+	}
+
+	comments, err := s.CommentRepo.List(ctx, repo.WithConditions(
+		repo.FilterCondition{Field: "moment_id", Operator: "eq", Value: momentID},
+	), repo.WithScopes(repo.CommentAlive(), repo.CommentApproved()))
+	if err != nil {
+		return err
+	}
+	current := make(map[uint64]struct{}, len(comments))
+	for i := range comments {
+		if err := s.save(ctx, commentDocument(&comments[i], moment)); err != nil {
+			return err
+		}
+		current[comments[i].ID] = struct{}{}
+	}
+
+	indexed, err := s.DocumentRepo.FindDocIDs(ctx, model.SearchDocumentComment, momentID)
+	if err != nil {
+		return err
+	}
+	var stale []uint64
+	for _, id := range indexed {
+		if _, ok := current[id]; !ok {
+			stale = append(stale, id)
+		}
+	}
+	return s.remove(ctx, model.SearchDocumentComment, stale)
+}
+
+// removeMissing 删除业务数据已不存在的文档
+func (s *SearchService) removeMissing(ctx context.Context, docType model.SearchDocumentType, existing []uint64) error {
+	indexed, err := s.DocumentRepo.FindDocIDs(ctx, docType, 0)
+	if err != nil {
+		return err
+	}
+	current := make(map[uint64]struct{}, len(existing))
+	for _, id := range existing {
+		current[id] = struct{}{}
+	}
+	var stale []uint64
+	for _, id := range indexed {
+		if _, ok := current[id]; !ok {
+			stale = append(stale, id)
+		}
+	}
+	if docType == model.SearchDocumentMoment {
+		// 动态删除时一并删除其评论
+		for _, id := range stale {
+			s.RemoveMoment(ctx, id)
+		}
+		return nil
+	}
+	return s.remove(ctx, docType, stale)
+}
+
+func (s *SearchService) save(ctx context.Context, doc *model.SearchDocument) error {
+	doc.Tokens = search.JoinTokens(search.Tokenize(doc.Title + " " + doc.Content))
+	if err := s.DocumentRepo.Save(ctx, doc); err != nil {
+		return err
+	}
+	return s.Index.Put(ctx, doc)
+}
+
+func (s *SearchService) remove(ctx context.Context, docType model.SearchDocumentType, docIDs []uint64) error {
+	ids, err := s.DocumentRepo.DeleteByDocs(ctx, docType, docIDs)
+	if err != nil {
+		return err
+	}
+	return s.Index.Remove(ctx, ids)
+}
+
+// momentAudience 动态的检索可见范围，草稿和定时动态仅作者可见
+func momentAudience(moment *model.Moment) model.MomentVisibility {
+	if !moment.IsPublished() {
+		return model.MomentVisibilityPrivate
+	}
+	return moment.Visibility
+}
+
+func momentDocument(moment *model.Moment) *model.SearchDocument {
+	return &model.SearchDocument{
+		DocType:  model.SearchDocumentMoment,
+		DocID:    moment.ID,
+		MomentID: moment.ID,
+		OwnerID:  moment.UserID,
+		Audience: momentAudience(moment),
+		Content:  moment.Content,
+	}
+}
+
+// commentDocument 评论的可见范围与所属动态一致
+func commentDocument(comment *model.Comment, moment *model.Moment) *model.SearchDocument {
+	return &model.SearchDocument{
+		DocType:  model.SearchDocumentComment,
+		DocID:    comment.ID,
+		MomentID: moment.ID,
+		OwnerID:  moment.UserID,
+		Audience: momentAudience(moment),
+		Content:  comment.Content,
+	}
+}
+
+func albumDocument(album *model.Album) *model.SearchDocument {
+	return &model.SearchDocument{
+		DocType:  model.SearchDocumentAlbum,
+		DocID:    album.ID,
+		Audience: model.MomentVisibilityPublic,
+		Title:    album.Name,
+		Content:  album.Description,
+	}
+}
+
+func placeDocument(place *model.Place) *model.SearchDocument {
+	return &model.SearchDocument{
+		DocType:  model.SearchDocumentPlace,
+		DocID:    place.ID,
+		Audience: model.MomentVisibilityPublic,
+		Title:    place.Name,
+		Content:  place.Description,
+	}
+}
+
+func anniversaryDocument(anniversary *model.Anniversary) *model.SearchDocument {
+	return &model.SearchDocument{
+		DocType:  model.SearchDocumentAnniversary,
+		DocID:    anniversary.ID,
+		Audience: model.MomentVisibilityPublic,
+		Title:    anniversary.Title,
+		Content:  anniversary.Description,
+	}
+}
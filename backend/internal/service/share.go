@@ -0,0 +1,567 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/audit"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+	"github.com/bookandmusic/love-girl/internal/utils"
+)
+
+// shareExhaustedGrace 访问次数用完后，最后一次访问仍可加载图片的时长
+const shareExhaustedGrace = 10 * time.Minute
+
+type ShareService struct {
+	*BaseService
+	ShareRepo   *repo.ShareRepo
+	AlbumRepo   *repo.AlbumRepo
+	MomentRepo  *repo.MomentRepo
+	FileService *FileService
+	Audit       *AuditService
+}
+
+func NewShareService(log *log.Logger, shareRepo *repo.ShareRepo, albumRepo *repo.AlbumRepo, momentRepo *repo.MomentRepo, fileService *FileService, auditService *AuditService) *ShareService {
+	return &ShareService{
+		BaseService: &BaseService{Log: log},
+		ShareRepo:   shareRepo,
+		AlbumRepo:   albumRepo,
+		MomentRepo:  momentRepo,
+		FileService: fileService,
+		Audit:       auditService,
+	}
+}
+
+// ShareCreateRequest 创建分享请求
+type ShareCreateRequest struct {
+	TargetType string   `json:"targetType" binding:"required,oneof=album moment photos"`
+	TargetID   uint64   `json:"targetId"`
+	FileIDs    []uint64 `json:"fileIds"` // photos 类型必填
+	Title      string   `json:"title"`
+	Password   string   `json:"password"`
+	ExpiresAt  *string  `json:"expiresAt"` // 可选，格式: "2006-01-02 15:04:05"
+	MaxViews   int      `json:"maxViews" binding:"omitempty,gte=0"`
+}
+
+// FrontendShare 管理后台的分享数据结构
+type FrontendShare struct {
+	ID          uint64 `json:"id"`
+	Token       string `json:"token"`
+	TargetType  string `json:"targetType"`
+	TargetID    uint64 `json:"targetId"`
+	Title       string `json:"title"`
+	HasPassword bool   `json:"hasPassword"`
+	ExpiresAt   string `json:"expiresAt,omitempty"`
+	MaxViews    int    `json:"maxViews"`
+	ViewCount   int    `json:"viewCount"`
+	PhotoCount  int    `json:"photoCount"`
+	Status      string `json:"status"` // active/expired/exhausted/revoked
+	CreatorName string `json:"creatorName"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+// ShareListResponse 分享列表响应
+type ShareListResponse struct {
+	Shares     []*FrontendShare `json:"shares"`
+	Page       int              `json:"page"`
+	Size       int              `json:"size"`
+	Total      int64            `json:"total"`
+	TotalPages int              `json:"totalPages"`
+}
+
+// SharedPhoto 分享中的照片
+type SharedPhoto struct {
+	ID        uint64        `json:"id"`
+	File      *FileResponse `json:"file"`
+	CreatedAt string        `json:"createdAt"`
+}
+
+// SharedMoment 分享中的动态
+type SharedMoment struct {
+	ID         uint64         `json:"id"`
+	Content    string         `json:"content"`
+	Images     []*SharedPhoto `json:"images"`
+	AuthorName string         `json:"authorName"`
+	CreatedAt  string         `json:"createdAt"`
+}
+
+// SharedAlbum 分享中的相册
+type SharedAlbum struct {
+	ID          uint64        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	PhotoCount  int           `json:"photoCount"`
+	CoverImage  *FileResponse `json:"coverImage,omitempty"`
+}
+
+// ShareContentResponse 公开访问分享链接的响应
+type ShareContentResponse struct {
+	Token       string         `json:"token"`
+	Title       string         `json:"title"`
+	TargetType  string         `json:"targetType"`
+	AccessToken string         `json:"accessToken,omitempty"` // 密码保护的分享在验证通过后签发，后续请求携带以免重复输入密码
+	Album       *SharedAlbum   `json:"album,omitempty"`
+	Moment      *SharedMoment  `json:"moment,omitempty"`
+	Photos      []*SharedPhoto `json:"photos,omitempty"`
+	Page        int            `json:"page"`
+	Size        int            `json:"size"`
+	Total       int64          `json:"total"`
+	TotalPages  int            `json:"totalPages"`
+}
+
+// ShareAccess 访问分享时提供的凭证
+type ShareAccess struct {
+	Password    string
+	AccessToken string
+}
+
+// generateShareToken 生成 32 字符随机令牌
+func generateShareToken() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := cryptorand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// shareAccessToken 计算密码保护分享的访问令牌
+// 以密码哈希为密钥对分享令牌做 HMAC，修改密码或重建分享后旧令牌自动失效
+func shareAccessToken(share *model.Share) string {
+	mac := hmac.New(sha256.New, []byte(share.Password))
+	mac.Write([]byte(share.Token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// shareStatus 计算分享当前状态
+func shareStatus(share *model.Share) string {
+	switch {
+	case share.RevokedAt != nil:
+		return "revoked"
+	case share.ExpiresAt != nil && time.Now().After(*share.ExpiresAt):
+		return "expired"
+	case share.MaxViews > 0 && share.ViewCount >= share.MaxViews:
+		return "exhausted"
+	default:
+		return "active"
+	}
+}
+
+func (s *ShareService) convertToFrontendFormat(share *model.Share) *FrontendShare {
+	if share == nil {
+		return nil
+	}
+
+	result := &FrontendShare{
+		ID:          share.ID,
+		Token:       share.Token,
+		TargetType:  string(share.TargetType),
+		TargetID:    share.TargetID,
+		Title:       share.Title,
+		HasPassword: share.Password != "",
+		MaxViews:    share.MaxViews,
+		ViewCount:   share.ViewCount,
+		PhotoCount:  len(share.EntityFiles),
+		Status:      shareStatus(share),
+		CreatedAt:   share.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if share.ExpiresAt != nil {
+		result.ExpiresAt = share.ExpiresAt.Format("2006-01-02 15:04:05")
+	}
+	if share.Creator != nil {
+		result.CreatorName = share.Creator.Name
+	}
+	return result
+}
+
+// CreateShare 创建分享链接
+func (s *ShareService) CreateShare(ctx context.Context, userID uint64, req *ShareCreateRequest) (*FrontendShare, error) {
+	targetType := model.ShareTargetType(req.TargetType)
+
+	// 校验分享目标是否存在
+	switch targetType {
+	case model.ShareTargetAlbum:
+		if _, err := s.AlbumRepo.FindByID(ctx, req.TargetID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("相册不存在")
+			}
+			s.Log.Error("查询相册失败", "error", err, "id", req.TargetID)
+			return nil, fmt.Errorf("系统内部错误")
+		}
+	case model.ShareTargetMoment:
+		moment, err := s.MomentRepo.FindByID(ctx, req.TargetID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("动态不存在")
+			}
+			s.Log.Error("查询动态失败", "error", err, "id", req.TargetID)
+			return nil, fmt.Errorf("系统内部错误")
+		}
+		// 不能分享对方仅自己可见的动态
+		if !moment.VisibleTo(userID) {
+			return nil, fmt.Errorf("动态不存在")
+		}
+	case model.ShareTargetPhotos:
+		if len(req.FileIDs) == 0 {
+			return nil, fmt.Errorf("请选择要分享的照片")
+		}
+		req.TargetID = 0
+	}
+
+	token, err := generateShareToken()
+	if err != nil {
+		s.Log.Error("生成分享令牌失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	share := &model.Share{
+		Token:      token,
+		TargetType: targetType,
+		TargetID:   req.TargetID,
+		Title:      req.Title,
+		MaxViews:   req.MaxViews,
+		CreatedBy:  userID,
+	}
+
+	if req.Password != "" {
+		hashedPassword, err := utils.EncryptPassword(req.Password)
+		if err != nil {
+			s.Log.Error("分享密码加密失败", "error", err)
+			return nil, fmt.Errorf("系统内部错误")
+		}
+		share.Password = hashedPassword
+	}
+
+	if req.ExpiresAt != nil && *req.ExpiresAt != "" {
+		parsedTime, err := time.ParseInLocation("2006-01-02 15:04:05", *req.ExpiresAt, time.Local)
+		if err != nil {
+			s.Log.Error("解析过期时间失败", "error", err, "expiresAt", *req.ExpiresAt)
+			return nil, fmt.Errorf("过期时间格式错误，应为: 2006-01-02 15:04:05")
+		}
+		share.ExpiresAt = &parsedTime
+	}
+
+	var fileIDs []uint64
+	if targetType == model.ShareTargetPhotos {
+		fileIDs = req.FileIDs
+	}
+	if err := s.ShareRepo.CreateWithFiles(ctx, share, fileIDs); err != nil {
+		s.Log.Error("创建分享失败", "error", err, "targetType", req.TargetType, "targetId", req.TargetID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	createdShare, err := s.ShareRepo.FindByID(ctx, share.ID)
+	if err != nil {
+		s.Log.Error("查询刚创建的分享失败", "error", err, "id", share.ID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	s.Audit.Record(ctx, AuditEntry{
+		Action:     audit.ActionShareCreate,
+		ActorID:    userID,
+		TargetType: repo.ShareEntityType,
+		TargetID:   share.ID,
+		After: map[string]any{
+			"targetType":  req.TargetType,
+			"targetId":    share.TargetID,
+			"fileIds":     fileIDs,
+			"hasPassword": share.Password != "",
+			"expiresAt":   req.ExpiresAt,
+			"maxViews":    share.MaxViews,
+		},
+	})
+
+	return s.convertToFrontendFormat(createdShare), nil
+}
+
+// ListShares 分页获取分享列表
+func (s *ShareService) ListShares(ctx context.Context, page, size int) (*ShareListResponse, error) {
+	shares, total, err := s.ShareRepo.ListShares(ctx, page, size, repo.WithOrder("created_at", true))
+	if err != nil {
+		s.Log.Error("获取分享列表失败", "error", err, "page", page, "size", size)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	result := make([]*FrontendShare, len(shares))
+	for i := range shares {
+		result[i] = s.convertToFrontendFormat(&shares[i])
+	}
+
+	return &ShareListResponse{
+		Shares:     result,
+		Page:       page,
+		Size:       size,
+		Total:      total,
+		TotalPages: int((total + int64(size) - 1) / int64(size)),
+	}, nil
+}
+
+// RevokeShare 撤销分享链接
+func (s *ShareService) RevokeShare(ctx context.Context, id uint64) (bool, error) {
+	if _, err := s.ShareRepo.FindByID(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.Log.Info("分享不存在", "id", id)
+			return false, nil
+		}
+		s.Log.Error("查询分享失败", "error", err, "id", id)
+		return false, fmt.Errorf("系统内部错误")
+	}
+
+	if err := s.ShareRepo.Revoke(ctx, id); err != nil {
+		s.Log.Error("撤销分享失败", "error", err, "id", id)
+		return false, fmt.Errorf("系统内部错误")
+	}
+
+	s.Audit.Record(ctx, AuditEntry{
+		Action:     audit.ActionShareRevoke,
+		TargetType: repo.ShareEntityType,
+		TargetID:   id,
+	})
+	return true, nil
+}
+
+// loadShare 根据令牌查询分享并校验状态与访问凭证
+// countView 表示本次请求是否计入访问次数；不计数的请求（加载图片）在次数用完后
+// 只在最后一次访问后的短时间内放行，保证最后一次访问能完整浏览
+func (s *ShareService) loadShare(ctx context.Context, token string, access ShareAccess, countView bool) (*model.Share, error) {
+	share, err := s.ShareRepo.FindByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errMsg.ErrShareNotFound
+		}
+		s.Log.Error("查询分享失败", "error", err)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+
+	switch shareStatus(share) {
+	case "revoked":
+		return nil, errMsg.ErrShareRevoked
+	case "expired":
+		return nil, errMsg.ErrShareExpired
+	case "exhausted":
+		if countView || share.LastViewedAt == nil || time.Since(*share.LastViewedAt) > shareExhaustedGrace {
+			return nil, errMsg.ErrShareExhausted
+		}
+	}
+
+	if share.Password == "" {
+		return share, nil
+	}
+
+	// 优先校验访问令牌
+	if access.AccessToken != "" && hmac.Equal([]byte(access.AccessToken), []byte(shareAccessToken(share))) {
+		return share, nil
+	}
+
+	if access.Password == "" {
+		return nil, errMsg.ErrSharePasswordRequired
+	}
+	if !utils.VerifyPassword(share.Password, access.Password) {
+		s.Log.Info("分享密码错误", "shareId", share.ID)
+		return nil, errMsg.ErrSharePasswordInvalid
+	}
+	return share, nil
+}
+
+// buildSharedPhoto 构建通过分享链接代理的照片地址
+func (s *ShareService) buildSharedPhoto(c *gin.Context, share *model.Share, file *model.File, accessToken string) *SharedPhoto {
+	if file == nil {
+		return nil
+	}
+
+	fileURL := fmt.Sprintf("%s/api/v1/s/%s/files/%d", s.FileService.getDynamicBaseURL(c), share.Token, file.ID)
+	query := url.Values{}
+	if accessToken != "" {
+		query.Set("access", accessToken)
+	}
+	originURL := fileURL
+	if encoded := query.Encode(); encoded != "" {
+		originURL += "?" + encoded
+	}
+	query.Set("w", "200")
+	query.Set("h", "200")
+
+	return &SharedPhoto{
+		ID: file.ID,
+		File: &FileResponse{
+			ID:        file.ID,
+			URL:       originURL,
+			Thumbnail: fileURL + "?" + query.Encode(),
+			Name:      file.OriginalName,
+			Size:      file.Size,
+			MimeType:  file.MimeType,
+		},
+		CreatedAt: file.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// ResolveShare 匿名访问分享链接，返回分享的内容
+func (s *ShareService) ResolveShare(c *gin.Context, token string, access ShareAccess, page, size int) (*ShareContentResponse, error) {
+	ctx := c.Request.Context()
+
+	share, err := s.loadShare(ctx, token, access, true)
+	if err != nil {
+		return nil, err
+	}
+	// 动态和照片集一次返回全部内容，不存在后续页
+	if page > 1 && share.TargetType != model.ShareTargetAlbum {
+		return nil, errMsg.ErrSharePageInvalid
+	}
+
+	// 每次请求（包括相册翻页）都计入访问次数，否则只请求后续页即可绕过次数限制
+	ok, err := s.ShareRepo.IncrementViews(ctx, share.ID)
+	if err != nil {
+		s.Log.Error("更新分享访问次数失败", "error", err, "id", share.ID)
+		return nil, fmt.Errorf("系统内部错误")
+	}
+	if !ok {
+		return nil, errMsg.ErrShareExhausted
+	}
+
+	var accessToken string
+	if share.Password != "" {
+		accessToken = shareAccessToken(share)
+	}
+
+	resp := &ShareContentResponse{
+		Token:       share.Token,
+		Title:       share.Title,
+		TargetType:  string(share.TargetType),
+		AccessToken: accessToken,
+		Page:        page,
+		Size:        size,
+	}
+
+	switch share.TargetType {
+	case model.ShareTargetAlbum:
+		if err := s.fillSharedAlbum(c, share, accessToken, resp); err != nil {
+			return nil, err
+		}
+	case model.ShareTargetMoment:
+		if err := s.fillSharedMoment(c, share, accessToken, resp); err != nil {
+			return nil, err
+		}
+	case model.ShareTargetPhotos:
+		photos := make([]*SharedPhoto, 0, len(share.EntityFiles))
+		for _, ef := range share.EntityFiles {
+			if ef.File == nil {
+				continue
+			}
+			photos = append(photos, s.buildSharedPhoto(c, share, ef.File, accessToken))
+		}
+		resp.Photos = photos
+		resp.Total = int64(len(photos))
+		resp.Page = 1
+		resp.Size = len(photos)
+	}
+
+	if resp.Size > 0 {
+		resp.TotalPages = int((resp.Total + int64(resp.Size) - 1) / int64(resp.Size))
+	}
+	return resp, nil
+}
+
+func (s *ShareService) fillSharedAlbum(c *gin.Context, share *model.Share, accessToken string, resp *ShareContentResponse) error {
+	ctx := c.Request.Context()
+
+	album, err := s.AlbumRepo.FindByID(ctx, share.TargetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errMsg.ErrShareNotFound
+		}
+		s.Log.Error("查询分享的相册失败", "error", err, "albumId", share.TargetID)
+		return fmt.Errorf("系统内部错误")
+	}
+
+	files, total, err := s.AlbumRepo.ListAlbumPhotos(ctx, album.ID, resp.Page, resp.Size)
+	if err != nil {
+		s.Log.Error("查询分享的相册照片失败", "error", err, "albumId", album.ID)
+		return fmt.Errorf("系统内部错误")
+	}
+
+	resp.Album = &SharedAlbum{
+		ID:          album.ID,
+		Name:        album.Name,
+		Description: album.Description,
+		PhotoCount:  album.PhotoCount,
+	}
+	if album.CoverImage != nil {
+		resp.Album.CoverImage = s.buildSharedPhoto(c, share, album.CoverImage, accessToken).File
+	}
+
+	photos := make([]*SharedPhoto, len(files))
+	for i := range files {
+		photos[i] = s.buildSharedPhoto(c, share, &files[i], accessToken)
+	}
+	resp.Photos = photos
+	resp.Total = total
+	return nil
+}
+
+func (s *ShareService) fillSharedMoment(c *gin.Context, share *model.Share, accessToken string, resp *ShareContentResponse) error {
+	moment, err := s.MomentRepo.FindByID(c.Request.Context(), share.TargetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errMsg.ErrShareNotFound
+		}
+		s.Log.Error("查询分享的动态失败", "error", err, "momentId", share.TargetID)
+		return fmt.Errorf("系统内部错误")
+	}
+	// 草稿和未到时间的定时动态不通过分享链接公开
+	if !moment.IsPublished() {
+		return errMsg.ErrShareNotFound
+	}
+
+	images := make([]*SharedPhoto, 0, len(moment.EntityFiles))
+	for _, ef := range moment.EntityFiles {
+		if ef.File == nil {
+			continue
+		}
+		images = append(images, s.buildSharedPhoto(c, share, ef.File, accessToken))
+	}
+
+	resp.Moment = &SharedMoment{
+		ID:        moment.ID,
+		Content:   moment.Content,
+		Images:    images,
+		CreatedAt: moment.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if moment.User != nil {
+		resp.Moment.AuthorName = moment.User.Name
+	}
+	resp.Total = 1
+	resp.Page = 1
+	resp.Size = 1
+	return nil
+}
+
+// AuthorizeFile 校验分享链接是否有权访问指定文件
+// 说明：文件请求不计入访问次数，否则一次页面浏览会因图片数量耗尽次数
+func (s *ShareService) AuthorizeFile(ctx context.Context, token string, access ShareAccess, fileID uint64) error {
+	share, err := s.loadShare(ctx, token, access, false)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.ShareRepo.ContainsFile(ctx, share, fileID)
+	if err != nil {
+		s.Log.Error("校验分享文件失败", "error", err, "shareId", share.ID, "fileId", fileID)
+		return fmt.Errorf("系统内部错误")
+	}
+	if !ok {
+		return errMsg.ErrShareFileForbidden
+	}
+	return nil
+}
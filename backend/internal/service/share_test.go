@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/bookandmusic/love-girl/internal/config"
+	errMsg "github.com/bookandmusic/love-girl/internal/error"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+)
+
+type shareTest struct {
+	svc  *ShareService
+	db   *gorm.DB
+	user *model.User
+}
+
+func newShareTest(t *testing.T) *shareTest {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.File{}, &model.EntityFile{}, &model.Album{}, &model.Share{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	user := &model.User{Name: "owner", Password: "x"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	logger := log.NewLogger(config.LogConfig{Level: "error"})
+	svc := NewShareService(logger, repo.NewShareRepo(db), repo.NewAlbumRepo(db), repo.NewMomentRepo(db),
+		NewFileService(logger, nil, *repo.NewFileRepo(db), &config.ServerConfig{}, &config.StorageConfig{}, &config.ImageProxyConfig{}), nil)
+	return &shareTest{svc: svc, db: db, user: user}
+}
+
+// createShare 创建指定类型和访问次数上限的分享；相册分享带 photos 张照片
+func (st *shareTest) createShare(t *testing.T, targetType model.ShareTargetType, maxViews, photos int) *model.Share {
+	t.Helper()
+	share := &model.Share{
+		Token:      fmt.Sprintf("%s-token", targetType),
+		TargetType: targetType,
+		MaxViews:   maxViews,
+		CreatedBy:  st.user.ID,
+	}
+	if targetType == model.ShareTargetAlbum {
+		album := &model.Album{Name: "旅行", PhotoCount: photos}
+		if err := st.db.Create(album).Error; err != nil {
+			t.Fatalf("create album: %v", err)
+		}
+		for i := 0; i < photos; i++ {
+			file := &model.File{OriginalName: fmt.Sprintf("%d.jpg", i), Storage: "local", Path: fmt.Sprintf("p/%d.jpg", i), Size: 1}
+			if err := st.db.Create(file).Error; err != nil {
+				t.Fatalf("create file: %v", err)
+			}
+			if err := st.db.Create(&model.EntityFile{EntityID: album.ID, EntityType: "album", FileID: file.ID}).Error; err != nil {
+				t.Fatalf("associate file: %v", err)
+			}
+		}
+		share.TargetID = album.ID
+	}
+	if err := st.db.Create(share).Error; err != nil {
+		t.Fatalf("create share: %v", err)
+	}
+	return share
+}
+
+func (st *shareTest) resolve(share *model.Share, page int) (*ShareContentResponse, error) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/v1/s/"+share.Token, nil)
+	return st.svc.ResolveShare(c, share.Token, ShareAccess{}, page, 1)
+}
+
+func (st *shareTest) viewCount(t *testing.T, share *model.Share) int {
+	t.Helper()
+	var reloaded model.Share
+	if err := st.db.WithContext(context.Background()).First(&reloaded, share.ID).Error; err != nil {
+		t.Fatalf("load share: %v", err)
+	}
+	return reloaded.ViewCount
+}
+
+// TestResolveShareCountsEveryPage 只请求相册后续页也计入访问次数，不能绕过上限
+func TestResolveShareCountsEveryPage(t *testing.T) {
+	st := newShareTest(t)
+	share := st.createShare(t, model.ShareTargetAlbum, 2, 3)
+
+	for _, page := range []int{2, 3} {
+		resp, err := st.resolve(share, page)
+		if err != nil {
+			t.Fatalf("第 %d 页: %v", page, err)
+		}
+		if len(resp.Photos) != 1 || resp.TotalPages != 3 {
+			t.Fatalf("第 %d 页 photos=%d totalPages=%d", page, len(resp.Photos), resp.TotalPages)
+		}
+	}
+	if got := st.viewCount(t, share); got != 2 {
+		t.Fatalf("view_count = %d, want 2", got)
+	}
+
+	for _, page := range []int{2, 1} {
+		if _, err := st.resolve(share, page); !errors.Is(err, errMsg.ErrShareExhausted) {
+			t.Fatalf("次数用完后访问第 %d 页: err = %v, want ErrShareExhausted", page, err)
+		}
+	}
+	if got := st.viewCount(t, share); got != 2 {
+		t.Fatalf("次数用完后 view_count = %d, want 2", got)
+	}
+}
+
+// TestResolveSharePageOutOfRange 动态和照片集只有一页，请求后续页被拒绝且不计数
+func TestResolveSharePageOutOfRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		targetType model.ShareTargetType
+	}{
+		{name: "动态", targetType: model.ShareTargetMoment},
+		{name: "照片集", targetType: model.ShareTargetPhotos},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newShareTest(t)
+			share := st.createShare(t, tt.targetType, 1, 0)
+
+			if _, err := st.resolve(share, 2); !errors.Is(err, errMsg.ErrSharePageInvalid) {
+				t.Fatalf("err = %v, want ErrSharePageInvalid", err)
+			}
+			if got := st.viewCount(t, share); got != 0 {
+				t.Fatalf("view_count = %d, want 0", got)
+			}
+		})
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/bookandmusic/love-girl/internal/audit"
 	"github.com/bookandmusic/love-girl/internal/auth"
 	"github.com/bookandmusic/love-girl/internal/config"
 	"github.com/bookandmusic/love-girl/internal/log"
@@ -18,14 +20,17 @@ import (
 
 type SystemService struct {
 	*BaseService
-	UserRepo    repo.UserRepo
-	SettingRepo repo.SettingRepo
-	AlbumRepo   repo.AlbumRepo
-	PlaceRepo   repo.PlaceRepo
-	MomentRepo  repo.MomentRepo
-	FileService *FileService
-	config      *config.AppConfig
-	jwt         auth.JWT
+	UserRepo        repo.UserRepo
+	SettingRepo     repo.SettingRepo
+	AlbumRepo       repo.AlbumRepo
+	PlaceRepo       repo.PlaceRepo
+	MomentRepo      repo.MomentRepo
+	FileService     *FileService
+	Audit           *AuditService
+	NotificationSvc *NotificationService
+	config          *config.AppConfig
+	jwt             auth.JWT
+	alerts          *alertState
 }
 
 func NewSystemService(
@@ -38,17 +43,22 @@ func NewSystemService(
 	fileService *FileService,
 	config *config.AppConfig,
 	jwt auth.JWT,
+	auditService *AuditService,
+	notificationService *NotificationService,
 ) *SystemService {
 	return &SystemService{
-		BaseService: &BaseService{Log: log},
-		UserRepo:    userRepo,
-		SettingRepo: settingRepo,
-		AlbumRepo:   albumRepo,
-		PlaceRepo:   placeRepo,
-		MomentRepo:  momentRepo,
-		FileService: fileService,
-		config:      config,
-		jwt:         jwt,
+		BaseService:     &BaseService{Log: log},
+		UserRepo:        userRepo,
+		SettingRepo:     settingRepo,
+		AlbumRepo:       albumRepo,
+		PlaceRepo:       placeRepo,
+		MomentRepo:      momentRepo,
+		FileService:     fileService,
+		Audit:           auditService,
+		NotificationSvc: notificationService,
+		config:          config,
+		jwt:             jwt,
+		alerts:          newAlertState(),
 	}
 }
 
@@ -107,6 +117,14 @@ func (s *SystemService) InitSystem(ctx context.Context, req *InitSystemRequest)
 		return fmt.Errorf("系统内部错误")
 	}
 
+	s.Audit.Record(ctx, AuditEntry{
+		Action:    audit.ActionSystemInit,
+		ActorName: req.UserAName,
+		After: map[string]any{
+			"siteTitle": req.SiteName,
+			"users":     []string{req.UserAName, req.UserBName},
+		},
+	})
 	return nil
 }
 
@@ -274,6 +292,25 @@ func (s *SystemService) SaveSettings(ctx context.Context, settings map[string]st
 	if settings["siteTitle"] == "" {
 		return errors.New("站点标题不能为空")
 	}
+	if value, ok := settings[AuditRetentionSettingKey]; ok && value != "" {
+		if days, err := strconv.Atoi(value); err != nil || days < 0 {
+			return errors.New("审计日志保留天数必须为非负整数")
+		}
+	}
+	if value, ok := settings[NotificationRetentionSettingKey]; ok && value != "" {
+		if days, err := strconv.Atoi(value); err != nil || days < 0 {
+			return errors.New("已读通知保留天数必须为非负整数")
+		}
+	}
+
+	// 记录修改前的设置，用于审计对比
+	before := make(map[string]any)
+	if current, err := s.GetSettings(ctx); err == nil {
+		for key, value := range current {
+			before[key] = value
+		}
+	}
+
 	for key, value := range settings {
 		setting := &model.Setting{
 			Value: value,
@@ -287,6 +324,20 @@ func (s *SystemService) SaveSettings(ctx context.Context, settings map[string]st
 			return fmt.Errorf("系统内部错误")
 		}
 	}
+
+	after := make(map[string]any, len(before))
+	for key, value := range before {
+		after[key] = value
+	}
+	for key, value := range settings {
+		after[key] = value
+	}
+	s.Audit.Record(ctx, AuditEntry{
+		Action:     audit.ActionSettingsUpdate,
+		TargetType: "settings",
+		Before:     before,
+		After:      after,
+	})
 	return nil
 }
 
@@ -338,10 +389,12 @@ func (s *SystemService) GetDashboardStats(ctx context.Context) (*model.Dashboard
 // getLabelByKey 根据键名获取标签
 func getLabelByKey(key string) string {
 	labels := map[string]string{
-		"siteTitle":       "站点标题",
-		"siteName":        "站点名称",
-		"siteDescription": "站点描述",
-		"startDate":       "故事开始日期",
+		"siteTitle":                     "站点标题",
+		"siteName":                      "站点名称",
+		"siteDescription":               "站点描述",
+		"startDate":                     "故事开始日期",
+		AuditRetentionSettingKey:        "审计日志保留天数",
+		NotificationRetentionSettingKey: "已读通知保留天数",
 	}
 	if label, ok := labels[key]; ok {
 		return label
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bookandmusic/love-girl/internal/model"
+)
+
+const (
+	// AlertStorageError 存储服务读写失败
+	AlertStorageError = "storage_error"
+	// systemAlertInterval 同一类告警持续存在时重复提醒的间隔
+	systemAlertInterval = 6 * time.Hour
+	// storageProbePath 存储检查写入的探测文件
+	storageProbePath = ".healthcheck/probe"
+)
+
+// alertState 记录每类告警最近一次发送的时间，避免故障持续期间反复提醒
+// 说明：只保存在内存中，服务重启后故障仍存在时会再提醒一次
+type alertState struct {
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+func newAlertState() *alertState {
+	return &alertState{sent: make(map[string]time.Time)}
+}
+
+// CheckStorage 写入、读取并删除探测文件以检查存储服务，失败时向全部用户发送系统告警，由后台任务定期调用
+func (s *SystemService) CheckStorage(ctx context.Context) error {
+	name := s.FileService.Storage.Name()
+	if err := s.probeStorage(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		s.Log.Error("存储服务检查失败", "storage", name, "error", err)
+		s.raiseAlert(ctx, model.AlertLevelError, AlertStorageError, fmt.Sprintf("存储服务（%s）读写失败：%v", name, err))
+		return nil
+	}
+	s.resolveAlert(AlertStorageError)
+	return nil
+}
+
+func (s *SystemService) probeStorage(ctx context.Context) error {
+	storage := s.FileService.Storage
+	content := []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+
+	if err := storage.Save(ctx, storageProbePath, bytes.NewReader(content)); err != nil {
+		return fmt.Errorf("写入失败: %w", err)
+	}
+	reader, err := storage.Open(ctx, storageProbePath)
+	if err != nil {
+		return fmt.Errorf("读取失败: %w", err)
+	}
+	data, err := io.ReadAll(reader)
+	_ = reader.Close()
+	if err != nil {
+		return fmt.Errorf("读取失败: %w", err)
+	}
+	if !bytes.Equal(data, content) {
+		return fmt.Errorf("读取的内容与写入的不一致")
+	}
+	if err := storage.Delete(ctx, storageProbePath); err != nil {
+		return fmt.Errorf("删除失败: %w", err)
+	}
+	return nil
+}
+
+// raiseAlert 向全部用户发送系统告警，同一类告警在 systemAlertInterval 内只发送一次
+func (s *SystemService) raiseAlert(ctx context.Context, level model.AlertLevel, code, message string) {
+	s.alerts.mu.Lock()
+	defer s.alerts.mu.Unlock()
+	if last, ok := s.alerts.sent[code]; ok && time.Since(last) < systemAlertInterval {
+		return
+	}
+
+	users, err := s.UserRepo.List(ctx)
+	if err != nil {
+		s.Log.Error("查询告警接收用户失败", "error", err, "code", code)
+		return
+	}
+	payload := model.SystemAlertPayload{Level: level, Code: code, Message: message}
+	sent := false
+	for _, user := range users {
+		// 系统告警不关联对象，发送者记为接收者本人
+		if err := s.NotificationSvc.CreateNotification(ctx, user.ID, user.ID, model.NotificationTypeSystem, model.NotificationEntityNone, 0, payload); err != nil {
+			s.Log.Error("创建系统告警失败", "error", err, "code", code, "userID", user.ID)
+			continue
+		}
+		sent = true
+	}
+	if sent {
+		s.alerts.sent[code] = time.Now()
+	}
+}
+
+// resolveAlert 故障恢复后清除记录，再次发生时立即提醒
+func (s *SystemService) resolveAlert(code string) {
+	s.alerts.mu.Lock()
+	defer s.alerts.mu.Unlock()
+	if _, ok := s.alerts.sent[code]; ok {
+		delete(s.alerts.sent, code)
+		s.Log.Info("系统告警已恢复", "code", code)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/bookandmusic/love-girl/internal/audit"
 	"github.com/bookandmusic/love-girl/internal/auth"
 	"github.com/bookandmusic/love-girl/internal/config"
 	"github.com/bookandmusic/love-girl/internal/log"
@@ -24,10 +25,11 @@ type UserService struct {
 	FileService *FileService
 	Storage     storage.Storage
 	JWT         auth.JWT
+	Audit       *AuditService
 	serverCfg   *config.ServerConfig
 }
 
-func NewUserService(log *log.Logger, userRepo repo.UserRepo, fileRepo repo.FileRepo, fileService *FileService, storage storage.Storage, serverCfg *config.ServerConfig, jwt auth.JWT) *UserService {
+func NewUserService(log *log.Logger, userRepo repo.UserRepo, fileRepo repo.FileRepo, fileService *FileService, storage storage.Storage, serverCfg *config.ServerConfig, jwt auth.JWT, auditService *AuditService) *UserService {
 	return &UserService{
 		BaseService: &BaseService{Log: log},
 		UserRepo:    userRepo,
@@ -35,6 +37,7 @@ func NewUserService(log *log.Logger, userRepo repo.UserRepo, fileRepo repo.FileR
 		FileService: fileService,
 		Storage:     storage,
 		JWT:         jwt,
+		Audit:       auditService,
 		serverCfg:   serverCfg,
 	}
 }
@@ -44,6 +47,7 @@ func (s *UserService) GenerateToken(ctx context.Context, username, password stri
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			s.Log.Info("用户登录失败，用户不存在", "username", username)
+			s.Audit.Record(ctx, AuditEntry{Action: audit.ActionLoginFailed, ActorName: username, Failed: true})
 			return nil, "", fmt.Errorf("用户名或密码错误")
 		}
 		s.Log.Error("用户查询失败", "error", err, "username", username)
@@ -52,16 +56,31 @@ func (s *UserService) GenerateToken(ctx context.Context, username, password stri
 
 	if !utils.VerifyPassword(user.Password, password) {
 		s.Log.Info("用户登录失败，密码错误", "username", username)
+		s.Audit.Record(ctx, AuditEntry{
+			Action:     audit.ActionLoginFailed,
+			ActorName:  username,
+			TargetType: "user",
+			TargetID:   user.ID,
+			Failed:     true,
+		})
 		return nil, "", fmt.Errorf("用户名或密码错误")
 	}
 	token, err := s.JWT.Generate(&auth.Claims{
-		Role:   "user",
-		UserID: uint64(user.ID),
+		Role:    "user",
+		UserID:  uint64(user.ID),
+		Version: user.TokenVersion,
 	})
 	if err != nil {
 		s.Log.Error("用户生成token失败", "error", err, "username", username)
 		return nil, "", fmt.Errorf("系统内部错误")
 	}
+	s.Audit.Record(ctx, AuditEntry{
+		Action:     audit.ActionLogin,
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		TargetType: "user",
+		TargetID:   user.ID,
+	})
 	return user, token, nil
 }
 
@@ -133,6 +152,8 @@ func (s *UserService) UpdateUser(c *gin.Context, userID uint64, name, email stri
 		return nil, fmt.Errorf("系统内部错误")
 	}
 
+	before := userAuditSnapshot(user)
+
 	// 更新用户信息
 	user.Name = name
 	if email != "" {
@@ -158,6 +179,20 @@ func (s *UserService) UpdateUser(c *gin.Context, userID uint64, name, email stri
 		return nil, fmt.Errorf("系统内部错误")
 	}
 
+	after := userAuditSnapshot(user)
+	if newPassword != "" {
+		// 密码只记录是否修改，不记录哈希
+		before["password"] = audit.Redacted
+		after["password"] = "changed"
+	}
+	s.Audit.Record(ctx, AuditEntry{
+		Action:     audit.ActionUserUpdate,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Before:     before,
+		After:      after,
+	})
+
 	// 获取email值
 	userEmail := ""
 	if user.Email != nil {
@@ -222,3 +257,19 @@ func (s *UserService) GetAvatarHistory(c *gin.Context, userID uint64, page, size
 		TotalPages: totalPage,
 	}, nil
 }
+
+// userAuditSnapshot 用户信息的审计快照
+func userAuditSnapshot(user *model.User) map[string]any {
+	snapshot := map[string]any{
+		"name":     user.Name,
+		"email":    "",
+		"avatarId": uint64(0),
+	}
+	if user.Email != nil {
+		snapshot["email"] = *user.Email
+	}
+	if user.AvatarID != nil {
+		snapshot["avatarId"] = *user.AvatarID
+	}
+	return snapshot
+}
@@ -0,0 +1,128 @@
+package utils
+
+import "strings"
+
+// DiffOp 文本差异片段的类型
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"  // 两个版本相同的部分
+	DiffInsert DiffOp = "insert" // 新版本增加的部分
+	DiffDelete DiffOp = "delete" // 旧版本删除的部分
+)
+
+// maxDiffCells 逐字比较时 LCS 表的最大单元数，超出后退化为逐行比较，避免长文本占用过多内存
+const maxDiffCells = 1 << 20
+
+// DiffSegment 文本差异片段
+type DiffSegment struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffText 比较两段文本，返回按顺序拼接即可还原两个版本的差异片段
+// 默认逐字比较（适合中文短文本），文本较长时逐行比较
+func DiffText(oldText, newText string) []DiffSegment {
+	a, b := splitRunes(oldText), splitRunes(newText)
+	if len(a)*len(b) > maxDiffCells {
+		a, b = splitLines(oldText), splitLines(newText)
+	}
+	return diffTokens(a, b)
+}
+
+func diffTokens(a, b []string) []DiffSegment {
+	var segments []DiffSegment
+	// 去掉公共前缀和后缀，缩小 LCS 表
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	segments = appendSegment(segments, DiffEqual, a[:prefix])
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	switch {
+	case len(midA) == 0:
+		segments = appendSegment(segments, DiffInsert, midB)
+	case len(midB) == 0:
+		segments = appendSegment(segments, DiffDelete, midA)
+	case len(midA)*len(midB) > maxDiffCells:
+		// 逐行比较仍然过大时整体替换
+		segments = appendSegment(segments, DiffDelete, midA)
+		segments = appendSegment(segments, DiffInsert, midB)
+	default:
+		segments = appendLCSDiff(segments, midA, midB)
+	}
+
+	segments = appendSegment(segments, DiffEqual, a[len(a)-suffix:])
+	if segments == nil {
+		segments = []DiffSegment{}
+	}
+	return segments
+}
+
+// appendLCSDiff 基于最长公共子序列生成差异，同一位置先输出删除再输出新增
+func appendLCSDiff(segments []DiffSegment, a, b []string) []DiffSegment {
+	n, m := len(a), len(b)
+	// lcs[i*(m+1)+j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([]int32, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+			} else {
+				lcs[i*(m+1)+j] = max(lcs[(i+1)*(m+1)+j], lcs[i*(m+1)+j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			segments = appendSegment(segments, DiffEqual, a[i:i+1])
+			i++
+			j++
+		case lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]:
+			segments = appendSegment(segments, DiffDelete, a[i:i+1])
+			i++
+		default:
+			segments = appendSegment(segments, DiffInsert, b[j:j+1])
+			j++
+		}
+	}
+	segments = appendSegment(segments, DiffDelete, a[i:])
+	return appendSegment(segments, DiffInsert, b[j:])
+}
+
+// appendSegment 追加片段，与前一个同类型片段合并
+func appendSegment(segments []DiffSegment, op DiffOp, tokens []string) []DiffSegment {
+	if len(tokens) == 0 {
+		return segments
+	}
+	text := strings.Join(tokens, "")
+	if last := len(segments) - 1; last >= 0 && segments[last].Op == op {
+		segments[last].Text += text
+		return segments
+	}
+	return append(segments, DiffSegment{Op: op, Text: text})
+}
+
+func splitRunes(text string) []string {
+	tokens := make([]string, 0, len(text))
+	for _, r := range text {
+		tokens = append(tokens, string(r))
+	}
+	return tokens
+}
+
+// splitLines 按行切分，保留行尾换行符以便还原原文
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.SplitAfter(text, "\n")
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"time"
+)
+
+// ExifHeadSize 解析 EXIF 需要读取的文件头长度，EXIF 位于 JPEG 的 APP1 段，不超过 64KB
+const ExifHeadSize = 64 << 10
+
+const (
+	exifTagDateTime         = 0x0132 // IFD0：文件修改时间
+	exifTagExifIFDPointer   = 0x8769 // IFD0：Exif 子目录偏移
+	exifTagDateTimeOriginal = 0x9003 // Exif IFD：拍摄时间
+	exifTypeASCII           = 2
+	exifTypeLong            = 4
+)
+
+// ExifTakenAt 从 JPEG 文件头中解析拍摄时间，优先使用 DateTimeOriginal，其次 DateTime
+// EXIF 时间不含时区，按服务器本地时区解析。非 JPEG 或没有时间信息时返回 false
+func ExifTakenAt(head []byte) (time.Time, bool) {
+	tiff := findExifSegment(head)
+	if tiff == nil {
+		return time.Time{}, false
+	}
+
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(tiff, []byte("II*\x00")):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(tiff, []byte("MM\x00*")):
+		order = binary.BigEndian
+	default:
+		return time.Time{}, false
+	}
+
+	ifd0 := order.Uint32(tiff[4:8])
+	var dateTime, original string
+	var exifIFD uint32
+	walkIFD(tiff, ifd0, order, func(tag, typ uint16, count, value uint32, valueOffset int) {
+		switch {
+		case tag == exifTagDateTime && typ == exifTypeASCII:
+			dateTime = readExifASCII(tiff, order, count, valueOffset)
+		case tag == exifTagExifIFDPointer && typ == exifTypeLong:
+			exifIFD = value
+		}
+	})
+	if exifIFD != 0 {
+		walkIFD(tiff, exifIFD, order, func(tag, typ uint16, count, value uint32, valueOffset int) {
+			if tag == exifTagDateTimeOriginal && typ == exifTypeASCII {
+				original = readExifASCII(tiff, order, count, valueOffset)
+			}
+		})
+	}
+
+	for _, raw := range []string{original, dateTime} {
+		// 未设置时间的相机会写入全 0 或空格
+		if raw == "" || strings.HasPrefix(raw, "0000") {
+			continue
+		}
+		if t, err := time.ParseInLocation("2006:01:02 15:04:05", raw, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// findExifSegment 在 JPEG 中查找 APP1 Exif 段，返回其中的 TIFF 数据
+func findExifSegment(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil
+		}
+		marker := data[pos+1]
+		// SOS 之后是图像数据，不会再有 EXIF
+		if marker == 0xDA || marker == 0xD9 {
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if length < 2 {
+			return nil
+		}
+		end := pos + 2 + length
+		if end > len(data) {
+			end = len(data)
+		}
+		segment := data[pos+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) && len(segment) >= 14 {
+			return segment[6:]
+		}
+		pos += 2 + length
+	}
+	return nil
+}
+
+// walkIFD 遍历 IFD 中的条目，valueOffset 为值所在位置（不超过 4 字节时就在条目内）
+func walkIFD(tiff []byte, offset uint32, order binary.ByteOrder, fn func(tag, typ uint16, count, value uint32, valueOffset int)) {
+	start := int(offset)
+	if start <= 0 || start+2 > len(tiff) {
+		return
+	}
+	entries := int(order.Uint16(tiff[start : start+2]))
+	for i := 0; i < entries; i++ {
+		entry := start + 2 + i*12
+		if entry+12 > len(tiff) {
+			return
+		}
+		tag := order.Uint16(tiff[entry : entry+2])
+		typ := order.Uint16(tiff[entry+2 : entry+4])
+		count := order.Uint32(tiff[entry+4 : entry+8])
+		value := order.Uint32(tiff[entry+8 : entry+12])
+		valueOffset := entry + 8
+		if typ == exifTypeASCII && count > 4 {
+			valueOffset = int(value)
+		}
+		fn(tag, typ, count, value, valueOffset)
+	}
+}
+
+func readExifASCII(tiff []byte, order binary.ByteOrder, count uint32, offset int) string {
+	end := offset + int(count)
+	if offset < 0 || end > len(tiff) || end < offset {
+		return ""
+	}
+	return strings.TrimRight(string(tiff[offset:end]), "\x00 ")
+}
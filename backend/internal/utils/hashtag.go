@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxHashtagLength 单个话题标签的最大字符数，超出的不视为标签
+	MaxHashtagLength = 32
+	// MaxHashtagsPerText 单条内容最多解析的话题标签数量
+	MaxHashtagsPerText = 20
+)
+
+// ParseHashtags 从内容中解析话题标签，返回去重后的小写标签名（不含 #），保持出现顺序
+// 兼容 "#旅行" 和微博风格的 "#旅行#" 两种写法，微博风格结尾的 # 可以紧接下一个标签，如 "#旅行#美食#"
+func ParseHashtags(content string) []string {
+	runes := []rune(content)
+	seen := make(map[string]struct{})
+	tags := make([]string, 0)
+	closing := -1 // 上一个微博风格标签结尾 # 的位置
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '#' || (i != closing && !isHashtagBoundary(runes, i)) {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && isHashtagRune(runes[end]) {
+			end++
+		}
+		if end == i+1 {
+			continue
+		}
+		if end < len(runes) && runes[end] == '#' {
+			closing = end
+		}
+
+		tag := NormalizeHashtag(string(runes[i+1 : end]))
+		i = end - 1
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+		if len(tags) == MaxHashtagsPerText {
+			break
+		}
+	}
+	return tags
+}
+
+// isHashtagBoundary # 前是否允许开始一个标签：开头、中日韩文字，或除 / & # 外的非单词字符，
+// 避免把 URL 锚点、HTML 实体（&#39;）、英文单词中的 # 等识别为标签
+func isHashtagBoundary(runes []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := runes[i-1]
+	if unicode.In(prev, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+		return true
+	}
+	return !isHashtagRune(prev) && prev != '/' && prev != '&' && prev != '#'
+}
+
+func isHashtagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_'
+}
+
+// NormalizeHashtag 规范化标签名：去掉 # 前缀和首尾空白并转为小写，超长或纯数字的返回空字符串
+func NormalizeHashtag(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(strings.Trim(strings.TrimSpace(tag), "#")))
+	if tag == "" || utf8.RuneCountInString(tag) > MaxHashtagLength {
+		return ""
+	}
+	// 纯数字通常是编号（如 #1），不作为话题
+	if strings.Trim(tag, "0123456789") == "" {
+		return ""
+	}
+	return tag
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHashtags(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "空内容", content: "", want: []string{}},
+		{name: "开头", content: "#旅行 很开心", want: []string{"旅行"}},
+		{name: "空格后", content: "今天 #旅行 很开心", want: []string{"旅行"}},
+		{name: "紧跟中文", content: "今天去了#旅行 很开心", want: []string{"旅行"}},
+		{name: "紧跟日文", content: "きょうは#旅行 です", want: []string{"旅行"}},
+		{name: "微博风格", content: "#旅行# 今天很开心", want: []string{"旅行"}},
+		{name: "微博风格连写", content: "#旅行#美食#", want: []string{"旅行", "美食"}},
+		{name: "微博风格紧跟中文", content: "#旅行# 今天#美食#", want: []string{"旅行", "美食"}},
+		{name: "标点后", content: "好开心！#周末，#Travel。", want: []string{"周末", "travel"}},
+		{name: "转为小写并去重", content: "#Travel #travel #TRAVEL", want: []string{"travel"}},
+		{name: "下划线和数字", content: "#day_1 #2024旅行", want: []string{"day_1", "2024旅行"}},
+		{name: "纯数字不是标签", content: "第 #1 名 #123", want: []string{}},
+		{name: "英文单词中的 #", content: "C#语言 abc#def", want: []string{}},
+		{name: "URL 锚点", content: "https://example.com/page#section 和 https://example.com/#/home", want: []string{}},
+		{name: "HTML 实体", content: "it&#39;s &#x4e2d;", want: []string{}},
+		{name: "连续的 #", content: "##旅行 #", want: []string{}},
+		{name: "超长", content: "#" + strings.Repeat("长", MaxHashtagLength+1) + " #短", want: []string{"短"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseHashtags(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseHashtags(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHashtagsLimit(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < MaxHashtagsPerText+5; i++ {
+		b.WriteString("#tag" + strings.Repeat("x", i) + " ")
+	}
+	if got := ParseHashtags(b.String()); len(got) != MaxHashtagsPerText {
+		t.Fatalf("len = %d, want %d", len(got), MaxHashtagsPerText)
+	}
+}
+
+func TestNormalizeHashtag(t *testing.T) {
+	tests := map[string]string{
+		"#旅行#":  "旅行",
+		" Foo ": "foo",
+		"2024":  "",
+		"":      "",
+	}
+	for input, want := range tests {
+		if got := NormalizeHashtag(input); got != want {
+			t.Errorf("NormalizeHashtag(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package provider
 import (
 	"github.com/google/wire"
 
+	"github.com/bookandmusic/love-girl/internal/config"
 	"github.com/bookandmusic/love-girl/internal/handler"
+	"github.com/bookandmusic/love-girl/internal/middleware"
 	"github.com/bookandmusic/love-girl/internal/service"
 )
 
@@ -47,6 +49,38 @@ func ProvideNotificationHandler(svc *service.NotificationService) *handler.Notif
 	return handler.NewNotificationHandler(svc)
 }
 
+func ProvideNotificationChannelHandler(svc *service.NotificationChannelService) *handler.NotificationChannelHandler {
+	return handler.NewNotificationChannelHandler(svc)
+}
+
+func ProvideNotificationDigestHandler(svc *service.NotificationDigestService) *handler.NotificationDigestHandler {
+	return handler.NewNotificationDigestHandler(svc)
+}
+
+func ProvideShareHandler(svc *service.ShareService, fileHandler *handler.FileHandler) *handler.ShareHandler {
+	return handler.NewShareHandler(svc, fileHandler)
+}
+
+func ProvideAPITokenHandler(svc *service.APITokenService) *handler.APITokenHandler {
+	return handler.NewAPITokenHandler(svc)
+}
+
+func ProvidePasswordResetHandler(svc *service.PasswordResetService) *handler.PasswordResetHandler {
+	return handler.NewPasswordResetHandler(svc)
+}
+
+func ProvideAuditHandler(svc *service.AuditService) *handler.AuditHandler {
+	return handler.NewAuditHandler(svc)
+}
+
+func ProvideOIDCHandler(svc *service.OIDCService) *handler.OIDCHandler {
+	return handler.NewOIDCHandler(svc)
+}
+
+func ProvideSearchHandler(svc *service.SearchService) *handler.SearchHandler {
+	return handler.NewSearchHandler(svc)
+}
+
 func ProvideStaticHandler() *handler.StaticHandler {
 	return handler.NewStaticHandler()
 }
@@ -55,13 +89,33 @@ func ProvideSwaggerHandler() *handler.SwaggerHandler {
 	return handler.NewSwaggerHandler()
 }
 
+func ProvideMemoryHandler(svc *service.MemoryService) *handler.MemoryHandler {
+	return handler.NewMemoryHandler(svc)
+}
+
+func ProvideCollectionHandler(svc *service.CollectionService) *handler.CollectionHandler {
+	return handler.NewCollectionHandler(svc)
+}
+
+func ProvideFeedHandler(svc *service.FeedService) *handler.FeedHandler {
+	return handler.NewFeedHandler(svc)
+}
+
+func ProvideNotificationStreamHandler(svc *service.NotificationService, authMiddleware *middleware.AuthMiddleware, cfg *config.AppConfig) *handler.NotificationStreamHandler {
+	return handler.NewNotificationStreamHandler(svc, authMiddleware, cfg)
+}
+
 func ProvideStaticHandlers(
 	staticHandler *handler.StaticHandler,
 	swaggerHandler *handler.SwaggerHandler,
+	feedHandler *handler.FeedHandler,
+	notificationStreamHandler *handler.NotificationStreamHandler,
 ) []handler.StaticHandlerAware {
 	return []handler.StaticHandlerAware{
 		staticHandler,
 		swaggerHandler,
+		feedHandler,
+		notificationStreamHandler,
 	}
 }
 
@@ -76,6 +130,16 @@ func ProvideHandlers(
 	albumHandler *handler.AlbumHandler,
 	commentHandler *handler.CommentHandler,
 	notificationHandler *handler.NotificationHandler,
+	notificationChannelHandler *handler.NotificationChannelHandler,
+	notificationDigestHandler *handler.NotificationDigestHandler,
+	shareHandler *handler.ShareHandler,
+	apiTokenHandler *handler.APITokenHandler,
+	passwordResetHandler *handler.PasswordResetHandler,
+	auditHandler *handler.AuditHandler,
+	oidcHandler *handler.OIDCHandler,
+	searchHandler *handler.SearchHandler,
+	memoryHandler *handler.MemoryHandler,
+	collectionHandler *handler.CollectionHandler,
 ) []handler.ApiHandler {
 	return []handler.ApiHandler{
 		userHandler,
@@ -88,6 +152,16 @@ func ProvideHandlers(
 		albumHandler,
 		commentHandler,
 		notificationHandler,
+		notificationChannelHandler,
+		notificationDigestHandler,
+		shareHandler,
+		apiTokenHandler,
+		passwordResetHandler,
+		auditHandler,
+		oidcHandler,
+		searchHandler,
+		memoryHandler,
+		collectionHandler,
 	}
 }
 
@@ -102,8 +176,20 @@ var HandlerSet = wire.NewSet(
 	ProvideAlbumHandler,
 	ProvideCommentHandler,
 	ProvideNotificationHandler,
+	ProvideNotificationChannelHandler,
+	ProvideNotificationDigestHandler,
+	ProvideShareHandler,
+	ProvideAPITokenHandler,
+	ProvidePasswordResetHandler,
+	ProvideAuditHandler,
+	ProvideOIDCHandler,
+	ProvideSearchHandler,
+	ProvideMemoryHandler,
+	ProvideCollectionHandler,
 	ProvideStaticHandler,
 	ProvideSwaggerHandler,
+	ProvideFeedHandler,
+	ProvideNotificationStreamHandler,
 	ProvideStaticHandlers,
 	ProvideHandlers,
 )
@@ -0,0 +1,21 @@
+package infra
+
+import (
+	"github.com/bookandmusic/love-girl/internal/config"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/mail"
+)
+
+// ProvideMailQueue 创建并启动邮件发送队列，重启或退出时由 cleanup 停止
+func ProvideMailQueue(cfg *config.AppConfig, logger *log.Logger) (*mail.Queue, func()) {
+	var sender mail.Sender
+	if cfg.Mail.Enabled() {
+		sender = mail.NewSMTPSender(&cfg.Mail)
+	} else {
+		logger.Info("未配置邮件服务，邮件功能不可用")
+	}
+
+	queue := mail.NewQueue(sender, logger, cfg.Mail.QueueSize, cfg.Mail.MaxRetries)
+	queue.Start()
+	return queue, queue.Close
+}
@@ -5,6 +5,6 @@ import (
 	"github.com/bookandmusic/love-girl/internal/middleware"
 )
 
-func ProvideAuthMiddleware(jwt auth.JWT) *middleware.AuthMiddleware {
-	return middleware.NewAuthMiddleware(jwt)
+func ProvideAuthMiddleware(jwt auth.JWT, tokenVerifier auth.TokenVerifier) *middleware.AuthMiddleware {
+	return middleware.NewAuthMiddleware(jwt, tokenVerifier)
 }
@@ -1,13 +1,24 @@
 package infra
 
 import (
+	"regexp"
+	"strconv"
+	"strings"
+
 	"gorm.io/gorm"
 
 	"github.com/bookandmusic/love-girl/internal/log"
 	"github.com/bookandmusic/love-girl/internal/model"
+	"github.com/bookandmusic/love-girl/internal/repo"
+	"github.com/bookandmusic/love-girl/internal/utils"
 )
 
 func ProvideMigrate(db *gorm.DB, logger *log.Logger) error {
+	// 话题标签表首次创建时需要从已有动态中解析标签
+	backfillTags := !db.Migrator().HasTable(&model.MomentTag{})
+	// 修订版本表首次创建时需要为已有动态记录当前状态作为第一个版本
+	backfillRevisions := !db.Migrator().HasTable(&model.MomentRevision{})
+
 	if err := db.AutoMigrate(
 		&model.User{},
 		&model.File{},
@@ -19,11 +30,214 @@ func ProvideMigrate(db *gorm.DB, logger *log.Logger) error {
 		&model.Setting{},
 		&model.Comment{},
 		&model.Notification{},
+		&model.Share{},
+		&model.APIToken{},
+		&model.AuditLog{},
+		&model.UserIdentity{},
+		&model.Reaction{},
+		&model.Tag{},
+		&model.MomentTag{},
+		&model.SearchDocument{},
+		&model.MomentRevision{},
+		&model.Collection{},
+		&model.CollectionMoment{},
+		&model.NotificationChannel{},
+		&model.NotificationDelivery{},
+		&model.NotificationDigest{},
 	); err != nil {
 		logger.Error("Database migration failed:", "error", err)
 		return err
 	}
 
+	if err := migrateMomentVisibility(db, logger); err != nil {
+		logger.Error("Database migration failed:", "error", err)
+		return err
+	}
+
+	if err := migrateNotificationReferences(db, logger); err != nil {
+		logger.Error("Database migration failed:", "error", err)
+		return err
+	}
+
+	if backfillTags {
+		if err := backfillMomentTags(db, logger); err != nil {
+			logger.Error("Database migration failed:", "error", err)
+			return err
+		}
+	}
+
+	if backfillRevisions {
+		if err := backfillMomentRevisions(db, logger); err != nil {
+			logger.Error("Database migration failed:", "error", err)
+			return err
+		}
+	}
+
 	logger.Info("Database migrated successfully")
 	return nil
 }
+
+// migrateMomentVisibility 将旧的 is_public 字段迁移为 visibility 后删除旧字段
+// 原公开动态迁移为 public，原非公开动态仅作者可见，迁移为 private
+func migrateMomentVisibility(db *gorm.DB, logger *log.Logger) error {
+	if !db.Migrator().HasColumn(&model.Moment{}, "is_public") {
+		return nil
+	}
+
+	result := db.Unscoped().Model(&model.Moment{}).
+		Where("is_public = ?", true).
+		UpdateColumn("visibility", model.MomentVisibilityPublic)
+	if result.Error != nil {
+		return result.Error
+	}
+	if err := db.Migrator().DropColumn(&model.Moment{}, "is_public"); err != nil {
+		return err
+	}
+	// SQLite 删除字段需要重建表，重建后补回索引
+	if err := db.AutoMigrate(&model.Moment{}); err != nil {
+		return err
+	}
+
+	logger.Info("Migrated moment visibility", "public", result.RowsAffected)
+	return nil
+}
+
+// legacyNotification 旧版通知记录，关联对象固定为动态和评论，内容为纯文本
+type legacyNotification struct {
+	ID        uint64
+	Type      model.NotificationType
+	MomentID  uint64
+	CommentID uint64
+	Content   string
+}
+
+// memoryCountPattern 旧版回忆通知内容中的数量，如 "2 条动态"
+var memoryCountPattern = regexp.MustCompile(`(\d+) (条动态|张照片|个地点|个纪念日)`)
+
+// migrateNotificationReferences 将旧的 moment_id、comment_id、content 字段迁移为关联对象和结构化数据后删除旧字段
+func migrateNotificationReferences(db *gorm.DB, logger *log.Logger) error {
+	if !db.Migrator().HasColumn(&model.Notification{}, "moment_id") {
+		return nil
+	}
+
+	var rows []legacyNotification
+	if err := db.Unscoped().Table(model.Notification{}.TableName()).
+		Select("id", "type", "moment_id", "comment_id", "content").
+		Find(&rows).Error; err != nil {
+		return err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			notification := convertLegacyNotification(row)
+			if err := tx.Unscoped().Model(&model.Notification{}).Where("id = ?", row.ID).
+				UpdateColumns(map[string]any{
+					"entity_type": notification.EntityType,
+					"entity_id":   notification.EntityID,
+					"payload":     notification.Payload,
+				}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, column := range []string{"moment_id", "comment_id", "content"} {
+		if err := db.Migrator().DropColumn(&model.Notification{}, column); err != nil {
+			return err
+		}
+	}
+	// SQLite 删除字段需要重建表，重建后补回索引
+	if err := db.AutoMigrate(&model.Notification{}); err != nil {
+		return err
+	}
+
+	logger.Info("Migrated notification references", "notifications", len(rows))
+	return nil
+}
+
+// convertLegacyNotification 按通知类型从旧字段还原关联对象和结构化数据
+func convertLegacyNotification(row legacyNotification) *model.Notification {
+	notification := &model.Notification{}
+	var payload any
+	switch row.Type {
+	case model.NotificationTypeComment, model.NotificationTypeReply:
+		notification.EntityType, notification.EntityID = model.NotificationEntityComment, row.CommentID
+		payload = model.CommentPayload{MomentID: row.MomentID, Excerpt: row.Content}
+	case model.NotificationTypeGuest:
+		// 旧内容格式为 "访客 昵称：评论"
+		name, excerpt, _ := strings.Cut(strings.TrimPrefix(row.Content, "访客 "), "：")
+		notification.EntityType, notification.EntityID = model.NotificationEntityComment, row.CommentID
+		payload = model.CommentPayload{MomentID: row.MomentID, Excerpt: excerpt, GuestName: name}
+	case model.NotificationTypeReaction:
+		notification.EntityType, notification.EntityID = model.NotificationEntityMoment, row.MomentID
+		payload = model.ReactionPayload{Reaction: model.ReactionType(row.Content)}
+	case model.NotificationTypePublish:
+		notification.EntityType, notification.EntityID = model.NotificationEntityMoment, row.MomentID
+		payload = model.MomentPayload{Excerpt: row.Content}
+	case model.NotificationTypeMemory:
+		var counts model.MemoryPayload
+		for _, match := range memoryCountPattern.FindAllStringSubmatch(row.Content, -1) {
+			n, _ := strconv.Atoi(match[1])
+			switch match[2] {
+			case "条动态":
+				counts.Moments = n
+			case "张照片":
+				counts.Photos = n
+			case "个地点":
+				counts.Places = n
+			case "个纪念日":
+				counts.Anniversaries = n
+			}
+		}
+		payload = counts
+	}
+	_ = notification.SetPayload(payload)
+	return notification
+}
+
+// backfillMomentTags 为已有动态解析并写入话题标签
+func backfillMomentTags(db *gorm.DB, logger *log.Logger) error {
+	var moments []model.Moment
+	if err := db.Select("id", "content").Find(&moments).Error; err != nil {
+		return err
+	}
+
+	tagged := 0
+	for _, moment := range moments {
+		tags := utils.ParseHashtags(moment.Content)
+		if len(tags) == 0 {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return repo.SyncMomentTags(tx, moment.ID, tags)
+		}); err != nil {
+			return err
+		}
+		tagged++
+	}
+
+	logger.Info("Backfilled moment tags", "moments", tagged)
+	return nil
+}
+
+// backfillMomentRevisions 为已有动态记录当前状态作为第一个修订版本
+func backfillMomentRevisions(db *gorm.DB, logger *log.Logger) error {
+	var momentIDs []uint64
+	if err := db.Model(&model.Moment{}).Pluck("id", &momentIDs).Error; err != nil {
+		return err
+	}
+
+	for _, id := range momentIDs {
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return repo.AppendMomentRevision(tx, id, repo.RevisionInfo{Action: model.MomentRevisionCreate})
+		}); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Backfilled moment revisions", "moments", len(momentIDs))
+	return nil
+}
@@ -0,0 +1,59 @@
+package infra
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bookandmusic/love-girl/internal/config"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/mail"
+	"github.com/bookandmusic/love-girl/internal/notify"
+)
+
+// defaultNotifyTimeout 未配置时单次投递的超时
+const defaultNotifyTimeout = 15 * time.Second
+
+// ProvideNotifyChannels 根据配置创建可用的站外通知渠道，Webhook 无需配置始终可用
+func ProvideNotifyChannels(cfg *config.AppConfig, logger *log.Logger) notify.Channels {
+	timeout := defaultNotifyTimeout
+	if cfg.Notify.Timeout > 0 {
+		timeout = time.Duration(cfg.Notify.Timeout) * time.Second
+	}
+	// Webhook 和 Web Push 的地址由用户填写，使用拒绝内网地址的客户端；Telegram 的地址来自站点配置
+	client := &http.Client{Timeout: timeout}
+	targetClient := notify.NewHTTPClient(timeout)
+
+	channels := notify.Channels{
+		notify.KindWebhook: notify.NewWebhook(targetClient),
+	}
+
+	if cfg.Mail.Enabled() {
+		channels[notify.KindEmail] = notify.NewEmail(mail.NewSMTPSender(&cfg.Mail))
+	}
+
+	if cfg.Notify.Telegram.BotToken != "" {
+		channels[notify.KindTelegram] = notify.NewTelegram(client, cfg.Notify.Telegram.APIURL, cfg.Notify.Telegram.BotToken)
+	}
+
+	// 未配置 VAPID 私钥时不启用浏览器推送
+	if cfg.Notify.WebPush.VAPIDPrivateKey == "" {
+		return channels
+	}
+
+	// 推送服务通过 subject 联系站点管理员，未配置时依次使用站点地址和发件人邮箱
+	subject := cfg.Notify.WebPush.Subject
+	if subject == "" && cfg.Mail.SiteURL != "" {
+		subject = cfg.Mail.SiteURL
+	}
+	if subject == "" && cfg.Mail.From != "" {
+		subject = "mailto:" + cfg.Mail.From
+	}
+	webPush, err := notify.NewWebPush(targetClient, cfg.Notify.WebPush.VAPIDPrivateKey, subject)
+	if err != nil {
+		logger.Error("VAPID 私钥无效，浏览器推送不可用", "error", err)
+	} else {
+		channels[notify.KindWebPush] = webPush
+	}
+
+	return channels
+}
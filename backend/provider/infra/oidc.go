@@ -0,0 +1,10 @@
+package infra
+
+import (
+	"github.com/bookandmusic/love-girl/internal/config"
+	"github.com/bookandmusic/love-girl/internal/oidc"
+)
+
+func ProvideOIDCClient(cfg *config.AppConfig) *oidc.Client {
+	return oidc.NewClient(&cfg.OIDC)
+}
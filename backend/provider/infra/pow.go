@@ -0,0 +1,23 @@
+package infra
+
+import (
+	"time"
+
+	"github.com/bookandmusic/love-girl/internal/config"
+	"github.com/bookandmusic/love-girl/internal/pow"
+)
+
+const (
+	// powChallengeTTL 访客评论题目的有效期
+	powChallengeTTL = 10 * time.Minute
+	// defaultPowDifficulty 未配置时访客评论题目的难度
+	defaultPowDifficulty = 18
+)
+
+func ProvidePowIssuer(cfg *config.AppConfig) *pow.Issuer {
+	difficulty := cfg.Comment.PowDifficulty
+	if difficulty <= 0 {
+		difficulty = defaultPowDifficulty
+	}
+	return pow.NewIssuer(cfg.JWT.Secret, difficulty, powChallengeTTL)
+}
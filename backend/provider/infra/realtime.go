@@ -0,0 +1,27 @@
+package infra
+
+import (
+	"github.com/bookandmusic/love-girl/internal/config"
+	"github.com/bookandmusic/love-girl/internal/realtime"
+)
+
+const (
+	// defaultRealtimeConnections 未配置时每个用户的最大实时连接数
+	defaultRealtimeConnections = 5
+	// defaultRealtimeBuffer 未配置时每个用户保留的最近事件数
+	defaultRealtimeBuffer = 100
+)
+
+// ProvideRealtimeHub 创建实时事件中心，应用退出时结束所有连接
+func ProvideRealtimeHub(cfg *config.AppConfig) (*realtime.Hub, func()) {
+	maxConns := cfg.Realtime.MaxConnections
+	if maxConns <= 0 {
+		maxConns = defaultRealtimeConnections
+	}
+	bufferSize := cfg.Realtime.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultRealtimeBuffer
+	}
+	hub := realtime.NewHub(maxConns, bufferSize)
+	return hub, hub.Close
+}
@@ -0,0 +1,22 @@
+package infra
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/bookandmusic/love-girl/internal/config"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/search"
+)
+
+// ProvideSearchIndex 创建全文索引后端，依赖迁移结果保证 search_documents 表已存在
+func ProvideSearchIndex(cfg *config.AppConfig, db *gorm.DB, logger *log.Logger, migrateErr error) (search.Index, error) {
+	index, err := search.NewIndex(context.Background(), db, cfg.Search.Backend, logger)
+	if err != nil {
+		logger.Error("初始化全文索引失败", "backend", cfg.Search.Backend, "error", err)
+		return nil, err
+	}
+	logger.Info("全文索引已就绪", "backend", index.Name())
+	return index, nil
+}
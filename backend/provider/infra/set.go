@@ -13,4 +13,10 @@ var InfraSet = wire.NewSet(
 	ProvideAuthMiddleware,
 	ProvideDB,
 	ProvideMigrate,
+	ProvideMailQueue,
+	ProvideOIDCClient,
+	ProvideSearchIndex,
+	ProvidePowIssuer,
+	ProvideRealtimeHub,
+	ProvideNotifyChannels,
 )
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/google/wire"
+
+	"github.com/bookandmusic/love-girl/internal/job"
+	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/service"
+)
+
+func ProvideJobs(auditService *service.AuditService, notificationService *service.NotificationService, momentService *service.MomentService, searchService *service.SearchService, memoryService *service.MemoryService, anniversaryService *service.AnniversaryService, systemService *service.SystemService, notificationChannelService *service.NotificationChannelService, notificationDigestService *service.NotificationDigestService) []job.Job {
+	return []job.Job{
+		{
+			Name:     "audit-retention",
+			Interval: 24 * time.Hour,
+			Delay:    time.Minute,
+			Run:      auditService.PurgeExpired,
+		},
+		{
+			// 只清理已读通知，未读通知始终保留
+			Name:     "notification-retention",
+			Interval: 24 * time.Hour,
+			Delay:    2 * time.Minute,
+			Run:      notificationService.PurgeExpired,
+		},
+		{
+			Name:     "moment-scheduled-publish",
+			Interval: 30 * time.Second,
+			Delay:    5 * time.Second,
+			Run:      momentService.PublishDueMoments,
+		},
+		{
+			// 增量索引失败时依靠每日全量重建兜底
+			Name:     "search-reindex",
+			Interval: 24 * time.Hour,
+			Delay:    10 * time.Second,
+			Run:      searchService.Reindex,
+		},
+		{
+			// 每天只发送一次，按小时检查以便服务重启后补发
+			Name:     "memories-notify",
+			Interval: time.Hour,
+			Delay:    time.Minute,
+			Run:      memoryService.NotifyMemories,
+		},
+		{
+			// 与回忆通知相同，按小时检查以便服务重启后补发
+			Name:     "anniversary-remind",
+			Interval: time.Hour,
+			Delay:    time.Minute,
+			Run:      anniversaryService.NotifyUpcoming,
+		},
+		{
+			// 存储不可用时向全部用户发送系统告警
+			Name:     "storage-check",
+			Interval: 10 * time.Minute,
+			Delay:    2 * time.Minute,
+			Run:      systemService.CheckStorage,
+		},
+		{
+			// 站外通知发件箱，失败的投递按各自的重试时间再次投递
+			Name:     "notification-outbox",
+			Interval: 10 * time.Second,
+			Delay:    5 * time.Second,
+			Run:      notificationChannelService.ProcessOutbox,
+		},
+		{
+			// 按用户设置的时间发送通知摘要邮件
+			Name:     "notification-digest",
+			Interval: time.Minute,
+			Delay:    30 * time.Second,
+			Run:      notificationDigestService.SendDueDigests,
+		},
+	}
+}
+
+// ProvideJobRunner 启动后台任务，依赖迁移结果保证任务执行时表已存在
+func ProvideJobRunner(logger *log.Logger, jobs []job.Job, migrateErr error) (*job.Runner, func()) {
+	runner := job.NewRunner(logger, jobs)
+	runner.Start()
+	return runner, runner.Stop
+}
+
+var JobSet = wire.NewSet(
+	ProvideJobs,
+	ProvideJobRunner,
+)
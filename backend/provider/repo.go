@@ -17,4 +17,16 @@ var RepoSet = wire.NewSet(
 	repo.NewSettingRepo,
 	repo.NewCommentRepo,
 	repo.NewNotificationRepo,
+	repo.NewShareRepo,
+	repo.NewAPITokenRepo,
+	repo.NewAuditLogRepo,
+	repo.NewUserIdentityRepo,
+	repo.NewReactionRepo,
+	repo.NewTagRepo,
+	repo.NewMomentRevisionRepo,
+	repo.NewSearchDocumentRepo,
+	repo.NewCollectionRepo,
+	repo.NewNotificationChannelRepo,
+	repo.NewNotificationDeliveryRepo,
+	repo.NewNotificationDigestRepo,
 )
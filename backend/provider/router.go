@@ -9,8 +9,10 @@ import (
 	"github.com/bookandmusic/love-girl/docs"
 	"github.com/bookandmusic/love-girl/internal/config"
 	"github.com/bookandmusic/love-girl/internal/handler"
+	"github.com/bookandmusic/love-girl/internal/job"
 	"github.com/bookandmusic/love-girl/internal/log"
 	"github.com/bookandmusic/love-girl/internal/middleware"
+	"github.com/bookandmusic/love-girl/internal/realtime"
 	"github.com/bookandmusic/love-girl/internal/server"
 )
 
@@ -61,6 +63,8 @@ func ProvideApp(
 	logger *log.Logger,
 	engine *gin.Engine,
 	migrateErr error, // 添加 migrateErr 依赖
+	jobRunner *job.Runner, // 后台任务随应用启动，由 cleanup 停止
+	hub *realtime.Hub,
 ) *server.App {
 	// 如果迁移失败，我们可以在这里处理错误
 	if migrateErr != nil {
@@ -69,7 +73,10 @@ func ProvideApp(
 		// 但在依赖注入上下文中，我们通常只是返回应用实例
 	}
 
-	return server.NewApp(logger, engine, *cfg)
+	app := server.NewApp(logger, engine, *cfg)
+	// 关闭服务器时结束实时推送的长连接
+	app.OnShutdown(hub.Close)
+	return app
 }
 
 var RouterSet = wire.NewSet(
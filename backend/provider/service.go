@@ -6,13 +6,19 @@ import (
 	"github.com/bookandmusic/love-girl/internal/auth"
 	"github.com/bookandmusic/love-girl/internal/config"
 	"github.com/bookandmusic/love-girl/internal/log"
+	"github.com/bookandmusic/love-girl/internal/mail"
+	"github.com/bookandmusic/love-girl/internal/notify"
+	"github.com/bookandmusic/love-girl/internal/oidc"
+	"github.com/bookandmusic/love-girl/internal/pow"
+	"github.com/bookandmusic/love-girl/internal/realtime"
 	"github.com/bookandmusic/love-girl/internal/repo"
+	"github.com/bookandmusic/love-girl/internal/search"
 	"github.com/bookandmusic/love-girl/internal/service"
 	"github.com/bookandmusic/love-girl/internal/storage"
 )
 
-func ProvideUserService(log *log.Logger, userRepo *repo.UserRepo, fileRepo *repo.FileRepo, fileService *service.FileService, storage storage.Storage, cfg *config.AppConfig, jwt auth.JWT) *service.UserService {
-	return service.NewUserService(log, *userRepo, *fileRepo, fileService, storage, &cfg.Server, jwt)
+func ProvideUserService(log *log.Logger, userRepo *repo.UserRepo, fileRepo *repo.FileRepo, fileService *service.FileService, storage storage.Storage, cfg *config.AppConfig, jwt auth.JWT, auditService *service.AuditService) *service.UserService {
+	return service.NewUserService(log, *userRepo, *fileRepo, fileService, storage, &cfg.Server, jwt, auditService)
 }
 
 func ProvideFileService(log *log.Logger, storage storage.Storage, fileRepo *repo.FileRepo, cfg *config.AppConfig) *service.FileService {
@@ -29,32 +35,87 @@ func ProvideSystemService(
 	fileService *service.FileService,
 	cfg *config.AppConfig,
 	jwt auth.JWT,
+	auditService *service.AuditService,
+	notificationService *service.NotificationService,
 ) *service.SystemService {
-	return service.NewSystemService(log, *userRepo, *settingRepo, *albumRepo, *placeRepo, *momentRepo, fileService, cfg, jwt)
+	return service.NewSystemService(log, *userRepo, *settingRepo, *albumRepo, *placeRepo, *momentRepo, fileService, cfg, jwt, auditService, notificationService)
 }
 
-func ProvideAnniversaryService(log *log.Logger, anniversaryRepo *repo.AnniversaryRepo) *service.AnniversaryService {
-	return service.NewAnniversaryService(log, anniversaryRepo)
+func ProvideAnniversaryService(log *log.Logger, anniversaryRepo *repo.AnniversaryRepo, userRepo *repo.UserRepo, notificationRepo *repo.NotificationRepo, searchService *service.SearchService, notificationService *service.NotificationService) *service.AnniversaryService {
+	return service.NewAnniversaryService(log, anniversaryRepo, userRepo, notificationRepo, searchService, notificationService)
 }
 
-func ProvideMomentService(log *log.Logger, momentRepo *repo.MomentRepo, commentRepo *repo.CommentRepo, fileService *service.FileService) *service.MomentService {
-	return service.NewMomentService(log, momentRepo, commentRepo, fileService)
+func ProvideMomentService(log *log.Logger, momentRepo *repo.MomentRepo, commentRepo *repo.CommentRepo, reactionRepo *repo.ReactionRepo, userRepo *repo.UserRepo, tagRepo *repo.TagRepo, revisionRepo *repo.MomentRevisionRepo, placeRepo *repo.PlaceRepo, anniversaryRepo *repo.AnniversaryRepo, fileService *service.FileService, notificationService *service.NotificationService, searchService *service.SearchService, auditService *service.AuditService, appCfg *config.AppConfig) *service.MomentService {
+	return service.NewMomentService(log, momentRepo, commentRepo, reactionRepo, userRepo, tagRepo, revisionRepo, placeRepo, anniversaryRepo, fileService, notificationService, searchService, auditService, appCfg)
 }
 
-func ProvidePlaceService(log *log.Logger, placeRepo *repo.PlaceRepo, fileService *service.FileService) *service.PlaceService {
-	return service.NewPlaceService(log, placeRepo, fileService)
+func ProvidePlaceService(log *log.Logger, placeRepo *repo.PlaceRepo, fileService *service.FileService, searchService *service.SearchService) *service.PlaceService {
+	return service.NewPlaceService(log, placeRepo, fileService, searchService)
 }
 
-func ProvideAlbumService(log *log.Logger, albumRepo *repo.AlbumRepo, fileService *service.FileService) *service.AlbumService {
-	return service.NewAlbumService(log, albumRepo, fileService)
+func ProvideAlbumService(log *log.Logger, albumRepo *repo.AlbumRepo, userRepo *repo.UserRepo, fileService *service.FileService, searchService *service.SearchService, notificationService *service.NotificationService) *service.AlbumService {
+	return service.NewAlbumService(log, albumRepo, userRepo, fileService, searchService, notificationService)
 }
 
-func ProvideCommentService(log *log.Logger, commentRepo *repo.CommentRepo, momentRepo *repo.MomentRepo, notificationRepo *repo.NotificationRepo, fileService *service.FileService, notificationService *service.NotificationService) *service.CommentService {
-	return service.NewCommentService(log, commentRepo, momentRepo, notificationRepo, fileService, notificationService)
+func ProvideCommentService(log *log.Logger, commentRepo *repo.CommentRepo, momentRepo *repo.MomentRepo, notificationRepo *repo.NotificationRepo, fileService *service.FileService, notificationService *service.NotificationService, searchService *service.SearchService, moderationService *service.ModerationService, challenge *pow.Issuer, cfg *config.AppConfig) *service.CommentService {
+	return service.NewCommentService(log, commentRepo, momentRepo, notificationRepo, fileService, notificationService, searchService, moderationService, challenge, cfg)
 }
 
-func ProvideNotificationService(log *log.Logger, notificationRepo *repo.NotificationRepo, fileService *service.FileService) *service.NotificationService {
-	return service.NewNotificationService(log, notificationRepo, fileService)
+func ProvideModerationService(log *log.Logger, commentRepo *repo.CommentRepo, cfg *config.AppConfig) *service.ModerationService {
+	return service.NewModerationService(log, commentRepo, cfg)
+}
+
+func ProvideSearchService(log *log.Logger, index search.Index, documentRepo *repo.SearchDocumentRepo, momentRepo *repo.MomentRepo, commentRepo *repo.CommentRepo, albumRepo *repo.AlbumRepo, placeRepo *repo.PlaceRepo, anniversaryRepo *repo.AnniversaryRepo) *service.SearchService {
+	return service.NewSearchService(log, index, documentRepo, momentRepo, commentRepo, albumRepo, placeRepo, anniversaryRepo)
+}
+
+func ProvideNotificationService(log *log.Logger, notificationRepo *repo.NotificationRepo, channelRepo *repo.NotificationChannelRepo, digestRepo *repo.NotificationDigestRepo, settingRepo *repo.SettingRepo, fileService *service.FileService, hub *realtime.Hub, channels notify.Channels) *service.NotificationService {
+	return service.NewNotificationService(log, notificationRepo, channelRepo, digestRepo, settingRepo, fileService, hub, channels)
+}
+
+func ProvideNotificationChannelService(log *log.Logger, channelRepo *repo.NotificationChannelRepo, deliveryRepo *repo.NotificationDeliveryRepo, userRepo *repo.UserRepo, settingRepo *repo.SettingRepo, channels notify.Channels, cfg *config.AppConfig) *service.NotificationChannelService {
+	return service.NewNotificationChannelService(log, channelRepo, deliveryRepo, userRepo, settingRepo, channels, cfg)
+}
+
+func ProvideNotificationDigestService(log *log.Logger, digestRepo *repo.NotificationDigestRepo, notificationRepo *repo.NotificationRepo, userRepo *repo.UserRepo, settingRepo *repo.SettingRepo, mailQueue *mail.Queue, cfg *config.AppConfig) *service.NotificationDigestService {
+	return service.NewNotificationDigestService(log, digestRepo, notificationRepo, userRepo, settingRepo, mailQueue, cfg)
+}
+
+func ProvideShareService(log *log.Logger, shareRepo *repo.ShareRepo, albumRepo *repo.AlbumRepo, momentRepo *repo.MomentRepo, fileService *service.FileService, auditService *service.AuditService) *service.ShareService {
+	return service.NewShareService(log, shareRepo, albumRepo, momentRepo, fileService, auditService)
+}
+
+func ProvideAPITokenService(log *log.Logger, apiTokenRepo *repo.APITokenRepo, userRepo *repo.UserRepo, auditService *service.AuditService) *service.APITokenService {
+	return service.NewAPITokenService(log, apiTokenRepo, userRepo, auditService)
+}
+
+func ProvidePasswordResetService(log *log.Logger, userRepo *repo.UserRepo, settingRepo *repo.SettingRepo, mailQueue *mail.Queue, cfg *config.AppConfig, auditService *service.AuditService) *service.PasswordResetService {
+	return service.NewPasswordResetService(log, userRepo, settingRepo, mailQueue, cfg, auditService)
+}
+
+func ProvideFeedService(log *log.Logger, momentRepo *repo.MomentRepo, settingRepo *repo.SettingRepo, tagRepo *repo.TagRepo, fileService *service.FileService, cfg *config.AppConfig) *service.FeedService {
+	return service.NewFeedService(log, momentRepo, settingRepo, tagRepo, fileService, cfg)
+}
+
+func ProvideAuditService(log *log.Logger, auditLogRepo *repo.AuditLogRepo, userRepo *repo.UserRepo, settingRepo *repo.SettingRepo) *service.AuditService {
+	return service.NewAuditService(log, auditLogRepo, userRepo, settingRepo)
+}
+
+func ProvideOIDCService(log *log.Logger, client *oidc.Client, userRepo *repo.UserRepo, identityRepo *repo.UserIdentityRepo, cfg *config.AppConfig, jwt auth.JWT, auditService *service.AuditService) *service.OIDCService {
+	return service.NewOIDCService(log, client, userRepo, identityRepo, &cfg.OIDC, jwt, auditService)
+}
+
+// ProvideTokenVerifier 认证中间件通过该接口校验个人访问令牌和登录令牌的版本
+func ProvideTokenVerifier(svc *service.APITokenService) auth.TokenVerifier {
+	return svc
+}
+
+func ProvideMemoryService(log *log.Logger, momentRepo *repo.MomentRepo, albumRepo *repo.AlbumRepo, placeRepo *repo.PlaceRepo, anniversaryRepo *repo.AnniversaryRepo, userRepo *repo.UserRepo, notificationRepo *repo.NotificationRepo, momentService *service.MomentService, albumService *service.AlbumService, placeService *service.PlaceService, anniversaryService *service.AnniversaryService, notificationService *service.NotificationService) *service.MemoryService {
+	return service.NewMemoryService(log, momentRepo, albumRepo, placeRepo, anniversaryRepo, userRepo, notificationRepo, momentService, albumService, placeService, anniversaryService, notificationService)
+}
+
+func ProvideCollectionService(log *log.Logger, collectionRepo *repo.CollectionRepo, momentRepo *repo.MomentRepo, momentService *service.MomentService, fileService *service.FileService) *service.CollectionService {
+	return service.NewCollectionService(log, collectionRepo, momentRepo, momentService, fileService)
 }
 
 var ServiceSet = wire.NewSet(
@@ -66,5 +127,18 @@ var ServiceSet = wire.NewSet(
 	ProvidePlaceService,
 	ProvideAlbumService,
 	ProvideCommentService,
+	ProvideModerationService,
+	ProvideSearchService,
 	ProvideNotificationService,
+	ProvideNotificationChannelService,
+	ProvideNotificationDigestService,
+	ProvideShareService,
+	ProvideAPITokenService,
+	ProvideTokenVerifier,
+	ProvidePasswordResetService,
+	ProvideAuditService,
+	ProvideOIDCService,
+	ProvideMemoryService,
+	ProvideCollectionService,
+	ProvideFeedService,
 )
@@ -26,6 +26,8 @@ func InitApp() (*server.App, func(), error) {
 		HandlerSet,
 		// router (includes GinEngine, Router, and App)
 		RouterSet,
+		// background jobs
+		JobSet,
 	)
 	return nil, nil, nil
 }
@@ -22,51 +22,103 @@ func InitApp() (*server.App, func(), error) {
 	logger := infra.ProvideLogger(appConfig)
 	ginEngine := ProvideGinEngine(appConfig, logger)
 	jwt := infra.ProvideJWT(appConfig)
-	authMiddleware := infra.ProvideAuthMiddleware(jwt)
 	gormLogger := infra.ProvideGormLogger(appConfig, logger)
 	db, err := infra.ProvideDB(appConfig, gormLogger)
 	if err != nil {
 		return nil, nil, err
 	}
+	apiTokenRepo := repo.NewAPITokenRepo(db)
+	auditLogRepo := repo.NewAuditLogRepo(db)
 	userRepo := repo.NewUserRepo(db, jwt)
+	settingRepo := repo.NewSettingRepo(db)
+	auditService := ProvideAuditService(logger, auditLogRepo, userRepo, settingRepo)
+	apiTokenService := ProvideAPITokenService(logger, apiTokenRepo, userRepo, auditService)
+	tokenVerifier := ProvideTokenVerifier(apiTokenService)
+	authMiddleware := infra.ProvideAuthMiddleware(jwt, tokenVerifier)
 	fileRepo := repo.NewFileRepo(db)
 	storage, err := ProvideStorage(appConfig, logger)
 	if err != nil {
 		return nil, nil, err
 	}
 	fileService := ProvideFileService(logger, storage, fileRepo, appConfig)
-	userService := ProvideUserService(logger, userRepo, fileRepo, fileService, storage, appConfig, jwt)
+	userService := ProvideUserService(logger, userRepo, fileRepo, fileService, storage, appConfig, jwt, auditService)
 	userHandler := ProvideUserHandler(userService)
 	healthHandler := ProvideHealthHandler()
 	fileHandler := ProvideFileHandler(fileService)
-	settingRepo := repo.NewSettingRepo(db)
 	albumRepo := repo.NewAlbumRepo(db)
 	placeRepo := repo.NewPlaceRepo(db)
 	momentRepo := repo.NewMomentRepo(db)
-	systemService := ProvideSystemService(logger, userRepo, settingRepo, albumRepo, placeRepo, momentRepo, fileService, appConfig, jwt)
+	notificationRepo := repo.NewNotificationRepo(db)
+	notificationChannelRepo := repo.NewNotificationChannelRepo(db)
+	notificationDigestRepo := repo.NewNotificationDigestRepo(db)
+	hub, cleanup := infra.ProvideRealtimeHub(appConfig)
+	channels := infra.ProvideNotifyChannels(appConfig, logger)
+	notificationService := ProvideNotificationService(logger, notificationRepo, notificationChannelRepo, notificationDigestRepo, settingRepo, fileService, hub, channels)
+	systemService := ProvideSystemService(logger, userRepo, settingRepo, albumRepo, placeRepo, momentRepo, fileService, appConfig, jwt, auditService, notificationService)
 	systemHandler := ProvideSystemHandler(systemService)
 	commentRepo := repo.NewCommentRepo(db)
-	momentService := ProvideMomentService(logger, momentRepo, commentRepo, fileService)
-	momentHandler := ProvideMomentHandler(momentService)
+	reactionRepo := repo.NewReactionRepo(db)
+	tagRepo := repo.NewTagRepo(db)
+	momentRevisionRepo := repo.NewMomentRevisionRepo(db)
 	anniversaryRepo := repo.NewAnniversaryRepo(db)
-	anniversaryService := ProvideAnniversaryService(logger, anniversaryRepo)
+	error2 := infra.ProvideMigrate(db, logger)
+	index, err := infra.ProvideSearchIndex(appConfig, db, logger, error2)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	searchDocumentRepo := repo.NewSearchDocumentRepo(db)
+	searchService := ProvideSearchService(logger, index, searchDocumentRepo, momentRepo, commentRepo, albumRepo, placeRepo, anniversaryRepo)
+	momentService := ProvideMomentService(logger, momentRepo, commentRepo, reactionRepo, userRepo, tagRepo, momentRevisionRepo, placeRepo, anniversaryRepo, fileService, notificationService, searchService, auditService, appConfig)
+	momentHandler := ProvideMomentHandler(momentService)
+	anniversaryService := ProvideAnniversaryService(logger, anniversaryRepo, userRepo, notificationRepo, searchService, notificationService)
 	anniversaryHandler := ProvideAnniversaryHandler(anniversaryService)
-	placeService := ProvidePlaceService(logger, placeRepo, fileService)
+	placeService := ProvidePlaceService(logger, placeRepo, fileService, searchService)
 	placeHandler := ProvidePlaceHandler(placeService)
-	albumService := ProvideAlbumService(logger, albumRepo, fileService)
+	albumService := ProvideAlbumService(logger, albumRepo, userRepo, fileService, searchService, notificationService)
 	albumHandler := ProvideAlbumHandler(albumService)
-	notificationRepo := repo.NewNotificationRepo(db)
-	notificationService := ProvideNotificationService(logger, notificationRepo, fileService)
-	commentService := ProvideCommentService(logger, commentRepo, momentRepo, notificationRepo, fileService, notificationService)
+	moderationService := ProvideModerationService(logger, commentRepo, appConfig)
+	issuer := infra.ProvidePowIssuer(appConfig)
+	commentService := ProvideCommentService(logger, commentRepo, momentRepo, notificationRepo, fileService, notificationService, searchService, moderationService, issuer, appConfig)
 	commentHandler := ProvideCommentHandler(commentService)
 	notificationHandler := ProvideNotificationHandler(notificationService)
-	v := ProvideHandlers(userHandler, healthHandler, fileHandler, systemHandler, momentHandler, anniversaryHandler, placeHandler, albumHandler, commentHandler, notificationHandler)
+	notificationDeliveryRepo := repo.NewNotificationDeliveryRepo(db)
+	notificationChannelService := ProvideNotificationChannelService(logger, notificationChannelRepo, notificationDeliveryRepo, userRepo, settingRepo, channels, appConfig)
+	notificationChannelHandler := ProvideNotificationChannelHandler(notificationChannelService)
+	queue, cleanup2 := infra.ProvideMailQueue(appConfig, logger)
+	notificationDigestService := ProvideNotificationDigestService(logger, notificationDigestRepo, notificationRepo, userRepo, settingRepo, queue, appConfig)
+	notificationDigestHandler := ProvideNotificationDigestHandler(notificationDigestService)
+	shareRepo := repo.NewShareRepo(db)
+	shareService := ProvideShareService(logger, shareRepo, albumRepo, momentRepo, fileService, auditService)
+	shareHandler := ProvideShareHandler(shareService, fileHandler)
+	apiTokenHandler := ProvideAPITokenHandler(apiTokenService)
+	passwordResetService := ProvidePasswordResetService(logger, userRepo, settingRepo, queue, appConfig, auditService)
+	passwordResetHandler := ProvidePasswordResetHandler(passwordResetService)
+	auditHandler := ProvideAuditHandler(auditService)
+	client := infra.ProvideOIDCClient(appConfig)
+	userIdentityRepo := repo.NewUserIdentityRepo(db)
+	oidcService := ProvideOIDCService(logger, client, userRepo, userIdentityRepo, appConfig, jwt, auditService)
+	oidcHandler := ProvideOIDCHandler(oidcService)
+	searchHandler := ProvideSearchHandler(searchService)
+	memoryService := ProvideMemoryService(logger, momentRepo, albumRepo, placeRepo, anniversaryRepo, userRepo, notificationRepo, momentService, albumService, placeService, anniversaryService, notificationService)
+	memoryHandler := ProvideMemoryHandler(memoryService)
+	collectionRepo := repo.NewCollectionRepo(db)
+	collectionService := ProvideCollectionService(logger, collectionRepo, momentRepo, momentService, fileService)
+	collectionHandler := ProvideCollectionHandler(collectionService)
+	v := ProvideHandlers(userHandler, healthHandler, fileHandler, systemHandler, momentHandler, anniversaryHandler, placeHandler, albumHandler, commentHandler, notificationHandler, notificationChannelHandler, notificationDigestHandler, shareHandler, apiTokenHandler, passwordResetHandler, auditHandler, oidcHandler, searchHandler, memoryHandler, collectionHandler)
 	staticHandler := ProvideStaticHandler()
 	swaggerHandler := ProvideSwaggerHandler()
-	v2 := ProvideStaticHandlers(staticHandler, swaggerHandler)
+	feedService := ProvideFeedService(logger, momentRepo, settingRepo, tagRepo, fileService, appConfig)
+	feedHandler := ProvideFeedHandler(feedService)
+	notificationStreamHandler := ProvideNotificationStreamHandler(notificationService, authMiddleware, appConfig)
+	v2 := ProvideStaticHandlers(staticHandler, swaggerHandler, feedHandler, notificationStreamHandler)
 	engine := ProvideRouter(appConfig, ginEngine, authMiddleware, v, v2)
-	error2 := infra.ProvideMigrate(db, logger)
-	app := ProvideApp(appConfig, logger, engine, error2)
+	v3 := ProvideJobs(auditService, notificationService, momentService, searchService, memoryService, anniversaryService, systemService, notificationChannelService, notificationDigestService)
+	runner, cleanup3 := ProvideJobRunner(logger, v3, error2)
+	app := ProvideApp(appConfig, logger, engine, error2, runner, hub)
 	return app, func() {
+		cleanup3()
+		cleanup2()
+		cleanup()
 	}, nil
 }